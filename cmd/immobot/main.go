@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -43,6 +45,7 @@ func main() {
 	configPath := flag.String("config", "configs/config.yaml", "Path to configuration file")
 	runOnce := flag.Bool("once", false, "Run a single poll cycle and exit")
 	healthcheck := flag.Bool("healthcheck", false, "Check poll heartbeat freshness and exit (0=healthy)")
+	importPath := flag.String("import-profiles", "", "Create search profiles from a file of IS24 search URLs (one \"[campaign] <url> [name]\" per line) and exit")
 	flag.Parse()
 
 	// Setup logging
@@ -80,6 +83,16 @@ func main() {
 		"contact_enabled", cfg.Contact.Enabled,
 	)
 
+	// Build per-campaign personalization (message template + AI prompt + contact
+	// profile) and a resolver the scheduler uses per listing. Done right after
+	// validation: a template with a misspelled field stops the bot here instead
+	// of failing every message.
+	resolver, err := newCampaignResolver(cfg, logger)
+	if err != nil {
+		logger.Error("failed to build campaigns", "error", err)
+		os.Exit(1)
+	}
+
 	// Ensure data directory exists
 	dataDir := filepath.Dir(cfg.DatabasePath)
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
@@ -88,7 +101,7 @@ func main() {
 	}
 
 	// Initialize repository
-	repo, err := sqlite.New(cfg.DatabasePath)
+	repo, err := openRepository(cfg)
 	if err != nil {
 		logger.Error("failed to initialize database", "error", err)
 		os.Exit(1)
@@ -97,13 +110,39 @@ func main() {
 
 	logger.Info("database initialized", "path", cfg.DatabasePath)
 
-	// Initialize anti-detection components
+	// Import mode: create profiles from a list of saved-search URLs, then exit.
+	if *importPath != "" {
+		os.Exit(runProfileImport(repo, cfg, *importPath, logger))
+	}
+
+	// Initialize anti-detection components. The rate limiter is the one
+	// outbound queue for everything IS24-bound (search, exposes, contacts);
+	// is24.rate_overrides give matching search profiles their own budget.
 	rateLimiter := antidetect.NewRateLimiter(
 		cfg.IS24.MaxRequestsPerMinute,
+		cfg.IS24.Burst,
 		cfg.IS24.MinDelay,
 		cfg.IS24.MaxDelay,
 	)
+	rateLimiter.SetAdaptive(cfg.IS24.AdaptiveRate)
+	for key := range cfg.IS24.RateOverrides {
+		rl := cfg.IS24.EffectiveRateLimit(key)
+		override := antidetect.NewRateLimiter(rl.MaxRequestsPerMinute, rl.Burst, rl.MinDelay, rl.MaxDelay)
+		override.SetAdaptive(cfg.IS24.AdaptiveRate)
+		rateLimiter.SetOverride(key, override)
+		logger.Info("rate override", "key", key, "max_requests_per_minute", rl.MaxRequestsPerMinute,
+			"burst", rl.Burst, "min_delay", rl.MinDelay, "max_delay", rl.MaxDelay)
+	}
 	humanBehavior := antidetect.NewHumanBehavior(cfg.Contact.TypeDelay, cfg.Contact.ActionDelay)
+	transport, err := antidetect.NewTransport(antidetect.TransportOptions{
+		LocalAddr:         cfg.IS24.LocalAddr,
+		MaxIdleConns:      cfg.IS24.MaxIdleConns,
+		DisableKeepAlives: cfg.IS24.DisableKeepAlives,
+	})
+	if err != nil {
+		logger.Error("failed to build IS24 transport", "error", err)
+		os.Exit(1)
+	}
 
 	// A previously hot-reloaded IS24 cookie (saved in the meta table via the
 	// dashboard / Telegram /cookie command) overrides the env-supplied one so
@@ -115,10 +154,23 @@ func main() {
 
 	// Initialize IS24 browser client (uses chromedp to bypass WAF)
 	is24Client := is24.NewBrowserClient(cfg.IS24.Cookie, rateLimiter, cfg.Contact.ChromePath)
+	if err := is24Client.SetSite(is24.Site{BaseURL: cfg.IS24.BaseURL, SearchPath: cfg.IS24.SearchPath}); err != nil {
+		logger.Error("invalid IS24 site", "error", err)
+		os.Exit(1)
+	}
+	is24Client.SetChallenge(is24.Challenge{
+		Titles:        cfg.IS24.Challenge.Titles,
+		ReadySelector: cfg.IS24.Challenge.ReadySelector,
+		Timeout:       cfg.IS24.Challenge.Timeout,
+	})
 	logger.Info("IS24 browser client initialized")
 
 	// Initialize filter engine
 	filterEngine := filter.NewEngine()
+	filterEngine.SetWarmRentFactor(cfg.Filter.WarmRentFactor)
+	filterEngine.SetPriceFloor(cfg.Filter.MinPlausiblePrice, cfg.Filter.ImplausiblePrice == config.ImplausiblePriceDrop)
+	filterEngine.SetDropPriceOnRequest(cfg.Filter.PriceOnRequest == config.PriceOnRequestDrop)
+	filterEngine.SetScoreWeights(filter.ScoreWeights(cfg.Filter.ScoreWeights))
 
 	// Shared, transport-neutral control state (contact mode, quiet hours).
 	// Defaults come from config.yaml; persisted overrides loaded from the
@@ -137,6 +189,9 @@ func main() {
 		os.Exit(1)
 	}
 	tgNotifier := telegram.NewNotifierFromController(botController)
+	tgNotifier.SetRetry(cfg.Telegram.SendRetries, cfg.Telegram.SendRetryBackoff)
+	tgNotifier.SetLogger(logger)
+	tgNotifier.SetCompact(cfg.Telegram.Style == config.TelegramStyleCompact)
 
 	// Initialize WhatsApp channel (notifications + commands via whatsmeow)
 	waClient, err := whatsapp.New(context.Background(), cfg.WhatsApp, ctrl, logger)
@@ -151,20 +206,39 @@ func main() {
 	// Initialize OpenAI enhancer
 	var enhancer scheduler.MessageEnhancer
 	if cfg.OpenAI.Enabled && cfg.OpenAI.APIKey != "" {
-		enhancer = messenger.NewOpenAIEnhancer(cfg.OpenAI.APIKey, cfg.OpenAI.Model, cfg.OpenAI.Enabled)
-		logger.Info("OpenAI message enhancement enabled", "model", cfg.OpenAI.Model)
+		oe := messenger.NewOpenAIEnhancer(cfg.OpenAI.APIKey, cfg.OpenAI.Model, cfg.OpenAI.Enabled)
+		oe.SetParams(cfg.OpenAI.Timeout, cfg.OpenAI.MaxTokens, cfg.OpenAI.Temperature)
+		oe.SetStructuredOutput(cfg.OpenAI.StructuredOutput)
+		oe.SetRateLimit(cfg.OpenAI.MaxConcurrent, cfg.OpenAI.MinInterval)
+		if cfg.OpenAI.DistrictInfo {
+			districts, err := messenger.LoadDistrictInfo(cfg.OpenAI.DistrictInfoPath)
+			if err != nil {
+				logger.Error("failed to load district info", "error", err)
+				os.Exit(1)
+			}
+			oe.SetDistrictInfo(districts)
+		}
+		enhancer = oe
+		logger.Info("OpenAI message enhancement enabled", "model", cfg.OpenAI.Model,
+			"timeout", cfg.OpenAI.Timeout, "max_tokens", cfg.OpenAI.MaxTokens, "temperature", cfg.OpenAI.Temperature,
+			"max_concurrent", cfg.OpenAI.MaxConcurrent, "min_interval", cfg.OpenAI.MinInterval)
 	}
 
 	// Initialize contact submitter. When OpenAI is configured, wire an LLM
 	// form-filler as fallback for when the static selectors miss IS24's DOM.
-	var contacter *contact.Submitter
-	if cfg.Contact.Enabled {
+	// contact.dry_run swaps in a submitter that never sends anything.
+	var contacter scheduler.ContactSubmitter
+	switch {
+	case cfg.Contact.Enabled && cfg.Contact.DryRun:
+		contacter = contact.NewNoopSubmitter(logger)
+		logger.Warn("contact dry run: messages are recorded as sent but no form is submitted")
+	case cfg.Contact.Enabled:
 		var mapper contact.FieldMapper
 		if cfg.OpenAI.Enabled && cfg.OpenAI.APIKey != "" {
 			mapper = messenger.NewOpenAIFormFiller(cfg.OpenAI.APIKey, cfg.OpenAI.Model)
 			logger.Info("contact form llm fallback enabled", "model", cfg.OpenAI.Model)
 		}
-		contacter = contact.NewSubmitter(
+		submitter := contact.NewSubmitter(
 			cfg.IS24.Cookie,
 			toContactProfile(cfg.Contact.Profile),
 			cfg.Contact.ChromePath,
@@ -172,17 +246,17 @@ func main() {
 			mapper,
 			logger,
 		)
+		submitter.SetHTTPFirst(cfg.Contact.HTTPFirst)
+		submitter.SetFormTimeout(cfg.Contact.FormTimeout)
+		submitter.SetDebugSelectors(cfg.Contact.DebugSelectors)
+		submitter.SetSubject(cfg.Contact.Subject, cfg.Contact.MessageCategory)
+		submitter.SetBaseURL(cfg.IS24.BaseURL)
+		submitter.SetRateLimiter(rateLimiter)
+		submitter.SetTransport(transport)
+		contacter = submitter
 		logger.Info("auto-contact ready (controlled via Telegram)")
 	}
 
-	// Build per-campaign personalization (message template + AI prompt + contact
-	// profile) and a resolver the scheduler uses per listing.
-	resolver, err := newCampaignResolver(cfg, logger)
-	if err != nil {
-		logger.Error("failed to build campaigns", "error", err)
-		os.Exit(1)
-	}
-
 	// Create scheduler
 	sched := scheduler.NewScheduler(
 		cfg,
@@ -208,7 +282,9 @@ func main() {
 			Lookback: cfg.Email.Lookback,
 		})
 		classifier := messenger.NewOpenAIEmailClassifier(cfg.OpenAI.APIKey, cfg.OpenAI.Model)
-		sched.SetEmailMonitor(email.NewMonitor(emailClient, classifier, repo, notif, logger))
+		monitor := email.NewMonitor(emailClient, classifier, repo, notif, logger)
+		monitor.SetBaseURL(cfg.IS24.BaseURL)
+		sched.SetEmailMonitor(monitor)
 		logger.Info("email inbox monitor enabled", "mailbox", cfg.Email.Mailbox, "host", cfg.Email.IMAPHost)
 	}
 
@@ -228,7 +304,8 @@ func main() {
 	ctrl.SetCallbacks(
 		func() string {
 			profiles, _ := repo.GetActiveSearchProfiles(context.Background())
-			return fmt.Sprintf("*Aktive Suchprofile:* %d", len(profiles))
+			return fmt.Sprintf("*Aktive Suchprofile:* %d\n\n%s", len(profiles),
+				formatRateStats(rateLimiter.Stats(), rateLimiter.OverrideStats(), cfg.IS24.AdaptiveRate))
 		},
 		func() string {
 			total, contacted, notified := sched.GetStats(context.Background())
@@ -240,6 +317,323 @@ func main() {
 		},
 	)
 
+	// /config: the effective configuration, secrets redacted.
+	ctrl.SetConfigCallback(func() string {
+		ctx := context.Background()
+		profiles, _ := repo.GetActiveSearchProfiles(ctx)
+		cookie := cfg.IS24.Cookie
+		if v, _ := repo.GetMeta(ctx, sqlite.MetaIS24Cookie); v != "" {
+			cookie = v // hot-reloaded via /cookie or the dashboard
+		}
+		return formatConfigReport(cfg, ctrl, len(profiles), cookie != "")
+	})
+
+	// /ping: can the bot still reach its Telegram chat?
+	ctrl.SetPingCallback(func() string {
+		if !botController.IsEnabled() {
+			return "🏓 Pong\n\nTelegram ist nicht aktiviert."
+		}
+		res, err := botController.SelfTest()
+		if err != nil {
+			return "🏓 Pong\n\n❌ Telegram: " + err.Error()
+		}
+		return fmt.Sprintf("🏓 Pong\n\n✅ Bot @%s schreibt in „%s“ (Chat %d).", res.Username, res.Chat, cfg.Telegram.ChatID)
+	})
+
+	// /funnel: seen → passed → notified → contacted over the last 7 days.
+	ctrl.SetFunnelCallback(func() string {
+		f, err := repo.GetFunnelStats(context.Background(), 7*24*time.Hour)
+		if err != nil {
+			return "❌ Funnel laden fehlgeschlagen: " + err.Error()
+		}
+		var sb strings.Builder
+		sb.WriteString("🔻 *Funnel (7 Tage)*\n")
+		sb.WriteString(fmt.Sprintf("\n*Gesehen:* %d", f.Seen))
+		sb.WriteString(fmt.Sprintf("\n*Filter bestanden:* %d", f.Passed))
+		sb.WriteString(fmt.Sprintf("\n*Benachrichtigt:* %d", f.Notified))
+		sb.WriteString(fmt.Sprintf("\n*Kontaktiert:* %d", f.Contacted))
+		if len(f.DropReasons) > 0 {
+			sb.WriteString("\n\n*Häufigste Ausschlussgründe:*")
+			for _, d := range f.DropReasons {
+				sb.WriteString(fmt.Sprintf("\n%d× %s", d.Count, d.Reason))
+			}
+		}
+		return sb.String()
+	})
+
+	// /diff: new, gone and repriced listings between the last two cycles.
+	ctrl.SetDiffCallback(func() string {
+		d, err := sched.Diff(context.Background())
+		if err != nil {
+			return "❌ Vergleich laden fehlgeschlagen: " + err.Error()
+		}
+		if d == nil {
+			return "Noch keine zwei Durchläufe zum Vergleichen."
+		}
+		return formatCycleDiff(d, cfg.QuietHours.Timezone)
+	})
+
+	// /summary: the daily digest on demand.
+	ctrl.SetSummaryCallback(func() string {
+		text, err := sched.Digest(context.Background())
+		if err != nil {
+			return "❌ Zusammenfassung laden fehlgeschlagen: " + err.Error()
+		}
+		return text
+	})
+
+	// /backup: a database snapshot on demand, also when the periodic one is off.
+	ctrl.SetBackupCallback(func() string {
+		path, size, err := backup.Now(context.Background(), repo, cfg.Backup, logger)
+		if err != nil {
+			logger.Error("backup failed", "error", err)
+			return "❌ Backup fehlgeschlagen: " + err.Error()
+		}
+		return fmt.Sprintf("💾 *Backup angelegt*\n`%s` (%.1f MB)", path, float64(size)/(1<<20))
+	})
+
+	// /selftest: one search plus one expose through the whole scraping stack,
+	// nothing saved or notified. The report follows once it is done.
+	ctrl.SetSelfTestCallback(func() string {
+		started := sched.TriggerSelfTest(context.Background(), func(r scheduler.SelfTestReport) {
+			notif.SendRawMessage(context.Background(), formatSelfTestReport(r))
+		})
+		if !started {
+			return "⏳ Es läuft bereits eine Suche, bitte kurz warten."
+		}
+		return "🩺 *Selbsttest gestartet* — Ergebnis folgt."
+	})
+
+	// /preview: generate the contact message for one listing and send it as a
+	// preview. Template + AI can take a while, so reply right away.
+	ctrl.SetPreviewCallback(func(is24ID string) string {
+		go func() {
+			if err := sched.PreviewMessage(context.Background(), is24ID); err != nil {
+				logger.Warn("message preview failed", "is24_id", is24ID, "error", err)
+				notif.SendRawMessage(context.Background(), fmt.Sprintf("❌ Vorschau für %s fehlgeschlagen: %s", is24ID, err))
+			}
+		}()
+		return fmt.Sprintf("📝 Nachricht für %s wird erstellt …", is24ID)
+	})
+
+	// /scan: one-off search of an IS24 URL without profile or filters. The
+	// report follows once the search is done; /scan_save keeps the new hits.
+	ctrl.SetScanCallbacks(
+		func(searchURL string) string {
+			started := sched.TriggerScan(context.Background(), searchURL, func(hits []scheduler.ScanHit, err error) {
+				if err != nil {
+					notif.SendRawMessage(context.Background(), "❌ Scan fehlgeschlagen: "+err.Error())
+					return
+				}
+				notif.SendRawMessage(context.Background(), formatScanReport(hits))
+			})
+			if !started {
+				return "⏳ Es läuft bereits eine Suche, bitte kurz warten."
+			}
+			return "🔎 *Scan gestartet* — Ergebnis folgt."
+		},
+		func() string {
+			n, err := sched.SaveScan(context.Background())
+			if errors.Is(err, scheduler.ErrPollInProgress) {
+				return "⏳ Es läuft gerade eine Suche, bitte gleich noch einmal."
+			}
+			if err != nil {
+				logger.Error("saving scan failed", "error", err)
+				return fmt.Sprintf("❌ Speichern fehlgeschlagen nach %d Wohnung(en): %s", n, err)
+			}
+			if n == 0 {
+				return "Nichts zu speichern — erst /scan <URL> ausführen (oder alle Treffer sind schon bekannt)."
+			}
+			return fmt.Sprintf("💾 *%d Wohnung(en) gespeichert* — sie laufen ab jetzt wie gefundene Wohnungen (inkl. Auto-Kontakt).", n)
+		},
+	)
+
+	// /mark_contacted, /mark_uncontacted: keep the contacted flag in sync with
+	// landlords the user reached on their own.
+	ctrl.SetMarkContactedCallback(func(is24ID string, contacted bool) string {
+		ctx := context.Background()
+		listing, err := repo.GetListingByIS24ID(ctx, is24ID)
+		if err != nil {
+			return "❌ Wohnung laden fehlgeschlagen: " + err.Error()
+		}
+		if listing == nil {
+			return fmt.Sprintf("❌ Wohnung %s ist nicht in der Datenbank.", is24ID)
+		}
+		action := domain.ActionMarkedContacted
+		if contacted {
+			err = repo.MarkListingContacted(ctx, listing.ID)
+		} else {
+			action = domain.ActionMarkedUncontacted
+			err = repo.MarkListingUncontacted(ctx, listing.ID)
+		}
+		if err != nil {
+			logger.Error("mark contacted failed", "is24_id", is24ID, "contacted", contacted, "error", err)
+			return "❌ Markieren fehlgeschlagen: " + err.Error()
+		}
+		repo.LogActivity(ctx, &domain.ActivityLog{
+			Action:     action,
+			EntityType: "listing",
+			EntityID:   listing.ID,
+			Details:    fmt.Sprintf("contacted %v → %v (manual)", listing.Contacted, contacted),
+		})
+		if contacted {
+			return fmt.Sprintf("✅ %s als kontaktiert markiert — der Bot schreibt sie nicht mehr an.", is24ID)
+		}
+		return fmt.Sprintf("↩️ %s als nicht kontaktiert markiert.", is24ID)
+	})
+
+	// /queue, /cancel: show the listings the next auto-contact run would write
+	// to, and take one out (skip flag, like the dashboard's "ignore").
+	ctrl.SetQueueCallbacks(
+		func() string {
+			queue, err := sched.ContactQueue(context.Background())
+			if err != nil {
+				return "❌ Warteschlange laden fehlgeschlagen: " + err.Error()
+			}
+			return formatContactQueue(queue, cfg.Contact.Enabled && ctrl.IsAutoContactEnabled())
+		},
+		func(is24ID string) string {
+			ctx := context.Background()
+			listing, err := repo.GetListingByIS24ID(ctx, is24ID)
+			if err != nil {
+				return "❌ Wohnung laden fehlgeschlagen: " + err.Error()
+			}
+			switch {
+			case listing == nil:
+				return fmt.Sprintf("❌ Wohnung %s ist nicht in der Datenbank.", is24ID)
+			case listing.Contacted:
+				return fmt.Sprintf("%s ist schon kontaktiert.", is24ID)
+			case listing.Skipped:
+				return fmt.Sprintf("%s ist schon aus der Warteschlange genommen.", is24ID)
+			}
+			if err := repo.SetListingSkipped(ctx, listing.ID, true); err != nil {
+				logger.Error("cancel contact failed", "is24_id", is24ID, "error", err)
+				return "❌ Abbrechen fehlgeschlagen: " + err.Error()
+			}
+			repo.LogActivity(ctx, &domain.ActivityLog{
+				Action:     domain.ActionContactCanceled,
+				EntityType: "listing",
+				EntityID:   listing.ID,
+				Details:    "manual",
+			})
+			return fmt.Sprintf("🚫 %s aus der Warteschlange genommen — der Bot schreibt sie nicht automatisch an.", is24ID)
+		},
+	)
+
+	// /ignore: never save, notify or contact a listing again.
+	ctrl.SetIgnoreCallback(func(is24ID string) string {
+		added, err := repo.IgnoreListing(context.Background(), is24ID)
+		if err != nil {
+			logger.Error("ignore listing failed", "is24_id", is24ID, "error", err)
+			return "❌ Ignorieren fehlgeschlagen: " + err.Error()
+		}
+		if !added {
+			return fmt.Sprintf("%s wird schon ignoriert.", is24ID)
+		}
+		return fmt.Sprintf("🙈 %s wird ab jetzt ignoriert — nie mehr gemeldet oder angeschrieben.", is24ID)
+	})
+
+	// /reset_listing: forget a listing so the next poll re-discovers it.
+	ctrl.SetResetListingCallback(func(is24ID string) string {
+		deleted, err := repo.ResetListing(context.Background(), is24ID)
+		if err != nil {
+			logger.Error("reset listing failed", "is24_id", is24ID, "error", err)
+			return "❌ Zurücksetzen fehlgeschlagen: " + err.Error()
+		}
+		if !deleted {
+			return fmt.Sprintf("❌ Wohnung %s ist nicht in der Datenbank.", is24ID)
+		}
+		logger.Info("listing reset", "is24_id", is24ID)
+		return fmt.Sprintf("🗑 %s gelöscht — der nächste Durchlauf findet und meldet sie neu.", is24ID)
+	})
+
+	// /html and /refetch: an expose page's source as a file, for debugging the
+	// parser. Files only go out via Telegram. The download takes a browser
+	// start, so /refetch replies right away.
+	ctrl.SetHTMLCallbacks(
+		func(is24ID string) string {
+			if !botController.IsEnabled() {
+				return "📄 Dateien gehen nur über Telegram."
+			}
+			html, err := is24.StoredExposeHTML(is24ID)
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Sprintf("❌ Kein gespeichertes HTML für %s (nur mit DEBUG_HTML=1). /refetch %s lädt das Exposé neu.", is24ID, is24ID)
+			}
+			if err != nil {
+				return "❌ HTML lesen fehlgeschlagen: " + err.Error()
+			}
+			if err := tgNotifier.SendDocument(context.Background(), "expose_"+is24ID+".html", html, "📄 Gespeichertes Exposé-HTML "+is24ID); err != nil {
+				return "❌ Datei senden fehlgeschlagen: " + err.Error()
+			}
+			return fmt.Sprintf("📄 HTML von %s gesendet (%d KB).", is24ID, len(html)/1024)
+		},
+		func(is24ID string) string {
+			if !botController.IsEnabled() {
+				return "📄 Dateien gehen nur über Telegram."
+			}
+			go func() {
+				ctx := context.Background()
+				html, err := is24Client.FetchExposeHTML(ctx, is24ID)
+				if err != nil {
+					logger.Warn("refetch failed", "is24_id", is24ID, "error", err)
+					notif.SendRawMessage(ctx, fmt.Sprintf("❌ Exposé %s laden fehlgeschlagen: %s", is24ID, err))
+					return
+				}
+				caption := fmt.Sprintf("📄 Exposé-HTML %s, abgerufen %s", is24ID, time.Now().Format("02.01. 15:04"))
+				if err := tgNotifier.SendDocument(ctx, "expose_"+is24ID+".html", html, caption); err != nil {
+					logger.Warn("sending refetched HTML failed", "is24_id", is24ID, "error", err)
+				}
+			}()
+			return fmt.Sprintf("📥 Exposé %s wird geladen …", is24ID)
+		},
+	)
+
+	// /log: a listing's activity timeline.
+	ctrl.SetLogCallback(func(is24ID string) string {
+		ctx := context.Background()
+		listing, err := repo.GetListingByIS24ID(ctx, is24ID)
+		if err != nil {
+			return "❌ Wohnung laden fehlgeschlagen: " + err.Error()
+		}
+		if listing == nil {
+			return fmt.Sprintf("❌ Wohnung %s ist nicht in der Datenbank.", is24ID)
+		}
+		entries, err := repo.ListListingActivity(ctx, listing.ID, logReportLimit)
+		if err != nil {
+			return "❌ Verlauf laden fehlgeschlagen: " + err.Error()
+		}
+		return formatListingLog(listing, entries, cfg.QuietHours.Timezone)
+	})
+
+	// /block_landlord: extend the landlord blocklist of all active profiles.
+	ctrl.SetBlockLandlordCallback(func(name string) string {
+		n, err := repo.AddExcludedLandlord(context.Background(), name)
+		if err != nil {
+			logger.Error("block landlord failed", "error", err)
+			return "❌ Anbieter sperren fehlgeschlagen: " + err.Error()
+		}
+		if n == 0 {
+			return fmt.Sprintf("„%s“ ist in allen aktiven Profilen bereits gesperrt (oder es gibt keine aktiven Profile).", name)
+		}
+		return fmt.Sprintf("🚫 *Anbieter gesperrt*\n\nWohnungen von „%s“ werden in %d Profil(en) aussortiert.", name, n)
+	})
+
+	// /poll: run a cycle now instead of waiting for the next tick. The reply
+	// is an immediate ack; the summary follows once the cycle is done.
+	ctrl.SetPollCallback(func() string {
+		started := sched.TriggerPoll(context.Background(), func(n int, err error) {
+			msg := fmt.Sprintf("🔎 *Suche abgeschlossen*\n\n%d neue Wohnung(en) gefunden.", n)
+			if err != nil {
+				msg = "❌ Suche fehlgeschlagen: " + err.Error()
+			}
+			notif.SendRawMessage(context.Background(), msg)
+		})
+		if !started {
+			return "⏳ Es läuft bereits eine Suche, bitte kurz warten."
+		}
+		return "🔎 *Suche gestartet* — Ergebnis folgt."
+	})
+
 	// Search-profile management commands (/addprofil, /listprofile, /delprofil)
 	ctrl.SetProfileCallbacks(
 		func(category, url, name string) string {
@@ -278,6 +672,9 @@ func main() {
 					camp = cfg.DefaultCampaign
 				}
 				sb.WriteString(fmt.Sprintf("\n*%d* — %s _(%s)_", p.ID, p.Name, camp))
+				if p.Paused {
+					sb.WriteString(" ⏸ pausiert")
+				}
 				if p.SearchURL != "" {
 					sb.WriteString("\n   🔗 " + p.SearchURL)
 				} else if p.City != "" {
@@ -298,6 +695,55 @@ func main() {
 		},
 	)
 
+	// /pause_profile, /resume_profile: skip one profile for a while without
+	// deactivating it.
+	ctrl.SetPauseProfileCallback(func(id int64, paused bool) string {
+		if err := repo.SetSearchProfilePaused(context.Background(), id, paused); err != nil {
+			return "❌ " + err.Error()
+		}
+		if paused {
+			return fmt.Sprintf("⏸ Profil %d pausiert. Mit /resume_profile %d geht es weiter.", id, id)
+		}
+		return fmt.Sprintf("▶️ Profil %d läuft wieder.", id)
+	})
+
+	// /import_profiles: several profiles from a pasted list of search URLs
+	ctrl.SetImportProfilesCallback(func(entries []control.ProfileImport) string {
+		created, skipped, err := importProfiles(context.Background(), repo, cfg, entries)
+		if err != nil {
+			logger.Error("import profiles failed", "error", err)
+			return "❌ Import fehlgeschlagen: " + err.Error()
+		}
+		return formatImportReport(created, skipped)
+	})
+
+	// /delete_profile: permanent deletion after confirmation. Listings stay
+	// but are detached from the profile (see DeleteSearchProfile).
+	ctrl.SetDeleteProfileCallbacks(
+		func(id int64) (*control.ProfileDeletion, error) {
+			ctx := context.Background()
+			sp, err := repo.GetSearchProfileByID(ctx, id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			total, contacted, err := repo.CountProfileListings(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return &control.ProfileDeletion{Name: sp.Name, Listings: total, Contacted: contacted}, nil
+		},
+		func(id int64) error {
+			if err := repo.DeleteSearchProfile(context.Background(), id); err != nil {
+				return err
+			}
+			logger.Info("search profile deleted via chat", "id", id)
+			return nil
+		},
+	)
+
 	// Context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -306,17 +752,25 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	stopped := make(chan struct{})
 	go func() {
 		sig := <-sigCh
 		logger.Info("received signal, shutting down", "signal", sig)
 		cancel()
 		sched.Stop()
+		close(stopped)
 	}()
 
 	// Start Telegram command listener
 	if botController.IsEnabled() {
 		botController.StartCommandListener(ctx)
 		logger.Info("Telegram command listener started")
+		if res, err := botController.SelfTest(); err != nil {
+			logger.Error("Telegram self-test failed: bot cannot post to the configured chat",
+				"chat_id", cfg.Telegram.ChatID, "error", err)
+		} else {
+			logger.Info("Telegram self-test ok", "bot", "@"+res.Username, "chat", res.Chat)
+		}
 	}
 
 	// Connect WhatsApp (prints a pairing code on first run) and start its command
@@ -334,6 +788,7 @@ func main() {
 	// Start web dashboard (localhost by default)
 	if cfg.Web.Enabled {
 		websrv := web.New(repo, ctrl, cfg, sched.GetStats, sched.SetIS24Cookie, logger)
+		websrv.SetRateLimiter(rateLimiter)
 		go func() {
 			if err := websrv.Start(ctx, cfg.Web.Addr); err != nil {
 				logger.Error("web dashboard failed", "error", err)
@@ -346,7 +801,14 @@ func main() {
 	if notif.IsEnabled() {
 		quietLabel := "☀️ Aus (24/7)"
 		if v := ctrl.IsQuietHoursEnabled(); v != nil && *v {
-			quietLabel = "🌙 An (22:00-07:00)"
+			qs, qe := ctrl.QuietHoursWindow()
+			quietLabel = fmt.Sprintf("🌙 An (%s-%s)", qs, qe)
+		}
+		// Say whether the mode survived the restart so a silent fallback to
+		// the default is noticed.
+		modeLabel := ctrl.ContactModeLabel() + " (Standard)"
+		if ctrl.ContactModeRestored() {
+			modeLabel = ctrl.ContactModeLabel() + " (wiederhergestellt)"
 		}
 		startupMsg := fmt.Sprintf(`🚀 *ImmoBot gestartet*
 
@@ -370,7 +832,7 @@ func main() {
 *Info:*
 /status - Aktueller Status
 /stats - Statistiken
-/help - Alle Befehle`, ctrl.ContactModeLabel(), quietLabel, len(profiles), cfg.PollInterval)
+/help - Alle Befehle`, modeLabel, quietLabel, len(profiles), cfg.PollInterval)
 
 		notif.SendRawMessage(ctx, startupMsg)
 	}
@@ -405,8 +867,8 @@ func main() {
 			go backup.Run(ctx, repo, cfg.Backup, logger)
 		}
 
-		// Wait for shutdown
-		<-ctx.Done()
+		// Wait for shutdown, including the contact submission in flight
+		<-stopped
 		logger.Info("shutdown complete")
 	}
 }
@@ -432,6 +894,364 @@ func profileNameFromURL(raw string) string {
 	return "IS24-Suche"
 }
 
+// importProfiles creates a search profile per entry. Entries whose URL an
+// existing profile (active or not) already searches, or that name an unknown
+// campaign, are skipped with the reason. Names default as with /addprofil.
+func importProfiles(ctx context.Context, repo *sqlite.Repository, cfg *config.Config, entries []control.ProfileImport) (created []domain.SearchProfile, skipped []string, err error) {
+	existing, err := repo.ListAllSearchProfiles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		known[strings.TrimRight(p.SearchURL, "/")] = true
+	}
+	for _, e := range entries {
+		key := strings.TrimRight(e.URL, "/")
+		switch {
+		case known[key]:
+			skipped = append(skipped, e.URL+" (schon vorhanden)")
+			continue
+		case e.Category != "" && !cfg.HasCampaign(e.Category):
+			skipped = append(skipped, fmt.Sprintf("%s (unbekannte Kampagne %q)", e.URL, e.Category))
+			continue
+		}
+		name := e.Name
+		if name == "" {
+			name = profileNameFromURL(e.URL)
+		}
+		sp := &domain.SearchProfile{Name: name, SearchURL: e.URL, Category: e.Category, Active: true}
+		if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+			return created, skipped, err
+		}
+		known[key] = true
+		created = append(created, *sp)
+	}
+	return created, skipped, nil
+}
+
+// formatImportReport renders the /import_profiles result.
+func formatImportReport(created []domain.SearchProfile, skipped []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📥 *Import*: %d Profile angelegt, %d übersprungen.\n", len(created), len(skipped)))
+	for _, p := range created {
+		sb.WriteString(fmt.Sprintf("\n*%d* — %s\n   🔗 %s", p.ID, p.Name, p.SearchURL))
+	}
+	if len(skipped) > 0 {
+		sb.WriteString("\n\nÜbersprungen:\n" + strings.Join(skipped, "\n"))
+	}
+	return sb.String()
+}
+
+// runProfileImport implements -import-profiles and returns the exit code.
+func runProfileImport(repo *sqlite.Repository, cfg *config.Config, path string, logger *slog.Logger) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("failed to read profile import", "error", err)
+		return 1
+	}
+	entries, invalid := control.ParseProfileImport(string(data))
+	for _, line := range invalid {
+		logger.Warn("import line without search URL", "line", line)
+	}
+	created, skipped, err := importProfiles(context.Background(), repo, cfg, entries)
+	for _, p := range created {
+		logger.Info("profile imported", "id", p.ID, "name", p.Name, "url", p.SearchURL)
+	}
+	for _, s := range skipped {
+		logger.Warn("profile skipped", "entry", s)
+	}
+	if err != nil {
+		logger.Error("profile import failed", "error", err)
+		return 1
+	}
+	logger.Info("profile import done", "created", len(created), "skipped", len(skipped)+len(invalid))
+	return 0
+}
+
+// logReportLimit caps how many activity entries /log shows.
+const logReportLimit = 20
+
+// formatListingLog renders /log: the listing's activity, oldest first, in
+// the quiet hours timezone.
+func formatListingLog(l *domain.Listing, entries []domain.ActivityLog, timezone string) string {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🕓 *Verlauf %s*\n%s\n", l.IS24ID, l.Title))
+	if len(entries) == 0 {
+		sb.WriteString("\nKeine Einträge.")
+	}
+	for _, e := range entries {
+		line := fmt.Sprintf("\n%s %s", e.CreatedAt.In(loc).Format("02.01. 15:04"), e.Action)
+		if e.Details != "" {
+			line += ": " + e.Details
+		}
+		if e.ErrorMsg != "" {
+			line += " ⚠️ " + e.ErrorMsg
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// scanReportLimit caps how many hits the /scan report lists.
+const scanReportLimit = 15
+
+// queueReportLimit caps how many listings the /queue report lists.
+const queueReportLimit = 20
+
+// formatContactQueue renders /queue: the listings in contact order, each
+// with its /cancel command. autoContact tells whether they will actually be
+// contacted in the current mode.
+func formatContactQueue(queue []domain.Listing, autoContact bool) string {
+	if len(queue) == 0 {
+		return "📭 *Kontakt-Warteschlange leer* — keine Wohnung wartet auf den Auto-Kontakt."
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📬 *Kontakt-Warteschlange* (%d)\n", len(queue)))
+	if !autoContact {
+		sb.WriteString("Auto-Kontakt ist aus (/contact_on), angeschrieben wird erst danach.\n")
+	}
+	for i, l := range queue {
+		if i == queueReportLimit {
+			sb.WriteString(fmt.Sprintf("\n… und %d weitere", len(queue)-queueReportLimit))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n*%s*\n💰 %d € | 🚪 %.1f Zi. | 📐 %d m²\n🔗 %s\n✋ /cancel %s\n",
+			l.Title, l.Price, l.Rooms, l.Area, l.URL, l.IS24ID))
+	}
+	return sb.String()
+}
+
+// formatScanReport renders the /scan result: counts, then the hits in search
+// order with the already stored ones marked.
+func formatScanReport(hits []scheduler.ScanHit) string {
+	known := 0
+	for _, h := range hits {
+		if h.Known {
+			known++
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔎 *Scan-Ergebnis*\n\n%d Treffer, davon %d neu.\n", len(hits), len(hits)-known))
+	for i, h := range hits {
+		if i == scanReportLimit {
+			sb.WriteString(fmt.Sprintf("\n… und %d weitere", len(hits)-scanReportLimit))
+			break
+		}
+		l := h.Listing
+		mark := "🆕"
+		if h.Known {
+			mark = "✓"
+		}
+		sb.WriteString(fmt.Sprintf("\n%s *%s*\n💰 %d € | 🚪 %.1f Zi. | 📐 %d m²\n🔗 %s\n", mark, l.Title, l.Price, l.Rooms, l.Area, l.URL))
+	}
+	if known < len(hits) {
+		sb.WriteString("\nNeue Treffer speichern: /scan_save")
+	}
+	return sb.String()
+}
+
+// formatSelfTestReport renders /selftest: browser and page load, cookie (any
+// hits at all), search parser and expose parser, each with ✅ or ❌.
+func formatSelfTestReport(r scheduler.SelfTestReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🩺 *Selbsttest* (%s)\n%s\n", r.Duration.Round(time.Second), r.URL))
+	if r.SearchErr != nil {
+		if errors.Is(r.SearchErr, is24.ErrChallenge) {
+			sb.WriteString("\n❌ *Browser:* Roboter-Check nicht gelöst — Cookie erneuern (/cookie)?")
+		} else {
+			sb.WriteString("\n❌ *Browser/Suche:* " + r.SearchErr.Error())
+		}
+		return sb.String()
+	}
+	sb.WriteString("\n✅ *Browser:* Suchseite geladen")
+	if r.Hits == 0 {
+		sb.WriteString("\n❌ *Cookie:* keine Treffer — Cookie abgelaufen (/cookie) oder Suche leer?")
+		return sb.String()
+	}
+	if r.TotalHits > 0 {
+		sb.WriteString(fmt.Sprintf("\n✅ *Cookie:* %d Treffer (IS24: %d)", r.Hits, r.TotalHits))
+	} else {
+		sb.WriteString(fmt.Sprintf("\n✅ *Cookie:* %d Treffer", r.Hits))
+	}
+	mark := "✅"
+	if r.Complete == 0 {
+		mark = "❌"
+	}
+	sb.WriteString(fmt.Sprintf("\n%s *Parser:* %d/%d mit Preis, Zimmer und Fläche", mark, r.Complete, r.Hits))
+	switch {
+	case r.ExposeErr != nil:
+		sb.WriteString(fmt.Sprintf("\n❌ *Exposé %s:* %s", r.ExposeID, r.ExposeErr))
+	case r.Expose == nil || r.Expose.Title == "":
+		sb.WriteString(fmt.Sprintf("\n❌ *Exposé %s:* nichts ausgelesen", r.ExposeID))
+	default:
+		l := r.Expose
+		sb.WriteString(fmt.Sprintf("\n✅ *Exposé %s:* %s\n💰 %d € | 🚪 %.1f Zi. | 📐 %d m²", r.ExposeID, l.Title, l.Price, l.Rooms, l.Area))
+	}
+	sb.WriteString("\n\nNichts gespeichert, nichts gemeldet.")
+	return sb.String()
+}
+
+// diffReportLimit caps how many listings /diff shows per section.
+const diffReportLimit = 10
+
+// formatCycleDiff renders /diff: new listings, listings no longer in the
+// results and price changes, timestamps in the quiet hours timezone.
+func formatCycleDiff(d *scheduler.CycleDiff, timezone string) string {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔀 *Änderungen* (%s → %s)\n",
+		d.From.In(loc).Format("02.01. 15:04"), d.To.In(loc).Format("02.01. 15:04")))
+	if len(d.New) == 0 && len(d.Gone) == 0 && len(d.PriceChanges) == 0 {
+		sb.WriteString("\nKeine Änderungen.")
+		return sb.String()
+	}
+
+	section := func(title string, n int, line func(i int) string) {
+		if n == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n*%s (%d):*", title, n))
+		for i := 0; i < n && i < diffReportLimit; i++ {
+			sb.WriteString("\n" + line(i))
+		}
+		if n > diffReportLimit {
+			sb.WriteString(fmt.Sprintf("\n… und %d weitere", n-diffReportLimit))
+		}
+		sb.WriteString("\n")
+	}
+	section("🆕 Neu", len(d.New), func(i int) string {
+		l := d.New[i]
+		return fmt.Sprintf("%s — %d € (%s)", l.Title, l.Price, l.IS24ID)
+	})
+	section("❌ Nicht mehr in den Ergebnissen", len(d.Gone), func(i int) string {
+		l := d.Gone[i]
+		return fmt.Sprintf("%s — %d € (%s)", l.Title, l.Price, l.IS24ID)
+	})
+	section("💶 Preisänderungen", len(d.PriceChanges), func(i int) string {
+		c := d.PriceChanges[i]
+		return fmt.Sprintf("%s: %d € → %d € (%s)", c.Title, c.OldPrice, c.Price, c.IS24ID)
+	})
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatRateStats renders the request budget for /status: how much of it the
+// last minute used and how often requests had to wait for it, per budget.
+func formatRateStats(global antidetect.RateStats, overrides map[string]antidetect.RateStats, adaptive bool) string {
+	line := func(name string, s antidetect.RateStats) string {
+		text := fmt.Sprintf("%s: %d/%d Anfragen in der letzten Minute", name, s.LastMinute, s.PerMinute)
+		if s.Requests > 0 {
+			text += fmt.Sprintf(", %.0f %% von %d gebremst, %s gewartet",
+				s.Saturation()*100, s.Requests, s.Waited.Round(time.Second))
+		}
+		if s.Slowdown > 1 {
+			text += fmt.Sprintf(", Abstände ×%.1f", s.Slowdown)
+		}
+		return text
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*IS24-Anfragebudget*")
+	if adaptive {
+		sb.WriteString(" (adaptiv)")
+	}
+	sb.WriteString("\n" + line("Global", global))
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sb.WriteString("\n" + line(key, overrides[key]))
+	}
+	return sb.String()
+}
+
+// formatConfigReport renders /config: the settings that decide what the bot
+// does, with the live chat overrides (contact mode, quiet hours) next to the
+// config file ones. Secrets are only reported as set or missing.
+func formatConfigReport(cfg *config.Config, ctrl *control.Controller, profiles int, cookieSet bool) string {
+	onOff := func(b bool) string {
+		if b {
+			return "an"
+		}
+		return "aus"
+	}
+	set := func(b bool) string {
+		if b {
+			return "gesetzt"
+		}
+		return "fehlt"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⚙️ *Konfiguration*\n")
+	sb.WriteString(fmt.Sprintf("\n*Poll-Intervall:* %s", cfg.PollInterval))
+	sb.WriteString(fmt.Sprintf("\n*Aktive Suchprofile:* %d", profiles))
+	sb.WriteString(fmt.Sprintf("\n*IS24-Cookie:* %s", set(cookieSet)))
+
+	sb.WriteString("\n\n*Kontakt*")
+	sb.WriteString(fmt.Sprintf("\nModus (Chat): %s", ctrl.ContactModeLabel()))
+	sb.WriteString(fmt.Sprintf("\ncontact.enabled: %s", onOff(cfg.Contact.Enabled)))
+	if cfg.Contact.Enabled {
+		sb.WriteString(fmt.Sprintf("\ndry_run: %s, http_first: %s", onOff(cfg.Contact.DryRun), onOff(cfg.Contact.HTTPFirst)))
+		if cfg.Contact.AutoContactPrivateOnly {
+			sb.WriteString("\nNur private Vermieter automatisch kontaktieren")
+		}
+		if cfg.Contact.RequirePhotosForContact {
+			sb.WriteString("\nInserate ohne Fotos nicht automatisch kontaktieren")
+		}
+		if cfg.Contact.MaxAge > 0 {
+			sb.WriteString(fmt.Sprintf("\nAuto-Kontakt nur für Inserate jünger als %s", cfg.Contact.MaxAge))
+		}
+		if cfg.Contact.Window.Enabled {
+			sb.WriteString(fmt.Sprintf("\nKontaktfenster: %s-%s", cfg.Contact.Window.Start, cfg.Contact.Window.End))
+		}
+	}
+	if ctrl.IsAutoContactEnabled() && !cfg.Contact.Enabled {
+		sb.WriteString("\n⚠️ Auto-Kontakt ist im Chat an, aber in der Config aus: es wird nichts gesendet.")
+	}
+
+	quiet := "aus (24/7)"
+	if q := ctrl.IsQuietHoursEnabled(); q != nil && *q {
+		start, end := ctrl.QuietHoursWindow()
+		quiet = fmt.Sprintf("%s-%s (%s)", start, end, cfg.QuietHours.Timezone)
+	}
+	sb.WriteString(fmt.Sprintf("\n\n*Ruhezeiten:* %s", quiet))
+
+	sb.WriteString("\n\n*Dienste*")
+	sb.WriteString(fmt.Sprintf("\nTelegram: %s, WhatsApp: %s", onOff(cfg.Telegram.Enabled), onOff(cfg.WhatsApp.Enabled)))
+	ai := onOff(cfg.OpenAI.Enabled)
+	if cfg.OpenAI.Enabled {
+		ai += fmt.Sprintf(" (%s, API-Key %s)", cfg.OpenAI.Model, set(cfg.OpenAI.APIKey != ""))
+	}
+	sb.WriteString("\nOpenAI: " + ai)
+	sb.WriteString(fmt.Sprintf("\nE-Mail-Monitor: %s", onOff(cfg.Email.Enabled)))
+	digest := onOff(cfg.Digest.Enabled)
+	if cfg.Digest.Enabled {
+		digest += " (" + cfg.Digest.Time + ")"
+	}
+	sb.WriteString("\nTageszusammenfassung: " + digest)
+	web := onOff(cfg.Web.Enabled)
+	if cfg.Web.Enabled {
+		web += " (" + cfg.Web.Addr + ")"
+	}
+	sb.WriteString("\nDashboard: " + web)
+	sb.WriteString(fmt.Sprintf("\nBackup: %s", onOff(cfg.Backup.Enabled)))
+
+	sb.WriteString("\n\n*IS24*")
+	sb.WriteString(fmt.Sprintf("\nAnfragen/min: %d (Burst %d), Exposé-Parallelität: %d", cfg.IS24.MaxRequestsPerMinute, cfg.IS24.Burst, cfg.IS24.ExposeConcurrency))
+	sb.WriteString(fmt.Sprintf("\nExposé-Fehler: %s", cfg.IS24.OnExposeFailure))
+	return sb.String()
+}
+
 // campaignResolver maps a search profile's category to its scheduler.Campaign
 // (message generator + AI prompt + applicant profile), built from config.
 type campaignResolver struct {
@@ -460,7 +1280,7 @@ func newCampaignResolver(cfg *config.Config, logger *slog.Logger) (*campaignReso
 	}
 	for name := range cfg.Campaigns {
 		camp := cfg.ResolveCampaign(name) // fills empty fields from globals
-		gen, err := messenger.NewGenerator(camp.MessageTemplatePath, "", "", "")
+		gen, err := newGenerator(camp, cfg.Message.Rotation)
 		if err != nil {
 			return nil, fmt.Errorf("campaign %q template: %w", name, err)
 		}
@@ -470,12 +1290,12 @@ func newCampaignResolver(cfg *config.Config, logger *slog.Logger) (*campaignReso
 			AIPrompt:  camp.AIPrompt,
 			Contact:   toContactProfile(camp.Contact),
 		}
-		logger.Info("campaign loaded", "name", name, "template", camp.MessageTemplatePath)
+		logger.Info("campaign loaded", "name", name, "template", camp.MessageTemplatePath, "variants", gen.Variants())
 	}
 
 	// Global fallback for unknown/empty categories.
 	fb := cfg.ResolveCampaign("")
-	gen, err := messenger.NewGenerator(fb.MessageTemplatePath, "", "", "")
+	gen, err := newGenerator(fb, cfg.Message.Rotation)
 	if err != nil {
 		return nil, fmt.Errorf("fallback campaign template: %w", err)
 	}
@@ -488,6 +1308,32 @@ func newCampaignResolver(cfg *config.Config, logger *slog.Logger) (*campaignReso
 	return r, nil
 }
 
+// newGenerator builds a campaign's message generator: the template variants
+// in MessageTemplateDir if set, otherwise the single template file, plus one
+// template per configured listing language.
+func newGenerator(camp config.Campaign, rotation string) (*messenger.Generator, error) {
+	var gen *messenger.Generator
+	var err error
+	if camp.MessageTemplateDir != "" {
+		gen, err = messenger.NewGeneratorFromDir(camp.MessageTemplateDir, rotation == config.RotationRandom)
+	} else {
+		gen, err = messenger.NewGenerator(camp.MessageTemplatePath, "", "", "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for lang, path := range camp.LanguageTemplates {
+		other, err := messenger.NewGenerator(path, "", "", "")
+		if err != nil {
+			return nil, err
+		}
+		if err := gen.SetLanguage(lang, other); err != nil {
+			return nil, err
+		}
+	}
+	return gen, nil
+}
+
 // toContactProfile maps the config applicant profile to the contact package type.
 func toContactProfile(p config.ContactProfile) contact.Profile {
 	return contact.Profile{
@@ -523,10 +1369,18 @@ func campaignNames(cfg *config.Config) []string {
 	return names
 }
 
+// openRepository opens the database with the configured pool settings.
+func openRepository(cfg *config.Config) (*sqlite.Repository, error) {
+	return sqlite.NewWithOptions(cfg.DatabasePath, sqlite.Options{
+		BusyTimeout:  cfg.DatabaseBusyTimeout,
+		MaxOpenConns: cfg.DatabaseMaxOpenConns,
+	})
+}
+
 // runHealthCheck reports whether the last successful poll is recent enough.
 // Returns 0 (healthy) or 1 (stale/unknown) for use as a container HEALTHCHECK.
 func runHealthCheck(cfg *config.Config) int {
-	repo, err := sqlite.New(cfg.DatabasePath)
+	repo, err := openRepository(cfg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "healthcheck: open db:", err)
 		return 1
@@ -1,61 +1,267 @@
 package antidetect
 
 import (
+	"context"
+	"math"
 	"math/rand"
 	"sync"
 	"time"
+	"unicode"
 )
 
-// RateLimiter controls request rate to avoid detection
+// RateLimiter is the single outbound queue for IS24-bound requests (search
+// pages, exposes, contact forms). Every request takes a token from a bucket
+// that refills at maxRequestsPerMinute and holds at most burst tokens, so all
+// callers together stay within the budget. Requests are also spaced by a
+// random minDelay..maxDelay gap. Slots are handed out in call order: Wait
+// reserves the next free slot under the lock and sleeps outside of it, so
+// concurrent callers queue up FIFO instead of bursting.
 type RateLimiter struct {
-	mu                   sync.Mutex
-	maxRequestsPerMinute int
-	requestTimes         []time.Time
-	minDelay             time.Duration
-	maxDelay             time.Duration
+	mu       sync.Mutex
+	rate     float64 // tokens per second
+	burst    float64
+	tokens   float64   // may go negative: slots already promised to waiters
+	updated  time.Time // last token refill
+	lastSlot time.Time // start of the latest reserved request
+	minDelay time.Duration
+	maxDelay time.Duration
+
+	// Counters for Stats; recent holds the slots of the last minute.
+	perMinute int
+	requests  int64
+	blocked   int64
+	waited    time.Duration
+	recent    []time.Time
+
+	// Adaptive mode (SetAdaptive): pressure is a moving average of how often
+	// the bucket was empty, slowdown the factor delays are widened by.
+	adaptive bool
+	pressure float64
+	slowdown float64
+
+	// overrides are separate budgets for requests made under a rate key
+	// (see WithRateKey).
+	overrides map[string]*RateLimiter
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(maxPerMinute int, minDelay, maxDelay time.Duration) *RateLimiter {
+// rateKeyCtx is the context key for WithRateKey.
+type rateKeyCtx struct{}
+
+// WithRateKey marks requests made with ctx as belonging to a rate override
+// (e.g. one city's search profiles). Limiters with an override registered
+// for key pace them with it instead of their own budget.
+func WithRateKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, rateKeyCtx{}, key)
+}
+
+// RateKey returns the rate override key set with WithRateKey, or "".
+func RateKey(ctx context.Context) string {
+	key, _ := ctx.Value(rateKeyCtx{}).(string)
+	return key
+}
+
+// NewRateLimiter creates the outbound limiter. burst < 1 is treated as 1.
+func NewRateLimiter(maxPerMinute, burst int, minDelay, maxDelay time.Duration) *RateLimiter {
+	if maxPerMinute < 1 {
+		maxPerMinute = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
 	return &RateLimiter{
-		maxRequestsPerMinute: maxPerMinute,
-		requestTimes:         make([]time.Time, 0, maxPerMinute),
-		minDelay:             minDelay,
-		maxDelay:             maxDelay,
+		rate:      float64(maxPerMinute) / 60,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+		updated:   time.Now(),
+		minDelay:  minDelay,
+		maxDelay:  maxDelay,
+		perMinute: maxPerMinute,
+		slowdown:  1,
+	}
+}
+
+// Adaptive mode thresholds: above adaptiveHigh pressure the delays widen by
+// adaptiveStep per request up to adaptiveMaxSlowdown, below adaptiveLow they
+// shrink back. The gap between the two keeps the factor from flapping.
+const (
+	adaptiveAlpha       = 0.1 // weight of the newest request in pressure
+	adaptiveHigh        = 0.5
+	adaptiveLow         = 0.1
+	adaptiveStep        = 1.1
+	adaptiveMaxSlowdown = 4.0
+)
+
+// SetAdaptive turns adaptive backpressure on or off. When most requests find
+// the bucket empty, i.e. the bot keeps running at its budget, the gap between
+// requests is widened step by step (up to 4x, plus up to three token
+// intervals) and narrowed again once the pressure is gone. Overrides are
+// configured separately.
+func (rl *RateLimiter) SetAdaptive(on bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.adaptive = on
+	if !on {
+		rl.pressure, rl.slowdown = 0, 1
 	}
 }
 
-// Wait blocks until a request can be made within rate limits
-func (rl *RateLimiter) Wait() {
+// RateStats are a limiter's counters since start.
+type RateStats struct {
+	PerMinute  int           // request budget per minute
+	LastMinute int           // requests in the last minute
+	Requests   int64         // requests since start
+	Blocked    int64         // requests that found the bucket empty and waited for a token
+	Waited     time.Duration // total time requests were held back (budget and delays)
+	Slowdown   float64       // current adaptive delay factor; 1 = as configured
+}
+
+// Saturation is the share of requests that had to wait for the budget.
+func (s RateStats) Saturation() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Blocked) / float64(s.Requests)
+}
+
+// Stats returns the limiter's counters. Requests under an override count
+// there, not here (see OverrideStats).
+func (rl *RateLimiter) Stats() RateStats {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
+	rl.pruneRecent(time.Now())
+	return RateStats{
+		PerMinute:  rl.perMinute,
+		LastMinute: len(rl.recent),
+		Requests:   rl.requests,
+		Blocked:    rl.blocked,
+		Waited:     rl.waited,
+		Slowdown:   rl.slowdown,
+	}
+}
 
-	now := time.Now()
+// OverrideStats returns the counters of each override by key.
+func (rl *RateLimiter) OverrideStats() map[string]RateStats {
+	rl.mu.Lock()
+	overrides := make(map[string]*RateLimiter, len(rl.overrides))
+	for key, o := range rl.overrides {
+		overrides[key] = o
+	}
+	rl.mu.Unlock()
+
+	stats := make(map[string]RateStats, len(overrides))
+	for key, o := range overrides {
+		stats[key] = o.Stats()
+	}
+	return stats
+}
+
+// pruneRecent drops slots older than a minute. Called with mu held.
+func (rl *RateLimiter) pruneRecent(now time.Time) {
 	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(rl.recent) && !rl.recent[i].After(cutoff) {
+		i++
+	}
+	rl.recent = rl.recent[i:]
+}
+
+// SetOverride registers the limiter used for requests made under key. Its
+// requests don't count against rl's budget.
+func (rl *RateLimiter) SetOverride(key string, o *RateLimiter) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.overrides == nil {
+		rl.overrides = make(map[string]*RateLimiter)
+	}
+	rl.overrides[key] = o
+}
 
-	// Remove old request times
-	var filtered []time.Time
-	for _, t := range rl.requestTimes {
-		if t.After(cutoff) {
-			filtered = append(filtered, t)
+// Wait blocks until the caller's request slot arrives. Returns ctx.Err() if
+// the context ends first; the slot is then forfeited (never reused), which
+// only errs on the side of fewer requests.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if key := RateKey(ctx); key != "" {
+		rl.mu.Lock()
+		o := rl.overrides[key]
+		rl.mu.Unlock()
+		if o != nil && o != rl {
+			return o.Wait(ctx)
 		}
 	}
-	rl.requestTimes = filtered
+	delay := rl.reserve(time.Now())
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve books the next request slot and returns how long to wait for it.
+func (rl *RateLimiter) reserve(now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
 
-	// If at limit, wait until oldest request expires
-	if len(rl.requestTimes) >= rl.maxRequestsPerMinute {
-		waitUntil := rl.requestTimes[0].Add(time.Minute)
-		if waitUntil.After(now) {
-			time.Sleep(waitUntil.Sub(now))
+	// Refill the bucket for the time passed.
+	rl.tokens = math.Min(rl.burst, rl.tokens+now.Sub(rl.updated).Seconds()*rl.rate)
+	rl.updated = now
+
+	rl.tokens--
+	slot := now
+	blocked := rl.tokens < 0
+	if blocked {
+		slot = now.Add(time.Duration(-rl.tokens / rl.rate * float64(time.Second)))
+		rl.blocked++
+	}
+	if rl.adaptive {
+		rl.adapt(blocked)
+	}
+	// Human-like gap to the previous request.
+	if !rl.lastSlot.IsZero() {
+		if gap := rl.lastSlot.Add(rl.gap()); gap.After(slot) {
+			slot = gap
 		}
 	}
+	rl.lastSlot = slot
+
+	rl.requests++
+	rl.waited += slot.Sub(now)
+	rl.pruneRecent(now)
+	rl.recent = append(rl.recent, slot)
+	return slot.Sub(now)
+}
 
-	// Add random delay for human-like behavior
-	delay := rl.randomDelay()
-	time.Sleep(delay)
+// adapt updates the pressure with one request and moves the slowdown factor.
+// Called with mu held.
+func (rl *RateLimiter) adapt(blocked bool) {
+	b := 0.0
+	if blocked {
+		b = 1
+	}
+	rl.pressure = (1-adaptiveAlpha)*rl.pressure + adaptiveAlpha*b
+	switch {
+	case rl.pressure > adaptiveHigh:
+		rl.slowdown = math.Min(rl.slowdown*adaptiveStep, adaptiveMaxSlowdown)
+	case rl.pressure < adaptiveLow:
+		rl.slowdown = math.Max(rl.slowdown/adaptiveStep, 1)
+	}
+}
 
-	// Record this request
-	rl.requestTimes = append(rl.requestTimes, time.Now())
+// gap returns the spacing to the previous request: the random human delay,
+// widened by the adaptive slowdown. The widening adds token intervals too, so
+// it also slows down a limiter configured without delays.
+func (rl *RateLimiter) gap() time.Duration {
+	d := rl.randomDelay()
+	if rl.slowdown <= 1 {
+		return d
+	}
+	extra := (rl.slowdown - 1) / rl.rate * float64(time.Second)
+	return time.Duration(float64(d)*rl.slowdown + extra)
 }
 
 // randomDelay returns a random duration between minDelay and maxDelay
@@ -118,40 +324,109 @@ func defaultUserAgents() []string {
 	}
 }
 
-// HumanBehavior provides human-like delays for browser automation
+// HumanBehavior provides human-like delays for browser automation. Delays
+// vary log-normally around the configured base, so they are as often shorter
+// as longer than it, with an occasional long "distraction" pause on top.
 type HumanBehavior struct {
 	TypeDelay   time.Duration
 	ActionDelay time.Duration
+
+	mu  sync.Mutex
+	rng *rand.Rand
 }
 
+const (
+	// typeSpread and actionSpread are the log-normal sigmas: about two
+	// thirds of delays fall within base/1.4..base*1.4 (typing) and
+	// base/1.65..base*1.65 (actions).
+	typeSpread   = 0.35
+	actionSpread = 0.5
+	// minJitterFactor bounds how much faster than the base a delay can be.
+	minJitterFactor = 0.25
+
+	// wordPauseFactor stretches the delay after a space or punctuation, where
+	// people pause before the next word.
+	wordPauseFactor = 2.5
+
+	// typeDistraction and actionDistraction are the chances that a keystroke
+	// or action is followed by a distraction pause (glancing away, rereading).
+	typeDistraction   = 0.015
+	actionDistraction = 0.05
+	minDistraction    = 800 * time.Millisecond
+	maxDistraction    = 4 * time.Second
+)
+
 // NewHumanBehavior creates a new human behavior simulator
 func NewHumanBehavior(typeDelay, actionDelay time.Duration) *HumanBehavior {
 	return &HumanBehavior{
 		TypeDelay:   typeDelay,
 		ActionDelay: actionDelay,
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
-// TypeChar returns a delay for typing a character (with variation)
-func (h *HumanBehavior) TypeChar() time.Duration {
-	// Add 0-50% variation
-	variation := time.Duration(rand.Int63n(int64(h.TypeDelay / 2)))
-	return h.TypeDelay + variation
+// SetSeed makes the delays reproducible (tests).
+func (h *HumanBehavior) SetSeed(seed int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rng = rand.New(rand.NewSource(seed))
+}
+
+// TypeChar returns the delay after typing r. Word boundaries (spaces,
+// punctuation) get a longer pause than keystrokes within a word.
+func (h *HumanBehavior) TypeChar(r rune) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	d := h.jitter(h.TypeDelay, typeSpread)
+	if unicode.IsSpace(r) || unicode.IsPunct(r) {
+		d = time.Duration(float64(d) * wordPauseFactor)
+	}
+	return d + h.distraction(typeDistraction)
 }
 
 // ActionPause returns a delay between actions
 func (h *HumanBehavior) ActionPause() time.Duration {
-	// Add 0-100% variation
-	variation := time.Duration(rand.Int63n(int64(h.ActionDelay)))
-	return h.ActionDelay + variation
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.jitter(h.ActionDelay, actionSpread) + h.distraction(actionDistraction)
 }
 
 // ScrollPause returns a delay for scrolling
 func (h *HumanBehavior) ScrollPause() time.Duration {
-	return time.Duration(200+rand.Intn(300)) * time.Millisecond
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Duration(200+h.random().Intn(300)) * time.Millisecond
 }
 
 // ThinkPause returns a delay for "thinking" before action
 func (h *HumanBehavior) ThinkPause() time.Duration {
-	return time.Duration(500+rand.Intn(1500)) * time.Millisecond
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Duration(500+h.random().Intn(1500)) * time.Millisecond
+}
+
+// jitter scales base by a log-normal factor with the given sigma (median 1),
+// never going below minJitterFactor of base.
+func (h *HumanBehavior) jitter(base time.Duration, sigma float64) time.Duration {
+	f := math.Max(math.Exp(sigma*h.random().NormFloat64()), minJitterFactor)
+	return time.Duration(float64(base) * f)
+}
+
+// distraction returns a minDistraction..maxDistraction pause with the given
+// probability, otherwise 0.
+func (h *HumanBehavior) distraction(p float64) time.Duration {
+	rng := h.random()
+	if rng.Float64() >= p {
+		return 0
+	}
+	return minDistraction + time.Duration(rng.Int63n(int64(maxDistraction-minDistraction)))
+}
+
+// random returns the behavior's generator, creating one for a zero
+// HumanBehavior. Callers hold h.mu.
+func (h *HumanBehavior) random() *rand.Rand {
+	if h.rng == nil {
+		h.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return h.rng
 }
@@ -0,0 +1,228 @@
+package antidetect
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	// 6/min = one token every 10s, burst 2, no human gap.
+	rl := NewRateLimiter(6, 2, 0, 0)
+	now := rl.updated
+
+	want := []time.Duration{0, 0, 10 * time.Second, 20 * time.Second}
+	for i, w := range want {
+		if got := rl.reserve(now); got != w {
+			t.Errorf("request %d: wait %v, want %v", i+1, got, w)
+		}
+	}
+
+	// After a minute the queued slots are paid off and the bucket is full again.
+	later := now.Add(time.Minute)
+	if got := rl.reserve(later); got != 0 {
+		t.Errorf("after refill: wait %v, want 0", got)
+	}
+}
+
+func TestRateLimiterMinDelay(t *testing.T) {
+	rl := NewRateLimiter(600, 5, 3*time.Second, 3*time.Second)
+	now := rl.updated
+
+	if got := rl.reserve(now); got != 0 {
+		t.Fatalf("first request: wait %v, want 0", got)
+	}
+	// Tokens are available, but requests stay spaced by minDelay.
+	if got := rl.reserve(now); got != 3*time.Second {
+		t.Errorf("second request: wait %v, want 3s", got)
+	}
+	if got := rl.reserve(now); got != 6*time.Second {
+		t.Errorf("third request: wait %v, want 6s", got)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	rl := NewRateLimiter(1, 1, 0, 0)
+	if err := rl.Wait(context.Background()); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rl.Wait(ctx); err != context.Canceled {
+		t.Errorf("canceled wait: err %v, want context.Canceled", err)
+	}
+}
+
+func TestRateLimiterOverride(t *testing.T) {
+	// The global budget is spent; the override still has tokens.
+	rl := NewRateLimiter(1, 1, 0, 0)
+	rl.SetOverride("berlin", NewRateLimiter(600, 5, 0, 0))
+	rl.reserve(time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		if err := rl.Wait(WithRateKey(ctx, "berlin")); err != nil {
+			t.Fatalf("override request %d: %v", i+1, err)
+		}
+	}
+	if rl.tokens != 0 {
+		t.Errorf("override requests used the global budget: %v tokens left", rl.tokens)
+	}
+	// Unknown keys fall back to the global budget.
+	if err := rl.Wait(WithRateKey(ctx, "hamburg")); err == nil {
+		t.Error("unknown key should wait on the spent global budget")
+	}
+}
+
+func TestRateLimiterStats(t *testing.T) {
+	// 6/min, burst 2: the third and fourth request wait 10s and 20s.
+	rl := NewRateLimiter(6, 2, 0, 0)
+	rl.SetOverride("berlin", NewRateLimiter(600, 5, 0, 0))
+	now := time.Now()
+	for range 4 {
+		rl.reserve(now)
+	}
+	rl.overrides["berlin"].reserve(now)
+
+	s := rl.Stats()
+	if s.PerMinute != 6 || s.LastMinute != 4 || s.Requests != 4 || s.Blocked != 2 || s.Waited != 30*time.Second {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.Saturation() != 0.5 || s.Slowdown != 1 {
+		t.Errorf("saturation %v, slowdown %v", s.Saturation(), s.Slowdown)
+	}
+	if o := rl.OverrideStats()["berlin"]; o.Requests != 1 || o.Blocked != 0 {
+		t.Errorf("override stats = %+v", o)
+	}
+	if (RateStats{}).Saturation() != 0 {
+		t.Error("no requests should mean no saturation")
+	}
+}
+
+func TestRateLimiterAdaptive(t *testing.T) {
+	// 60/min = one token per second, no configured delays.
+	rl := NewRateLimiter(60, 1, 0, 0)
+	rl.SetAdaptive(true)
+	now := rl.updated
+
+	// Requests arriving faster than the budget keep the bucket empty.
+	var last time.Duration
+	for range 30 {
+		last = rl.reserve(now)
+	}
+	s := rl.Stats()
+	if s.Slowdown <= 1 {
+		t.Fatalf("saturated limiter should slow down, slowdown %v", s.Slowdown)
+	}
+	if last <= 29*time.Second {
+		t.Errorf("last request waits %v, want more than the budget alone (29s)", last)
+	}
+
+	// Unhurried requests find tokens again and the delays narrow back.
+	later := now.Add(time.Hour)
+	for i := range 100 {
+		rl.reserve(later.Add(time.Duration(i) * time.Minute))
+	}
+	if s := rl.Stats(); s.Slowdown != 1 {
+		t.Errorf("slowdown %v after the pressure is gone, want 1", s.Slowdown)
+	}
+
+	// Off by default.
+	plain := NewRateLimiter(60, 1, 0, 0)
+	for range 30 {
+		plain.reserve(now)
+	}
+	if s := plain.Stats(); s.Slowdown != 1 {
+		t.Errorf("non-adaptive limiter slowed down: %v", s.Slowdown)
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	tr, err := NewTransport(TransportOptions{LocalAddr: "127.0.0.1", MaxIdleConns: 1, DisableKeepAlives: true})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	if !tr.DisableKeepAlives || tr.MaxIdleConnsPerHost != 1 {
+		t.Errorf("options not applied: keepalives disabled=%v, idle per host=%d", tr.DisableKeepAlives, tr.MaxIdleConnsPerHost)
+	}
+
+	// The bound source address is used for outgoing connections.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no loopback listener")
+	}
+	defer ln.Close()
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	conn, err := tr.DialContext(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if ip := conn.LocalAddr().(*net.TCPAddr).IP.String(); ip != "127.0.0.1" {
+		t.Errorf("local addr = %s, want 127.0.0.1", ip)
+	}
+
+	if _, err := NewTransport(TransportOptions{LocalAddr: "no-such-iface0"}); err == nil {
+		t.Error("unknown interface should be an error")
+	}
+}
+
+func TestHumanBehaviorJitter(t *testing.T) {
+	const base = 100 * time.Millisecond
+	h := NewHumanBehavior(base, base)
+	h.SetSeed(1)
+
+	var shorter, longer, distracted int
+	var inWord, afterWord time.Duration
+	const n = 2000
+	for i := 0; i < n; i++ {
+		d := h.TypeChar('a')
+		switch {
+		case d >= minDistraction:
+			distracted++
+		case d < base:
+			shorter++
+		case d > base:
+			longer++
+		}
+		if d < base/4 {
+			t.Fatalf("delay %v below the floor", d)
+		}
+		if d < minDistraction {
+			inWord += d
+		}
+		if d := h.TypeChar(' '); d < minDistraction {
+			afterWord += d
+		}
+	}
+	if shorter < n/3 || longer < n/3 {
+		t.Errorf("delays should vary both ways around the base: %d shorter, %d longer", shorter, longer)
+	}
+	if distracted == 0 {
+		t.Error("expected occasional distraction pauses")
+	}
+	if afterWord < 2*inWord {
+		t.Errorf("pauses after words (%v) should be clearly longer than within words (%v)", afterWord, inWord)
+	}
+}
+
+func TestHumanBehaviorSeeded(t *testing.T) {
+	a := NewHumanBehavior(50*time.Millisecond, time.Second)
+	b := NewHumanBehavior(50*time.Millisecond, time.Second)
+	a.SetSeed(42)
+	b.SetSeed(42)
+	for i := 0; i < 50; i++ {
+		if x, y := a.TypeChar('x'), b.TypeChar('x'); x != y {
+			t.Fatalf("step %d: TypeChar %v != %v with the same seed", i, x, y)
+		}
+		if x, y := a.ActionPause(), b.ActionPause(); x != y {
+			t.Fatalf("step %d: ActionPause %v != %v with the same seed", i, x, y)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package antidetect
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tune the TCP/HTTP layer of IS24-bound requests. Which
+// source IP is used and how long connections are reused are both visible to
+// the server, so they are knobs for careful anti-detection tuning.
+type TransportOptions struct {
+	// LocalAddr is the source address to bind: an IPv4/IPv6 address or a
+	// network interface name (its first address is used). Empty = OS default.
+	LocalAddr string
+	// MaxIdleConns caps the idle connections kept for reuse per host.
+	// 0 keeps the net/http default.
+	MaxIdleConns int
+	// DisableKeepAlives opens a fresh connection for every request.
+	DisableKeepAlives bool
+}
+
+// NewTransport builds an HTTP transport from the default one with opts
+// applied.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.LocalAddr != "" {
+		ip, err := resolveLocalAddr(opts.LocalAddr)
+		if err != nil {
+			return nil, err
+		}
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			LocalAddr: &net.TCPAddr{IP: ip},
+		}
+		t.DialContext = dialer.DialContext
+	}
+	if opts.MaxIdleConns > 0 {
+		t.MaxIdleConns = opts.MaxIdleConns
+		t.MaxIdleConnsPerHost = opts.MaxIdleConns
+	}
+	t.DisableKeepAlives = opts.DisableKeepAlives
+	return t, nil
+}
+
+// resolveLocalAddr turns an IP literal or interface name into the IP to bind.
+func resolveLocalAddr(addr string) (net.IP, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip, nil
+	}
+	iface, err := net.InterfaceByName(addr)
+	if err != nil {
+		return nil, fmt.Errorf("local addr %q is neither an IP nor an interface: %w", addr, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("interface %s addresses: %w", addr, err)
+	}
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			return ipNet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("interface %s has no usable address", addr)
+}
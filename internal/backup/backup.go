@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/julianbeese/immo_bot/internal/config"
@@ -26,6 +27,9 @@ type Vacuumer interface {
 	VacuumInto(ctx context.Context, path string) error
 }
 
+// mu serializes snapshots so an on-demand /backup can't race the loop.
+var mu sync.Mutex
+
 // Run drives a ticker that snapshots the database every cfg.Interval and
 // prunes snapshots older than cfg.RetentionDays. Returns when ctx is done.
 // Safe to call as a goroutine from main.
@@ -69,17 +73,32 @@ func once(ctx context.Context, repo Vacuumer, cfg config.BackupConfig, logger *s
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if _, _, err := Now(ctx, repo, cfg, logger); err != nil {
+		logger.Error("backup failed", "error", err)
+	}
+}
+
+// Now writes one snapshot into cfg.Dir and prunes older files, independent of
+// cfg.Enabled and cfg.Interval (the /backup command). It returns the path and
+// size of the new file.
+func Now(ctx context.Context, repo Vacuumer, cfg config.BackupConfig, logger *slog.Logger) (path string, size int64, err error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return "", 0, fmt.Errorf("create backup dir: %w", err)
+	}
 	start := time.Now()
 	name := fmt.Sprintf("immobot-%s.db", start.UTC().Format("20060102-150405"))
-	path := filepath.Join(cfg.Dir, name)
+	path = filepath.Join(cfg.Dir, name)
 
 	if err := repo.VacuumInto(ctx, path); err != nil {
-		logger.Error("backup: VACUUM INTO failed", "path", path, "error", err)
-		return
+		return "", 0, fmt.Errorf("VACUUM INTO %s: %w", path, err)
 	}
-	info, _ := os.Stat(path)
-	var size int64
-	if info != nil {
+	if info, err := os.Stat(path); err == nil {
 		size = info.Size()
 	}
 	logger.Info("backup written",
@@ -88,6 +107,7 @@ func once(ctx context.Context, repo Vacuumer, cfg config.BackupConfig, logger *s
 	if err := prune(cfg.Dir, cfg.RetentionDays, logger); err != nil {
 		logger.Warn("backup prune failed", "error", err)
 	}
+	return path, size, nil
 }
 
 // prune deletes backup files (matching immobot-*.db) older than retentionDays.
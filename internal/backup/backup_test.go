@@ -45,6 +45,22 @@ func TestOnceWritesAndPrunes(t *testing.T) {
 	}
 }
 
+func TestNowReturnsPathAndSize(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups") // created on demand
+	cfg := config.BackupConfig{RetentionDays: 7, Dir: dir}
+	path, size, err := Now(context.Background(), &fakeVacuumer{}, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != dir || size != int64(len("fake-db")) {
+		t.Errorf("Now = %q, %d; want a file in %s of %d bytes", path, size, dir, len("fake-db"))
+	}
+
+	if _, _, err := Now(context.Background(), &fakeVacuumer{err: errors.New("disk full")}, cfg, nil); err == nil {
+		t.Error("a failed VACUUM INTO should be returned")
+	}
+}
+
 func TestPruneKeepsOnlyNewestWhenRetentionZero(t *testing.T) {
 	dir := t.TempDir()
 	now := time.Now()
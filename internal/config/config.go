@@ -16,17 +16,31 @@ type Config struct {
 	PollInterval time.Duration `yaml:"poll_interval"`
 	DatabasePath string        `yaml:"database_path"`
 	LogLevel     string        `yaml:"log_level"`
+	// MaxStoredDescriptionLength caps the expose description saved per
+	// listing, in characters (0 = unlimited). Filters and the quality score
+	// still see the full text.
+	MaxStoredDescriptionLength int `yaml:"max_stored_description_length"`
+	// DatabaseBusyTimeout is how long a query waits for another connection's
+	// write lock before failing; DatabaseMaxOpenConns sizes the pool.
+	DatabaseBusyTimeout  time.Duration `yaml:"database_busy_timeout"`
+	DatabaseMaxOpenConns int           `yaml:"database_max_open_conns"`
+	// NoNewListingsAlert sends a one-time warning when no profile has found
+	// a new listing for this long (0 = off).
+	NoNewListingsAlert time.Duration `yaml:"no_new_listings_alert"`
 
-	IS24       IS24Config       `yaml:"is24"`
-	Telegram   TelegramConfig   `yaml:"telegram"`
-	WhatsApp   WhatsAppConfig   `yaml:"whatsapp"`
-	OpenAI     OpenAIConfig     `yaml:"openai"`
-	Email      EmailConfig      `yaml:"email"`
-	Contact    ContactConfig    `yaml:"contact"`
-	Message    MessageConfig    `yaml:"message"`
-	QuietHours QuietHoursConfig `yaml:"quiet_hours"`
-	Web        WebConfig        `yaml:"web"`
-	Backup     BackupConfig     `yaml:"backup"`
+	IS24        IS24Config       `yaml:"is24"`
+	Telegram    TelegramConfig   `yaml:"telegram"`
+	WhatsApp    WhatsAppConfig   `yaml:"whatsapp"`
+	OpenAI      OpenAIConfig     `yaml:"openai"`
+	Email       EmailConfig      `yaml:"email"`
+	Contact     ContactConfig    `yaml:"contact"`
+	Message     MessageConfig    `yaml:"message"`
+	Filter      FilterConfig     `yaml:"filter"`
+	QuietHours  QuietHoursConfig `yaml:"quiet_hours"`
+	Digest      DigestConfig     `yaml:"digest"`
+	PriceAlerts PriceAlertConfig `yaml:"price_alerts"`
+	Web         WebConfig        `yaml:"web"`
+	Backup      BackupConfig     `yaml:"backup"`
 
 	// DefaultCampaign / Campaigns enable per-search-profile personalization:
 	// a search profile's category selects a campaign (message template, AI
@@ -40,8 +54,12 @@ type Config struct {
 // the global Message/Contact settings.
 type Campaign struct {
 	MessageTemplatePath string         `yaml:"message_template_path"`
+	MessageTemplateDir  string         `yaml:"message_template_dir"` // rotate over *.txt variants; takes precedence over the path
 	AIPrompt            string         `yaml:"ai_prompt"`
 	Contact             ContactProfile `yaml:"contact_profile"`
+	// LanguageTemplates maps a language code ("en") to the template used for
+	// listings posted in that language. Empty = message.language_templates.
+	LanguageTemplates map[string]string `yaml:"language_templates"`
 }
 
 // BackupConfig controls the periodic sqlite "VACUUM INTO" snapshot of the
@@ -68,6 +86,51 @@ type WhatsAppConfig struct {
 	LogLevel    string `yaml:"log_level"`    // whatsmeow log level: "INFO", "DEBUG", ...
 }
 
+// FilterConfig for listing filter settings
+type FilterConfig struct {
+	// WarmRentFactor estimates the Warmmiete as Kaltmiete * factor when a
+	// listing states neither warm rent nor Nebenkosten (max_total_rent).
+	WarmRentFactor float64 `yaml:"warm_rent_factor"`
+	// MinPlausiblePrice is the lowest Kaltmiete taken at face value; below it
+	// (price 1, unreadable price) the price is a data error handled per
+	// ImplausiblePrice. 0 disables the check.
+	MinPlausiblePrice int `yaml:"min_plausible_price"`
+	// ImplausiblePrice is ImplausiblePriceDrop or ImplausiblePriceUnknown.
+	ImplausiblePrice string `yaml:"implausible_price"`
+	// PriceOnRequest decides what happens to "Preis auf Anfrage" listings,
+	// which are exempt from the plausibility check: PriceOnRequestDrop,
+	// PriceOnRequestNotify or PriceOnRequestInclude.
+	PriceOnRequest string `yaml:"price_on_request"`
+	// ScoreWeights weights the 0-100 listing quality score signals.
+	ScoreWeights ScoreWeightsConfig `yaml:"score_weights"`
+	// IgnoreListingIDs are IS24 IDs that are never saved, notified or
+	// contacted, e.g. a scam that keeps coming back. /ignore adds more at
+	// runtime (stored in the database).
+	IgnoreListingIDs []string `yaml:"ignore_listing_ids"`
+}
+
+// FilterConfig.ImplausiblePrice modes.
+const (
+	ImplausiblePriceDrop    = "drop"    // filter the listing out (reason implausible_price)
+	ImplausiblePriceUnknown = "unknown" // clear the price and treat it like a listing without one
+)
+
+// FilterConfig.PriceOnRequest modes.
+const (
+	PriceOnRequestDrop    = "drop"    // filter the listing out (reason price_on_request)
+	PriceOnRequestNotify  = "notify"  // notify, flagged, but never auto-contact
+	PriceOnRequestInclude = "include" // handle like any other listing, auto-contact included
+)
+
+// ScoreWeightsConfig weights the quality score signals; only ratios matter.
+type ScoreWeightsConfig struct {
+	PricePerSqm float64 `yaml:"price_per_sqm"` // cheaper per m² than the profile average
+	Images      float64 `yaml:"images"`        // number of photos
+	FloorPlan   float64 `yaml:"floor_plan"`    // floor plan attached
+	Private     float64 `yaml:"private"`       // private landlord instead of an agent
+	Description float64 `yaml:"description"`   // description length
+}
+
 // QuietHoursConfig for defining when the bot should not send messages
 type QuietHoursConfig struct {
 	Enabled  bool   `yaml:"enabled"`
@@ -76,27 +139,177 @@ type QuietHoursConfig struct {
 	Timezone string `yaml:"timezone"` // e.g. "Europe/Berlin"
 }
 
+// DigestConfig for the daily summary message (found/notified/contacted in the
+// last 24h, cheapest new listings, errors). Uses the quiet hours timezone.
+type DigestConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Time    string `yaml:"time"` // e.g. "20:00"
+}
+
+// PriceAlertConfig for messages about price changes of already notified
+// listings. A change is reported once the price is at least MinChange euros
+// or MinChangePercent percent away from the last reported one (0 = threshold
+// unused, both 0 = any change), at most once per Cooldown per listing.
+type PriceAlertConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	MinChange        int           `yaml:"min_change"`
+	MinChangePercent float64       `yaml:"min_change_percent"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
 // IS24Config for ImmobilienScout24 settings
 type IS24Config struct {
 	Cookie               string        `yaml:"cookie"`
 	MaxRequestsPerMinute int           `yaml:"max_requests_per_minute"`
+	Burst                int           `yaml:"burst"` // requests allowed back to back before pacing kicks in
 	MinDelay             time.Duration `yaml:"min_delay"`
 	MaxDelay             time.Duration `yaml:"max_delay"`
 	UserAgents           []string      `yaml:"user_agents"`
+	// AdaptiveRate widens the request delays while the bot keeps running at
+	// its budget and narrows them again once it doesn't (all budgets).
+	AdaptiveRate bool `yaml:"adaptive_rate"`
+	// ProfileDelay is the pause between two search profiles of a poll cycle,
+	// plus a random 0..ProfileJitter. Both 0 = next profile right away.
+	ProfileDelay  time.Duration `yaml:"profile_delay"`
+	ProfileJitter time.Duration `yaml:"profile_jitter"`
+	// StartupDelay holds back the first poll after start, plus a random
+	// 0..StartupJitter, so a fresh deploy doesn't search right away. WarmUp
+	// then loads the homepage once before the first search.
+	StartupDelay  time.Duration `yaml:"startup_delay"`
+	StartupJitter time.Duration `yaml:"startup_jitter"`
+	WarmUp        bool          `yaml:"warm_up"`
+	// SelfTestURL is the search /selftest runs; empty = the first active
+	// profile's search URL.
+	SelfTestURL string `yaml:"selftest_url"`
+	// ExposeConcurrency bounds how many expose detail pages are fetched in
+	// parallel per search profile (still paced by the rate limiter). 1 = serial.
+	ExposeConcurrency int `yaml:"expose_concurrency"`
+	// OnExposeFailure decides what happens to a listing whose expose detail
+	// page can't be fetched: ExposeFailureUseBasic, ExposeFailureSkip or
+	// ExposeFailureRetry.
+	OnExposeFailure string `yaml:"on_expose_failure"`
+	// Transport settings for the HTTP-first contact path only. Scraping runs
+	// through Chrome, which keeps its own connection handling and source IP.
+	LocalAddr         string `yaml:"local_addr"`          // source IP (v4/v6) or interface name; empty = OS default
+	MaxIdleConns      int    `yaml:"max_idle_conns"`      // idle connections kept per host; 0 = net/http default
+	DisableKeepAlives bool   `yaml:"disable_keep_alives"` // new connection per request
+	// RateOverrides give matching search profiles their own request budget
+	// instead of the global one. Keys are matched case-insensitively against
+	// a profile's name, its city, or a path segment of its search URL (e.g.
+	// "berlin"). Unset fields inherit the global values.
+	RateOverrides map[string]RateLimit `yaml:"rate_overrides"`
+	// BaseURL and SearchPath select the IS24 country site, e.g.
+	// "https://www.immobilienscout24.at". Empty = immobilienscout24.de and
+	// its "/Suche/de/%s/wohnung-mieten" city search (%s = city).
+	BaseURL    string `yaml:"base_url"`
+	SearchPath string `yaml:"search_path"`
+	// Challenge configures how the browser recognizes the WAF robot check.
+	Challenge ChallengeConfig `yaml:"challenge"`
 }
 
+// ChallengeConfig for the WAF robot check in front of IS24 pages. A page is
+// still the challenge while its title contains one of Titles; it is the real
+// page once ReadySelector (a CSS selector) matches. Empty fields keep the
+// built-in defaults (German and English robot-check titles, 30s).
+type ChallengeConfig struct {
+	Titles        []string      `yaml:"titles"`
+	ReadySelector string        `yaml:"ready_selector"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// RateLimit is a request budget for IS24: a token bucket of Burst refilled at
+// MaxRequestsPerMinute, with a random MinDelay..MaxDelay gap per request.
+type RateLimit struct {
+	MaxRequestsPerMinute int           `yaml:"max_requests_per_minute"`
+	Burst                int           `yaml:"burst"`
+	MinDelay             time.Duration `yaml:"min_delay"`
+	MaxDelay             time.Duration `yaml:"max_delay"`
+}
+
+// EffectiveRateLimit returns the rate limit for an is24.rate_overrides key:
+// the override's fields merged over the global ones. Unknown keys get the
+// global limit.
+func (c IS24Config) EffectiveRateLimit(key string) RateLimit {
+	rl := RateLimit{
+		MaxRequestsPerMinute: c.MaxRequestsPerMinute,
+		Burst:                c.Burst,
+		MinDelay:             c.MinDelay,
+		MaxDelay:             c.MaxDelay,
+	}
+	o, ok := c.RateOverrides[key]
+	if !ok {
+		return rl
+	}
+	if o.MaxRequestsPerMinute > 0 {
+		rl.MaxRequestsPerMinute = o.MaxRequestsPerMinute
+	}
+	if o.Burst > 0 {
+		rl.Burst = o.Burst
+	}
+	if o.MinDelay > 0 {
+		rl.MinDelay = o.MinDelay
+	}
+	if o.MaxDelay > 0 {
+		rl.MaxDelay = o.MaxDelay
+	}
+	return rl
+}
+
+// IS24Config.OnExposeFailure modes.
+const (
+	ExposeFailureUseBasic = "use_basic"        // save the sparse search data as is
+	ExposeFailureSkip     = "skip"             // don't save; a later search may pick it up again
+	ExposeFailureRetry    = "retry_next_cycle" // save as incomplete (also without price/rooms/area), notify once a retry completes it
+)
+
+// ContactConfig.QueueOrder modes.
+const (
+	ContactOrderNewest   = "newest"   // first seen by the bot, newest first
+	ContactOrderScore    = "score"    // highest quality score first, newest first on ties
+	ContactOrderBalanced = "balanced" // quality score halved for every day since the bot first saw the listing
+)
+
 // TelegramConfig for Telegram bot settings
 type TelegramConfig struct {
 	BotToken string `yaml:"bot_token"`
 	ChatID   int64  `yaml:"chat_id"`
 	Enabled  bool   `yaml:"enabled"`
+	// SendRetries retries a notification that failed on the network or on
+	// Telegram's side, first after SendRetryBackoff, then doubling.
+	SendRetries      int           `yaml:"send_retries"`
+	SendRetryBackoff time.Duration `yaml:"send_retry_backoff"`
+	// Style of new-listing messages: TelegramStyleCard or TelegramStyleCompact.
+	Style string `yaml:"style"`
 }
 
+// TelegramConfig.Style values.
+const (
+	TelegramStyleCard    = "card"    // multi-line card with all details and link buttons
+	TelegramStyleCompact = "compact" // one line: price · rooms · area · district — link
+)
+
 // OpenAIConfig for GPT message enhancement
 type OpenAIConfig struct {
 	APIKey  string `yaml:"api_key"`
 	Model   string `yaml:"model"`
 	Enabled bool   `yaml:"enabled"`
+	// Request parameters for message personalization.
+	Timeout     time.Duration `yaml:"timeout"`
+	MaxTokens   int           `yaml:"max_tokens"`
+	Temperature float64       `yaml:"temperature"`
+	// DistrictInfo adds a short blurb about the listing's district to the
+	// prompt (built-in list, or DistrictInfoPath to use your own YAML).
+	DistrictInfo     bool   `yaml:"district_info"`
+	DistrictInfoPath string `yaml:"district_info_path"`
+	// StructuredOutput requests the personalization as JSON (opening
+	// sentence + highlights) and builds the sentences from it, instead of
+	// splicing in free text.
+	StructuredOutput bool `yaml:"structured_output"`
+	// MaxConcurrent caps the requests in flight and MinInterval spaces their
+	// starts, so bursts (many queued previews) stay within OpenAI's rate
+	// limits. 0 turns the respective limit off.
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	MinInterval   time.Duration `yaml:"min_interval"`
 }
 
 // EmailConfig for IMAP monitoring of IS24-related provider replies.
@@ -112,11 +325,68 @@ type EmailConfig struct {
 
 // ContactConfig for auto-contact settings
 type ContactConfig struct {
-	Enabled     bool           `yaml:"enabled"`
-	TypeDelay   time.Duration  `yaml:"type_delay"`
-	ActionDelay time.Duration  `yaml:"action_delay"`
-	ChromePath  string         `yaml:"chrome_path"`
-	Profile     ContactProfile `yaml:"profile"`
+	Enabled     bool                `yaml:"enabled"`
+	TypeDelay   time.Duration       `yaml:"type_delay"`
+	ActionDelay time.Duration       `yaml:"action_delay"`
+	ChromePath  string              `yaml:"chrome_path"`
+	Profile     ContactProfile      `yaml:"profile"`
+	Window      ContactWindowConfig `yaml:"window"`
+	// HTTPFirst tries to POST server-rendered contact forms directly before
+	// starting the browser; the browser still handles everything else.
+	// Opt-in: off unless set.
+	HTTPFirst bool `yaml:"http_first"`
+	// FollowUpDays sends a one-time "no reply yet?" reminder this many days
+	// after a successful contact. 0 disables reminders.
+	FollowUpDays int `yaml:"follow_up_days"`
+	// DryRun runs the whole contact pipeline and records the messages as
+	// sent (marked test), but never launches a browser or submits a form.
+	DryRun bool `yaml:"dry_run"`
+	// NotifyUnconfirmed sends a separate warning for contacts that were
+	// submitted without a detected confirmation, so they can be checked by
+	// hand. Off: they are announced like confirmed ones. Either way they
+	// count as contacted and are never resubmitted.
+	NotifyUnconfirmed bool `yaml:"notify_unconfirmed"`
+	// AutoContactPrivateOnly restricts automatic contacts to private
+	// landlords, whatever the chat contact mode says. Agency listings and
+	// listings with an unknown landlord type are only notified.
+	AutoContactPrivateOnly bool `yaml:"auto_contact_private_only"`
+	// RequirePhotosForContact skips automatic contacts for listings without
+	// photos, mostly scams and placeholders; they are still notified.
+	RequirePhotosForContact bool `yaml:"require_photos_for_contact"`
+	// MaxAge limits automatic contacts to listings the bot first saw at
+	// most this long ago; older ones are only notified. 0 = no limit.
+	MaxAge time.Duration `yaml:"max_age"`
+	// AddressDedupWindow skips automatic contacts to an address (street,
+	// house number, postal code) the bot already contacted within this long,
+	// e.g. the same flat relisted under a new ID. 0 = off.
+	AddressDedupWindow time.Duration `yaml:"address_dedup_window"`
+	// QueueOrder decides which listings a contact round sends first:
+	// ContactOrderNewest, ContactOrderScore or ContactOrderBalanced.
+	QueueOrder string `yaml:"queue_order"`
+	// FormTimeout bounds one browser contact attempt: opening, filling and
+	// submitting the form.
+	FormTimeout time.Duration `yaml:"form_timeout"`
+	// DebugSelectors logs, for every browser fill, which selector matched
+	// each form field and which fields none did.
+	DebugSelectors bool `yaml:"debug_selectors"`
+	// FailureHints overrides the suggested action in contact-failed
+	// notifications per category (captcha, cookie_expired, rate_limited,
+	// form_not_found, form_incomplete, timeout, unknown).
+	FailureHints map[string]string `yaml:"failure_hints"`
+	// Subject fills the subject field of forms that have one ("" = "Anfrage
+	// zur Wohnung"); MessageCategory picks their message category by value
+	// or label ("" = the first one offered).
+	Subject         string `yaml:"subject"`
+	MessageCategory string `yaml:"message_category"`
+}
+
+// ContactWindowConfig limits when contact forms are submitted (e.g. only
+// 08:00–21:00), independent of the notification quiet hours. Uses the quiet
+// hours timezone.
+type ContactWindowConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Start   string `yaml:"start"` // e.g. "08:00"
+	End     string `yaml:"end"`   // e.g. "21:00"
 }
 
 // ContactProfile contains applicant information for IS24 forms
@@ -145,21 +415,45 @@ type ContactProfile struct {
 // MessageConfig for contact message templates
 type MessageConfig struct {
 	TemplatePath string `yaml:"template_path"`
+	TemplateDir  string `yaml:"template_dir"` // directory of template variants, overrides template_path
+	Rotation     string `yaml:"rotation"`     // RotationRoundRobin or RotationRandom
 	SenderName   string `yaml:"sender_name"`
 	SenderEmail  string `yaml:"sender_email"`
 	SenderPhone  string `yaml:"sender_phone"`
+
+	// LanguageTemplates maps a language code ("en") to the template for
+	// listings detected as posted in that language; the others get German.
+	LanguageTemplates map[string]string `yaml:"language_templates"`
 }
 
+// MinStoredDescriptionLength keeps enough of the description for the AI
+// prompt, which uses the first 500 characters.
+const MinStoredDescriptionLength = 500
+
+// MessageConfig.Rotation modes for picking a template variant per listing.
+const (
+	RotationRoundRobin = "round_robin"
+	RotationRandom     = "random"
+)
+
 // DefaultConfig returns configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
 		PollInterval: 5 * time.Minute,
 		DatabasePath: "data/immobot.db",
 		LogLevel:     "info",
+
+		MaxStoredDescriptionLength: 4000,
+		DatabaseBusyTimeout:        5 * time.Second,
+		DatabaseMaxOpenConns:       4,
+
 		IS24: IS24Config{
 			MaxRequestsPerMinute: 10,
+			Burst:                2,
 			MinDelay:             2 * time.Second,
 			MaxDelay:             8 * time.Second,
+			ExposeConcurrency:    1,
+			OnExposeFailure:      ExposeFailureUseBasic,
 			UserAgents: []string{
 				"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
 				"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
@@ -169,7 +463,10 @@ func DefaultConfig() *Config {
 			},
 		},
 		Telegram: TelegramConfig{
-			Enabled: false,
+			Enabled:          false,
+			SendRetries:      3,
+			SendRetryBackoff: 2 * time.Second,
+			Style:            TelegramStyleCard,
 		},
 		WhatsApp: WhatsAppConfig{
 			Enabled:   false,
@@ -177,8 +474,27 @@ func DefaultConfig() *Config {
 			LogLevel:  "INFO",
 		},
 		OpenAI: OpenAIConfig{
-			Model:   "gpt-4o-mini",
-			Enabled: false,
+			Model:       "gpt-4o-mini",
+			Enabled:     false,
+			Timeout:     30 * time.Second,
+			MaxTokens:   150,
+			Temperature: 0.7,
+
+			MaxConcurrent: 2,
+			MinInterval:   time.Second,
+		},
+		Filter: FilterConfig{
+			WarmRentFactor:    1.25,
+			MinPlausiblePrice: 100,
+			ImplausiblePrice:  ImplausiblePriceDrop,
+			PriceOnRequest:    PriceOnRequestNotify,
+			ScoreWeights: ScoreWeightsConfig{
+				PricePerSqm: 40,
+				Images:      20,
+				FloorPlan:   10,
+				Private:     15,
+				Description: 15,
+			},
 		},
 		Email: EmailConfig{
 			Enabled:  false,
@@ -186,12 +502,25 @@ func DefaultConfig() *Config {
 			Lookback: 72 * time.Hour,
 		},
 		Contact: ContactConfig{
-			Enabled:     false,
-			TypeDelay:   50 * time.Millisecond,
-			ActionDelay: 1 * time.Second,
+			Enabled:           false,
+			TypeDelay:         50 * time.Millisecond,
+			ActionDelay:       1 * time.Second,
+			HTTPFirst:         false,
+			NotifyUnconfirmed: true,
+			FormTimeout:       2 * time.Minute,
+
+			RequirePhotosForContact: true,
+			AddressDedupWindow:      90 * 24 * time.Hour,
+			QueueOrder:              ContactOrderNewest,
+			Window: ContactWindowConfig{
+				Enabled: false,
+				Start:   "08:00",
+				End:     "21:00",
+			},
 		},
 		Message: MessageConfig{
 			TemplatePath: "configs/message_template.txt",
+			Rotation:     RotationRoundRobin,
 		},
 		QuietHours: QuietHoursConfig{
 			Enabled:  true, // Enabled by default
@@ -199,6 +528,16 @@ func DefaultConfig() *Config {
 			End:      "07:00",
 			Timezone: "Europe/Berlin",
 		},
+		Digest: DigestConfig{
+			Enabled: false,
+			Time:    "20:00",
+		},
+		PriceAlerts: PriceAlertConfig{
+			Enabled:          false,
+			MinChange:        50,
+			MinChangePercent: 5,
+			Cooldown:         24 * time.Hour,
+		},
 		Web: WebConfig{
 			Enabled: false,
 			Addr:    "127.0.0.1:8080",
@@ -274,6 +613,9 @@ func Load(path string) (*Config, error) {
 	if err := applyEnvBool("CONTACT_ENABLED", &cfg.Contact.Enabled); err != nil {
 		return nil, err
 	}
+	if err := applyEnvBool("CONTACT_DRY_RUN", &cfg.Contact.DryRun); err != nil {
+		return nil, err
+	}
 	if v := os.Getenv("CONTACT_CHROME_PATH"); v != "" {
 		cfg.Contact.ChromePath = v
 	}
@@ -318,6 +660,7 @@ func Load(path string) (*Config, error) {
 		cfg.Campaigns = map[string]Campaign{
 			"default": {
 				MessageTemplatePath: cfg.Message.TemplatePath,
+				MessageTemplateDir:  cfg.Message.TemplateDir,
 				Contact:             cfg.Contact.Profile,
 			},
 		}
@@ -341,7 +684,9 @@ func (c *Config) ResolveCampaign(category string) Campaign {
 	}
 	return Campaign{
 		MessageTemplatePath: c.Message.TemplatePath,
+		MessageTemplateDir:  c.Message.TemplateDir,
 		Contact:             c.Contact.Profile,
+		LanguageTemplates:   c.Message.LanguageTemplates,
 	}
 }
 
@@ -352,8 +697,12 @@ func (c *Config) HasCampaign(name string) bool {
 }
 
 func (c *Config) fillCampaign(camp Campaign) Campaign {
-	if camp.MessageTemplatePath == "" {
+	if camp.MessageTemplatePath == "" && camp.MessageTemplateDir == "" {
 		camp.MessageTemplatePath = c.Message.TemplatePath
+		camp.MessageTemplateDir = c.Message.TemplateDir
+	}
+	if len(camp.LanguageTemplates) == 0 {
+		camp.LanguageTemplates = c.Message.LanguageTemplates
 	}
 	// A campaign that omits contact_profile (no name given) uses the global one.
 	if camp.Contact.FirstName == "" && camp.Contact.Email == "" {
@@ -443,6 +792,23 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.DatabasePath) == "" {
 		problems = append(problems, "database_path is required")
 	}
+	if c.DatabaseBusyTimeout < 0 {
+		problems = append(problems, "database_busy_timeout must not be negative")
+	}
+	if c.DatabaseMaxOpenConns < 0 {
+		problems = append(problems, "database_max_open_conns must not be negative")
+	}
+	if c.NoNewListingsAlert < 0 {
+		problems = append(problems, "no_new_listings_alert must not be negative")
+	}
+	if c.Telegram.SendRetries < 0 || c.Telegram.SendRetryBackoff < 0 {
+		problems = append(problems, "telegram.send_retries and telegram.send_retry_backoff must not be negative")
+	}
+	switch c.Telegram.Style {
+	case TelegramStyleCard, TelegramStyleCompact:
+	default:
+		problems = append(problems, "telegram.style must be card or compact")
+	}
 	if strings.TrimSpace(c.IS24.Cookie) == "" {
 		problems = append(problems, "is24.cookie or IS24_COOKIE is required")
 	}
@@ -452,9 +818,75 @@ func (c *Config) Validate() error {
 	if c.IS24.MinDelay < 0 || c.IS24.MaxDelay < 0 {
 		problems = append(problems, "is24 delays must be non-negative")
 	}
+	if c.IS24.ProfileDelay < 0 || c.IS24.ProfileJitter < 0 {
+		problems = append(problems, "is24.profile_delay and profile_jitter must be non-negative")
+	}
+	if c.IS24.StartupDelay < 0 || c.IS24.StartupJitter < 0 {
+		problems = append(problems, "is24.startup_delay and startup_jitter must be non-negative")
+	}
 	if c.IS24.MaxDelay < c.IS24.MinDelay {
 		problems = append(problems, "is24.max_delay must be greater than or equal to min_delay")
 	}
+	if c.IS24.Burst < 0 {
+		problems = append(problems, "is24.burst must be non-negative")
+	}
+	if c.IS24.Challenge.Timeout < 0 {
+		problems = append(problems, "is24.challenge.timeout must be non-negative")
+	}
+	if c.IS24.ExposeConcurrency < 0 {
+		problems = append(problems, "is24.expose_concurrency must be non-negative")
+	}
+	if c.MaxStoredDescriptionLength < 0 || (c.MaxStoredDescriptionLength > 0 && c.MaxStoredDescriptionLength < MinStoredDescriptionLength) {
+		problems = append(problems, fmt.Sprintf("max_stored_description_length must be 0 (unlimited) or at least %d", MinStoredDescriptionLength))
+	}
+	if c.IS24.MaxIdleConns < 0 {
+		problems = append(problems, "is24.max_idle_conns must be non-negative")
+	}
+	if (c.IS24.LocalAddr != "" || c.IS24.MaxIdleConns > 0 || c.IS24.DisableKeepAlives) && !(c.Contact.Enabled && c.Contact.HTTPFirst) {
+		problems = append(problems, "is24.local_addr, max_idle_conns and disable_keep_alives only apply to the HTTP-first contact path; enable contact.enabled and contact.http_first or unset them")
+	}
+	for key, o := range c.IS24.RateOverrides {
+		if o.MaxRequestsPerMinute < 0 || o.Burst < 0 || o.MinDelay < 0 || o.MaxDelay < 0 {
+			problems = append(problems, fmt.Sprintf("is24.rate_overrides.%s values must be non-negative", key))
+		}
+		if rl := c.IS24.EffectiveRateLimit(key); rl.MaxDelay < rl.MinDelay {
+			problems = append(problems, fmt.Sprintf("is24.rate_overrides.%s: max_delay must be greater than or equal to min_delay", key))
+		}
+	}
+	switch c.IS24.OnExposeFailure {
+	case ExposeFailureUseBasic, ExposeFailureSkip, ExposeFailureRetry:
+	default:
+		problems = append(problems, "is24.on_expose_failure must be use_basic, skip or retry_next_cycle")
+	}
+	if c.Filter.WarmRentFactor <= 0 {
+		problems = append(problems, "filter.warm_rent_factor must be greater than 0")
+	}
+	if c.Filter.MinPlausiblePrice < 0 {
+		problems = append(problems, "filter.min_plausible_price must be non-negative")
+	}
+	switch c.Filter.ImplausiblePrice {
+	case ImplausiblePriceDrop, ImplausiblePriceUnknown:
+	default:
+		problems = append(problems, "filter.implausible_price must be drop or unknown")
+	}
+	switch c.Contact.QueueOrder {
+	case ContactOrderNewest, ContactOrderScore, ContactOrderBalanced:
+	default:
+		problems = append(problems, "contact.queue_order must be newest, score or balanced")
+	}
+	switch c.Filter.PriceOnRequest {
+	case PriceOnRequestDrop, PriceOnRequestNotify, PriceOnRequestInclude:
+	default:
+		problems = append(problems, "filter.price_on_request must be drop, notify or include")
+	}
+	if w := c.Filter.ScoreWeights; w.PricePerSqm < 0 || w.Images < 0 || w.FloorPlan < 0 || w.Private < 0 || w.Description < 0 {
+		problems = append(problems, "filter.score_weights must be non-negative")
+	}
+	for _, id := range c.Filter.IgnoreListingIDs {
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			problems = append(problems, fmt.Sprintf("filter.ignore_listing_ids: %q is not an IS24 ID", id))
+		}
+	}
 
 	if c.Telegram.Enabled {
 		if strings.TrimSpace(c.Telegram.BotToken) == "" {
@@ -474,6 +906,21 @@ func (c *Config) Validate() error {
 		if strings.TrimSpace(c.OpenAI.Model) == "" {
 			problems = append(problems, "openai.model is required when openai.enabled is true")
 		}
+		if c.OpenAI.Timeout <= 0 {
+			problems = append(problems, "openai.timeout must be positive")
+		}
+		if c.OpenAI.MaxTokens <= 0 {
+			problems = append(problems, "openai.max_tokens must be positive")
+		}
+		if c.OpenAI.Temperature < 0 || c.OpenAI.Temperature > 2 {
+			problems = append(problems, "openai.temperature must be between 0 and 2")
+		}
+		if c.OpenAI.MaxConcurrent < 0 {
+			problems = append(problems, "openai.max_concurrent must not be negative")
+		}
+		if c.OpenAI.MinInterval < 0 {
+			problems = append(problems, "openai.min_interval must not be negative")
+		}
 	}
 	if c.Email.Enabled {
 		if strings.TrimSpace(c.Email.IMAPHost) == "" {
@@ -489,6 +936,18 @@ func (c *Config) Validate() error {
 			problems = append(problems, "openai must be enabled when email.enabled is true (classification requires it)")
 		}
 	}
+	for lang, path := range c.Message.LanguageTemplates {
+		if path == "" {
+			problems = append(problems, fmt.Sprintf("message.language_templates.%s needs a template path", lang))
+		}
+	}
+	for name, camp := range c.Campaigns {
+		for lang, path := range camp.LanguageTemplates {
+			if path == "" {
+				problems = append(problems, fmt.Sprintf("campaigns.%s.language_templates.%s needs a template path", name, lang))
+			}
+		}
+	}
 	if c.Contact.Enabled {
 		p := c.Contact.Profile
 		required := map[string]string{
@@ -507,6 +966,26 @@ func (c *Config) Validate() error {
 		if c.Contact.TypeDelay < 0 || c.Contact.ActionDelay < 0 {
 			problems = append(problems, "contact delays must be non-negative")
 		}
+		if c.Contact.MaxAge < 0 {
+			problems = append(problems, "contact.max_age must not be negative")
+		}
+		if c.Contact.AddressDedupWindow < 0 {
+			problems = append(problems, "contact.address_dedup_window must not be negative")
+		}
+		if c.Contact.FormTimeout < 0 {
+			problems = append(problems, "contact.form_timeout must be non-negative")
+		}
+		if c.Contact.FollowUpDays < 0 {
+			problems = append(problems, "contact.follow_up_days must be non-negative")
+		}
+		if c.Contact.Window.Enabled {
+			if !validClock(c.Contact.Window.Start) {
+				problems = append(problems, "contact.window.start must use HH:MM")
+			}
+			if !validClock(c.Contact.Window.End) {
+				problems = append(problems, "contact.window.end must use HH:MM")
+			}
+		}
 	}
 	if len(c.Campaigns) > 0 {
 		if strings.TrimSpace(c.DefaultCampaign) == "" {
@@ -515,15 +994,26 @@ func (c *Config) Validate() error {
 			problems = append(problems, "default_campaign must reference a configured campaign")
 		}
 	}
-	if c.Message.TemplatePath == "" && len(c.Campaigns) == 0 {
+	if c.Message.TemplatePath == "" && c.Message.TemplateDir == "" && len(c.Campaigns) == 0 {
 		problems = append(problems, "message.template_path is required")
 	}
+	switch c.Message.Rotation {
+	case RotationRoundRobin, RotationRandom:
+	default:
+		problems = append(problems, "message.rotation must be round_robin or random")
+	}
 	if !validClock(c.QuietHours.Start) {
 		problems = append(problems, "quiet_hours.start must use HH:MM")
 	}
 	if !validClock(c.QuietHours.End) {
 		problems = append(problems, "quiet_hours.end must use HH:MM")
 	}
+	if c.Digest.Enabled && !validClock(c.Digest.Time) {
+		problems = append(problems, "digest.time must use HH:MM")
+	}
+	if a := c.PriceAlerts; a.MinChange < 0 || a.MinChangePercent < 0 || a.Cooldown < 0 {
+		problems = append(problems, "price_alerts.min_change, min_change_percent and cooldown must be non-negative")
+	}
 
 	if len(problems) > 0 {
 		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
@@ -533,40 +1023,95 @@ func (c *Config) Validate() error {
 
 // IsQuietTime checks if the current time is within quiet hours
 func (c *Config) IsQuietTime() bool {
+	return c.IsQuietTimeAt(time.Now())
+}
+
+// IsQuietTimeAt is IsQuietTime for an explicit instant (used by tests).
+func (c *Config) IsQuietTimeAt(now time.Time) bool {
 	if !c.QuietHours.Enabled {
 		return false
 	}
-	return c.IsWithinQuietHours()
+	return c.IsWithinQuietHoursAt(now)
 }
 
 // IsWithinQuietHours checks the configured quiet-hours window regardless of the
 // enabled flag. Runtime command overrides use this to turn quiet hours on even
 // when the static config default is off.
 func (c *Config) IsWithinQuietHours() bool {
-	// Load timezone
+	return c.IsWithinQuietHoursAt(time.Now())
+}
+
+// IsWithinQuietHoursAt reports whether now falls inside the quiet-hours window.
+// The window is half-open [start, end) in wall-clock minutes of the configured
+// timezone: with 22:00–07:00, 22:00 and 06:59 are quiet, 07:00 is not. An
+// empty window (start == end) is never quiet. An unknown timezone falls back
+// to the local one.
+func (c *Config) IsWithinQuietHoursAt(now time.Time) bool {
+	return inClockWindow(now, c.QuietHours.Start, c.QuietHours.End, c.QuietHours.Timezone)
+}
+
+// IsContactAllowed reports whether contact forms may be submitted right now.
+func (c *Config) IsContactAllowed() bool {
+	return c.IsContactAllowedAt(time.Now())
+}
+
+// IsContactAllowedAt reports whether now falls inside the contact window. With
+// the window disabled contacting is always allowed (quiet hours still apply
+// in the scheduler).
+func (c *Config) IsContactAllowedAt(now time.Time) bool {
+	if !c.Contact.Window.Enabled {
+		return true
+	}
+	return inClockWindow(now, c.Contact.Window.Start, c.Contact.Window.End, c.QuietHours.Timezone)
+}
+
+// NextDigestAt returns the first daily digest time strictly after now, in the
+// quiet hours timezone (local time if it is unknown).
+func (c *Config) NextDigestAt(now time.Time) time.Time {
 	loc, err := time.LoadLocation(c.QuietHours.Timezone)
 	if err != nil {
 		loc = time.Local
 	}
+	now = now.In(loc)
+	hour, min := parseTimeString(c.Digest.Time)
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, hour, min, 0, 0, loc)
+	}
+	return next
+}
+
+// inClockWindow reports whether now lies in the half-open wall-clock window
+// [start, end) in timezone tz, wrapping past midnight when start > end.
+func inClockWindow(now time.Time, start, end, tz string) bool {
+	// Load timezone
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.Local
+	}
 
-	now := time.Now().In(loc)
+	now = now.In(loc)
 	currentMinutes := now.Hour()*60 + now.Minute()
 
 	// Parse start time
-	startHour, startMin := parseTimeString(c.QuietHours.Start)
+	startHour, startMin := parseTimeString(start)
 	startMinutes := startHour*60 + startMin
 
 	// Parse end time
-	endHour, endMin := parseTimeString(c.QuietHours.End)
+	endHour, endMin := parseTimeString(end)
 	endMinutes := endHour*60 + endMin
 
-	// Handle overnight quiet hours (e.g., 22:00 - 07:00)
+	if startMinutes == endMinutes {
+		return false
+	}
+
+	// Handle overnight windows (e.g., 22:00 - 07:00)
 	if startMinutes > endMinutes {
-		// Quiet time spans midnight
+		// Window spans midnight
 		return currentMinutes >= startMinutes || currentMinutes < endMinutes
 	}
 
-	// Same-day quiet hours (e.g., 12:00 - 14:00)
+	// Same-day window (e.g., 12:00 - 14:00)
 	return currentMinutes >= startMinutes && currentMinutes < endMinutes
 }
 
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func testCfg() *Config {
@@ -39,6 +40,22 @@ func TestResolveCampaignFillsContactFromGlobal(t *testing.T) {
 	}
 }
 
+func TestResolveCampaignTemplateDir(t *testing.T) {
+	c := &Config{
+		Message: MessageConfig{TemplatePath: "global.txt", TemplateDir: "variants"},
+		Campaigns: map[string]Campaign{
+			"wg":     {MessageTemplatePath: "wg.txt"},
+			"single": {},
+		},
+	}
+	if got := c.ResolveCampaign("wg"); got.MessageTemplatePath != "wg.txt" || got.MessageTemplateDir != "" {
+		t.Errorf("own template must not inherit the global dir: %+v", got)
+	}
+	if got := c.ResolveCampaign("single"); got.MessageTemplateDir != "variants" {
+		t.Errorf("campaign without template should inherit the global dir: %+v", got)
+	}
+}
+
 func TestResolveCampaignFallsBackToDefault(t *testing.T) {
 	c := testCfg()
 	got := c.ResolveCampaign("does-not-exist")
@@ -141,3 +158,242 @@ func TestValidateRequiresContactProfileWhenEnabled(t *testing.T) {
 		t.Fatalf("expected contact profile error, got %v", err)
 	}
 }
+
+func TestValidateOpenAIParams(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IS24.Cookie = "session=value"
+	cfg.OpenAI.Enabled = true
+	cfg.OpenAI.APIKey = "sk-test"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults should validate: %v", err)
+	}
+
+	cfg.OpenAI.MaxTokens = 0
+	cfg.OpenAI.Temperature = 2.5
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"openai.max_tokens", "openai.temperature"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %s error, got %v", want, err)
+		}
+	}
+}
+
+func TestValidateImplausiblePrice(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IS24.Cookie = "session=value"
+	if cfg.Filter.MinPlausiblePrice != 100 || cfg.Filter.ImplausiblePrice != ImplausiblePriceDrop {
+		t.Fatalf("defaults = %d/%q", cfg.Filter.MinPlausiblePrice, cfg.Filter.ImplausiblePrice)
+	}
+	cfg.Filter.ImplausiblePrice = "ignore"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "filter.implausible_price") {
+		t.Errorf("unknown mode should be rejected, got %v", err)
+	}
+	cfg.Filter.ImplausiblePrice = ImplausiblePriceUnknown
+	cfg.Filter.MinPlausiblePrice = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "filter.min_plausible_price") {
+		t.Errorf("negative floor should be rejected, got %v", err)
+	}
+}
+
+func TestValidateTransportNeedsHTTPContact(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IS24.Cookie = "session=value"
+	cfg.IS24.LocalAddr = "eth1"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "is24.local_addr") {
+		t.Errorf("transport options without HTTP contact should be rejected, got %v", err)
+	}
+	cfg.IS24.LocalAddr = ""
+	cfg.IS24.DisableKeepAlives = true
+	cfg.Contact.Enabled = true
+	cfg.Contact.HTTPFirst = false
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "is24.local_addr") {
+		t.Errorf("transport options with browser-only contact should be rejected, got %v", err)
+	}
+}
+
+func TestValidateMaxStoredDescriptionLength(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IS24.Cookie = "session=value"
+	for _, n := range []int{0, MinStoredDescriptionLength, 10000} {
+		cfg.MaxStoredDescriptionLength = n
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("%d should be valid: %v", n, err)
+		}
+	}
+	for _, n := range []int{-1, 100} {
+		cfg.MaxStoredDescriptionLength = n
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "max_stored_description_length") {
+			t.Errorf("%d should be rejected, got %v", n, err)
+		}
+	}
+}
+
+func TestEffectiveRateLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IS24.Cookie = "session=value"
+	cfg.IS24.RateOverrides = map[string]RateLimit{
+		"berlin": {MaxRequestsPerMinute: 30, MinDelay: time.Second},
+	}
+	got := cfg.IS24.EffectiveRateLimit("berlin")
+	want := RateLimit{MaxRequestsPerMinute: 30, Burst: cfg.IS24.Burst, MinDelay: time.Second, MaxDelay: cfg.IS24.MaxDelay}
+	if got != want {
+		t.Errorf("berlin = %+v, want %+v", got, want)
+	}
+	if got := cfg.IS24.EffectiveRateLimit("hamburg"); got.MaxRequestsPerMinute != cfg.IS24.MaxRequestsPerMinute || got.MinDelay != cfg.IS24.MinDelay {
+		t.Errorf("unknown key should get the global limit, got %+v", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("valid override rejected: %v", err)
+	}
+
+	// An override min_delay above the inherited max_delay is inconsistent.
+	cfg.IS24.RateOverrides["berlin"] = RateLimit{MinDelay: time.Hour}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "is24.rate_overrides.berlin") {
+		t.Errorf("min_delay > max_delay should be rejected, got %v", err)
+	}
+}
+
+func TestIsWithinQuietHoursAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	at := func(h, m int) time.Time { return time.Date(2026, 6, 15, h, m, 0, 0, berlin) }
+
+	tests := []struct {
+		name       string
+		start, end string
+		now        time.Time
+		want       bool
+	}{
+		{"overnight late evening", "22:00", "07:00", at(23, 30), true},
+		{"overnight after midnight", "22:00", "07:00", at(3, 0), true},
+		{"overnight midday", "22:00", "07:00", at(12, 0), false},
+		{"overnight start minute is quiet", "22:00", "07:00", at(22, 0), true},
+		{"overnight minute before start", "22:00", "07:00", at(21, 59), false},
+		{"overnight minute before end", "22:00", "07:00", at(6, 59), true},
+		{"overnight end minute is active", "22:00", "07:00", at(7, 0), false},
+		{"overnight midnight", "22:00", "07:00", at(0, 0), true},
+		{"same-day inside", "12:00", "14:00", at(13, 0), true},
+		{"same-day start minute", "12:00", "14:00", at(12, 0), true},
+		{"same-day end minute", "12:00", "14:00", at(14, 0), false},
+		{"same-day before", "12:00", "14:00", at(11, 59), false},
+		{"start until midnight", "22:00", "00:00", at(23, 59), true},
+		{"start until midnight end", "22:00", "00:00", at(0, 0), false},
+		{"empty window", "07:00", "07:00", at(7, 0), false},
+		{"other timezone converted", "22:00", "07:00", time.Date(2026, 6, 15, 21, 30, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{QuietHours: QuietHoursConfig{Start: tt.start, End: tt.end, Timezone: "Europe/Berlin"}}
+			if got := c.IsWithinQuietHoursAt(tt.now); got != tt.want {
+				t.Errorf("IsWithinQuietHoursAt(%s) = %v, want %v", tt.now.In(berlin).Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWithinQuietHoursAtDST(t *testing.T) {
+	c := &Config{QuietHours: QuietHoursConfig{Start: "02:00", End: "03:00", Timezone: "Europe/Berlin"}}
+
+	// Spring forward (2026-03-29): 02:00 CET jumps to 03:00 CEST, so the
+	// window never occurs that night.
+	if c.IsWithinQuietHoursAt(time.Date(2026, 3, 29, 0, 59, 0, 0, time.UTC)) {
+		t.Error("01:59 CET should not be quiet")
+	}
+	if c.IsWithinQuietHoursAt(time.Date(2026, 3, 29, 1, 0, 0, 0, time.UTC)) {
+		t.Error("03:00 CEST (right after the jump) should not be quiet")
+	}
+
+	// Fall back (2026-10-25): 02:30 happens twice, both are quiet.
+	if !c.IsWithinQuietHoursAt(time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC)) {
+		t.Error("first 02:30 (CEST) should be quiet")
+	}
+	if !c.IsWithinQuietHoursAt(time.Date(2026, 10, 25, 1, 30, 0, 0, time.UTC)) {
+		t.Error("second 02:30 (CET) should be quiet")
+	}
+	if c.IsWithinQuietHoursAt(time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC)) {
+		t.Error("03:00 CET should not be quiet")
+	}
+}
+
+func TestIsWithinQuietHoursAtInvalidTimezone(t *testing.T) {
+	orig := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = orig }()
+
+	c := &Config{QuietHours: QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}}
+	if !c.IsWithinQuietHoursAt(time.Date(2026, 6, 15, 23, 0, 0, 0, time.UTC)) {
+		t.Error("invalid timezone should fall back to local time (23:00 UTC is quiet)")
+	}
+	if c.IsWithinQuietHoursAt(time.Date(2026, 6, 15, 21, 30, 0, 0, time.UTC)) {
+		t.Error("invalid timezone should fall back to local time (21:30 UTC is not quiet)")
+	}
+}
+
+func TestIsQuietTimeAtRespectsEnabled(t *testing.T) {
+	c := &Config{QuietHours: QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}}
+	night := time.Date(2026, 6, 15, 23, 0, 0, 0, time.UTC)
+	if c.IsQuietTimeAt(night) {
+		t.Error("disabled quiet hours should never be quiet")
+	}
+	c.QuietHours.Enabled = true
+	if !c.IsQuietTimeAt(night) {
+		t.Error("enabled quiet hours should be quiet at 23:00")
+	}
+}
+
+func TestIsContactAllowedAt(t *testing.T) {
+	c := &Config{QuietHours: QuietHoursConfig{Timezone: "UTC"}}
+	night := time.Date(2026, 6, 15, 3, 14, 0, 0, time.UTC)
+	if !c.IsContactAllowedAt(night) {
+		t.Error("disabled contact window should always allow contacting")
+	}
+
+	c.Contact.Window = ContactWindowConfig{Enabled: true, Start: "08:00", End: "21:00"}
+	for _, tt := range []struct {
+		h, m int
+		want bool
+	}{
+		{3, 14, false},
+		{7, 59, false},
+		{8, 0, true},
+		{20, 59, true},
+		{21, 0, false},
+	} {
+		now := time.Date(2026, 6, 15, tt.h, tt.m, 0, 0, time.UTC)
+		if got := c.IsContactAllowedAt(now); got != tt.want {
+			t.Errorf("IsContactAllowedAt(%02d:%02d) = %v, want %v", tt.h, tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestNextDigestAt(t *testing.T) {
+	c := &Config{
+		QuietHours: QuietHoursConfig{Timezone: "Europe/Berlin"},
+		Digest:     DigestConfig{Enabled: true, Time: "20:00"},
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+
+	for _, tt := range []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"later today", time.Date(2026, 6, 15, 9, 0, 0, 0, berlin), time.Date(2026, 6, 15, 20, 0, 0, 0, berlin)},
+		{"exactly at digest time", time.Date(2026, 6, 15, 20, 0, 0, 0, berlin), time.Date(2026, 6, 16, 20, 0, 0, 0, berlin)},
+		{"after digest time", time.Date(2026, 6, 15, 21, 30, 0, 0, berlin), time.Date(2026, 6, 16, 20, 0, 0, 0, berlin)},
+		// 25-hour day: still 20:00 wall clock.
+		{"across DST end", time.Date(2026, 10, 24, 21, 0, 0, 0, berlin), time.Date(2026, 10, 25, 20, 0, 0, 0, berlin)},
+	} {
+		if got := c.NextDigestAt(tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: NextDigestAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
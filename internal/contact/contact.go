@@ -2,9 +2,14 @@ package contact
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chromedp/cdproto/network"
@@ -36,14 +41,54 @@ type Profile struct {
 	CommercialUse bool
 }
 
+// ErrUnconfirmed means the form was submitted but no confirmation was
+// detected afterwards. The message may well have gone out, so callers must
+// not retry; the contact needs a manual check on IS24.
+var ErrUnconfirmed = errors.New("contact submitted but not confirmed")
+
+// ErrRequiredFieldsEmpty means required form fields were still empty after
+// filling, so the form was not submitted. The wrapping error names them.
+var ErrRequiredFieldsEmpty = errors.New("required form fields empty")
+
+// ErrSubmitDisabled means the form's send button stayed disabled, usually
+// because a field the static selectors don't know is still unset.
+var ErrSubmitDisabled = errors.New("contact form send button stayed disabled")
+
+// defaultSubject fills the subject field some contact forms have.
+const defaultSubject = "Anfrage zur Wohnung"
+
+// submitEnableWait is how long the send button gets to become enabled after
+// the form is filled.
+const submitEnableWait = 10 * time.Second
+
+// defaultBaseURL is the IS24 site contacted unless SetBaseURL picks another
+// country's.
+const defaultBaseURL = "https://www.immobilienscout24.de"
+
+// defaultFormTimeout bounds one browser contact attempt: loading, filling and
+// submitting the form.
+const defaultFormTimeout = 2 * time.Minute
+
 // Submitter handles contact form submission via browser automation
 type Submitter struct {
 	cookie     string
 	behavior   *antidetect.HumanBehavior
 	profile    Profile
 	chromePath string
-	mapper     FieldMapper // optional LLM fallback when static-selector fill fails
+	mapper     FieldMapper   // optional LLM fallback when static-selector fill fails
+	httpFirst  bool          // try a direct HTTP form POST before the browser
+	timeout    time.Duration // one browser attempt: load, fill, submit
+	baseURL    string        // IS24 site origin
+	subject    string        // subject field, where the form has one
+	category   string        // message category; "" = first offered
+	limiter    *antidetect.RateLimiter
+	transport  http.RoundTripper // HTTP-first path; nil = net/http default
 	logger     *slog.Logger
+
+	// debugSelectors logs which selector filled each field (SelectorReport).
+	debugSelectors bool
+	reportMu       sync.Mutex
+	lastReport     SelectorReport
 }
 
 // NewSubmitter creates a new contact form submitter. mapper is optional: when
@@ -59,16 +104,111 @@ func NewSubmitter(cookie string, profile Profile, chromePath string, behavior *a
 		profile:    profile,
 		chromePath: chromePath,
 		mapper:     mapper,
+		timeout:    defaultFormTimeout,
+		baseURL:    defaultBaseURL,
+		subject:    defaultSubject,
 		logger:     logger,
 	}
 }
 
+// SetBaseURL sets the IS24 site (e.g. "https://www.immobilienscout24.at")
+// whose exposes are contacted and whose domain gets the session cookie.
+// Empty keeps immobilienscout24.de.
+func (s *Submitter) SetBaseURL(baseURL string) {
+	if baseURL != "" {
+		s.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// cookieDomain covers the site's subdomains (".immobilienscout24.at").
+func (s *Submitter) cookieDomain() string {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return ""
+	}
+	return "." + strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// SetFormTimeout bounds one browser contact attempt. Non-positive keeps the
+// default of two minutes.
+func (s *Submitter) SetFormTimeout(d time.Duration) {
+	if d > 0 {
+		s.timeout = d
+	}
+}
+
+// SetSubject sets the subject and message category used by forms that have
+// such fields; the send button stays disabled until they are set. An empty
+// subject keeps "Anfrage zur Wohnung", an empty category (or one the form
+// does not offer) picks the form's first category.
+func (s *Submitter) SetSubject(subject, category string) {
+	if subject != "" {
+		s.subject = subject
+	}
+	s.category = category
+}
+
+// SetDebugSelectors logs, after every browser fill, which selector matched
+// each form field and which fields none did.
+func (s *Submitter) SetDebugSelectors(on bool) {
+	s.debugSelectors = on
+}
+
+// LastSelectorReport returns the selector report of the most recent browser
+// fill, nil before the first one.
+func (s *Submitter) LastSelectorReport() SelectorReport {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+	return s.lastReport
+}
+
+// SetRateLimiter makes form loads and submissions share the IS24 request
+// budget with searches and expose fetches. Without one they are unpaced.
+func (s *Submitter) SetRateLimiter(rl *antidetect.RateLimiter) {
+	s.limiter = rl
+}
+
+// SetTransport sets the transport of the HTTP-first path (source IP binding,
+// connection reuse). The browser path is not affected.
+func (s *Submitter) SetTransport(rt http.RoundTripper) {
+	s.transport = rt
+}
+
+// waitTurn blocks until the next IS24 request may go out.
+func (s *Submitter) waitTurn(ctx context.Context) error {
+	if s.limiter == nil {
+		return nil
+	}
+	return s.limiter.Wait(ctx)
+}
+
 // Submit fills and submits the IS24 contact form for a listing using the given
 // applicant profile (per-campaign; falls back to the submitter's default when zero).
+// The contact entry points are tried in order; the one that showed the form
+// is stored in listing.ContactFormURL.
 func (s *Submitter) Submit(ctx context.Context, listing *domain.Listing, message string, profile Profile) error {
 	if profile == (Profile{}) {
 		profile = s.profile
 	}
+
+	entries := contactEntries(listing, s.baseURL)
+
+	// Phase 0: plain HTTP POST for server-rendered forms. Only a clear
+	// "can't" or "rejected" falls through to the browser; an unconfirmed
+	// POST may have gone out, so it is reported instead of retried.
+	if s.httpFirst {
+		err := s.submitHTTP(ctx, entries[0].URL, message, profile)
+		if err == nil {
+			listing.ContactFormURL = entries[0].URL
+			s.logger.Info("contact submitted via http", "is24_id", listing.IS24ID, "contact_url", entries[0].URL)
+			return nil
+		}
+		if !errors.Is(err, errHTTPUnsupported) && !errors.Is(err, errHTTPRejected) {
+			return err
+		}
+		s.logger.Debug("http contact not possible, using browser", "is24_id", listing.IS24ID, "reason", err)
+	}
+
 	// Create browser context with options
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
 		chromedp.Flag("headless", true),
@@ -90,38 +230,44 @@ func (s *Submitter) Submit(ctx context.Context, listing *domain.Listing, message
 	defer browserCancel()
 
 	// Set timeout
-	browserCtx, cancel := context.WithTimeout(browserCtx, 2*time.Minute)
+	browserCtx, cancel := context.WithTimeout(browserCtx, s.timeout)
 	defer cancel()
 
-	// Build contact URL
-	contactURL := listing.ContactFormURL
-	if contactURL == "" {
-		contactURL = fmt.Sprintf("https://www.immobilienscout24.de/expose/%s#/basicContact/email", listing.IS24ID)
+	// Phase 1: open the form through the first entry point that shows it. If
+	// none does, the page is not reachable (WAF, cookie, removed listing) —
+	// the LLM fallback can't help, so abort.
+	if err := chromedp.Run(browserCtx, s.setCookies()); err != nil {
+		return fmt.Errorf("start browser: %w", err)
 	}
-
-	// Phase 1: navigate and wait for the form. If this fails the page is not
-	// reachable (WAF, cookie, bad URL) — the LLM fallback can't help, so abort.
-	if err := chromedp.Run(browserCtx,
-		s.setCookies(),
-		chromedp.Navigate(contactURL),
-		chromedp.Sleep(s.behavior.ThinkPause()),
-		chromedp.WaitVisible(`form[data-qa="contactForm"], .contact-form, #contactForm`, chromedp.ByQuery),
-	); err != nil {
-		return fmt.Errorf("contact form not reachable: %w", err)
+	contactURL, err := s.openContactForm(browserCtx, entries)
+	if err != nil {
+		return err
 	}
+	listing.ContactFormURL = contactURL
+	s.logger.Info("contact form opened", "is24_id", listing.IS24ID, "contact_url", contactURL)
 
-	// Phase 2: fast path — fill via hard-coded selectors, submit, verify.
+	// Phase 2: fast path — fill via hard-coded selectors, submit, verify. A
+	// required field the selectors missed stops it before the click; the LLM
+	// fallback then gets to fill the rest.
+	report := SelectorReport{}
 	fastErr := chromedp.Run(browserCtx,
-		s.fillFormWithDelay(message, profile),
+		s.fillFormWithDelay(message, profile, report),
+		s.checkRequiredFields(),
 		s.submitForm(),
 		chromedp.Sleep(2*time.Second),
 		// Verify that the page moved into a success state. Without this a
 		// validation error after the click would be recorded as a real contact.
 		s.ensureSubmitted(),
 	)
+	s.reportSelectors(listing.IS24ID, report)
 	if fastErr == nil {
 		return nil
 	}
+	// The form is gone without a confirmation: a second fill could send the
+	// message twice.
+	if errors.Is(fastErr, ErrUnconfirmed) {
+		return fastErr
+	}
 
 	// Phase 3: LLM fallback. Static selectors likely drifted from IS24's DOM;
 	// let the mapper read the live form and decide how to fill it.
@@ -132,12 +278,145 @@ func (s *Submitter) Submit(ctx context.Context, listing *domain.Listing, message
 		"is24_id", listing.IS24ID, "error", fastErr)
 
 	if err := s.fillViaLLM(browserCtx, message, profile); err != nil {
+		if errors.Is(err, ErrUnconfirmed) {
+			return err
+		}
 		return fmt.Errorf("contact submission failed (static: %v; llm fallback: %w)", fastErr, err)
 	}
 	s.logger.Info("contact submitted via llm fallback", "is24_id", listing.IS24ID)
 	return nil
 }
 
+// reportSelectors keeps report as the last one and, in debug mode, logs it.
+func (s *Submitter) reportSelectors(is24ID string, report SelectorReport) {
+	s.reportMu.Lock()
+	s.lastReport = report
+	s.reportMu.Unlock()
+	if s.debugSelectors {
+		s.logger.Info("contact form selectors", "is24_id", is24ID,
+			"missing", strings.Join(report.Missing(), ", "), "matched", report.String())
+	}
+}
+
+// contactFormSelector matches the contact form in its known variants.
+const contactFormSelector = `form[data-qa="contactForm"], .contact-form, #contactForm`
+
+// contactFormWait is how long one entry point gets to show the form.
+const contactFormWait = 20 * time.Second
+
+// contactButtonSelectors open the contact form from the expose page.
+var contactButtonSelectors = []string{
+	`[data-qa="sendButton"]`,
+	`[data-qa="contactButton"]`,
+	`[data-testid="contact-button"]`,
+	`.is24-expose-contact-button`,
+	`a[href*="basicContact"]`,
+}
+
+// contactEntry is one way into a listing's contact form. Button, if set, is
+// clicked after loading URL to open the form.
+type contactEntry struct {
+	URL    string
+	Button bool
+}
+
+// contactEntries lists a listing's contact entry points in the order they
+// are tried: the Kontaktformular link parsed from the expose (or the URL that
+// worked last time), the expose's email-contact route, and the expose page's
+// contact button.
+func contactEntries(listing *domain.Listing, baseURL string) []contactEntry {
+	expose := baseURL + "/expose/" + listing.IS24ID
+	candidates := []contactEntry{
+		{URL: listing.ContactFormURL},
+		{URL: expose + "#/basicContact/email"},
+		{URL: expose, Button: true},
+	}
+	// The expose page itself only works through its button.
+	if listing.ContactFormURL == expose {
+		candidates = candidates[1:]
+	}
+	var entries []contactEntry
+	seen := make(map[string]bool)
+	for _, e := range candidates {
+		if e.URL == "" || (!e.Button && seen[e.URL]) {
+			continue
+		}
+		seen[e.URL] = true
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// openContactForm loads the entry points in order until one shows the
+// contact form and returns its URL. Fails only if none does.
+func (s *Submitter) openContactForm(ctx context.Context, entries []contactEntry) (string, error) {
+	var errs []error
+	for _, e := range entries {
+		if err := s.waitTurn(ctx); err != nil {
+			return "", err
+		}
+		err := s.tryContactEntry(ctx, e)
+		if err == nil {
+			return e.URL, nil
+		}
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("contact form not reachable: %w", ctx.Err())
+		}
+		s.logger.Debug("contact entry point failed", "url", e.URL, "button", e.Button, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", e.URL, err))
+	}
+	return "", fmt.Errorf("%w: %w", ErrFormNotFound, errors.Join(errs...))
+}
+
+// tryContactEntry navigates to one entry point and waits for the form.
+func (s *Submitter) tryContactEntry(ctx context.Context, e contactEntry) error {
+	if err := chromedp.Run(ctx,
+		chromedp.Navigate(e.URL),
+		chromedp.Sleep(s.behavior.ThinkPause()),
+	); err != nil {
+		return err
+	}
+	if e.Button {
+		var clicked bool
+		js := fmt.Sprintf(`(() => {
+			for (const sel of %s) {
+				const el = document.querySelector(sel);
+				if (el) { el.click(); return true; }
+			}
+			return false;
+		})()`, jsStringArray(contactButtonSelectors))
+		if err := chromedp.Run(ctx, chromedp.Evaluate(js, &clicked)); err != nil {
+			return err
+		}
+		if !clicked {
+			return errors.New("no contact button")
+		}
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, contactFormWait)
+	defer cancel()
+	err := chromedp.Run(waitCtx, chromedp.WaitVisible(contactFormSelector, chromedp.ByQuery))
+	if err != nil && ctx.Err() == nil {
+		// Tell a blocked page (robot check, login, rate limit) apart from a
+		// listing that simply has no form.
+		var href, title string
+		if chromedp.Run(ctx, chromedp.Location(&href), chromedp.Title(&title)) == nil {
+			if blocked := blockedPageErr(href, title); blocked != nil {
+				return fmt.Errorf("%w (title %q)", blocked, title)
+			}
+		}
+	}
+	return err
+}
+
+// jsStringArray renders ss as a JavaScript array literal.
+func jsStringArray(ss []string) string {
+	quoted := make([]string, len(ss))
+	for i, v := range ss {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 func (s *Submitter) setCookies() chromedp.ActionFunc {
 	return func(ctx context.Context) error {
 		if s.cookie == "" {
@@ -148,7 +427,7 @@ func (s *Submitter) setCookies() chromedp.ActionFunc {
 		cookies := parseCookieString(s.cookie)
 		for _, cookie := range cookies {
 			err := network.SetCookie(cookie.Name, cookie.Value).
-				WithDomain(".immobilienscout24.de").
+				WithDomain(s.cookieDomain()).
 				WithPath("/").
 				Do(ctx)
 			if err != nil {
@@ -192,7 +471,7 @@ func (s *Submitter) ensureSubmitted() chromedp.ActionFunc {
 			if state.FormOpen {
 				return fmt.Errorf("contact form submission not confirmed")
 			}
-			return fmt.Errorf("contact submission confirmation not detected")
+			return fmt.Errorf("%w: form closed without confirmation", ErrUnconfirmed)
 		}
 		return nil
 	}
@@ -230,12 +509,12 @@ func parseCookieString(cookieStr string) []Cookie {
 	return cookies
 }
 
-func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.ActionFunc {
+func (s *Submitter) fillFormWithDelay(message string, profile Profile, report SelectorReport) chromedp.ActionFunc {
 	return func(ctx context.Context) error {
 		p := profile
 
 		// Try to select "Mit Profil bewerben" (Apply with profile) if available
-		s.tryClick(ctx, []string{
+		s.tryClick(ctx, report, "apply_with_profile", []string{
 			`input[name="applyWithProfile"][value="true"]`,
 			`input[type="radio"][value="true"]`,
 			`label:contains("Mit Profil") input`,
@@ -244,7 +523,7 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 		time.Sleep(s.behavior.ActionPause())
 
 		// Select Anrede (Salutation)
-		s.trySelect(ctx, []string{
+		s.trySelect(ctx, report, "salutation", []string{
 			`select[name="salutation"]`,
 			`select[name="contactFormMessage.salutation"]`,
 			`select[data-qa="salutation"]`,
@@ -252,14 +531,14 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 		time.Sleep(s.behavior.ActionPause())
 
 		// Fill Vorname (First name)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "first_name", []string{
 			`input[name="firstName"]`,
 			`input[name="contactFormMessage.firstName"]`,
 			`input[data-qa="firstName"]`,
 		}, p.FirstName)
 
 		// Fill Nachname (Last name)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "last_name", []string{
 			`input[name="lastName"]`,
 			`input[name="contactFormMessage.lastName"]`,
 			`input[data-qa="lastName"]`,
@@ -267,14 +546,14 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 
 		// Fill full name if separate fields don't exist
 		fullName := p.FirstName + " " + p.LastName
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "full_name", []string{
 			`input[name="contactFormMessage.fullName"]`,
 			`input[name="name"]`,
 			`input[data-qa="fullName"]`,
 		}, fullName)
 
 		// Fill Email
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "email", []string{
 			`input[name="contactFormMessage.emailAddress"]`,
 			`input[name="email"]`,
 			`input[type="email"]`,
@@ -282,7 +561,7 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 		}, p.Email)
 
 		// Fill Telefon
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "phone", []string{
 			`input[name="contactFormMessage.phoneNumber"]`,
 			`input[name="phone"]`,
 			`input[type="tel"]`,
@@ -290,21 +569,21 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 		}, p.Phone)
 
 		// Fill Straße (Street)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "street", []string{
 			`input[name="street"]`,
 			`input[name="contactFormMessage.street"]`,
 			`input[data-qa="street"]`,
 		}, p.Street)
 
 		// Fill Hausnummer (House number)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "house_number", []string{
 			`input[name="houseNumber"]`,
 			`input[name="contactFormMessage.houseNumber"]`,
 			`input[data-qa="houseNumber"]`,
 		}, p.HouseNumber)
 
 		// Fill PLZ (Postal code)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "postal_code", []string{
 			`input[name="postalCode"]`,
 			`input[name="zipCode"]`,
 			`input[name="contactFormMessage.postalCode"]`,
@@ -312,21 +591,21 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 		}, p.PostalCode)
 
 		// Fill Ort (City)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "city", []string{
 			`input[name="city"]`,
 			`input[name="contactFormMessage.city"]`,
 			`input[data-qa="city"]`,
 		}, p.City)
 
 		// Fill Anzahl Erwachsene (Adults)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "adults", []string{
 			`input[name="numberOfAdults"]`,
 			`input[name="adults"]`,
 			`input[data-qa="numberOfAdults"]`,
 		}, fmt.Sprintf("%d", p.Adults))
 
 		// Fill Anzahl Kinder (Children)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "children", []string{
 			`input[name="numberOfChildren"]`,
 			`input[name="children"]`,
 			`input[data-qa="numberOfChildren"]`,
@@ -334,19 +613,19 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 
 		// Haustiere (Pets) - select No
 		if !p.Pets {
-			s.tryClick(ctx, []string{
+			s.tryClick(ctx, report, "pets", []string{
 				`input[name="pets"][value="false"]`,
 				`input[name="hasPets"][value="NO"]`,
 				`input[data-qa="pets-no"]`,
 			})
-			s.trySelect(ctx, []string{
+			s.trySelect(ctx, report, "pets", []string{
 				`select[name="pets"]`,
 				`select[name="hasPets"]`,
 			}, "NO")
 		}
 
 		// Fill Einkommen (Income)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "income", []string{
 			`input[name="income"]`,
 			`input[name="monthlyIncome"]`,
 			`input[name="netHouseholdIncome"]`,
@@ -354,18 +633,18 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 		}, fmt.Sprintf("%d", p.Income))
 
 		// Fill Einzugstermin (Move-in date)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "move_in_date", []string{
 			`input[name="moveInDate"]`,
 			`input[name="earliestMoveInDate"]`,
 			`input[data-qa="moveInDate"]`,
 		}, p.MoveInDate)
-		s.trySelect(ctx, []string{
+		s.trySelect(ctx, report, "move_in_date", []string{
 			`select[name="moveInDate"]`,
 			`select[name="earliestMoveInDate"]`,
 		}, "FLEXIBLE")
 
 		// Beschäftigungsstatus (Employment)
-		s.trySelect(ctx, []string{
+		s.trySelect(ctx, report, "employment", []string{
 			`select[name="employmentStatus"]`,
 			`select[name="employment"]`,
 			`select[data-qa="employmentStatus"]`,
@@ -373,56 +652,70 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 
 		// Mietrückstände (Rent arrears) - No
 		if !p.RentArrears {
-			s.tryClick(ctx, []string{
+			s.tryClick(ctx, report, "rent_arrears", []string{
 				`input[name="rentArrears"][value="false"]`,
 				`input[name="hasRentArrears"][value="NO"]`,
 				`input[data-qa="rentArrears-no"]`,
 			})
-			s.trySelect(ctx, []string{
+			s.trySelect(ctx, report, "rent_arrears", []string{
 				`select[name="rentArrears"]`,
 			}, "NO")
 		}
 
 		// Insolvenzverfahren (Insolvency) - No
 		if !p.Insolvency {
-			s.tryClick(ctx, []string{
+			s.tryClick(ctx, report, "insolvency", []string{
 				`input[name="insolvency"][value="false"]`,
 				`input[name="hasInsolvency"][value="NO"]`,
 				`input[data-qa="insolvency-no"]`,
 			})
-			s.trySelect(ctx, []string{
+			s.trySelect(ctx, report, "insolvency", []string{
 				`select[name="insolvency"]`,
 			}, "NO")
 		}
 
 		// Raucher (Smoker) - No
 		if !p.Smoker {
-			s.tryClick(ctx, []string{
+			s.tryClick(ctx, report, "smoker", []string{
 				`input[name="smoker"][value="false"]`,
 				`input[name="isSmoker"][value="NO"]`,
 				`input[data-qa="smoker-no"]`,
 			})
-			s.trySelect(ctx, []string{
+			s.trySelect(ctx, report, "smoker", []string{
 				`select[name="smoker"]`,
 			}, "NO")
 		}
 
 		// Gewerbliche Nutzung (Commercial use) - No
 		if !p.CommercialUse {
-			s.tryClick(ctx, []string{
+			s.tryClick(ctx, report, "commercial_use", []string{
 				`input[name="commercialUse"][value="false"]`,
 				`input[name="isCommercialUse"][value="NO"]`,
 				`input[data-qa="commercialUse-no"]`,
 			})
-			s.trySelect(ctx, []string{
+			s.trySelect(ctx, report, "commercial_use", []string{
 				`select[name="commercialUse"]`,
 			}, "NO")
 		}
 
+		// Betreff (Subject) and Anliegen (message category): without them
+		// some forms keep the send button disabled.
+		s.tryType(ctx, report, "subject", []string{
+			`input[name="contactFormMessage.subject"]`,
+			`input[name="subject"]`,
+			`input[data-qa="subject"]`,
+		}, s.subject)
+		s.selectCategory(ctx, report, []string{
+			`select[name="contactFormMessage.category"]`,
+			`select[name="messageCategory"]`,
+			`select[name="category"]`,
+			`select[data-qa="messageCategory"]`,
+		}, s.category)
+
 		time.Sleep(s.behavior.ActionPause())
 
 		// Fill message (always last)
-		s.tryType(ctx, []string{
+		s.tryType(ctx, report, "message", []string{
 			`textarea[name="contactFormMessage.message"]`,
 			`textarea[name="message"]`,
 			`textarea[data-qa="message"]`,
@@ -434,32 +727,74 @@ func (s *Submitter) fillFormWithDelay(message string, profile Profile) chromedp.
 }
 
 // Helper: try to click any of the selectors
-func (s *Submitter) tryClick(ctx context.Context, selectors []string) {
+func (s *Submitter) tryClick(ctx context.Context, report SelectorReport, field string, selectors []string) {
 	for _, sel := range selectors {
-		_ = chromedp.Run(ctx, chromedp.Click(sel, chromedp.ByQuery))
+		if err := chromedp.Run(ctx, chromedp.Click(sel, chromedp.ByQuery)); err == nil {
+			report.record(field, sel)
+		}
 	}
+	report.record(field, "")
 }
 
 // Helper: try to select value in any of the selectors
-func (s *Submitter) trySelect(ctx context.Context, selectors []string, value string) {
+func (s *Submitter) trySelect(ctx context.Context, report SelectorReport, field string, selectors []string, value string) {
 	for _, sel := range selectors {
-		_ = chromedp.Run(ctx, chromedp.SetValue(sel, value, chromedp.ByQuery))
+		if err := chromedp.Run(ctx, chromedp.SetValue(sel, value, chromedp.ByQuery)); err == nil {
+			report.record(field, sel)
+		}
 	}
+	report.record(field, "")
 }
 
 // Helper: try to type in any of the selectors
-func (s *Submitter) tryType(ctx context.Context, selectors []string, value string) {
+func (s *Submitter) tryType(ctx context.Context, report SelectorReport, field string, selectors []string, value string) {
 	if value == "" {
 		return
 	}
 	for _, sel := range selectors {
 		if err := s.typeWithDelay(ctx, sel, value); err == nil {
+			report.record(field, sel)
 			time.Sleep(s.behavior.ActionPause())
 			return
 		}
 	}
+	report.record(field, "")
 }
 
+// selectCategory sets the first of selectors that exists to the option whose
+// value or label is want, else to its first non-empty option. A category the
+// form already shows is kept unless want names another.
+func (s *Submitter) selectCategory(ctx context.Context, report SelectorReport, selectors []string, want string) {
+	var matched string
+	js := fmt.Sprintf(selectCategoryJS, jsStringArray(selectors), strconv.Quote(want))
+	if err := chromedp.Run(ctx, chromedp.Evaluate(js, &matched)); err == nil && matched != "" {
+		report.record("category", matched)
+		time.Sleep(s.behavior.ActionPause())
+		return
+	}
+	report.record("category", "")
+}
+
+// selectCategoryJS is formatted with the selectors and the wanted category.
+// It dispatches input and change so script-driven forms notice the choice,
+// and returns the selector it used ("" when none matched).
+const selectCategoryJS = `((selectors, want) => {
+  for (const sel of selectors) {
+    const el = document.querySelector(sel);
+    if (!el) continue;
+    const opts = Array.from(el.options).filter(o => o.value && !o.disabled);
+    let opt = want && opts.find(o => o.value === want || o.text.trim() === want);
+    if (!opt && el.value) return sel;
+    opt = opt || opts[0];
+    if (!opt) return '';
+    el.value = opt.value;
+    el.dispatchEvent(new Event('input', {bubbles: true}));
+    el.dispatchEvent(new Event('change', {bubbles: true}));
+    return sel;
+  }
+  return '';
+})(%s, %s)`
+
 func (s *Submitter) typeWithDelay(ctx context.Context, selector, text string) error {
 	// First check if element exists
 	var exists bool
@@ -493,37 +828,114 @@ func (s *Submitter) typeWithDelay(ctx context.Context, selector, text string) er
 		if err != nil {
 			return err
 		}
-		time.Sleep(s.behavior.TypeChar())
+		time.Sleep(s.behavior.TypeChar(char))
 	}
 
 	return nil
 }
 
-func (s *Submitter) submitForm() chromedp.ActionFunc {
+// checkRequiredFields fails with ErrRequiredFieldsEmpty, naming the fields,
+// when a required field of the contact form is still empty.
+func (s *Submitter) checkRequiredFields() chromedp.ActionFunc {
 	return func(ctx context.Context) error {
-		// Try different submit button selectors
-		submitSelectors := []string{
-			`button[data-qa="sendButton"]`,
-			`button[type="submit"]`,
-			`input[type="submit"]`,
-			`.is24qa-submit`,
-			`button.button-primary`,
-			`button:contains("Nachricht senden")`,
-			`button:contains("Absenden")`,
+		var missing []string
+		if err := chromedp.Run(ctx, chromedp.Evaluate(emptyRequiredFieldsJS, &missing)); err != nil {
+			return fmt.Errorf("check required fields: %w", err)
 		}
+		return requiredFieldsError(missing)
+	}
+}
 
+// requiredFieldsError returns ErrRequiredFieldsEmpty naming the missing
+// fields, nil when there are none.
+func requiredFieldsError(missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrRequiredFieldsEmpty, strings.Join(missing, ", "))
+}
+
+// emptyRequiredFieldsJS returns the labels of the contact form's required
+// controls that are still empty: blank inputs, selects and textareas, radio
+// groups without a choice and unchecked checkboxes. Hidden and disabled
+// controls are ignored; the form would not validate them either.
+const emptyRequiredFieldsJS = `(() => {
+  const form = document.querySelector('` + contactFormSelector + `') || document;
+  const missing = [];
+  const radios = new Set();
+  for (const el of form.querySelectorAll('input, select, textarea')) {
+    const type = (el.type || el.tagName).toLowerCase();
+    if (type === 'hidden' || type === 'submit' || type === 'button' || el.disabled) continue;
+    if (!el.required && el.getAttribute('aria-required') !== 'true') continue;
+    let empty;
+    if (type === 'radio') {
+      if (radios.has(el.name)) continue;
+      radios.add(el.name);
+      empty = !form.querySelector('input[type="radio"][name="' + el.name + '"]:checked');
+    } else if (type === 'checkbox') {
+      empty = !el.checked;
+    } else {
+      if (el.offsetParent === null) continue;
+      empty = !(el.value || '').trim();
+    }
+    if (!empty) continue;
+    let label = '';
+    if (el.id) {
+      const l = form.querySelector('label[for="' + (window.CSS && CSS.escape ? CSS.escape(el.id) : el.id) + '"]');
+      if (l) label = l.innerText;
+    }
+    if (!label) { const l = el.closest('label'); if (l) label = l.innerText; }
+    if (!label) label = el.getAttribute('aria-label') || el.placeholder || el.name || el.id || type;
+    missing.push(label.trim().replace(/\s+/g, ' ').slice(0, 60));
+  }
+  return missing;
+})()`
+
+// submitSelectors match the contact form's send button.
+var submitSelectors = []string{
+	`button[data-qa="sendButton"]`,
+	`button[type="submit"]`,
+	`input[type="submit"]`,
+	`.is24qa-submit`,
+	`button.button-primary`,
+}
+
+// submitButtonJS is formatted with submitSelectors and ":not([disabled])"
+// (or "" for any state); it returns the first selector with a matching
+// button.
+const submitButtonJS = `((selectors, state) => {
+  for (const sel of selectors) {
+    if (document.querySelector(sel + state)) return sel;
+  }
+  return '';
+})(%s, %q)`
+
+func (s *Submitter) submitForm() chromedp.ActionFunc {
+	return func(ctx context.Context) error {
 		time.Sleep(s.behavior.ThinkPause())
+		if err := s.waitTurn(ctx); err != nil {
+			return err
+		}
 
-		for _, sel := range submitSelectors {
-			err := chromedp.Run(ctx,
-				chromedp.Click(sel, chromedp.ByQuery),
-			)
-			if err == nil {
-				return nil
-			}
+		// Forms disable the send button until they validate; clicking it
+		// earlier does nothing.
+		var sel string
+		enabled := fmt.Sprintf(submitButtonJS, jsStringArray(submitSelectors), ":not([disabled])")
+		err := chromedp.Run(ctx, chromedp.Poll(enabled, &sel, chromedp.WithPollingTimeout(submitEnableWait)))
+		if err == nil && sel != "" {
+			return chromedp.Run(ctx, chromedp.Click(sel+":not([disabled])", chromedp.ByQuery))
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var disabled string
+		present := fmt.Sprintf(submitButtonJS, jsStringArray(submitSelectors), "")
+		if err := chromedp.Run(ctx, chromedp.Evaluate(present, &disabled)); err == nil && disabled != "" {
+			return ErrSubmitDisabled
 		}
 
-		// Fallback: try submitting the form directly
+		// Fallback: no known button, try submitting the form directly
 		return chromedp.Run(ctx,
 			chromedp.Evaluate(`document.querySelector('form').submit()`, nil),
 		)
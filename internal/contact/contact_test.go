@@ -0,0 +1,109 @@
+package contact
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestContactEntries(t *testing.T) {
+	const expose = "https://www.immobilienscout24.de/expose/42"
+	tests := []struct {
+		name    string
+		formURL string
+		want    []contactEntry
+	}{
+		{"no parsed link", "", []contactEntry{
+			{URL: expose + "#/basicContact/email"},
+			{URL: expose, Button: true},
+		}},
+		{"kontaktformular link first", "https://www.immobilienscout24.de/kontaktformular/42", []contactEntry{
+			{URL: "https://www.immobilienscout24.de/kontaktformular/42"},
+			{URL: expose + "#/basicContact/email"},
+			{URL: expose, Button: true},
+		}},
+		{"email route not repeated", expose + "#/basicContact/email", []contactEntry{
+			{URL: expose + "#/basicContact/email"},
+			{URL: expose, Button: true},
+		}},
+		{"expose page only via button", expose, []contactEntry{
+			{URL: expose + "#/basicContact/email"},
+			{URL: expose, Button: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := contactEntries(&domain.Listing{IS24ID: "42", ContactFormURL: tt.formURL}, defaultBaseURL)
+			if len(got) != len(tt.want) {
+				t.Fatalf("entries = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRequiredFieldsError(t *testing.T) {
+	if err := requiredFieldsError(nil); err != nil {
+		t.Errorf("no missing fields: got %v", err)
+	}
+	err := requiredFieldsError([]string{"Telefon", "Einzugstermin"})
+	if !errors.Is(err, ErrRequiredFieldsEmpty) || err.Error() != "required form fields empty: Telefon, Einzugstermin" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestSetFormTimeout(t *testing.T) {
+	s := NewSubmitter("", Profile{}, "", nil, nil, nil)
+	if s.timeout != defaultFormTimeout {
+		t.Errorf("default timeout = %v", s.timeout)
+	}
+	s.SetFormTimeout(0)
+	if s.timeout != defaultFormTimeout {
+		t.Errorf("zero must keep the default, got %v", s.timeout)
+	}
+	s.SetFormTimeout(5 * time.Minute)
+	if s.timeout != 5*time.Minute {
+		t.Errorf("timeout = %v, want 5m", s.timeout)
+	}
+}
+
+func TestSelectorReport(t *testing.T) {
+	r := SelectorReport{}
+	r.record("email", "")
+	r.record("email", `input[type="email"]`)
+	r.record("email", `input[name="email"]`) // first match wins
+	r.record("phone", "")
+	r.record("city", `input[name="city"]`)
+	r.record("city", "")
+
+	if got, want := r.String(), `city=input[name="city"], email=input[type="email"], phone=-`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := r.Missing(); len(got) != 1 || got[0] != "phone" {
+		t.Errorf("Missing() = %v, want [phone]", got)
+	}
+
+	var none SelectorReport
+	none.record("email", "x") // nil report: recording is a no-op
+}
+
+func TestSetSubject(t *testing.T) {
+	s := NewSubmitter("", Profile{}, "", nil, nil, nil)
+	if s.subject != defaultSubject || s.category != "" {
+		t.Errorf("defaults = %q/%q", s.subject, s.category)
+	}
+	s.SetSubject("", "Besichtigung")
+	if s.subject != defaultSubject || s.category != "Besichtigung" {
+		t.Errorf("empty subject must keep the default, got %q/%q", s.subject, s.category)
+	}
+	s.SetSubject("Frage zum Exposé", "")
+	if s.subject != "Frage zum Exposé" || s.category != "" {
+		t.Errorf("got %q/%q", s.subject, s.category)
+	}
+}
@@ -0,0 +1,106 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// ErrFormNotFound means none of the entry points showed the contact form.
+// The wrapping error lists what each one failed with.
+var ErrFormNotFound = errors.New("contact form not reachable")
+
+// ErrCaptcha means IS24 showed its robot check instead of the listing.
+var ErrCaptcha = errors.New("captcha shown")
+
+// ErrCookieExpired means IS24 redirected to its login page, so the session
+// cookie is no longer valid.
+var ErrCookieExpired = errors.New("session cookie expired")
+
+// ErrRateLimited means IS24 answered with its "too many requests" page.
+var ErrRateLimited = errors.New("rate limited by IS24")
+
+// Failure categories reported by Classify. They are also the keys of
+// contact.failure_hints.
+const (
+	FailureCaptcha        = "captcha"
+	FailureCookieExpired  = "cookie_expired"
+	FailureRateLimited    = "rate_limited"
+	FailureFormNotFound   = "form_not_found"
+	FailureFormIncomplete = "form_incomplete"
+	FailureTimeout        = "timeout"
+	FailureUnknown        = "unknown"
+)
+
+// failureTexts holds label and default hint per category.
+var failureTexts = map[string]struct{ label, hint string }{
+	FailureCaptcha:        {"CAPTCHA / Roboter-Check", "IS24 einmal im Browser öffnen, Check lösen und Cookie erneuern (/cookie)."},
+	FailureCookieExpired:  {"Cookie abgelaufen", "Cookie erneuern? (/cookie)"},
+	FailureRateLimited:    {"Zu viele Anfragen", "Eine Weile warten; bei Häufung poll_interval erhöhen."},
+	FailureFormNotFound:   {"Kontaktformular nicht gefunden", "Anzeige im Browser prüfen – evtl. deaktiviert oder nur per Telefon."},
+	FailureFormIncomplete: {"Formular unvollständig", "Pflichtfelder im Bewerberprofil prüfen (contact.* in der config)."},
+	FailureTimeout:        {"Zeitüberschreitung", "Meist vorübergehend; bei Häufung contact.form_timeout erhöhen."},
+	FailureUnknown:        {"Unbekannter Fehler", ""},
+}
+
+// Classify maps a Submit error to a failure category with a suggested
+// action. hints overrides the default action per category; an empty value
+// removes it.
+func Classify(err error, hints map[string]string) domain.ContactFailure {
+	category := failureCategory(err)
+	f := domain.ContactFailure{
+		Category: category,
+		Label:    failureTexts[category].label,
+		Hint:     failureTexts[category].hint,
+	}
+	if hint, ok := hints[category]; ok {
+		f.Hint = hint
+	}
+	if err != nil {
+		f.Detail = err.Error()
+	}
+	return f
+}
+
+// failureCategory picks the category for err. Page-level blocks come first:
+// they usually surface wrapped in ErrFormNotFound.
+func failureCategory(err error) string {
+	switch {
+	case errors.Is(err, ErrCaptcha):
+		return FailureCaptcha
+	case errors.Is(err, ErrCookieExpired):
+		return FailureCookieExpired
+	case errors.Is(err, ErrRateLimited):
+		return FailureRateLimited
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case errors.Is(err, ErrFormNotFound):
+		return FailureFormNotFound
+	case errors.Is(err, ErrRequiredFieldsEmpty), errors.Is(err, ErrSubmitDisabled):
+		return FailureFormIncomplete
+	}
+	return FailureUnknown
+}
+
+// blockedPageErr recognizes the pages IS24 shows instead of a listing: the
+// robot check, the login page after the cookie expired and the rate-limit
+// page. Returns nil for anything else.
+func blockedPageErr(href, title string) error {
+	t := strings.ToLower(title)
+	switch {
+	case strings.Contains(t, "kein roboter"), strings.Contains(t, "not a robot"), strings.Contains(t, "captcha"):
+		return ErrCaptcha
+	case strings.Contains(t, "too many requests"), strings.Contains(t, "zu viele anfragen"), strings.HasPrefix(t, "429"):
+		return ErrRateLimited
+	}
+	if u, err := url.Parse(href); err == nil {
+		host, path := strings.ToLower(u.Hostname()), strings.ToLower(u.Path)
+		if strings.HasPrefix(host, "sso.") || strings.Contains(path, "/login") || strings.Contains(path, "/anmelden") {
+			return ErrCookieExpired
+		}
+	}
+	return nil
+}
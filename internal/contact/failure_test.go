@@ -0,0 +1,71 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	blocked := fmt.Errorf("%w: %w", ErrFormNotFound, errors.Join(
+		fmt.Errorf("https://example.org/a: %w", ErrCaptcha),
+		errors.New("https://example.org/b: no contact button"),
+	))
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"captcha inside form not found", blocked, FailureCaptcha},
+		{"cookie", fmt.Errorf("x: %w", ErrCookieExpired), FailureCookieExpired},
+		{"rate limit", ErrRateLimited, FailureRateLimited},
+		{"no form", fmt.Errorf("%w: %w", ErrFormNotFound, errors.New("no contact button")), FailureFormNotFound},
+		{"timeout", fmt.Errorf("contact form not reachable: %w", context.DeadlineExceeded), FailureTimeout},
+		{"missing fields", fmt.Errorf("%w: phone", ErrRequiredFieldsEmpty), FailureFormIncomplete},
+		{"disabled button", ErrSubmitDisabled, FailureFormIncomplete},
+		{"other", errors.New("boom"), FailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Classify(tt.err, nil)
+			if f.Category != tt.want {
+				t.Fatalf("category = %q, want %q", f.Category, tt.want)
+			}
+			if f.Label == "" || f.Detail != tt.err.Error() {
+				t.Errorf("failure = %+v", f)
+			}
+		})
+	}
+}
+
+func TestClassifyHintOverride(t *testing.T) {
+	f := Classify(ErrCookieExpired, map[string]string{FailureCookieExpired: "Neu einloggen"})
+	if f.Hint != "Neu einloggen" {
+		t.Errorf("hint = %q", f.Hint)
+	}
+	f = Classify(ErrCookieExpired, map[string]string{FailureCookieExpired: ""})
+	if f.Hint != "" {
+		t.Errorf("empty override kept hint %q", f.Hint)
+	}
+	if f := Classify(ErrCookieExpired, nil); f.Hint == "" {
+		t.Error("default hint missing")
+	}
+}
+
+func TestBlockedPageErr(t *testing.T) {
+	tests := []struct {
+		href, title string
+		want        error
+	}{
+		{"https://www.immobilienscout24.de/expose/1", "Ich bin kein Roboter - ImmobilienScout24", ErrCaptcha},
+		{"https://www.immobilienscout24.de/expose/1", "429 Too Many Requests", ErrRateLimited},
+		{"https://sso.immobilienscout24.de/sso/login?appName=is24main", "Anmelden", ErrCookieExpired},
+		{"https://www.immobilienscout24.de/expose/1", "3-Zimmer-Wohnung in Berlin", nil},
+	}
+	for _, tt := range tests {
+		if got := blockedPageErr(tt.href, tt.title); got != tt.want {
+			t.Errorf("blockedPageErr(%q, %q) = %v, want %v", tt.href, tt.title, got, tt.want)
+		}
+	}
+}
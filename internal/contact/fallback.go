@@ -44,7 +44,8 @@ type FieldMapper interface {
 }
 
 // fillViaLLM is the fallback fill path. It reads the current form's fields,
-// asks the mapper how to fill them, applies the actions, submits, and verifies.
+// asks the mapper how to fill them, applies the actions, checks that no
+// required field is left empty, submits, and verifies.
 // Assumes the form is already navigated to and visible on browserCtx.
 func (s *Submitter) fillViaLLM(ctx context.Context, message string, profile Profile) error {
 	fields, err := s.extractFormFields(ctx)
@@ -65,6 +66,9 @@ func (s *Submitter) fillViaLLM(ctx context.Context, message string, profile Prof
 
 	s.applyActions(ctx, actions)
 
+	if err := chromedp.Run(ctx, s.checkRequiredFields()); err != nil {
+		return err
+	}
 	if err := chromedp.Run(ctx, s.submitForm()); err != nil {
 		return fmt.Errorf("submit after llm fill: %w", err)
 	}
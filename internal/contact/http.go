@@ -0,0 +1,390 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The HTTP path submits server-rendered contact forms directly: fetch the
+// form, fill the fields we recognize by name, POST it with the session cookie.
+// It is much faster than the browser and leaves no automation fingerprint, but
+// only works when the form is plain HTML. Anything it can't handle returns
+// errHTTPUnsupported or errHTTPRejected so Submit falls back to chromedp.
+var (
+	errHTTPUnsupported = errors.New("contact form not submittable via http")
+	errHTTPRejected    = errors.New("http contact submission rejected")
+)
+
+const httpUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+var (
+	contactFormRe = regexp.MustCompile(`(?is)<form\b([^>]*(?:data-qa="contactForm"|id="contactForm"|class="[^"]*contact-form[^"]*")[^>]*)>(.*?)</form>`)
+	formControlRe = regexp.MustCompile(`(?is)<(input)\b([^>]*)>|<(select)\b([^>]*)>(.*?)</select>|<(textarea)\b([^>]*)>(.*?)</textarea>`)
+	optionRe      = regexp.MustCompile(`(?is)<option\b([^>]*)>([^<]*)`)
+	attrRe        = regexp.MustCompile(`([\w:.-]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+	submitOKRe    = regexp.MustCompile(`(?is)nachricht.*(gesendet|versendet)|kontaktanfrage.*(gesendet|versendet)|vielen dank`)
+)
+
+// httpForm is a contact form parsed from server-rendered HTML.
+type httpForm struct {
+	action   string
+	method   string
+	controls []formControl
+}
+
+// formControl is one named input, select or textarea.
+type formControl struct {
+	tag      string // input, select, textarea
+	typ      string // input type, lowercased
+	name     string
+	value    string   // default value (value attribute / textarea body)
+	options  []string // select option values or radio values
+	checked  bool
+	required bool
+}
+
+// SetHTTPFirst enables trying a direct HTTP form submission before starting
+// the browser. The browser path still runs when the HTTP path can't be used.
+func (s *Submitter) SetHTTPFirst(enabled bool) {
+	s.httpFirst = enabled
+}
+
+// submitHTTP fetches contactURL, fills the contact form and posts it with the
+// session cookie. Returns errHTTPUnsupported / errHTTPRejected (wrapped) when
+// the browser should take over; any other error means the outcome is unknown
+// and retrying in the browser could send the message twice.
+func (s *Submitter) submitHTTP(ctx context.Context, contactURL, message string, profile Profile) error {
+	pageURL, err := url.Parse(contactURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errHTTPUnsupported, err)
+	}
+	pageURL.Fragment = ""
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errHTTPUnsupported, err)
+	}
+	var cookies []*http.Cookie
+	for _, c := range parseCookieString(s.cookie) {
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	jar.SetCookies(pageURL, cookies)
+	client := &http.Client{Jar: jar, Timeout: 30 * time.Second, Transport: s.transport}
+
+	if err := s.waitTurn(ctx); err != nil {
+		return err
+	}
+	status, page, err := doFormRequest(ctx, client, http.MethodGet, pageURL.String(), nil)
+	if err != nil || status != http.StatusOK {
+		return fmt.Errorf("%w: fetch form: status %d, %v", errHTTPUnsupported, status, err)
+	}
+	form, err := parseContactForm(page, pageURL)
+	if err != nil {
+		return err
+	}
+	values, err := form.fill(message, profile)
+	if err != nil {
+		return err
+	}
+
+	pause := time.NewTimer(s.behavior.ThinkPause())
+	select {
+	case <-ctx.Done():
+		pause.Stop()
+		return ctx.Err()
+	case <-pause.C:
+	}
+	if err := s.waitTurn(ctx); err != nil {
+		return err
+	}
+
+	status, body, err := doFormRequest(ctx, client, http.MethodPost, form.action, values)
+	if err != nil {
+		return fmt.Errorf("post contact form: %w", err)
+	}
+	// A 5xx may come after the server already stored the message, so only
+	// a 4xx is a safe rejection to retry in the browser.
+	if status >= 500 {
+		return fmt.Errorf("%w: http status %d", ErrUnconfirmed, status)
+	}
+	if status >= 400 {
+		return fmt.Errorf("%w: status %d", errHTTPRejected, status)
+	}
+	if submitOKRe.MatchString(body) {
+		return nil
+	}
+	if contactFormRe.MatchString(body) {
+		return fmt.Errorf("%w: form returned again (validation failed?)", errHTTPRejected)
+	}
+	return fmt.Errorf("%w: http status %d", ErrUnconfirmed, status)
+}
+
+func doFormRequest(ctx context.Context, client *http.Client, method, target string, values url.Values) (int, string, error) {
+	var body io.Reader
+	if values != nil {
+		body = strings.NewReader(values.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.8")
+	if values != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Referer", target)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	return resp.StatusCode, string(data), nil
+}
+
+// parseContactForm extracts the IS24 contact form from page. Only plain POST
+// forms are supported; SPA-rendered pages have no form in the HTML.
+func parseContactForm(page string, pageURL *url.URL) (*httpForm, error) {
+	m := contactFormRe.FindStringSubmatch(page)
+	if m == nil {
+		return nil, fmt.Errorf("%w: no contact form in html", errHTTPUnsupported)
+	}
+	attrs := parseAttrs(m[1])
+	form := &httpForm{method: strings.ToUpper(attrs["method"])}
+	if form.method != http.MethodPost {
+		return nil, fmt.Errorf("%w: form method %q", errHTTPUnsupported, attrs["method"])
+	}
+	action, err := pageURL.Parse(attrs["action"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: form action: %v", errHTTPUnsupported, err)
+	}
+	form.action = action.String()
+
+	radios := make(map[string]int) // name → index in controls
+	for _, cm := range formControlRe.FindAllStringSubmatch(m[2], -1) {
+		var c formControl
+		var a map[string]string
+		switch {
+		case cm[1] != "":
+			a = parseAttrs(cm[2])
+			c.tag, c.typ = "input", strings.ToLower(a["type"])
+			if c.typ == "" {
+				c.typ = "text"
+			}
+		case cm[3] != "":
+			a = parseAttrs(cm[4])
+			c.tag, c.typ = "select", "select"
+			for _, om := range optionRe.FindAllStringSubmatch(cm[5], -1) {
+				oa := parseAttrs(om[1])
+				value, ok := oa["value"]
+				if !ok {
+					value = strings.TrimSpace(html.UnescapeString(om[2]))
+				}
+				c.options = append(c.options, value)
+				if _, sel := oa["selected"]; sel {
+					c.value = value
+				}
+			}
+		default:
+			a = parseAttrs(cm[7])
+			c.tag, c.typ = "textarea", "textarea"
+			c.value = html.UnescapeString(cm[8])
+		}
+		c.name = a["name"]
+		if c.name == "" || c.typ == "submit" || c.typ == "button" || c.typ == "file" {
+			continue
+		}
+		_, c.checked = a["checked"]
+		_, c.required = a["required"]
+		if c.tag == "input" {
+			c.value = a["value"]
+		}
+
+		// Group radio buttons into one control with their values as options.
+		if c.typ == "radio" {
+			if i, ok := radios[c.name]; ok {
+				form.controls[i].options = append(form.controls[i].options, c.value)
+				if c.checked {
+					form.controls[i].value = c.value
+				}
+				form.controls[i].required = form.controls[i].required || c.required
+				continue
+			}
+			c.options = []string{c.value}
+			if !c.checked {
+				c.value = ""
+			}
+			radios[c.name] = len(form.controls)
+		}
+		form.controls = append(form.controls, c)
+	}
+	return form, nil
+}
+
+// parseAttrs parses the attributes of a tag. Boolean attributes map to "".
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllStringSubmatch(s, -1) {
+		name := strings.ToLower(m[1])
+		if _, dup := attrs[name]; dup {
+			continue
+		}
+		attrs[name] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// fill builds the POST body: hidden fields and defaults are kept, known
+// applicant fields (matched by name, like the static selectors) are set.
+// Fails with errHTTPUnsupported when the message field is missing or a
+// required field would stay empty.
+func (f *httpForm) fill(message string, p Profile) (url.Values, error) {
+	values := url.Values{}
+	hasMessage := false
+	for _, c := range f.controls {
+		value, known := profileValue(fieldKey(c.name), c, message, p)
+		if !known {
+			value = c.value
+			if c.typ == "checkbox" && !c.checked {
+				value = ""
+			}
+		}
+		if c.tag == "textarea" && fieldKey(c.name) == "message" && value != "" {
+			hasMessage = true
+		}
+		if value == "" {
+			if c.required {
+				return nil, fmt.Errorf("%w: required field %q unknown", errHTTPUnsupported, c.name)
+			}
+			continue
+		}
+		values.Set(c.name, value)
+	}
+	if !hasMessage {
+		return nil, fmt.Errorf("%w: no message field", errHTTPUnsupported)
+	}
+	return values, nil
+}
+
+// fieldKey normalizes a field name: "contactFormMessage.firstName" → "firstname".
+func fieldKey(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ToLower(name)
+}
+
+// profileValue returns the value for a known applicant field. known is false
+// for fields the form should submit unchanged (hidden tokens etc.).
+func profileValue(key string, c formControl, message string, p Profile) (value string, known bool) {
+	switch key {
+	case "message":
+		return message, true
+	case "salutation":
+		return pickOption(c, p.Salutation), true
+	case "firstname":
+		return p.FirstName, true
+	case "lastname":
+		return p.LastName, true
+	case "fullname", "name":
+		return strings.TrimSpace(p.FirstName + " " + p.LastName), true
+	case "emailaddress", "email":
+		return p.Email, true
+	case "phonenumber", "phone":
+		return p.Phone, true
+	case "street":
+		return p.Street, true
+	case "housenumber":
+		return p.HouseNumber, true
+	case "postalcode", "zipcode":
+		return p.PostalCode, true
+	case "city":
+		return p.City, true
+	case "numberofadults", "adults":
+		return strconv.Itoa(p.Adults), true
+	case "numberofchildren", "children":
+		return strconv.Itoa(p.Children), true
+	case "income", "monthlyincome", "nethouseholdincome":
+		return strconv.Itoa(p.Income), true
+	case "moveindate", "earliestmoveindate":
+		if c.tag == "select" {
+			return pickOption(c, "FLEXIBLE"), true
+		}
+		return p.MoveInDate, true
+	case "employmentstatus", "employment":
+		return pickOption(c, "PERMANENT"), true
+	case "applywithprofile":
+		return boolValue(c, true), true
+	case "pets", "haspets":
+		return boolValue(c, p.Pets), true
+	case "rentarrears", "hasrentarrears":
+		return boolValue(c, p.RentArrears), true
+	case "insolvency", "hasinsolvency":
+		return boolValue(c, p.Insolvency), true
+	case "smoker", "issmoker":
+		return boolValue(c, p.Smoker), true
+	case "commercialuse", "iscommercialuse":
+		return boolValue(c, p.CommercialUse), true
+	}
+	return "", false
+}
+
+// pickOption returns want if the control offers it (or takes free text),
+// else its current value.
+func pickOption(c formControl, want string) string {
+	if len(c.options) == 0 {
+		if want != "" {
+			return want
+		}
+		return c.value
+	}
+	for _, o := range c.options {
+		if strings.EqualFold(o, want) {
+			return o
+		}
+	}
+	return c.value
+}
+
+// boolValue picks the yes/no representation the control uses.
+func boolValue(c formControl, v bool) string {
+	words := []string{"false", "no", "nein"}
+	if v {
+		words = []string{"true", "yes", "ja"}
+	}
+	if c.typ == "checkbox" {
+		if v {
+			if c.value != "" {
+				return c.value
+			}
+			return "on"
+		}
+		return ""
+	}
+	for _, o := range c.options {
+		for _, w := range words {
+			if strings.EqualFold(o, w) {
+				return o
+			}
+		}
+	}
+	if len(c.options) == 0 {
+		return strings.ToUpper(words[1])
+	}
+	return c.value
+}
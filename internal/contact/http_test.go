@@ -0,0 +1,121 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/julianbeese/immo_bot/internal/antidetect"
+)
+
+const plainForm = `<html><body>
+<form data-qa="contactForm" method="post" action="/expose/123/contact">
+  <input type="hidden" name="csrfToken" value="tok&amp;42">
+  <select name="contactFormMessage.salutation"><option value="">-</option><option value="MALE">Herr</option><option value="FEMALE">Frau</option></select>
+  <input name="contactFormMessage.firstName" required>
+  <input name="contactFormMessage.lastName" required>
+  <input type="email" name="contactFormMessage.emailAddress" required>
+  <label><input type="radio" name="hasPets" value="YES"> Ja</label>
+  <label><input type="radio" name="hasPets" value="NO"> Nein</label>
+  <input type="checkbox" name="newsletter" value="true">
+  <textarea name="contactFormMessage.message"></textarea>
+  <button type="submit">Senden</button>
+</form></body></html>`
+
+func newHTTPTestSubmitter() *Submitter {
+	return &Submitter{
+		cookie:   "reese84=abc; session=xyz",
+		behavior: antidetect.NewHumanBehavior(time.Millisecond, time.Millisecond),
+		profile:  Profile{Salutation: "FEMALE", FirstName: "Eva", LastName: "Muster", Email: "eva@example.com"},
+	}
+}
+
+func TestSubmitHTTPPostsForm(t *testing.T) {
+	var got url.Values
+	var cookie string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(plainForm))
+			return
+		}
+		if r.URL.Path != "/expose/123/contact" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = r.ParseForm()
+		got = r.PostForm
+		if c, err := r.Cookie("session"); err == nil {
+			cookie = c.Value
+		}
+		_, _ = w.Write([]byte(`<p>Vielen Dank! Ihre Nachricht wurde gesendet.</p>`))
+	}))
+	defer srv.Close()
+
+	s := newHTTPTestSubmitter()
+	if err := s.submitHTTP(context.Background(), srv.URL+"/expose/123#/basicContact/email", "Hallo!", s.profile); err != nil {
+		t.Fatalf("submitHTTP: %v", err)
+	}
+	if cookie != "xyz" {
+		t.Errorf("session cookie not sent, got %q", cookie)
+	}
+	want := map[string]string{
+		"csrfToken":                       "tok&42",
+		"contactFormMessage.salutation":   "FEMALE",
+		"contactFormMessage.firstName":    "Eva",
+		"contactFormMessage.lastName":     "Muster",
+		"contactFormMessage.emailAddress": "eva@example.com",
+		"hasPets":                         "NO",
+		"contactFormMessage.message":      "Hallo!",
+	}
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, got.Get(k), v)
+		}
+	}
+	if got.Has("newsletter") {
+		t.Error("unchecked checkbox should not be submitted")
+	}
+}
+
+func TestSubmitHTTPFallbackErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		post     func(w http.ResponseWriter)
+		wantErr  error
+		fallback bool
+	}{
+		{"spa page without form", `<div id="root"></div>`, nil, errHTTPUnsupported, true},
+		{"unknown required field", `<form data-qa="contactForm" method="post"><input name="captcha" required><textarea name="message"></textarea></form>`, nil, errHTTPUnsupported, true},
+		{"rejected by server", plainForm, func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) }, errHTTPRejected, true},
+		{"server error", plainForm, func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) }, ErrUnconfirmed, false},
+		{"form returned again", plainForm, func(w http.ResponseWriter) { _, _ = w.Write([]byte(plainForm)) }, errHTTPRejected, true},
+		{"unconfirmed", plainForm, func(w http.ResponseWriter) { _, _ = w.Write([]byte(`<p>ok</p>`)) }, ErrUnconfirmed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					_, _ = w.Write([]byte(tt.page))
+					return
+				}
+				tt.post(w)
+			}))
+			defer srv.Close()
+
+			s := newHTTPTestSubmitter()
+			err := s.submitHTTP(context.Background(), srv.URL+"/expose/123", "Hallo!", s.profile)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			fallback := errors.Is(err, errHTTPUnsupported) || errors.Is(err, errHTTPRejected)
+			if fallback != tt.fallback || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("err = %v, want %v (fallback %v)", err, tt.wantErr, tt.fallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package contact
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// NoopSubmitter accepts every contact without launching a browser or sending
+// anything (contact.dry_run). It lets the full contact pipeline — message
+// generation, sent_messages accounting, contacted flags — run in CI or on a
+// machine without Chrome.
+type NoopSubmitter struct {
+	logger *slog.Logger
+}
+
+// NewNoopSubmitter creates a dry-run submitter. logger may be nil.
+func NewNoopSubmitter(logger *slog.Logger) *NoopSubmitter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &NoopSubmitter{logger: logger}
+}
+
+// Submit logs the contact that would be sent and reports success.
+func (n *NoopSubmitter) Submit(ctx context.Context, listing *domain.Listing, message string, _ Profile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n.logger.Info("dry run: contact not submitted", "is24_id", listing.IS24ID, "message_len", len(message))
+	return nil
+}
@@ -0,0 +1,53 @@
+package contact
+
+import (
+	"sort"
+	"strings"
+)
+
+// SelectorReport records, per form field the static fill path tried, which
+// selector matched it. An empty selector means none of the field's selectors
+// did — the field was left as IS24 rendered it.
+type SelectorReport map[string]string
+
+// record notes sel for field. The first match wins; "" only marks a field
+// nothing has matched yet.
+func (r SelectorReport) record(field, sel string) {
+	if r == nil {
+		return
+	}
+	if prev, ok := r[field]; ok && (prev != "" || sel == "") {
+		return
+	}
+	r[field] = sel
+}
+
+// Missing returns the fields no selector matched, sorted.
+func (r SelectorReport) Missing() []string {
+	var missing []string
+	for field, sel := range r {
+		if sel == "" {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// String lists "field=selector" sorted by field, "field=-" for misses.
+func (r SelectorReport) String() string {
+	fields := make([]string, 0, len(r))
+	for field := range r {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		sel := r[field]
+		if sel == "" {
+			sel = "-"
+		}
+		parts[i] = field + "=" + sel
+	}
+	return strings.Join(parts, ", ")
+}
@@ -14,6 +14,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -53,6 +55,39 @@ type Defaults struct {
 	Timezone          string // IANA tz, e.g. "Europe/Berlin"
 }
 
+// Reply is a command response with optional quick-reply buttons. Transports
+// without buttons (WhatsApp) show only Text, which always spells out the
+// equivalent typed commands.
+type Reply struct {
+	Text    string
+	Buttons []Button
+}
+
+// Button is a quick-reply button that runs Command when pressed.
+type Button struct {
+	Label   string
+	Command string
+}
+
+// ProfileDeletion describes a search profile for the /delete_profile
+// confirmation prompt.
+type ProfileDeletion struct {
+	Name      string
+	Listings  int // stored listings found by the profile
+	Contacted int // of those, already contacted
+}
+
+// ProfileImport is one search profile of an /import_profiles list: an IS24
+// search URL with optional campaign and name.
+type ProfileImport struct {
+	Category string
+	URL      string
+	Name     string
+}
+
+// deleteConfirmTTL is how long a /delete_profile confirmation stays valid.
+const deleteConfirmTTL = 5 * time.Minute
+
 // Controller holds shared bot state and turns chat commands into responses.
 // It is safe for concurrent use.
 type Controller struct {
@@ -63,19 +98,93 @@ type Controller struct {
 	timezone string
 
 	contactMode ContactMode
+	modeLoaded  bool // contactMode came from the store, not the default
 	quietHours  bool
 	quietStart  string
 	quietEnd    string
 
-	// Callbacks providing extra info for /status and /stats.
-	onStatusRequest func() string
-	onStatsRequest  func() string
+	// Callbacks providing extra info for /status, /stats, /funnel, /summary
+	// and /diff.
+	onStatusRequest  func() string
+	onStatsRequest   func() string
+	onFunnelRequest  func() string
+	onSummaryRequest func() string
+	onDiffRequest    func() string
+
+	// Callback rendering the effective configuration, secrets redacted
+	// (/config).
+	onConfigRequest func() string
+
+	// Callback checking the notification channels (/ping).
+	onPingRequest func() string
+
+	// Callback writing a database snapshot now (/backup).
+	onBackupRequest func() string
+
+	// Callback starting the scraping self-test (/selftest). Returns the
+	// acknowledgement; the report is delivered asynchronously.
+	onSelfTestRequest func() string
+
+	// Callback that starts an immediate poll cycle (/poll). Returns the
+	// acknowledgement; the result summary is delivered asynchronously.
+	onPollRequest func() string
+
+	// Callback that generates the contact message for one listing (/preview).
+	// Returns the acknowledgement; the preview itself follows asynchronously.
+	onPreview func(is24ID string) string
 
 	// Callbacks for managing search profiles (need DB access, injected by main).
 	onAddProfile   func(category, url, name string) string
 	onListProfiles func() string
 	onDelProfile   func(id string) string
 
+	// Callback that creates profiles from a pasted list of saved-search
+	// URLs (/import_profiles).
+	onImportProfiles func(entries []ProfileImport) string
+
+	// Callback that adds a landlord/agency to every active profile's
+	// blocklist (/block_landlord).
+	onBlockLandlord func(name string) string
+
+	// Callbacks for /scan (one-off search of an arbitrary IS24 URL, result
+	// delivered asynchronously) and /scan_save (keep its new listings).
+	onScan     func(searchURL string) string
+	onScanSave func() string
+
+	// Callback that sets or clears a listing's contacted flag by hand
+	// (/mark_contacted, /mark_uncontacted).
+	onMarkContacted func(is24ID string, contacted bool) string
+
+	// Callbacks for /queue (listings awaiting auto-contact) and /cancel
+	// (take one out of it).
+	onQueue         func() string
+	onCancelContact func(is24ID string) string
+
+	// Callback that ignores a listing for good (/ignore).
+	onIgnore func(is24ID string) string
+
+	// Callbacks that send an expose page's HTML as a file: the copy kept by
+	// DEBUG_HTML (/html) or a fresh download (/refetch).
+	onStoredHTML  func(is24ID string) string
+	onRefetchHTML func(is24ID string) string
+
+	// Callback that deletes a listing so the next poll finds it again
+	// (/reset_listing).
+	onResetListing func(is24ID string) string
+
+	// Callback that pauses or resumes one search profile (/pause_profile,
+	// /resume_profile).
+	onPauseProfile func(id int64, paused bool) string
+
+	// Callback rendering a listing's activity timeline (/log).
+	onLog func(is24ID string) string
+
+	// Callbacks for /delete_profile (permanent deletion after confirmation)
+	// and the confirmations still pending, keyed by profile ID.
+	onDescribeProfile func(id int64) (*ProfileDeletion, error)
+	onDeleteProfile   func(id int64) error
+	pendingDeletes    map[int64]time.Time
+
 	// Callback that applies a fresh IS24 cookie at runtime (scheduler hot-reload
 	// + meta persistence). Used by /cookie chat command.
 	onSetCookie func(ctx context.Context, cookie string) error
@@ -111,6 +220,7 @@ func (c *Controller) loadFromStore() {
 	if v, _ := c.store.GetMeta(ctx, MetaContactMode); v != "" {
 		if mode, ok := parseContactMode(v); ok {
 			c.contactMode = mode
+			c.modeLoaded = true
 		}
 	}
 	if v, _ := c.store.GetMeta(ctx, MetaQuietHoursEnabled); v != "" {
@@ -146,6 +256,54 @@ func (c *Controller) SetCallbacks(onStatus, onStats func() string) {
 	c.onStatsRequest = onStats
 }
 
+// SetFunnelCallback wires the /funnel command (seen → passed → notified →
+// contacted conversion, rendered by main from the repository).
+func (c *Controller) SetFunnelCallback(fn func() string) {
+	c.onFunnelRequest = fn
+}
+
+// SetSummaryCallback wires the /summary command (the daily digest on demand).
+func (c *Controller) SetSummaryCallback(fn func() string) {
+	c.onSummaryRequest = fn
+}
+
+// SetDiffCallback wires the /diff command (what changed between the last two
+// poll cycles).
+func (c *Controller) SetDiffCallback(fn func() string) {
+	c.onDiffRequest = fn
+}
+
+// SetConfigCallback wires the /config command.
+func (c *Controller) SetConfigCallback(fn func() string) {
+	c.onConfigRequest = fn
+}
+
+// SetBackupCallback wires the /backup command (database snapshot on demand).
+func (c *Controller) SetBackupCallback(fn func() string) {
+	c.onBackupRequest = fn
+}
+
+// SetSelfTestCallback wires the /selftest command (test search without
+// saving or notifying).
+func (c *Controller) SetSelfTestCallback(fn func() string) {
+	c.onSelfTestRequest = fn
+}
+
+// SetPingCallback wires the /ping command (connection self-test).
+func (c *Controller) SetPingCallback(fn func() string) {
+	c.onPingRequest = fn
+}
+
+// SetPollCallback wires the /poll command to an on-demand scheduler cycle.
+func (c *Controller) SetPollCallback(fn func() string) {
+	c.onPollRequest = fn
+}
+
+// SetPreviewCallback wires /preview <is24_id> to the message preview.
+func (c *Controller) SetPreviewCallback(fn func(is24ID string) string) {
+	c.onPreview = fn
+}
+
 // SetProfileCallbacks wires the search-profile management commands.
 func (c *Controller) SetProfileCallbacks(onAdd func(category, url, name string) string, onList func() string, onDel func(id string) string) {
 	c.onAddProfile = onAdd
@@ -153,12 +311,81 @@ func (c *Controller) SetProfileCallbacks(onAdd func(category, url, name string)
 	c.onDelProfile = onDel
 }
 
+// SetImportProfilesCallback wires /import_profiles.
+func (c *Controller) SetImportProfilesCallback(fn func(entries []ProfileImport) string) {
+	c.onImportProfiles = fn
+}
+
+// SetBlockLandlordCallback wires /block_landlord <name>.
+func (c *Controller) SetBlockLandlordCallback(fn func(name string) string) {
+	c.onBlockLandlord = fn
+}
+
+// SetScanCallbacks wires /scan <url> and /scan_save.
+func (c *Controller) SetScanCallbacks(onScan func(searchURL string) string, onSave func() string) {
+	c.onScan = onScan
+	c.onScanSave = onSave
+}
+
+// SetQueueCallbacks wires /queue and /cancel <id>.
+func (c *Controller) SetQueueCallbacks(onQueue func() string, onCancel func(is24ID string) string) {
+	c.onQueue = onQueue
+	c.onCancelContact = onCancel
+}
+
+// SetIgnoreCallback wires /ignore <id>.
+func (c *Controller) SetIgnoreCallback(fn func(is24ID string) string) {
+	c.onIgnore = fn
+}
+
+// SetMarkContactedCallback wires /mark_contacted and /mark_uncontacted.
+func (c *Controller) SetMarkContactedCallback(fn func(is24ID string, contacted bool) string) {
+	c.onMarkContacted = fn
+}
+
+// SetHTMLCallbacks wires /html <id> and /refetch <id>.
+func (c *Controller) SetHTMLCallbacks(onStored, onRefetch func(is24ID string) string) {
+	c.onStoredHTML = onStored
+	c.onRefetchHTML = onRefetch
+}
+
+// SetResetListingCallback wires /reset_listing.
+func (c *Controller) SetResetListingCallback(fn func(is24ID string) string) {
+	c.onResetListing = fn
+}
+
+// SetPauseProfileCallback wires /pause_profile and /resume_profile.
+func (c *Controller) SetPauseProfileCallback(fn func(id int64, paused bool) string) {
+	c.onPauseProfile = fn
+}
+
+// SetLogCallback wires /log <is24_id>.
+func (c *Controller) SetLogCallback(fn func(is24ID string) string) {
+	c.onLog = fn
+}
+
+// SetDeleteProfileCallbacks wires /delete_profile. describe returns nil for
+// an unknown profile; del deletes it permanently.
+func (c *Controller) SetDeleteProfileCallbacks(describe func(id int64) (*ProfileDeletion, error), del func(id int64) error) {
+	c.onDescribeProfile = describe
+	c.onDeleteProfile = del
+}
+
 // SetCookieCallback wires the /cookie chat command to the scheduler's hot
 // reload (validates + persists + tells the IS24 client to use the new value).
 func (c *Controller) SetCookieCallback(fn func(ctx context.Context, cookie string) error) {
 	c.onSetCookie = fn
 }
 
+// HandleCommandReply is HandleCommand for transports that can show buttons.
+func (c *Controller) HandleCommandReply(raw string) Reply {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(raw), "/"))
+	if len(fields) > 0 && isDeleteProfileCommand(fields[0]) {
+		return c.handleDeleteProfile(fields[1:])
+	}
+	return Reply{Text: c.HandleCommand(raw)}
+}
+
 // HandleCommand normalizes a raw chat message and returns the response text.
 // Accepts both slash and plain forms: "/contact_on", "contact on", "Status".
 // Returns "" if the message is not a recognized command (caller may ignore it).
@@ -170,6 +397,9 @@ func (c *Controller) HandleCommand(raw string) string {
 	if len(fields) == 0 {
 		return ""
 	}
+	if isDeleteProfileCommand(fields[0]) {
+		return c.handleDeleteProfile(fields[1:]).Text
+	}
 	switch strings.ToLower(fields[0]) {
 	case "addprofil", "addprofile", "addprof":
 		return c.handleAddProfile(fields[1:])
@@ -178,6 +408,9 @@ func (c *Controller) HandleCommand(raw string) string {
 			return c.onListProfiles()
 		}
 		return "Profil-Verwaltung nicht verfügbar."
+	case "import_profiles", "importprofiles":
+		// One profile per line; keep the line breaks.
+		return c.handleImportProfiles(stripFirstToken(raw))
 	case "delprofil", "delprofile", "delprof":
 		if len(fields) < 2 {
 			return "Nutzung: /delprofil <id>"
@@ -186,6 +419,38 @@ func (c *Controller) HandleCommand(raw string) string {
 			return c.onDelProfile(fields[1])
 		}
 		return "Profil-Verwaltung nicht verfügbar."
+	case "pause_profile", "pauseprofile":
+		return c.handlePauseProfile(fields[1:], true)
+	case "resume_profile", "resumeprofile":
+		return c.handlePauseProfile(fields[1:], false)
+	case "preview", "vorschau":
+		return c.handlePreview(fields[1:])
+	case "scan":
+		return c.handleScan(fields[1:])
+	case "scan_save", "scansave":
+		if c.onScanSave != nil {
+			return c.onScanSave()
+		}
+		return "Scan nicht verfügbar."
+	case "log", "verlauf":
+		return c.handleLog(fields[1:])
+	case "mark_contacted", "markcontacted":
+		return c.handleMarkContacted(fields[1:], true)
+	case "mark_uncontacted", "markuncontacted":
+		return c.handleMarkContacted(fields[1:], false)
+	case "reset_listing", "resetlisting":
+		return c.handleResetListing(fields[1:])
+	case "html":
+		return c.handleHTML(fields[1:], false)
+	case "refetch":
+		return c.handleHTML(fields[1:], true)
+	case "cancel", "abbrechen":
+		return c.handleCancelContact(fields[1:])
+	case "ignore", "ignorieren":
+		return c.handleIgnore(fields[1:])
+	case "block_landlord", "blocklandlord", "sperren":
+		// Agency names contain spaces; keep everything after the command.
+		return c.handleBlockLandlord(stripFirstToken(raw))
 	case "cookie":
 		// Everything after "/cookie " is the new cookie string. Preserve the
 		// raw payload (cookies contain '=' and ';' which Fields() leaves alone,
@@ -227,6 +492,51 @@ func (c *Controller) HandleCommand(raw string) string {
 			return c.onStatsRequest()
 		}
 		return "Statistiken nicht verfügbar."
+	case "funnel":
+		if c.onFunnelRequest != nil {
+			return c.onFunnelRequest()
+		}
+		return "Funnel nicht verfügbar."
+	case "summary":
+		if c.onSummaryRequest != nil {
+			return c.onSummaryRequest()
+		}
+		return "Zusammenfassung nicht verfügbar."
+	case "diff":
+		if c.onDiffRequest != nil {
+			return c.onDiffRequest()
+		}
+		return "Vergleich nicht verfügbar."
+	case "queue", "warteschlange":
+		if c.onQueue != nil {
+			return c.onQueue()
+		}
+		return "Warteschlange nicht verfügbar."
+	case "backup":
+		if c.onBackupRequest != nil {
+			return c.onBackupRequest()
+		}
+		return "Backup nicht verfügbar."
+	case "selftest", "self_test":
+		if c.onSelfTestRequest != nil {
+			return c.onSelfTestRequest()
+		}
+		return "Selbsttest nicht verfügbar."
+	case "config":
+		if c.onConfigRequest != nil {
+			return c.onConfigRequest()
+		}
+		return "Konfiguration nicht verfügbar."
+	case "ping":
+		if c.onPingRequest != nil {
+			return c.onPingRequest()
+		}
+		return "🏓 Pong"
+	case "poll":
+		if c.onPollRequest != nil {
+			return c.onPollRequest()
+		}
+		return "Sofort-Suche nicht verfügbar."
 	default:
 		return "Unbekannter Befehl. Nutze /help für eine Übersicht."
 	}
@@ -255,21 +565,236 @@ func normalizeCommand(raw string) string {
 func (c *Controller) handleAddProfile(args []string) string {
 	const usage = "Nutzung: /addprofil [kampagne] <IS24-Such-URL> [Name]\n\nErst auf immobilienscout24.de die Suche bauen, dann die URL hierher kopieren."
 
-	category := ""
+	p, ok := parseProfileArgs(args)
+	if !ok {
+		return usage
+	}
+	if c.onAddProfile == nil {
+		return "Profil-Verwaltung nicht verfügbar."
+	}
+	return c.onAddProfile(p.Category, p.URL, p.Name)
+}
+
+// parseProfileArgs parses "[campaign] <url> [name...]". The optional leading
+// token (not a URL) is the campaign/category.
+func parseProfileArgs(args []string) (ProfileImport, bool) {
+	var p ProfileImport
 	rest := args
 	if len(rest) > 0 && !looksLikeURL(rest[0]) {
-		category = strings.ToLower(rest[0])
+		p.Category = strings.ToLower(rest[0])
 		rest = rest[1:]
 	}
 	if len(rest) == 0 || !looksLikeURL(rest[0]) {
+		return p, false
+	}
+	p.URL = rest[0]
+	p.Name = strings.TrimSpace(strings.Join(rest[1:], " "))
+	return p, true
+}
+
+// ParseProfileImport reads a list of search profiles, one
+// "[campaign] <url> [name]" per line, as pasted into /import_profiles or
+// given to -import-profiles. Blank lines and lines starting with # are
+// skipped; lines that don't parse are returned in invalid.
+func ParseProfileImport(text string) (entries []ProfileImport, invalid []string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, ok := parseProfileArgs(strings.Fields(line))
+		if !ok {
+			invalid = append(invalid, line)
+			continue
+		}
+		entries = append(entries, p)
+	}
+	return entries, invalid
+}
+
+// handleImportProfiles parses the pasted list and delegates creation to the
+// import callback. Unparseable lines are listed after its reply.
+func (c *Controller) handleImportProfiles(text string) string {
+	const usage = "Nutzung: /import_profiles, darunter eine Zeile pro Suche:\n[kampagne] <IS24-Such-URL> [Name]\n\nDie URLs der gespeicherten Suchen findest du auf immobilienscout24.de unter „Suchaufträge“."
+	entries, invalid := ParseProfileImport(text)
+	if len(entries) == 0 {
 		return usage
 	}
-	if c.onAddProfile == nil {
+	if c.onImportProfiles == nil {
 		return "Profil-Verwaltung nicht verfügbar."
 	}
-	url := rest[0]
-	name := strings.TrimSpace(strings.Join(rest[1:], " "))
-	return c.onAddProfile(category, url, name)
+	reply := c.onImportProfiles(entries)
+	if len(invalid) > 0 {
+		reply += "\n\n⚠️ Ohne Such-URL übersprungen:\n" + strings.Join(invalid, "\n")
+	}
+	return reply
+}
+
+// handlePreview accepts an IS24 ID or expose URL and delegates to the
+// preview callback.
+func (c *Controller) handlePreview(args []string) string {
+	const usage = "Nutzung: /preview <IS24-ID oder Exposé-URL>\n\nZeigt die Nachricht, die für die Wohnung gesendet würde (ohne Browser, ohne Versand)."
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if c.onPreview == nil {
+		return "Vorschau nicht verfügbar."
+	}
+	return c.onPreview(id)
+}
+
+// handleScan checks that the argument is an IS24 search URL and delegates to
+// the scan callback.
+func (c *Controller) handleScan(args []string) string {
+	const usage = "Nutzung: /scan <IS24-Such-URL>\n\nDurchsucht die URL einmalig, ohne Profil und ohne Filter, und zeigt die Treffer. Gespeichert wird nur mit /scan_save."
+	if len(args) != 1 || !looksLikeURL(args[0]) || !isIS24URL(args[0]) {
+		return usage
+	}
+	if c.onScan == nil {
+		return "Scan nicht verfügbar."
+	}
+	return c.onScan(args[0])
+}
+
+// handleLog accepts an IS24 ID or expose URL and delegates to the log
+// callback.
+func (c *Controller) handleLog(args []string) string {
+	const usage = "Nutzung: /log <IS24-ID oder Exposé-URL>\n\nZeigt den Verlauf einer Wohnung: gefunden, Preisänderungen, benachrichtigt, kontaktiert."
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if c.onLog == nil {
+		return "Verlauf nicht verfügbar."
+	}
+	return c.onLog(id)
+}
+
+// handleMarkContacted accepts an IS24 ID or expose URL and delegates to the
+// mark-contacted callback.
+func (c *Controller) handleMarkContacted(args []string, contacted bool) string {
+	usage := "Nutzung: /mark_contacted <IS24-ID oder Exposé-URL>\n\nMarkiert eine selbst kontaktierte Wohnung, damit der Bot sie nicht mehr anschreibt."
+	if !contacted {
+		usage = "Nutzung: /mark_uncontacted <IS24-ID oder Exposé-URL>\n\nSetzt die Wohnung zurück auf „nicht kontaktiert“."
+	}
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if c.onMarkContacted == nil {
+		return "Markieren nicht verfügbar."
+	}
+	return c.onMarkContacted(id, contacted)
+}
+
+// handleCancelContact accepts an IS24 ID or expose URL and delegates to the
+// cancel callback.
+func (c *Controller) handleCancelContact(args []string) string {
+	const usage = "Nutzung: /cancel <IS24-ID oder Exposé-URL>\n\nNimmt eine Wohnung aus der Kontakt-Warteschlange (/queue), der Bot schreibt sie dann nicht automatisch an."
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if c.onCancelContact == nil {
+		return "Warteschlange nicht verfügbar."
+	}
+	return c.onCancelContact(id)
+}
+
+// handleIgnore accepts an IS24 ID or expose URL and delegates to the ignore
+// callback.
+func (c *Controller) handleIgnore(args []string) string {
+	const usage = "Nutzung: /ignore <IS24-ID oder Exposé-URL>\n\nDie Wohnung wird nie mehr gespeichert, gemeldet oder angeschrieben, egal welches Profil sie findet."
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if c.onIgnore == nil {
+		return "Ignorieren nicht verfügbar."
+	}
+	return c.onIgnore(id)
+}
+
+// handleHTML accepts an IS24 ID or expose URL and delegates to the stored
+// HTML (/html) or refetch (/refetch) callback.
+func (c *Controller) handleHTML(args []string, refetch bool) string {
+	usage := "Nutzung: /html <IS24-ID oder Exposé-URL>\n\nSchickt das mit DEBUG_HTML=1 gespeicherte HTML des Exposés als Datei."
+	fn := c.onStoredHTML
+	if refetch {
+		usage = "Nutzung: /refetch <IS24-ID oder Exposé-URL>\n\nLädt das Exposé neu und schickt das HTML als Datei, ohne etwas zu speichern."
+		fn = c.onRefetchHTML
+	}
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if fn == nil {
+		return "HTML-Abruf nicht verfügbar."
+	}
+	return fn(id)
+}
+
+// handleResetListing accepts an IS24 ID or expose URL and delegates to the
+// reset callback.
+func (c *Controller) handleResetListing(args []string) string {
+	usage := "Nutzung: /reset_listing <IS24-ID oder Exposé-URL>\n\nLöscht die Wohnung aus der Datenbank, damit der nächste Durchlauf sie neu findet und meldet."
+	id, ok := listingIDArg(args)
+	if !ok {
+		return usage
+	}
+	if c.onResetListing == nil {
+		return "Zurücksetzen nicht verfügbar."
+	}
+	return c.onResetListing(id)
+}
+
+// handlePauseProfile parses the profile ID and delegates to the callback.
+func (c *Controller) handlePauseProfile(args []string, paused bool) string {
+	usage := "Nutzung: /pause_profile <id>\n\nPausiert ein Suchprofil, ohne es zu deaktivieren; /resume_profile <id> setzt es fort."
+	if !paused {
+		usage = "Nutzung: /resume_profile <id>\n\nSetzt ein pausiertes Suchprofil fort."
+	}
+	if len(args) != 1 {
+		return usage
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return usage
+	}
+	if c.onPauseProfile == nil {
+		return "Profil-Verwaltung nicht verfügbar."
+	}
+	return c.onPauseProfile(id, paused)
+}
+
+// handleBlockLandlord validates the name and delegates to the callback.
+func (c *Controller) handleBlockLandlord(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if len([]rune(name)) < 3 {
+		return "Nutzung: /block_landlord <Name>\n\nWohnungen von Anbietern, deren Name den Text enthält (Groß-/Kleinschreibung egal), werden künftig aussortiert."
+	}
+	if c.onBlockLandlord == nil {
+		return "Profil-Verwaltung nicht verfügbar."
+	}
+	return c.onBlockLandlord(name)
+}
+
+// exposeIDRe matches a bare IS24 ID or the ID in an expose URL.
+var exposeIDRe = regexp.MustCompile(`^(?:https?://\S*/expose/)?(\d+)(?:[/?#]\S*)?$`)
+
+// listingIDArg returns the IS24 ID from a command's single argument (a bare
+// ID or an expose URL). ok is false when the handler should answer with its
+// usage text.
+func listingIDArg(args []string) (id string, ok bool) {
+	if len(args) != 1 {
+		return "", false
+	}
+	m := exposeIDRe.FindStringSubmatch(args[0])
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
 }
 
 // handleCookie validates the new IS24 cookie string and pushes it through the
@@ -291,18 +816,97 @@ func (c *Controller) handleCookie(value string) string {
 	return fmt.Sprintf("✅ *Cookie aktualisiert* (Länge: %d).\nNächster Poll-Zyklus nutzt den neuen Cookie.", len(v))
 }
 
+func isDeleteProfileCommand(token string) bool {
+	t := strings.ToLower(token)
+	return t == "delete_profile" || t == "deleteprofile"
+}
+
+// handleDeleteProfile runs "/delete_profile <id> [ja|nein]". Without a
+// decision it asks for confirmation (warning when listings were already
+// contacted) and remembers the request for deleteConfirmTTL; "ja" then
+// deletes, "nein" cancels.
+func (c *Controller) handleDeleteProfile(args []string) Reply {
+	const usage = "Nutzung: /delete_profile <id>"
+	if len(args) == 0 || len(args) > 2 {
+		return Reply{Text: usage}
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return Reply{Text: "Ungültige ID. " + usage}
+	}
+	if c.onDescribeProfile == nil || c.onDeleteProfile == nil {
+		return Reply{Text: "Profil-Verwaltung nicht verfügbar."}
+	}
+
+	if len(args) == 2 {
+		c.mu.Lock()
+		requested, pending := c.pendingDeletes[id]
+		delete(c.pendingDeletes, id)
+		c.mu.Unlock()
+
+		switch strings.ToLower(args[1]) {
+		case "ja", "yes":
+			if !pending || time.Since(requested) > deleteConfirmTTL {
+				return Reply{Text: fmt.Sprintf("⌛ Keine offene Löschanfrage für Profil %d. Erst /delete_profile %d senden.", id, id)}
+			}
+			if err := c.onDeleteProfile(id); err != nil {
+				return Reply{Text: "❌ Löschen fehlgeschlagen: " + err.Error()}
+			}
+			return Reply{Text: fmt.Sprintf("🗑 *Profil %d gelöscht.*", id)}
+		case "nein", "no":
+			return Reply{Text: fmt.Sprintf("↩️ Löschen von Profil %d abgebrochen.", id)}
+		}
+		return Reply{Text: usage}
+	}
+
+	info, err := c.onDescribeProfile(id)
+	if err != nil {
+		return Reply{Text: "❌ Profil laden fehlgeschlagen: " + err.Error()}
+	}
+	if info == nil {
+		return Reply{Text: fmt.Sprintf("Profil %d nicht gefunden.", id)}
+	}
+
+	c.mu.Lock()
+	if c.pendingDeletes == nil {
+		c.pendingDeletes = make(map[int64]time.Time)
+	}
+	c.pendingDeletes[id] = time.Now()
+	c.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚠️ *Profil %d endgültig löschen?*\n\n*%s* — %d gespeicherte Wohnung(en).\n", id, info.Name, info.Listings))
+	if info.Contacted > 0 {
+		sb.WriteString(fmt.Sprintf("\n❗ *Achtung:* %d Wohnung(en) aus diesem Profil wurden schon angeschrieben. Sie bleiben gespeichert, verlieren aber die Profil-Zuordnung.\n", info.Contacted))
+	}
+	sb.WriteString(fmt.Sprintf("\nBestätigen: /delete_profile %d ja\nAbbrechen: /delete_profile %d nein", id, id))
+	return Reply{
+		Text: sb.String(),
+		Buttons: []Button{
+			{Label: "🗑 Endgültig löschen", Command: fmt.Sprintf("/delete_profile %d ja", id)},
+			{Label: "Abbrechen", Command: fmt.Sprintf("/delete_profile %d nein", id)},
+		},
+	}
+}
+
 // stripFirstToken returns the raw input with the first whitespace-delimited
 // token removed (the command name itself). Preserves the rest verbatim,
 // including any '=' or ';' characters in the payload.
 func stripFirstToken(raw string) string {
 	s := strings.TrimSpace(raw)
 	s = strings.TrimPrefix(s, "/")
-	if i := strings.IndexAny(s, " \t"); i >= 0 {
+	if i := strings.IndexAny(s, " \t\r\n"); i >= 0 {
 		return strings.TrimSpace(s[i+1:])
 	}
 	return ""
 }
 
+// isIS24URL reports whether s points to one of the IS24 country sites
+// (immobilienscout24.de, .at, immoscout24.ch).
+func isIS24URL(s string) bool {
+	return strings.Contains(s, "immobilienscout24.") || strings.Contains(s, "immoscout24.")
+}
+
 func looksLikeURL(s string) bool {
 	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
 }
@@ -315,6 +919,9 @@ func (c *Controller) helpMessage() string {
 /contact_test - Test-Modus (Nachricht-Vorschau)
 /contact_notify - Nur benachrichtigen (kein Kontakt)
 /contact_off - Pausiert (keine Meldungen)
+/queue - Wohnungen, die auf den Auto-Kontakt warten
+/cancel <id> - Wohnung aus der Kontakt-Warteschlange nehmen
+/ignore <id> - Wohnung dauerhaft ignorieren (nie melden oder anschreiben)
 
 *Ruhezeiten:*
 /quiet_on - Ruhezeiten an
@@ -322,15 +929,37 @@ func (c *Controller) helpMessage() string {
 
 *Suchprofile:*
 /addprofil [kampagne] <URL> [Name] - Profil aus IS24-Such-URL anlegen
+/import_profiles - Mehrere Such-URLs auf einmal importieren (eine pro Zeile)
 /listprofile - Aktive Profile anzeigen
+/pause_profile <id> - Profil vorübergehend pausieren
+/resume_profile <id> - Pausiertes Profil fortsetzen
 /delprofil <id> - Profil deaktivieren
+/delete_profile <id> - Profil endgültig löschen (mit Bestätigung)
+/block_landlord <Name> - Anbieter/Makler in allen Profilen sperren
 
 *Cookie:*
 /cookie <string> - IS24-Cookie aktualisieren (ohne Restart)
 
 *Info:*
 /status - Aktueller Bot-Status
+/config - Aktive Konfiguration (ohne Geheimnisse)
+/ping - Verbindung zu Telegram prüfen
+/selftest - Scraping testen (Browser, Cookie, Parser), ohne zu speichern
 /stats - Statistiken anzeigen
+/poll - Sofort nach neuen Wohnungen suchen
+/funnel - Funnel der letzten 7 Tage (gesehen → kontaktiert)
+/preview <id> - Nachricht für eine Wohnung anzeigen (ohne Versand)
+/scan <URL> - IS24-Such-URL einmalig durchsuchen (ohne Filter)
+/scan_save - Neue Treffer des letzten Scans speichern
+/mark_contacted <id> - Wohnung als kontaktiert markieren
+/mark_uncontacted <id> - Markierung „kontaktiert“ aufheben
+/reset_listing <id> - Wohnung löschen, damit sie neu gemeldet wird
+/log <id> - Verlauf einer Wohnung anzeigen
+/html <id> - Gespeichertes Exposé-HTML als Datei (DEBUG_HTML=1)
+/refetch <id> - Exposé neu laden und HTML als Datei schicken
+/summary - Zusammenfassung der letzten 24h
+/diff - Was sich seit dem vorletzten Durchlauf geändert hat
+/backup - Datenbank-Backup jetzt anlegen
 /help - Diese Hilfe`
 }
 
@@ -367,6 +996,14 @@ func (c *Controller) ContactModeLabel() string {
 	return contactModeLabel(c.GetContactMode())
 }
 
+// ContactModeRestored reports whether the contact mode was restored from the
+// store at startup rather than falling back to the default.
+func (c *Controller) ContactModeRestored() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.modeLoaded
+}
+
 func contactModeLabel(mode ContactMode) string {
 	switch mode {
 	case ContactModeOff:
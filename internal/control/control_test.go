@@ -2,6 +2,7 @@ package control
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -111,6 +112,284 @@ func TestStatsCallback(t *testing.T) {
 	}
 }
 
+func TestFunnelCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/funnel"); got == "" {
+		t.Error("funnel without callback should still respond")
+	}
+	c.SetFunnelCallback(func() string { return "FUNNEL" })
+	if got := c.HandleCommand("funnel"); got != "FUNNEL" {
+		t.Errorf("funnel should use callback, got %q", got)
+	}
+}
+
+func TestDiffCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/diff"); got != "Vergleich nicht verfügbar." {
+		t.Errorf("diff without callback: got %q", got)
+	}
+	c.SetDiffCallback(func() string { return "DIFF" })
+	if got := c.HandleCommand("/diff"); got != "DIFF" {
+		t.Errorf("diff should use callback, got %q", got)
+	}
+}
+
+func TestPingCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/ping"); got != "🏓 Pong" {
+		t.Errorf("ping without callback: got %q", got)
+	}
+	c.SetPingCallback(func() string { return "PONG" })
+	if got := c.HandleCommand("/ping"); got != "PONG" {
+		t.Errorf("ping should use callback, got %q", got)
+	}
+}
+
+func TestSummaryCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/summary"); got == "" {
+		t.Error("summary without callback should still respond")
+	}
+	c.SetSummaryCallback(func() string { return "DIGEST" })
+	if got := c.HandleCommand("Summary"); got != "DIGEST" {
+		t.Errorf("summary should use callback, got %q", got)
+	}
+}
+
+func TestBackupCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/backup"); got != "Backup nicht verfügbar." {
+		t.Errorf("backup without callback: got %q", got)
+	}
+	c.SetBackupCallback(func() string { return "BACKUP" })
+	if got := c.HandleCommand("/backup"); got != "BACKUP" {
+		t.Errorf("backup should use callback, got %q", got)
+	}
+}
+
+func TestSelfTestCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/selftest"); got != "Selbsttest nicht verfügbar." {
+		t.Errorf("selftest without callback: got %q", got)
+	}
+	c.SetSelfTestCallback(func() string { return "SELFTEST" })
+	if got := c.HandleCommand("self test"); got != "SELFTEST" {
+		t.Errorf("selftest should use callback, got %q", got)
+	}
+}
+
+func TestConfigCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/config"); got != "Konfiguration nicht verfügbar." {
+		t.Errorf("config without callback: got %q", got)
+	}
+	c.SetConfigCallback(func() string { return "CONFIG" })
+	if got := c.HandleCommand("Config"); got != "CONFIG" {
+		t.Errorf("config should use callback, got %q", got)
+	}
+}
+
+func TestLogCommand(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/log 123"); got != "Verlauf nicht verfügbar." {
+		t.Errorf("log without callback: got %q", got)
+	}
+	var gotID string
+	c.SetLogCallback(func(id string) string { gotID = id; return "LOG" })
+	if got := c.HandleCommand("/log https://www.immobilienscout24.de/expose/456"); got != "LOG" || gotID != "456" {
+		t.Errorf("log: got %q for id %q", got, gotID)
+	}
+	if got := c.HandleCommand("/log"); !strings.HasPrefix(got, "Nutzung: /log") {
+		t.Errorf("log without id: got %q", got)
+	}
+}
+
+func TestPreviewCommand(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/preview 123"); got != "Vorschau nicht verfügbar." {
+		t.Errorf("preview without callback: got %q", got)
+	}
+	var gotID string
+	c.SetPreviewCallback(func(id string) string { gotID = id; return "OK" })
+	for _, in := range []string{"/preview 148123456", "/preview https://www.immobilienscout24.de/expose/148123456?referrer=RESULT_LIST", "vorschau 148123456"} {
+		gotID = ""
+		if got := c.HandleCommand(in); got != "OK" || gotID != "148123456" {
+			t.Errorf("%q: got %q with id %q", in, got, gotID)
+		}
+	}
+	for _, in := range []string{"/preview", "/preview abc", "/preview 1 2"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /preview") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestScanCommands(t *testing.T) {
+	c := newTestCtrl()
+	var gotURL string
+	c.SetScanCallbacks(func(u string) string { gotURL = u; return "STARTED" }, func() string { return "SAVED" })
+	const u = "https://www.immobilienscout24.de/Suche/de/berlin/berlin/wohnung-mieten?price=-1500"
+	if got := c.HandleCommand("/scan " + u); got != "STARTED" || gotURL != u {
+		t.Errorf("scan: got %q with url %q", got, gotURL)
+	}
+	if got := c.HandleCommand("/scan_save"); got != "SAVED" {
+		t.Errorf("scan_save: got %q", got)
+	}
+	const at = "https://www.immobilienscout24.at/regional/wien/wohnung-mieten"
+	if got := c.HandleCommand("/scan " + at); got != "STARTED" || gotURL != at {
+		t.Errorf("scan .at: got %q with url %q", got, gotURL)
+	}
+	for _, in := range []string{"/scan", "/scan berlin", "/scan https://example.com/Suche/x"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /scan") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestMarkContactedCommands(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/mark_contacted 123"); got != "Markieren nicht verfügbar." {
+		t.Errorf("mark without callback: got %q", got)
+	}
+	var gotID string
+	var gotContacted bool
+	c.SetMarkContactedCallback(func(id string, contacted bool) string { gotID, gotContacted = id, contacted; return "OK" })
+	if got := c.HandleCommand("/mark_contacted https://www.immobilienscout24.de/expose/148123456"); got != "OK" || gotID != "148123456" || !gotContacted {
+		t.Errorf("mark_contacted: got %q with id %q contacted=%v", got, gotID, gotContacted)
+	}
+	if got := c.HandleCommand("/mark_uncontacted 148123456"); got != "OK" || gotID != "148123456" || gotContacted {
+		t.Errorf("mark_uncontacted: got %q with id %q contacted=%v", got, gotID, gotContacted)
+	}
+	for _, in := range []string{"/mark_contacted", "/mark_uncontacted abc"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /mark_") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestQueueCommands(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/queue"); got != "Warteschlange nicht verfügbar." {
+		t.Errorf("queue without callback: got %q", got)
+	}
+	var gotID string
+	c.SetQueueCallbacks(func() string { return "QUEUE" }, func(id string) string { gotID = id; return "OK" })
+	if got := c.HandleCommand("/queue"); got != "QUEUE" {
+		t.Errorf("queue: got %q", got)
+	}
+	if got := c.HandleCommand("/cancel https://www.immobilienscout24.de/expose/148123456"); got != "OK" || gotID != "148123456" {
+		t.Errorf("cancel: got %q with id %q", got, gotID)
+	}
+	for _, in := range []string{"/cancel", "/cancel abc"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /cancel") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestResetListingCommand(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/reset_listing 123"); got != "Zurücksetzen nicht verfügbar." {
+		t.Errorf("reset without callback: got %q", got)
+	}
+	var gotID string
+	c.SetResetListingCallback(func(id string) string { gotID = id; return "OK" })
+	if got := c.HandleCommand("/reset_listing https://www.immobilienscout24.de/expose/148123456"); got != "OK" || gotID != "148123456" {
+		t.Errorf("reset_listing: got %q with id %q", got, gotID)
+	}
+	for _, in := range []string{"/reset_listing", "/reset_listing abc"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /reset_listing") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestHTMLCommands(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/html 123"); got != "HTML-Abruf nicht verfügbar." {
+		t.Errorf("html without callback: got %q", got)
+	}
+	var stored, refetched string
+	c.SetHTMLCallbacks(
+		func(id string) string { stored = id; return "STORED" },
+		func(id string) string { refetched = id; return "REFETCH" },
+	)
+	if got := c.HandleCommand("/html 148123456"); got != "STORED" || stored != "148123456" {
+		t.Errorf("html: got %q with id %q", got, stored)
+	}
+	if got := c.HandleCommand("/refetch https://www.immobilienscout24.de/expose/148123456#/"); got != "REFETCH" || refetched != "148123456" {
+		t.Errorf("refetch: got %q with id %q", got, refetched)
+	}
+	for _, in := range []string{"/html", "/refetch ../../etc/passwd", "/html 1 2"} {
+		cmd := strings.Fields(in)[0]
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: "+cmd) {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestIgnoreCommand(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/ignore 123"); got != "Ignorieren nicht verfügbar." {
+		t.Errorf("ignore without callback: got %q", got)
+	}
+	var ignored string
+	c.SetIgnoreCallback(func(id string) string { ignored = id; return "OK" })
+	if got := c.HandleCommand("/ignorieren https://www.immobilienscout24.de/expose/148123456"); got != "OK" || ignored != "148123456" {
+		t.Errorf("ignore: got %q with id %q", got, ignored)
+	}
+	if got := c.HandleCommand("/ignore Musterstraße"); !strings.HasPrefix(got, "Nutzung: /ignore") {
+		t.Errorf("ignore without ID should show usage, got %q", got)
+	}
+}
+
+func TestPauseProfileCommands(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/pause_profile 3"); got != "Profil-Verwaltung nicht verfügbar." {
+		t.Errorf("pause without callback: got %q", got)
+	}
+	var gotID int64
+	var gotPaused bool
+	c.SetPauseProfileCallback(func(id int64, paused bool) string { gotID, gotPaused = id, paused; return "OK" })
+	if got := c.HandleCommand("/pause_profile 3"); got != "OK" || gotID != 3 || !gotPaused {
+		t.Errorf("pause_profile: got %q with id %d paused=%v", got, gotID, gotPaused)
+	}
+	if got := c.HandleCommand("/resume_profile 4"); got != "OK" || gotID != 4 || gotPaused {
+		t.Errorf("resume_profile: got %q with id %d paused=%v", got, gotID, gotPaused)
+	}
+	for _, in := range []string{"/pause_profile", "/pause_profile abc", "/resume_profile 1 2"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestBlockLandlordCommand(t *testing.T) {
+	c := newTestCtrl()
+	var gotName string
+	c.SetBlockLandlordCallback(func(name string) string { gotName = name; return "OK" })
+	if got := c.HandleCommand("/block_landlord  Spam   Immobilien GmbH"); got != "OK" || gotName != "Spam Immobilien GmbH" {
+		t.Errorf("got %q with name %q", got, gotName)
+	}
+	for _, in := range []string{"/block_landlord", "/block_landlord ab"} {
+		if got := c.HandleCommand(in); !strings.HasPrefix(got, "Nutzung: /block_landlord") {
+			t.Errorf("%q should show usage, got %q", in, got)
+		}
+	}
+}
+
+func TestPollCallback(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/poll"); got == "" {
+		t.Error("poll without callback should still respond")
+	}
+	calls := 0
+	c.SetPollCallback(func() string { calls++; return "STARTED" })
+	if got := c.HandleCommand("/Poll"); got != "STARTED" || calls != 1 {
+		t.Errorf("poll should use callback, got %q (calls=%d)", got, calls)
+	}
+}
+
 func TestProfileCommands(t *testing.T) {
 	c := newTestCtrl()
 	var gotCat, gotURL, gotName, gotDel string
@@ -255,6 +534,9 @@ func TestReloadsSettingsFromStore(t *testing.T) {
 	if !c.IsAutoContactEnabled() {
 		t.Error("contact mode should reload as ContactModeOn")
 	}
+	if !c.ContactModeRestored() {
+		t.Error("ContactModeRestored should be true after reload")
+	}
 	if v := c.IsQuietHoursEnabled(); v == nil || *v {
 		t.Error("quiet hours should reload as false")
 	}
@@ -263,6 +545,16 @@ func TestReloadsSettingsFromStore(t *testing.T) {
 	}
 }
 
+func TestContactModeDefaultNotRestored(t *testing.T) {
+	c := New(newMemStore(map[string]string{MetaContactMode: "bogus"}), nil, Defaults{})
+	if c.GetContactMode() != ContactModeTest {
+		t.Errorf("unparseable mode should fall back to test, got %v", c.GetContactMode())
+	}
+	if c.ContactModeRestored() {
+		t.Error("default mode must not be reported as restored")
+	}
+}
+
 func TestSetQuietHoursWindowRejectsGarbage(t *testing.T) {
 	c := newTestCtrl()
 	if err := c.SetQuietHoursWindow("99:00", "07:00"); err == nil {
@@ -341,3 +633,102 @@ func timeAt(t *testing.T, h, m int) time.Time {
 	}
 	return time.Date(2026, 1, 15, h, m, 0, 0, loc)
 }
+
+func TestDeleteProfileConfirmation(t *testing.T) {
+	c := newTestCtrl()
+	if got := c.HandleCommand("/delete_profile 3"); got != "Profil-Verwaltung nicht verfügbar." {
+		t.Errorf("without callbacks: %q", got)
+	}
+
+	var deleted []int64
+	c.SetDeleteProfileCallbacks(
+		func(id int64) (*ProfileDeletion, error) {
+			if id != 3 {
+				return nil, nil
+			}
+			return &ProfileDeletion{Name: "WG Berlin", Listings: 12, Contacted: 2}, nil
+		},
+		func(id int64) error { deleted = append(deleted, id); return nil },
+	)
+
+	// Confirming without a prompt first must not delete.
+	c.HandleCommand("/delete_profile 3 ja")
+	if len(deleted) != 0 {
+		t.Fatal("deleted without confirmation prompt")
+	}
+
+	r := c.HandleCommandReply("/delete_profile 3")
+	if !strings.Contains(r.Text, "WG Berlin") || !strings.Contains(r.Text, "Achtung") {
+		t.Errorf("prompt should name the profile and warn about contacted listings: %q", r.Text)
+	}
+	if len(r.Buttons) != 2 || r.Buttons[0].Command != "/delete_profile 3 ja" {
+		t.Errorf("buttons = %+v", r.Buttons)
+	}
+	if len(deleted) != 0 {
+		t.Fatal("prompt must not delete")
+	}
+
+	// The button command deletes; a second press finds nothing pending.
+	c.HandleCommandReply(r.Buttons[0].Command)
+	c.HandleCommandReply(r.Buttons[0].Command)
+	if len(deleted) != 1 || deleted[0] != 3 {
+		t.Errorf("deleted = %v, want [3]", deleted)
+	}
+
+	// Cancel drops the pending request.
+	c.HandleCommand("/delete_profile 3")
+	c.HandleCommand("/delete_profile 3 nein")
+	c.HandleCommand("/delete_profile 3 ja")
+	if len(deleted) != 1 {
+		t.Errorf("cancelled deletion went through: %v", deleted)
+	}
+
+	if got := c.HandleCommand("/delete_profile 9"); !strings.Contains(got, "nicht gefunden") {
+		t.Errorf("unknown profile: %q", got)
+	}
+	if got := c.HandleCommand("/delete_profile abc"); !strings.Contains(got, "Ungültige ID") {
+		t.Errorf("invalid id: %q", got)
+	}
+}
+
+func TestDeleteProfilePromptWithoutContacts(t *testing.T) {
+	c := newTestCtrl()
+	c.SetDeleteProfileCallbacks(
+		func(int64) (*ProfileDeletion, error) { return &ProfileDeletion{Name: "Neu"}, nil },
+		func(int64) error { return nil },
+	)
+	if got := c.HandleCommand("/delete_profile 1"); strings.Contains(got, "Achtung") {
+		t.Errorf("no contacted listings → no warning: %q", got)
+	}
+}
+
+func TestImportProfilesCommand(t *testing.T) {
+	c := newTestCtrl()
+	var got []ProfileImport
+	c.SetImportProfilesCallback(func(entries []ProfileImport) string {
+		got = entries
+		return "IMPORTED"
+	})
+
+	reply := c.HandleCommand("/import_profiles\nhttps://is24.de/Suche/a Altbau Mitte\n\n# Kommentar\nwg https://is24.de/Suche/b\nkeine url hier")
+	want := []ProfileImport{
+		{URL: "https://is24.de/Suche/a", Name: "Altbau Mitte"},
+		{Category: "wg", URL: "https://is24.de/Suche/b"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if !strings.HasPrefix(reply, "IMPORTED") || !strings.Contains(reply, "keine url hier") {
+		t.Errorf("reply = %q", reply)
+	}
+
+	got = nil
+	if reply := c.HandleCommand("/import_profiles"); !strings.HasPrefix(reply, "Nutzung: /import_profiles") || got != nil {
+		t.Errorf("empty import: reply %q, entries %+v", reply, got)
+	}
+}
@@ -1,65 +1,258 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // SearchProfile defines criteria for apartment search
 type SearchProfile struct {
-	ID              int64     `json:"id"`
-	Name            string    `json:"name"`
-	City            string    `json:"city"`
-	Districts       []string  `json:"districts,omitempty"`
-	PostalCodes     []string  `json:"postal_codes,omitempty"`
-	MinPrice        int       `json:"min_price,omitempty"`
-	MaxPrice        int       `json:"max_price,omitempty"`
-	MinRooms        float64   `json:"min_rooms,omitempty"`
-	MaxRooms        float64   `json:"max_rooms,omitempty"`
-	MinArea         int       `json:"min_area,omitempty"`
-	MaxArea         int       `json:"max_area,omitempty"`
-	HasBalcony      *bool     `json:"has_balcony,omitempty"`
-	HasEBK          *bool     `json:"has_ebk,omitempty"`
-	HasElevator     *bool     `json:"has_elevator,omitempty"`
-	PetsAllowed     *bool     `json:"pets_allowed,omitempty"`
-	MinBuildYear    int       `json:"min_build_year,omitempty"`
-	MaxBuildYear    int       `json:"max_build_year,omitempty"`
-	ExcludeKeywords []string  `json:"exclude_keywords,omitempty"`
-	SearchURL       string    `json:"search_url,omitempty"`
-	Category        string    `json:"category,omitempty"` // campaign name (see config.Campaigns); empty = default
-	Active          bool      `json:"active"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID                     int64     `json:"id"`
+	Name                   string    `json:"name"`
+	City                   string    `json:"city"`
+	AllowedCities          []string  `json:"allowed_cities,omitempty"` // further acceptable cities (metro-area search URLs)
+	Districts              []string  `json:"districts,omitempty"`
+	PostalCodes            []string  `json:"postal_codes,omitempty"`
+	MinPrice               int       `json:"min_price,omitempty"`
+	MaxPrice               int       `json:"max_price,omitempty"`
+	MaxTotalRent           int       `json:"max_total_rent,omitempty"`             // warm budget (Warmmiete incl. Nebenkosten)
+	MaxMonthlyFees         int       `json:"max_monthly_fees,omitempty"`           // purchase: Hausgeld/Wohngeld limit
+	MaxPurchasePricePerSqm float64   `json:"max_purchase_price_per_sqm,omitempty"` // purchase: Kaufpreis per m² limit
+	MinRooms               float64   `json:"min_rooms,omitempty"`
+	MaxRooms               float64   `json:"max_rooms,omitempty"`
+	MinRoomsExclusive      bool      `json:"min_rooms_exclusive,omitempty"` // "more than MinRooms": 2 → 2.5 and up
+	MaxRoomsExclusive      bool      `json:"max_rooms_exclusive,omitempty"` // "less than MaxRooms": 3 → up to 2.5
+	MinArea                int       `json:"min_area,omitempty"`
+	MaxArea                int       `json:"max_area,omitempty"`
+	HasBalcony             *bool     `json:"has_balcony,omitempty"`
+	HasEBK                 *bool     `json:"has_ebk,omitempty"`
+	HasElevator            *bool     `json:"has_elevator,omitempty"`
+	PetsAllowed            *bool     `json:"pets_allowed,omitempty"`
+	HasGuestToilet         *bool     `json:"has_guest_toilet,omitempty"`
+	HasCellar              *bool     `json:"has_cellar,omitempty"`
+	HasSeparateKitchen     *bool     `json:"has_separate_kitchen,omitempty"`
+	MinBuildYear           int       `json:"min_build_year,omitempty"`
+	MaxBuildYear           int       `json:"max_build_year,omitempty"`
+	ExcludeKeywords        []string  `json:"exclude_keywords,omitempty"`
+	ExcludeLandlords       []string  `json:"exclude_landlords,omitempty"`    // landlord/agency name substrings
+	ExcludeCooperatives    bool      `json:"exclude_cooperatives,omitempty"` // drop Genossenschaft listings
+	ExcludeFloorTypes      []string  `json:"exclude_floor_types,omitempty"`  // e.g. ["Erdgeschoss", "Souterrain"]
+	MaxTrackedListings     int       `json:"max_tracked_listings,omitempty"` // keep at most this many listings; 0 = no cap
+	ContactMinRooms        float64   `json:"contact_min_rooms,omitempty"`    // auto-contact thresholds, stricter than the notify filters
+	ContactMinArea         int       `json:"contact_min_area,omitempty"`
+	ContactMaxPrice        int       `json:"contact_max_price,omitempty"`
+	SearchURL              string    `json:"search_url,omitempty"`
+	Category               string    `json:"category,omitempty"`      // campaign name (see config.Campaigns); empty = default
+	ContactPhone           string    `json:"contact_phone,omitempty"` // overrides the campaign's phone in contact forms
+	ContactEmail           string    `json:"contact_email,omitempty"` // overrides the campaign's e-mail in contact forms
+	Active                 bool      `json:"active"`
+	Paused                 bool      `json:"paused,omitempty"`             // temporarily skipped by the scheduler, unlike !Active
+	Silent                 bool      `json:"silent,omitempty"`             // notify without sound (Telegram), for low-priority profiles
+	CalibrationCycles      int       `json:"calibration_cycles,omitempty"` // cycles left that also report filtered-out listings
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// RoomBounds returns the profile's room range as inclusive bounds on IS24's
+// half-room steps, so "at least 2" is 2.0 and "more than 2" is 2.5. Bounds
+// between steps are rounded inward (min 2.3 → 2.5). 0 = unbounded.
+func (sp *SearchProfile) RoomBounds() (minRooms, maxRooms float64) {
+	if sp.MinRooms > 0 {
+		if sp.MinRoomsExclusive {
+			minRooms = math.Floor(sp.MinRooms*2)/2 + 0.5
+		} else {
+			minRooms = math.Ceil(sp.MinRooms*2) / 2
+		}
+	}
+	if sp.MaxRooms > 0 {
+		if sp.MaxRoomsExclusive {
+			maxRooms = math.Ceil(sp.MaxRooms*2)/2 - 0.5
+		} else {
+			maxRooms = math.Floor(sp.MaxRooms*2) / 2
+		}
+	}
+	return minRooms, maxRooms
+}
+
+// ContactBlock reports which of the profile's auto-contact thresholds a
+// listing misses ("rooms", "area" or "price"), or "" when it may be
+// contacted. An unknown value misses a set threshold.
+func (sp *SearchProfile) ContactBlock(l *Listing) string {
+	switch {
+	case sp.ContactMinRooms > 0 && l.Rooms < sp.ContactMinRooms:
+		return "rooms"
+	case sp.ContactMinArea > 0 && l.Area < sp.ContactMinArea:
+		return "area"
+	case sp.ContactMaxPrice > 0 && (l.Price <= 0 || l.Price > sp.ContactMaxPrice):
+		return "price"
+	}
+	return ""
+}
+
+// searchTypeRe finds the property type segment of an IS24 search URL
+// (".../wohnung-mieten?...").
+var searchTypeRe = regexp.MustCompile(`/(wohnung|haus)-(mieten|kaufen)(?:[/?#]|$)`)
+
+// PropertyType returns the IS24 type the profile's search URL asks for, in
+// the form of Listing.PropertyType ("apartmentrent", "housebuy"), or "" when
+// the URL does not say.
+func (sp *SearchProfile) PropertyType() string {
+	m := searchTypeRe.FindStringSubmatch(strings.ToLower(sp.SearchURL))
+	if m == nil {
+		return ""
+	}
+	kind, marketing := "apartment", "rent"
+	if m[1] == "haus" {
+		kind = "house"
+	}
+	if m[2] == "kaufen" {
+		marketing = "buy"
+	}
+	return kind + marketing
 }
 
 // Listing represents an apartment listing from IS24
 type Listing struct {
-	ID              int64     `json:"id"`
-	IS24ID          string    `json:"is24_id"`
-	Title           string    `json:"title"`
-	URL             string    `json:"url"`
-	Address         string    `json:"address"`
-	City            string    `json:"city"`
-	District        string    `json:"district,omitempty"`
-	PostalCode      string    `json:"postal_code,omitempty"`
-	Price           int       `json:"price"`
-	PricePerSqm     float64   `json:"price_per_sqm,omitempty"`
-	Rooms           float64   `json:"rooms"`
-	Area            int       `json:"area"`
-	HasBalcony      bool      `json:"has_balcony"`
-	HasEBK          bool      `json:"has_ebk"`
-	HasElevator     bool      `json:"has_elevator"`
-	PetsAllowed     *bool     `json:"pets_allowed,omitempty"`
-	BuildYear       int       `json:"build_year,omitempty"`
-	AvailableFrom   string    `json:"available_from,omitempty"`
-	Description     string    `json:"description,omitempty"`
-	LandlordName    string    `json:"landlord_name,omitempty"`
-	LandlordType    string    `json:"landlord_type,omitempty"`
-	ImageURLs       []string  `json:"image_urls,omitempty"`
-	ContactFormURL  string    `json:"contact_form_url,omitempty"`
-	SearchProfileID int64     `json:"search_profile_id"`
-	Contacted       bool      `json:"contacted"`
-	Notified        bool      `json:"notified"`
-	Skipped         bool      `json:"skipped"` // manually marked seen/handled → excluded from auto-contact
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID                 int64     `json:"id"`
+	IS24ID             string    `json:"is24_id"`
+	Title              string    `json:"title"`
+	URL                string    `json:"url"`
+	Address            string    `json:"address"`
+	City               string    `json:"city"`
+	District           string    `json:"district,omitempty"`
+	PostalCode         string    `json:"postal_code,omitempty"`
+	Latitude           float64   `json:"latitude,omitempty"` // WGS84, 0 = unknown
+	Longitude          float64   `json:"longitude,omitempty"`
+	Price              int       `json:"price"`                      // Kaltmiete
+	PriceOnRequest     bool      `json:"price_on_request,omitempty"` // "Preis auf Anfrage": Price is 0 by design
+	WarmRent           int       `json:"warm_rent,omitempty"`        // Warmmiete as listed (0 = unknown)
+	ServiceCharge      int       `json:"service_charge,omitempty"`   // Nebenkosten (0 = unknown)
+	HeatingIncluded    *bool     `json:"heating_included,omitempty"` // Heizkosten in Nebenkosten enthalten (nil = unknown)
+	MonthlyFees        int       `json:"monthly_fees,omitempty"`     // purchase: Hausgeld/Wohngeld per month (0 = unknown)
+	TotalRent          int       `json:"total_rent,omitempty"`       // warm rent checked against MaxTotalRent
+	RentEstimated      bool      `json:"rent_estimated,omitempty"`   // TotalRent is Kaltmiete × factor, not listed data
+	PricePerSqm        float64   `json:"price_per_sqm,omitempty"`    // Kaltmiete, or Kaufpreis for purchases, per m²
+	Rooms              float64   `json:"rooms"`
+	Area               int       `json:"area"`
+	HasBalcony         bool      `json:"has_balcony"`
+	HasEBK             bool      `json:"has_ebk"`
+	HasElevator        bool      `json:"has_elevator"`
+	HasGuestToilet     bool      `json:"has_guest_toilet"`
+	HasCellar          bool      `json:"has_cellar"`
+	HasSeparateKitchen bool      `json:"has_separate_kitchen"`
+	RequiresMembership bool      `json:"requires_membership,omitempty"` // Genossenschaft: shares and membership required
+	FloorType          string    `json:"floor_type,omitempty"`          // Erdgeschoss, Dachgeschoss, Penthouse, ... (German label)
+	PetsAllowed        *bool     `json:"pets_allowed,omitempty"`
+	BuildYear          int       `json:"build_year,omitempty"`
+	AvailableFrom      string    `json:"available_from,omitempty"` // "Verfügbar ab"
+	VacantFrom         string    `json:"vacant_from,omitempty"`    // "Bezugsfrei ab"; on purchases e.g. "vermietet"
+	Description        string    `json:"description,omitempty"`
+	LandlordName       string    `json:"landlord_name,omitempty"`
+	LandlordCompany    string    `json:"landlord_company,omitempty"`
+	LandlordType       string    `json:"landlord_type,omitempty"`
+	ImageURLs          []string  `json:"image_urls,omitempty"`
+	HasFloorPlan       bool      `json:"has_floor_plan,omitempty"`
+	QualityScore       int       `json:"quality_score"` // 0-100, see filter.Engine.Score
+	ContactFormURL     string    `json:"contact_form_url,omitempty"`
+	SearchProfileID    int64     `json:"search_profile_id"`
+	Contacted          bool      `json:"contacted"`
+	Notified           bool      `json:"notified"`
+	Skipped            bool      `json:"skipped"`              // manually marked seen/handled → excluded from auto-contact
+	FollowedUp         bool      `json:"followed_up"`          // "no reply yet?" reminder already sent
+	Incomplete         bool      `json:"incomplete"`           // no expose or no price/rooms/area yet; held back until a retry completes it
+	Duplicates         int       `json:"duplicates,omitempty"` // further postings of this flat by the same landlord in the search that found it
+	IsProject          bool      `json:"-"`                    // new-build project (whole building), dropped by the filter; not stored
+	IsProjected        bool      `json:"-"`                    // planned building (Bauphase "projektiert"), dropped by the filter; not stored
+	PropertyType       string    `json:"-"`                    // normalized IS24 type ("apartmentrent", "office"); "" = unknown; not stored
+	Silent             bool      `json:"-"`                    // notify without sound, from the profile's Silent; not stored
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// HeatingExtra reports whether heating is known to be paid on top of a
+// TotalRent built from Kaltmiete + Nebenkosten (or estimated from them). A
+// listed Warmmiete already includes it.
+func (l *Listing) HeatingExtra() bool {
+	return l.WarmRent == 0 && l.HeatingIncluded != nil && !*l.HeatingIncluded
+}
+
+// MoveIn returns the move-in date to show and its label. Buyers care whether
+// the flat comes vacant, so purchases prefer "Bezugsfrei ab"; rentals prefer
+// "Verfügbar ab". date is "" when the listing has neither.
+func (l *Listing) MoveIn() (label, date string) {
+	if l.IsPurchase() {
+		if l.VacantFrom != "" {
+			return "Bezugsfrei ab", l.VacantFrom
+		}
+		return "Verfügbar ab", l.AvailableFrom
+	}
+	if l.AvailableFrom != "" {
+		return "Ab", l.AvailableFrom
+	}
+	return "Ab", l.VacantFrom
+}
+
+// HasCoreData reports whether price, rooms and area are all known, the data
+// the filters and notifications can't do without.
+func (l *Listing) HasCoreData() bool {
+	return l.Price > 0 && l.Rooms > 0 && l.Area > 0
+}
+
+// ErrListingGone is returned by expose fetches for a listing IS24 has taken
+// down ("Objekt nicht mehr verfügbar").
+var ErrListingGone = errors.New("listing no longer available")
+
+// SearchResult is the crawl of one search: the listings plus what IS24
+// reported about the whole result list.
+type SearchResult struct {
+	Listings     []Listing
+	TotalHits    int  // hits across all result pages, 0 = unknown
+	PagesCrawled int  // result pages fetched
+	Truncated    bool // the page limit cut the result list short
+}
+
+// IsPurchase reports whether the listing is for sale: by its IS24 type
+// ("apartmentbuy"), or by a Hausgeld, which only purchase exposes carry.
+func (l *Listing) IsPurchase() bool {
+	return strings.HasSuffix(l.PropertyType, "buy") || l.MonthlyFees > 0
+}
+
+// IsPrivateLandlord reports whether the listing is offered privately rather
+// than by an agency. Unknown landlord types are not private.
+func (l *Listing) IsPrivateLandlord() bool {
+	return strings.EqualFold(l.LandlordType, "privat") || strings.EqualFold(l.LandlordType, "private")
+}
+
+// HasCoordinates reports whether the listing's map position is known.
+func (l *Listing) HasCoordinates() bool {
+	return l.Latitude != 0 && l.Longitude != 0
+}
+
+// MapsURL returns a Google Maps link to the listing: at its coordinates when
+// known, otherwise a search for its address. "" when there is nothing to
+// search for.
+func (l *Listing) MapsURL() string {
+	const base = "https://www.google.com/maps/search/?api=1&query="
+	if l.HasCoordinates() {
+		return base + strconv.FormatFloat(l.Latitude, 'f', 6, 64) + "," + strconv.FormatFloat(l.Longitude, 'f', 6, 64)
+	}
+	var query string
+	switch {
+	case l.Address != "" && (l.City == "" || strings.Contains(l.Address, l.City)):
+		query = l.Address
+	case l.Address != "":
+		query = l.Address + ", " + l.City
+	case l.City != "":
+		query = strings.Join(strings.Fields(l.District+" "+l.PostalCode+" "+l.City), " ")
+	default:
+		return ""
+	}
+	return base + url.QueryEscape(query)
 }
 
 // SentMessage tracks contact messages sent to avoid duplicates
@@ -68,12 +261,23 @@ type SentMessage struct {
 	ListingID int64     `json:"listing_id"`
 	IS24ID    string    `json:"is24_id"`
 	Message   string    `json:"message"`
-	Status    string    `json:"status"` // pending, sent, failed, preview
+	Variant   string    `json:"template_variant,omitempty"` // message template file the text came from
+	Status    string    `json:"status"`                     // pending, sent, failed, preview
+	Test      bool      `json:"test,omitempty"`             // recorded by contact.dry_run, nothing was submitted
 	ErrorMsg  string    `json:"error_msg,omitempty"`
 	SentAt    time.Time `json:"sent_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ContactFailure describes a failed contact attempt for notifications: a
+// category, what to do about it and the raw error.
+type ContactFailure struct {
+	Category string // stable key, e.g. "captcha", "cookie_expired"
+	Label    string // human-readable category
+	Hint     string // suggested action; may be empty
+	Detail   string // raw error text
+}
+
 // InboxMessage is an IS24-related email found in the monitored mailbox, with
 // the AI's verdict on whether it is a genuine reply from a provider/landlord
 // who answered by email instead of via the IS24 chat.
@@ -121,15 +325,29 @@ const (
 	MessageStatusSent    = "sent"
 	MessageStatusFailed  = "failed"
 	MessageStatusPreview = "preview"
+	// MessageStatusUnconfirmed: submitted, but IS24 showed no confirmation.
+	MessageStatusUnconfirmed = "unconfirmed"
 )
 
 // ActivityAction constants
 const (
-	ActionSearch           = "search"
-	ActionListingFound     = "listing_found"
-	ActionListingFiltered  = "listing_filtered"
-	ActionNotificationSent = "notification_sent"
-	ActionContactSent      = "contact_sent"
-	ActionContactFailed    = "contact_failed"
-	ActionError            = "error"
+	ActionSearch             = "search"
+	ActionListingFound       = "listing_found"
+	ActionListingFiltered    = "listing_filtered"
+	ActionNotificationSent   = "notification_sent"
+	ActionContactSent        = "contact_sent"
+	ActionContactFailed      = "contact_failed"
+	ActionContactUnconfirmed = "contact_unconfirmed"
+	ActionError              = "error"
+
+	// Listing status changes; Details holds "before → after".
+	ActionPriceChanged      = "price_changed"
+	ActionDelisted          = "delisted"
+	ActionMarkedContacted   = "marked_contacted"
+	ActionMarkedUncontacted = "marked_uncontacted"
+	ActionContactCanceled   = "contact_canceled"
 )
+
+// ErrorDetailCookie marks an ActionError entry raised because searches keep
+// coming back empty, the usual sign of an expired IS24 cookie.
+const ErrorDetailCookie = "cookie_expired"
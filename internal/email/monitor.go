@@ -48,6 +48,7 @@ type Monitor struct {
 	classifier Classifier
 	store      Store
 	notifier   Notifier
+	baseURL    string // IS24 site the expose links point to
 	logger     *slog.Logger
 }
 
@@ -56,7 +57,18 @@ func NewMonitor(client *Client, classifier Classifier, store Store, notifier Not
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Monitor{client: client, classifier: classifier, store: store, notifier: notifier, logger: logger}
+	return &Monitor{client: client, classifier: classifier, store: store, notifier: notifier, baseURL: defaultBaseURL, logger: logger}
+}
+
+// defaultBaseURL is the IS24 site alerts link to unless SetBaseURL changes it.
+const defaultBaseURL = "https://www.immobilienscout24.de"
+
+// SetBaseURL sets the IS24 site the alerts' expose links point to. Empty
+// keeps immobilienscout24.de.
+func (m *Monitor) SetBaseURL(baseURL string) {
+	if baseURL != "" {
+		m.baseURL = strings.TrimRight(baseURL, "/")
+	}
 }
 
 // Poll fetches new IS24 mails since the stored UID watermark, classifies the
@@ -125,7 +137,7 @@ func (m *Monitor) process(ctx context.Context, msg Message) error {
 	// Notify before persisting so the stored row reflects whether the alert went
 	// out. Only genuine landlord replies trigger a push.
 	if cls.IsLandlordReply && m.notifier != nil {
-		if err := m.notifier.SendRawMessage(ctx, formatAlert(rec)); err != nil {
+		if err := m.notifier.SendRawMessage(ctx, formatAlert(rec, m.baseURL)); err != nil {
 			m.logger.Warn("inbox notification failed", "error", err)
 		} else {
 			rec.Notified = true
@@ -154,7 +166,7 @@ func (m *Monitor) loadWatermark(ctx context.Context) uint32 {
 }
 
 // formatAlert renders the Telegram/WhatsApp alert in the shared *bold* markup.
-func formatAlert(r *domain.InboxMessage) string {
+func formatAlert(r *domain.InboxMessage, baseURL string) string {
 	var b strings.Builder
 	b.WriteString("📬 *Neue Anbieter-Nachricht (E-Mail)*\n\n")
 	b.WriteString("*Von:* " + r.FromAddr + "\n")
@@ -165,7 +177,7 @@ func formatAlert(r *domain.InboxMessage) string {
 		b.WriteString("\n" + r.Summary + "\n")
 	}
 	if r.IS24ID != "" {
-		b.WriteString("\n🔗 https://www.immobilienscout24.de/expose/" + r.IS24ID + "\n")
+		b.WriteString("\n🔗 " + baseURL + "/expose/" + r.IS24ID + "\n")
 	}
 	return b.String()
 }
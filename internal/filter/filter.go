@@ -1,38 +1,138 @@
 package filter
 
 import (
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/julianbeese/immo_bot/internal/domain"
 )
 
+// DefaultWarmRentFactor estimates the Warmmiete from the Kaltmiete when a
+// listing states neither the warm rent nor the Nebenkosten.
+const DefaultWarmRentFactor = 1.25
+
 // Engine applies search profile filters to listings
-type Engine struct{}
+type Engine struct {
+	warmRentFactor float64
+	scoreWeights   ScoreWeights
+
+	// Prices below minPlausiblePrice are data errors: dropped or cleared.
+	minPlausiblePrice    int
+	dropImplausiblePrice bool
+
+	// "Preis auf Anfrage" listings are dropped or let through.
+	dropPriceOnRequest bool
+}
 
 // NewEngine creates a new filter engine
 func NewEngine() *Engine {
-	return &Engine{}
+	return &Engine{warmRentFactor: DefaultWarmRentFactor, scoreWeights: DefaultScoreWeights}
+}
+
+// SetWarmRentFactor sets the factor used to estimate the Warmmiete from the
+// Kaltmiete. Non-positive values are ignored.
+func (e *Engine) SetWarmRentFactor(f float64) {
+	if f > 0 {
+		e.warmRentFactor = f
+	}
+}
+
+// SetPriceFloor sets the lowest plausible Kaltmiete (0 = no check). A lower
+// price either drops the listing (drop) or is cleared to unknown, after which
+// the listing is handled like one without a price.
+func (e *Engine) SetPriceFloor(minPrice int, drop bool) {
+	e.minPlausiblePrice = minPrice
+	e.dropImplausiblePrice = drop
+}
+
+// SanitizePrice clears an implausible price when the price floor is set to
+// treat it as unknown, so stored and tracked prices match what Filter judged.
+// Reports whether the price was cleared. Drop mode leaves the price to Filter.
+func (e *Engine) SanitizePrice(l *domain.Listing) bool {
+	if e.dropImplausiblePrice || e.minPlausiblePrice <= 0 || l.PriceOnRequest ||
+		l.Price <= 0 || l.Price >= e.minPlausiblePrice {
+		return false
+	}
+	l.Price = 0
+	return true
+}
+
+// SetDropPriceOnRequest drops "Preis auf Anfrage" listings instead of letting
+// them pass the price filters.
+func (e *Engine) SetDropPriceOnRequest(drop bool) {
+	e.dropPriceOnRequest = drop
+}
+
+// TotalRent returns the monthly rent including Nebenkosten: the stated
+// Warmmiete, else Kaltmiete + Nebenkosten, else Kaltmiete times the warm rent
+// factor. estimated is true for the last case. Returns 0 without price info.
+// When the Nebenkosten exclude heating (Listing.HeatingExtra) the sum is
+// still used as is; notifications point out the extra heating costs.
+func (e *Engine) TotalRent(l *domain.Listing) (total int, estimated bool) {
+	switch {
+	case l.WarmRent > 0:
+		return l.WarmRent, false
+	case l.Price > 0 && l.ServiceCharge > 0:
+		return l.Price + l.ServiceCharge, false
+	case l.Price > 0:
+		return int(math.Round(float64(l.Price) * e.warmRentFactor)), true
+	}
+	return 0, false
 }
 
 // FilterResult contains filtering outcome for a listing
 type FilterResult struct {
 	Passed  bool
-	Reasons []string // Reasons for filtering out
+	Reasons []string // Reasons for filtering out, rendered with Reason.String
+	Details []Reason // The same reasons with expected and actual values
 }
 
 // Filter applies all profile filters to a listing
 func (e *Engine) Filter(listing *domain.Listing, profile *domain.SearchProfile) FilterResult {
+	// A new-build project describes a whole building; its price and room
+	// ranges would only produce misleading reasons.
+	if listing.IsProject {
+		return Rejected(Reason{Code: ReasonNewBuildProject})
+	}
+	// So is a building that is only planned.
+	if listing.IsProjected {
+		return Rejected(Reason{Code: ReasonProjected})
+	}
+
+	// No price on purpose is no data error.
+	if listing.PriceOnRequest {
+		if e.dropPriceOnRequest {
+			return Rejected(Reason{Code: ReasonPriceOnRequest})
+		}
+	} else if e.minPlausiblePrice > 0 && listing.Price < e.minPlausiblePrice {
+		if e.dropImplausiblePrice {
+			return Rejected(Reason{Code: ReasonImplausiblePrice,
+				Expected: minLimit(e.minPlausiblePrice), Actual: strconv.Itoa(listing.Price)})
+		}
+		// Judged like a listing without a price; the caller's listing is
+		// left alone (see SanitizePrice).
+		cleared := *listing
+		cleared.Price = 0
+		listing = &cleared
+	}
+
 	result := FilterResult{Passed: true}
 
 	// Apply all matchers
 	matchers := []Matcher{
+		&PropertyTypeMatcher{Type: profile.PropertyType()},
 		&PriceMatcher{MinPrice: profile.MinPrice, MaxPrice: profile.MaxPrice},
-		&RoomsMatcher{MinRooms: profile.MinRooms, MaxRooms: profile.MaxRooms},
+		&TotalRentMatcher{MaxTotalRent: profile.MaxTotalRent, engine: e},
+		&MonthlyFeesMatcher{MaxMonthlyFees: profile.MaxMonthlyFees},
+		&PurchasePricePerSqmMatcher{MaxPricePerSqm: profile.MaxPurchasePricePerSqm},
+		NewRoomsMatcher(profile),
 		&AreaMatcher{MinArea: profile.MinArea, MaxArea: profile.MaxArea},
 		&LocationMatcher{
-			City:        profile.City,
-			Districts:   profile.Districts,
-			PostalCodes: profile.PostalCodes,
+			City:          profile.City,
+			AllowedCities: profile.AllowedCities,
+			Districts:     profile.Districts,
+			PostalCodes:   profile.PostalCodes,
 		},
 		&AmenitiesMatcher{
 			HasBalcony:  profile.HasBalcony,
@@ -40,14 +140,21 @@ func (e *Engine) Filter(listing *domain.Listing, profile *domain.SearchProfile)
 			HasElevator: profile.HasElevator,
 			PetsAllowed: profile.PetsAllowed,
 		},
+		&RoomFeaturesMatcher{
+			HasGuestToilet:     profile.HasGuestToilet,
+			HasCellar:          profile.HasCellar,
+			HasSeparateKitchen: profile.HasSeparateKitchen,
+		},
 		&BuildYearMatcher{MinYear: profile.MinBuildYear, MaxYear: profile.MaxBuildYear},
 		&KeywordExclusionMatcher{Keywords: profile.ExcludeKeywords},
+		&LandlordExclusionMatcher{Landlords: profile.ExcludeLandlords},
+		&CooperativeMatcher{Exclude: profile.ExcludeCooperatives},
+		&FloorTypeMatcher{Exclude: profile.ExcludeFloorTypes},
 	}
 
 	for _, matcher := range matchers {
-		if reason := matcher.Match(listing); reason != "" {
-			result.Passed = false
-			result.Reasons = append(result.Reasons, reason)
+		if reason := matcher.Match(listing); reason != nil {
+			result.add(*reason)
 		}
 	}
 
@@ -67,7 +174,34 @@ func (e *Engine) FilterListings(listings []domain.Listing, profile *domain.Searc
 
 // Matcher interface for individual filter criteria
 type Matcher interface {
-	Match(listing *domain.Listing) string // Returns empty string if passes, reason if filtered
+	Match(listing *domain.Listing) *Reason // Returns nil if passes, reason if filtered
+}
+
+// PropertyTypeMatcher drops commercial and other non-residential listings
+// (Gewerbe: office, store, …). With Type set (see
+// domain.SearchProfile.PropertyType) the listing must be of exactly that
+// type. Listings of unknown type pass.
+type PropertyTypeMatcher struct {
+	Type string
+}
+
+func (m *PropertyTypeMatcher) Match(l *domain.Listing) *Reason {
+	if l.PropertyType == "" {
+		return nil // No info, let it pass
+	}
+	if m.Type != "" && l.PropertyType != m.Type {
+		return &Reason{Code: ReasonWrongPropertyType, Expected: m.Type, Actual: l.PropertyType}
+	}
+	if !isResidential(l.PropertyType) {
+		return &Reason{Code: ReasonWrongPropertyType, Expected: "apartment or house", Actual: l.PropertyType}
+	}
+	return nil
+}
+
+// isResidential reports whether a normalized IS24 type is a flat or house
+// for rent or sale.
+func isResidential(t string) bool {
+	return strings.HasPrefix(t, "apartment") || strings.HasPrefix(t, "house")
 }
 
 // PriceMatcher filters by price range
@@ -76,36 +210,77 @@ type PriceMatcher struct {
 	MaxPrice int
 }
 
-func (m *PriceMatcher) Match(l *domain.Listing) string {
+func (m *PriceMatcher) Match(l *domain.Listing) *Reason {
 	if l.Price == 0 {
-		return "" // No price info, let it pass
+		return nil // No price info, let it pass
 	}
 	if m.MinPrice > 0 && l.Price < m.MinPrice {
-		return "price_too_low"
+		return tooLow(ReasonPriceTooLow, m.MinPrice, l.Price)
 	}
 	if m.MaxPrice > 0 && l.Price > m.MaxPrice {
-		return "price_too_high"
+		return tooHigh(ReasonPriceTooHigh, m.MaxPrice, l.Price)
+	}
+	return nil
+}
+
+// MonthlyFeesMatcher filters purchase listings by Hausgeld/Wohngeld. Listings
+// without a known amount pass.
+type MonthlyFeesMatcher struct {
+	MaxMonthlyFees int
+}
+
+func (m *MonthlyFeesMatcher) Match(l *domain.Listing) *Reason {
+	if m.MaxMonthlyFees > 0 && l.MonthlyFees > m.MaxMonthlyFees {
+		return tooHigh(ReasonMonthlyFeesTooHigh, m.MaxMonthlyFees, l.MonthlyFees)
+	}
+	return nil
+}
+
+// TotalRentMatcher filters by rent including Nebenkosten (see Engine.TotalRent)
+type TotalRentMatcher struct {
+	MaxTotalRent int
+	engine       *Engine
+}
+
+func (m *TotalRentMatcher) Match(l *domain.Listing) *Reason {
+	if m.MaxTotalRent <= 0 {
+		return nil
+	}
+	total, _ := m.engine.TotalRent(l)
+	if total == 0 {
+		return nil // No price info, let it pass
+	}
+	if total > m.MaxTotalRent {
+		return tooHigh(ReasonTotalRentTooHigh, m.MaxTotalRent, total)
 	}
-	return ""
+	return nil
 }
 
-// RoomsMatcher filters by room count
+// RoomsMatcher filters by room count. Both bounds are inclusive.
 type RoomsMatcher struct {
 	MinRooms float64
 	MaxRooms float64
 }
 
-func (m *RoomsMatcher) Match(l *domain.Listing) string {
+// NewRoomsMatcher builds the matcher from the profile's room bounds, with
+// exclusive bounds resolved to the next half-room step (see
+// domain.SearchProfile.RoomBounds).
+func NewRoomsMatcher(profile *domain.SearchProfile) *RoomsMatcher {
+	minRooms, maxRooms := profile.RoomBounds()
+	return &RoomsMatcher{MinRooms: minRooms, MaxRooms: maxRooms}
+}
+
+func (m *RoomsMatcher) Match(l *domain.Listing) *Reason {
 	if l.Rooms == 0 {
-		return "" // No room info, let it pass
+		return nil // No room info, let it pass
 	}
 	if m.MinRooms > 0 && l.Rooms < m.MinRooms {
-		return "too_few_rooms"
+		return &Reason{Code: ReasonTooFewRooms, Expected: "min " + formatRooms(m.MinRooms), Actual: formatRooms(l.Rooms)}
 	}
 	if m.MaxRooms > 0 && l.Rooms > m.MaxRooms {
-		return "too_many_rooms"
+		return &Reason{Code: ReasonTooManyRooms, Expected: "max " + formatRooms(m.MaxRooms), Actual: formatRooms(l.Rooms)}
 	}
-	return ""
+	return nil
 }
 
 // AreaMatcher filters by living space
@@ -114,31 +289,40 @@ type AreaMatcher struct {
 	MaxArea int
 }
 
-func (m *AreaMatcher) Match(l *domain.Listing) string {
+func (m *AreaMatcher) Match(l *domain.Listing) *Reason {
 	if l.Area == 0 {
-		return "" // No area info, let it pass
+		return nil // No area info, let it pass
 	}
 	if m.MinArea > 0 && l.Area < m.MinArea {
-		return "area_too_small"
+		return tooLow(ReasonAreaTooSmall, m.MinArea, l.Area)
 	}
 	if m.MaxArea > 0 && l.Area > m.MaxArea {
-		return "area_too_large"
+		return tooHigh(ReasonAreaTooLarge, m.MaxArea, l.Area)
 	}
-	return ""
+	return nil
 }
 
-// LocationMatcher filters by city, district, or postal code
+// LocationMatcher filters by city, district, or postal code. A listing may
+// be in City or any of AllowedCities.
 type LocationMatcher struct {
-	City        string
-	Districts   []string
-	PostalCodes []string
+	City          string
+	AllowedCities []string
+	Districts     []string
+	PostalCodes   []string
 }
 
-func (m *LocationMatcher) Match(l *domain.Listing) string {
+func (m *LocationMatcher) Match(l *domain.Listing) *Reason {
 	// City check (if specified and listing has city info)
-	if m.City != "" && l.City != "" {
-		if !strings.EqualFold(l.City, m.City) {
-			return "wrong_city"
+	if (m.City != "" || len(m.AllowedCities) > 0) && l.City != "" {
+		found := strings.EqualFold(l.City, m.City)
+		for _, c := range m.AllowedCities {
+			if strings.EqualFold(l.City, c) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return oneOf(ReasonWrongCity, cities(m.City, m.AllowedCities), l.City)
 		}
 	}
 
@@ -152,7 +336,7 @@ func (m *LocationMatcher) Match(l *domain.Listing) string {
 			}
 		}
 		if !found {
-			return "wrong_district"
+			return oneOf(ReasonWrongDistrict, m.Districts, l.District)
 		}
 	}
 
@@ -167,11 +351,11 @@ func (m *LocationMatcher) Match(l *domain.Listing) string {
 			}
 		}
 		if !found {
-			return "wrong_postal_code"
+			return oneOf(ReasonWrongPostalCode, m.PostalCodes, l.PostalCode)
 		}
 	}
 
-	return ""
+	return nil
 }
 
 // AmenitiesMatcher filters by required amenities
@@ -182,22 +366,43 @@ type AmenitiesMatcher struct {
 	PetsAllowed *bool
 }
 
-func (m *AmenitiesMatcher) Match(l *domain.Listing) string {
+func (m *AmenitiesMatcher) Match(l *domain.Listing) *Reason {
 	if m.HasBalcony != nil && *m.HasBalcony && !l.HasBalcony {
-		return "no_balcony"
+		return required(ReasonNoBalcony)
 	}
 	if m.HasEBK != nil && *m.HasEBK && !l.HasEBK {
-		return "no_ebk"
+		return required(ReasonNoEBK)
 	}
 	if m.HasElevator != nil && *m.HasElevator && !l.HasElevator {
-		return "no_elevator"
+		return required(ReasonNoElevator)
 	}
 	if m.PetsAllowed != nil && *m.PetsAllowed {
 		if l.PetsAllowed != nil && !*l.PetsAllowed {
-			return "no_pets"
+			return &Reason{Code: ReasonNoPets, Expected: "allowed", Actual: "not allowed"}
 		}
 	}
-	return ""
+	return nil
+}
+
+// RoomFeaturesMatcher filters by family-relevant features (Gäste-WC, Keller,
+// separate Küche). Like AmenitiesMatcher, only "required" is enforced.
+type RoomFeaturesMatcher struct {
+	HasGuestToilet     *bool
+	HasCellar          *bool
+	HasSeparateKitchen *bool
+}
+
+func (m *RoomFeaturesMatcher) Match(l *domain.Listing) *Reason {
+	if m.HasGuestToilet != nil && *m.HasGuestToilet && !l.HasGuestToilet {
+		return required(ReasonNoGuestToilet)
+	}
+	if m.HasCellar != nil && *m.HasCellar && !l.HasCellar {
+		return required(ReasonNoCellar)
+	}
+	if m.HasSeparateKitchen != nil && *m.HasSeparateKitchen && !l.HasSeparateKitchen {
+		return required(ReasonNoSeparateKitchen)
+	}
+	return nil
 }
 
 // BuildYearMatcher filters by construction year
@@ -206,17 +411,17 @@ type BuildYearMatcher struct {
 	MaxYear int
 }
 
-func (m *BuildYearMatcher) Match(l *domain.Listing) string {
+func (m *BuildYearMatcher) Match(l *domain.Listing) *Reason {
 	if l.BuildYear == 0 {
-		return "" // No info, let it pass
+		return nil // No info, let it pass
 	}
 	if m.MinYear > 0 && l.BuildYear < m.MinYear {
-		return "building_too_old"
+		return tooLow(ReasonBuildingTooOld, m.MinYear, l.BuildYear)
 	}
 	if m.MaxYear > 0 && l.BuildYear > m.MaxYear {
-		return "building_too_new"
+		return tooHigh(ReasonBuildingTooNew, m.MaxYear, l.BuildYear)
 	}
-	return ""
+	return nil
 }
 
 // KeywordExclusionMatcher filters out listings containing certain keywords
@@ -224,9 +429,9 @@ type KeywordExclusionMatcher struct {
 	Keywords []string
 }
 
-func (m *KeywordExclusionMatcher) Match(l *domain.Listing) string {
+func (m *KeywordExclusionMatcher) Match(l *domain.Listing) *Reason {
 	if len(m.Keywords) == 0 {
-		return ""
+		return nil
 	}
 
 	// Combine title and description for search
@@ -234,34 +439,130 @@ func (m *KeywordExclusionMatcher) Match(l *domain.Listing) string {
 
 	for _, keyword := range m.Keywords {
 		if strings.Contains(text, strings.ToLower(keyword)) {
-			return "excluded_keyword:" + keyword
+			return &Reason{Code: ReasonExcludedKeyword, Detail: keyword}
+		}
+	}
+	return nil
+}
+
+// LandlordExclusionMatcher filters out listings from blocked landlords or
+// agencies (case-insensitive substring of the landlord name or company).
+type LandlordExclusionMatcher struct {
+	Landlords []string
+}
+
+func (m *LandlordExclusionMatcher) Match(l *domain.Listing) *Reason {
+	if len(m.Landlords) == 0 || (l.LandlordName == "" && l.LandlordCompany == "") {
+		return nil
+	}
+
+	name := strings.ToLower(l.LandlordName + " " + l.LandlordCompany)
+	for _, landlord := range m.Landlords {
+		if landlord = strings.TrimSpace(landlord); landlord == "" {
+			continue
+		}
+		if strings.Contains(name, strings.ToLower(landlord)) {
+			return &Reason{Code: ReasonExcludedLandlord, Detail: landlord}
 		}
 	}
-	return ""
+	return nil
 }
 
-// PricePerSqmMatcher filters by price per square meter
+// CooperativeMatcher filters out Genossenschaft listings, which need shares
+// and membership, when the profile excludes them.
+type CooperativeMatcher struct {
+	Exclude bool
+}
+
+func (m *CooperativeMatcher) Match(l *domain.Listing) *Reason {
+	if m.Exclude && l.RequiresMembership {
+		return &Reason{Code: ReasonCooperative, Expected: "excluded", Actual: "Genossenschaft"}
+	}
+	return nil
+}
+
+// floorTypeAliases maps the usual abbreviations to the labels the parser
+// stores in Listing.FloorType.
+var floorTypeAliases = map[string]string{
+	"eg":  "erdgeschoss",
+	"dg":  "dachgeschoss",
+	"hp":  "hochparterre",
+	"ug":  "souterrain",
+	"sou": "souterrain",
+}
+
+// FloorTypeMatcher filters out listings whose floor type (Erdgeschoss,
+// Dachgeschoss, ...) the profile excludes. Listings without a known floor
+// type pass.
+type FloorTypeMatcher struct {
+	Exclude []string
+}
+
+func (m *FloorTypeMatcher) Match(l *domain.Listing) *Reason {
+	if l.FloorType == "" {
+		return nil
+	}
+	floorType := strings.ToLower(l.FloorType)
+	for _, excluded := range m.Exclude {
+		key := strings.ToLower(strings.TrimSpace(excluded))
+		if alias, ok := floorTypeAliases[key]; ok {
+			key = alias
+		}
+		if key != "" && key == floorType {
+			return &Reason{Code: ReasonFloorType, Expected: "excluded", Actual: l.FloorType, Detail: excluded}
+		}
+	}
+	return nil
+}
+
+// PricePerSqmMatcher filters rentals by Kaltmiete per square meter.
+// Purchase listings are left to PurchasePricePerSqmMatcher: a Kaufpreis per
+// m² is no rent.
 type PricePerSqmMatcher struct {
 	MaxPricePerSqm float64
 }
 
-func (m *PricePerSqmMatcher) Match(l *domain.Listing) string {
-	if m.MaxPricePerSqm <= 0 {
-		return ""
+func (m *PricePerSqmMatcher) Match(l *domain.Listing) *Reason {
+	if m.MaxPricePerSqm <= 0 || l.IsPurchase() {
+		return nil
 	}
+	if pricePerSqm := listingPricePerSqm(l); pricePerSqm > m.MaxPricePerSqm {
+		return &Reason{Code: ReasonPricePerSqmTooHigh,
+			Expected: "max " + formatPricePerSqm(m.MaxPricePerSqm), Actual: formatPricePerSqm(pricePerSqm)}
+	}
+	return nil
+}
 
-	// Calculate price per sqm if not provided
-	pricePerSqm := l.PricePerSqm
-	if pricePerSqm == 0 && l.Price > 0 && l.Area > 0 {
-		pricePerSqm = float64(l.Price) / float64(l.Area)
+// PurchasePricePerSqmMatcher filters purchase listings by Kaufpreis per
+// square meter. Rentals pass.
+type PurchasePricePerSqmMatcher struct {
+	MaxPricePerSqm float64
+}
+
+func (m *PurchasePricePerSqmMatcher) Match(l *domain.Listing) *Reason {
+	if m.MaxPricePerSqm <= 0 || !l.IsPurchase() {
+		return nil
+	}
+	if pricePerSqm := listingPricePerSqm(l); pricePerSqm > m.MaxPricePerSqm {
+		return &Reason{Code: ReasonPurchasePricePerSqmTooHigh,
+			Expected: "max " + formatPricePerSqm(m.MaxPricePerSqm), Actual: formatPricePerSqm(pricePerSqm)}
 	}
+	return nil
+}
 
-	if pricePerSqm == 0 {
-		return "" // No info, let it pass
+// listingPricePerSqm returns the listing's price per m², computed from price
+// and area if the parser didn't set it. 0 = unknown, which passes.
+func listingPricePerSqm(l *domain.Listing) float64 {
+	if l.PricePerSqm == 0 && l.Price > 0 && l.Area > 0 {
+		return float64(l.Price) / float64(l.Area)
 	}
+	return l.PricePerSqm
+}
 
-	if pricePerSqm > m.MaxPricePerSqm {
-		return "price_per_sqm_too_high"
+// cities lists the profile's city followed by its allowed cities.
+func cities(city string, allowed []string) []string {
+	if city == "" {
+		return allowed
 	}
-	return ""
+	return append([]string{city}, allowed...)
 }
@@ -0,0 +1,379 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestTotalRent(t *testing.T) {
+	e := NewEngine()
+	tests := []struct {
+		name          string
+		listing       domain.Listing
+		want          int
+		wantEstimated bool
+	}{
+		{"warm rent listed", domain.Listing{Price: 1000, ServiceCharge: 150, WarmRent: 1250}, 1250, false},
+		{"cold plus service charge", domain.Listing{Price: 1000, ServiceCharge: 180}, 1180, false},
+		{"estimated from cold rent", domain.Listing{Price: 1000}, 1250, true},
+		{"no price", domain.Listing{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, estimated := e.TotalRent(&tt.listing)
+			if got != tt.want || estimated != tt.wantEstimated {
+				t.Errorf("TotalRent = %d (estimated %v), want %d (estimated %v)", got, estimated, tt.want, tt.wantEstimated)
+			}
+		})
+	}
+
+	e.SetWarmRentFactor(1.4)
+	if got, _ := e.TotalRent(&domain.Listing{Price: 1000}); got != 1400 {
+		t.Errorf("custom factor: TotalRent = %d, want 1400", got)
+	}
+}
+
+func TestFilterRoomBounds(t *testing.T) {
+	e := NewEngine()
+	tests := []struct {
+		name    string
+		profile domain.SearchProfile
+		pass    []float64
+		fail    []float64
+	}{
+		{"at least 2", domain.SearchProfile{MinRooms: 2}, []float64{2, 2.5, 3}, []float64{1.5}},
+		{"more than 2", domain.SearchProfile{MinRooms: 2, MinRoomsExclusive: true}, []float64{2.5, 3}, []float64{2}},
+		{"at most 3", domain.SearchProfile{MaxRooms: 3}, []float64{2.5, 3}, []float64{3.5}},
+		{"less than 3", domain.SearchProfile{MaxRooms: 3, MaxRoomsExclusive: true}, []float64{2, 2.5}, []float64{3}},
+		{"2 to 3", domain.SearchProfile{MinRooms: 2, MaxRooms: 3}, []float64{2, 2.5, 3}, []float64{1.5, 3.5}},
+		{"between steps", domain.SearchProfile{MinRooms: 2.2, MaxRooms: 2.8}, []float64{2.5}, []float64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, rooms := range tt.pass {
+				if r := e.Filter(&domain.Listing{Rooms: rooms}, &tt.profile); !r.Passed {
+					t.Errorf("%.1f rooms should pass, got %v", rooms, r.Reasons)
+				}
+			}
+			for _, rooms := range tt.fail {
+				if r := e.Filter(&domain.Listing{Rooms: rooms}, &tt.profile); r.Passed {
+					t.Errorf("%.1f rooms should be dropped", rooms)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterPriceFloor(t *testing.T) {
+	profile := &domain.SearchProfile{MinPrice: 500, MaxPrice: 1200}
+
+	e := NewEngine()
+	e.SetPriceFloor(100, true)
+	for _, price := range []int{0, 1, 99} {
+		r := e.Filter(&domain.Listing{Price: price}, profile)
+		if r.Passed || len(r.Reasons) != 1 || r.Reasons[0] != "implausible_price" {
+			t.Errorf("drop: price %d should be dropped as implausible, got %+v", price, r)
+		}
+	}
+	if r := e.Filter(&domain.Listing{Price: 900}, profile); !r.Passed {
+		t.Errorf("drop: plausible price should pass, got %v", r.Reasons)
+	}
+
+	if l := (&domain.Listing{Price: 1}); e.SanitizePrice(l) || l.Price != 1 {
+		t.Errorf("drop: SanitizePrice should leave the price to Filter, got %d", l.Price)
+	}
+
+	e.SetPriceFloor(100, false)
+	l := &domain.Listing{Price: 1}
+	if r := e.Filter(l, profile); !r.Passed || l.Price != 1 {
+		t.Errorf("unknown: price 1 should pass like no price without touching the listing, got %+v (price %d)", r, l.Price)
+	}
+	if !e.SanitizePrice(l) || l.Price != 0 {
+		t.Errorf("unknown: SanitizePrice should clear price 1, got %d", l.Price)
+	}
+	if l := (&domain.Listing{Price: 900}); e.SanitizePrice(l) || l.Price != 900 {
+		t.Errorf("unknown: SanitizePrice should keep a plausible price, got %d", l.Price)
+	}
+
+	e.SetPriceFloor(0, true)
+	if r := e.Filter(&domain.Listing{Price: 0}, profile); !r.Passed {
+		t.Errorf("disabled floor: no price should pass as before, got %v", r.Reasons)
+	}
+}
+
+func TestFilterMaxTotalRent(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{MaxPrice: 1200, MaxTotalRent: 1300}
+
+	if r := e.Filter(&domain.Listing{Price: 1000, ServiceCharge: 250}, profile); !r.Passed {
+		t.Errorf("1250 warm should pass: %v", r.Reasons)
+	}
+	r := e.Filter(&domain.Listing{Price: 1100, WarmRent: 1400}, profile)
+	if r.Passed || len(r.Reasons) != 1 || r.Reasons[0] != "total_rent_too_high" {
+		t.Errorf("1400 warm should be filtered as total_rent_too_high, got %+v", r)
+	}
+	if r := e.Filter(&domain.Listing{Price: 1100}, profile); r.Passed {
+		t.Error("estimated 1375 warm should be filtered")
+	}
+	if r := e.Filter(&domain.Listing{Price: 1100}, &domain.SearchProfile{}); !r.Passed {
+		t.Errorf("no max_total_rent should not filter: %v", r.Reasons)
+	}
+}
+
+func TestScore(t *testing.T) {
+	e := NewEngine()
+	images := make([]string, 12)
+	best := &domain.Listing{
+		Price: 500, Area: 50, ImageURLs: images, HasFloorPlan: true,
+		LandlordType: "privat", Description: strings.Repeat("x", 1000),
+	}
+	if got := e.Score(best, 20); got != 100 {
+		t.Errorf("best listing score = %d, want 100", got)
+	}
+	if got := e.Score(&domain.Listing{Price: 1500, Area: 50, LandlordType: "gewerblich"}, 20); got != 0 {
+		t.Errorf("worst listing score = %d, want 0", got)
+	}
+	// Unknown price and landlord type are neutral: (40*0.5 + 15*0.5) / 100.
+	if got := e.Score(&domain.Listing{}, 0); got != 28 {
+		t.Errorf("empty listing score = %d, want 28", got)
+	}
+
+	e.SetScoreWeights(ScoreWeights{FloorPlan: 1})
+	if got := e.Score(&domain.Listing{HasFloorPlan: true}, 0); got != 100 {
+		t.Errorf("floor plan only weights: score = %d, want 100", got)
+	}
+	e.SetScoreWeights(ScoreWeights{})
+	if got := e.Score(&domain.Listing{HasFloorPlan: true}, 0); got != 100 {
+		t.Errorf("all-zero weights should be ignored, score = %d", got)
+	}
+}
+
+func TestFilterRoomFeatures(t *testing.T) {
+	e := NewEngine()
+	yes := true
+	profile := &domain.SearchProfile{HasGuestToilet: &yes, HasCellar: &yes}
+
+	if r := e.Filter(&domain.Listing{HasGuestToilet: true, HasCellar: true}, profile); !r.Passed {
+		t.Errorf("listing with both features should pass: %v", r.Reasons)
+	}
+	r := e.Filter(&domain.Listing{HasGuestToilet: true}, profile)
+	if r.Passed || r.Reasons[0] != "no_cellar" {
+		t.Errorf("missing cellar should be filtered as no_cellar, got %+v", r)
+	}
+	if r := e.Filter(&domain.Listing{}, &domain.SearchProfile{}); !r.Passed {
+		t.Errorf("unset requirements should not filter: %v", r.Reasons)
+	}
+}
+
+func TestFilterMaxPurchasePricePerSqm(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{MaxPurchasePricePerSqm: 5000}
+
+	buy := &domain.Listing{PropertyType: "apartmentbuy", Price: 420000, Area: 70}
+	if r := e.Filter(buy, profile); r.Passed || r.Reasons[0] != "purchase_price_per_sqm_too_high" {
+		t.Errorf("6000 €/m² purchase should be filtered, got %+v", r)
+	}
+	buy.Area = 90
+	if r := e.Filter(buy, profile); !r.Passed {
+		t.Errorf("4667 €/m² purchase should pass: %v", r.Reasons)
+	}
+	// Rentals are not measured against purchase prices.
+	if r := e.Filter(&domain.Listing{PropertyType: "apartmentrent", Price: 1200, Area: 60}, profile); !r.Passed {
+		t.Errorf("rental should pass: %v", r.Reasons)
+	}
+}
+
+func TestPricePerSqmMatcherSkipsPurchases(t *testing.T) {
+	m := &PricePerSqmMatcher{MaxPricePerSqm: 20}
+	if r := m.Match(&domain.Listing{Price: 1500, Area: 60}); r == nil || r.Code != ReasonPricePerSqmTooHigh {
+		t.Errorf("25 €/m² rent should be filtered, got %+v", r)
+	}
+	if r := m.Match(&domain.Listing{Price: 300000, Area: 60, MonthlyFees: 250}); r != nil {
+		t.Errorf("purchase should be left to the purchase matcher, got %+v", r)
+	}
+}
+
+func TestFilterDropsProjects(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{MaxPrice: 500}
+	result := e.Filter(&domain.Listing{Price: 690, IsProject: true}, profile)
+	if result.Passed || len(result.Reasons) != 1 || result.Reasons[0] != "new_build_project" {
+		t.Errorf("project should be dropped with its own reason only, got %+v", result)
+	}
+}
+
+func TestFilterMaxMonthlyFees(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{MaxMonthlyFees: 300}
+	if r := e.Filter(&domain.Listing{Price: 350000, MonthlyFees: 420}, profile); r.Passed || r.Reasons[0] != "monthly_fees_too_high" {
+		t.Errorf("Hausgeld above the limit should be filtered, got %+v", r)
+	}
+	for _, fees := range []int{300, 0} {
+		if r := e.Filter(&domain.Listing{Price: 350000, MonthlyFees: fees}, profile); !r.Passed {
+			t.Errorf("fees %d should pass: %v", fees, r.Reasons)
+		}
+	}
+}
+
+func TestFilterAllowedCities(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{City: "Berlin", AllowedCities: []string{"Potsdam", "Teltow"}}
+
+	for _, city := range []string{"Berlin", "potsdam", "Teltow", ""} {
+		if r := e.Filter(&domain.Listing{City: city}, profile); !r.Passed {
+			t.Errorf("%q should pass: %v", city, r.Reasons)
+		}
+	}
+	if r := e.Filter(&domain.Listing{City: "Falkensee"}, profile); r.Passed || r.Reasons[0] != "wrong_city" {
+		t.Errorf("city outside the list should be filtered, got %+v", r)
+	}
+
+	// Without a profile city the list alone decides.
+	profile.City = ""
+	if r := e.Filter(&domain.Listing{City: "Berlin"}, profile); r.Passed {
+		t.Error("Berlin is not in the list and should be filtered")
+	}
+}
+
+func TestFilterExcludeLandlords(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{ExcludeLandlords: []string{"Spam Immobilien"}}
+
+	r := e.Filter(&domain.Listing{LandlordName: "SPAM Immobilien GmbH & Co. KG"}, profile)
+	if r.Passed || r.Reasons[0] != "excluded_landlord:Spam Immobilien" {
+		t.Errorf("blocked agency should be filtered, got %+v", r)
+	}
+	if r := e.Filter(&domain.Listing{LandlordName: "Herr Meier", LandlordCompany: "Spam Immobilien AG"}, profile); r.Passed {
+		t.Error("blocked agency should also match on the company")
+	}
+	if r := e.Filter(&domain.Listing{LandlordName: "Frau Müller"}, profile); !r.Passed {
+		t.Errorf("other landlord should pass: %v", r.Reasons)
+	}
+	if r := e.Filter(&domain.Listing{}, profile); !r.Passed {
+		t.Errorf("unknown landlord should pass: %v", r.Reasons)
+	}
+}
+
+func TestFilterExcludeCooperatives(t *testing.T) {
+	e := NewEngine()
+	coop := &domain.Listing{RequiresMembership: true}
+
+	if r := e.Filter(coop, &domain.SearchProfile{}); !r.Passed {
+		t.Errorf("cooperatives should pass unless excluded: %v", r.Reasons)
+	}
+	r := e.Filter(coop, &domain.SearchProfile{ExcludeCooperatives: true})
+	if r.Passed || r.Reasons[0] != "cooperative" {
+		t.Errorf("excluded cooperative should be filtered, got %+v", r)
+	}
+	if r := e.Filter(&domain.Listing{}, &domain.SearchProfile{ExcludeCooperatives: true}); !r.Passed {
+		t.Errorf("other listings should pass: %v", r.Reasons)
+	}
+}
+
+func TestFilterExcludeFloorTypes(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{ExcludeFloorTypes: []string{"EG", "souterrain"}}
+
+	tests := []struct {
+		floorType string
+		passed    bool
+	}{
+		{"Erdgeschoss", false},
+		{"Souterrain", false},
+		{"Dachgeschoss", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		r := e.Filter(&domain.Listing{FloorType: tt.floorType}, profile)
+		if r.Passed != tt.passed {
+			t.Errorf("%q: passed = %v, want %v (%v)", tt.floorType, r.Passed, tt.passed, r.Reasons)
+		}
+	}
+	r := e.Filter(&domain.Listing{FloorType: "Erdgeschoss"}, profile)
+	if len(r.Reasons) != 1 || r.Reasons[0] != "floor_type:EG" {
+		t.Errorf("reasons = %v", r.Reasons)
+	}
+}
+
+func TestFilterReasonDetails(t *testing.T) {
+	e := NewEngine()
+	profile := &domain.SearchProfile{
+		MaxPrice:        1500,
+		MinRooms:        2,
+		City:            "Berlin",
+		AllowedCities:   []string{"Potsdam"},
+		ExcludeKeywords: []string{"WG"},
+	}
+	r := e.Filter(&domain.Listing{Title: "WG-Zimmer", Price: 1700, Rooms: 1.5, City: "Falkensee"}, profile)
+
+	want := []Reason{
+		{Code: ReasonPriceTooHigh, Expected: "max 1500", Actual: "1700"},
+		{Code: ReasonTooFewRooms, Expected: "min 2", Actual: "1.5"},
+		{Code: ReasonWrongCity, Expected: "Berlin, Potsdam", Actual: "Falkensee"},
+		{Code: ReasonExcludedKeyword, Detail: "WG"},
+	}
+	if r.Passed || len(r.Details) != len(want) {
+		t.Fatalf("got %+v, want %d reasons", r, len(want))
+	}
+	for i, w := range want {
+		if r.Details[i] != w {
+			t.Errorf("reason %d = %+v, want %+v", i, r.Details[i], w)
+		}
+		if r.Reasons[i] != w.String() {
+			t.Errorf("rendered reason %d = %q, want %q", i, r.Reasons[i], w.String())
+		}
+	}
+
+	wantText := "price_too_high (max 1500, actual 1700), too_few_rooms (min 2, actual 1.5), " +
+		"wrong_city (Berlin, Potsdam, actual Falkensee), excluded_keyword:WG"
+	if got := r.Explain(); got != wantText {
+		t.Errorf("Explain = %q, want %q", got, wantText)
+	}
+}
+
+func TestFilterPropertyType(t *testing.T) {
+	e := NewEngine()
+	anyType := &domain.SearchProfile{}
+	rentURL := &domain.SearchProfile{SearchURL: "https://www.immobilienscout24.de/Suche/de/berlin/berlin/wohnung-mieten?price=-1500"}
+
+	for _, typ := range []string{"", "apartmentrent", "housebuy"} {
+		if r := e.Filter(&domain.Listing{PropertyType: typ}, anyType); !r.Passed {
+			t.Errorf("%q should pass without a type in the URL: %v", typ, r.Reasons)
+		}
+	}
+	if r := e.Filter(&domain.Listing{PropertyType: "office"}, anyType); r.Passed || r.Reasons[0] != "wrong_property_type" {
+		t.Errorf("office should be filtered, got %+v", r)
+	}
+	if r := e.Filter(&domain.Listing{PropertyType: "apartmentrent"}, rentURL); !r.Passed {
+		t.Errorf("rental flat should pass a wohnung-mieten profile: %v", r.Reasons)
+	}
+	r := e.Filter(&domain.Listing{PropertyType: "houserent"}, rentURL)
+	want := Reason{Code: ReasonWrongPropertyType, Expected: "apartmentrent", Actual: "houserent"}
+	if r.Passed || r.Details[0] != want {
+		t.Errorf("house on a wohnung-mieten profile: got %+v, want %+v", r.Details, want)
+	}
+	if r := e.Filter(&domain.Listing{PropertyType: "apartmentrent", IsProjected: true}, rentURL); r.Passed || r.Reasons[0] != "projected" {
+		t.Errorf("planned building should be filtered, got %+v", r)
+	}
+}
+
+func TestFilterPriceOnRequest(t *testing.T) {
+	e := NewEngine()
+	e.SetPriceFloor(100, true)
+	profile := &domain.SearchProfile{MaxPrice: 1500}
+	onRequest := domain.Listing{PriceOnRequest: true}
+
+	if r := e.Filter(&onRequest, profile); !r.Passed {
+		t.Errorf("price on request is no implausible price: %v", r.Reasons)
+	}
+	if r := e.Filter(&domain.Listing{}, profile); r.Passed || r.Reasons[0] != "implausible_price" {
+		t.Errorf("a missing price is still implausible, got %+v", r)
+	}
+
+	e.SetDropPriceOnRequest(true)
+	if r := e.Filter(&onRequest, profile); r.Passed || r.Reasons[0] != "price_on_request" {
+		t.Errorf("drop: got %+v", r)
+	}
+}
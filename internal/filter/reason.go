@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReasonCode identifies why a listing was filtered out. The values are stable:
+// they are stored with seen listings and shown in logs.
+type ReasonCode string
+
+const (
+	ReasonNewBuildProject            ReasonCode = "new_build_project"
+	ReasonProjected                  ReasonCode = "projected"
+	ReasonWrongPropertyType          ReasonCode = "wrong_property_type"
+	ReasonImplausiblePrice           ReasonCode = "implausible_price"
+	ReasonPriceOnRequest             ReasonCode = "price_on_request"
+	ReasonExposeFetchFailed          ReasonCode = "expose_fetch_failed"
+	ReasonPriceTooLow                ReasonCode = "price_too_low"
+	ReasonPriceTooHigh               ReasonCode = "price_too_high"
+	ReasonMonthlyFeesTooHigh         ReasonCode = "monthly_fees_too_high"
+	ReasonTotalRentTooHigh           ReasonCode = "total_rent_too_high"
+	ReasonTooFewRooms                ReasonCode = "too_few_rooms"
+	ReasonTooManyRooms               ReasonCode = "too_many_rooms"
+	ReasonAreaTooSmall               ReasonCode = "area_too_small"
+	ReasonAreaTooLarge               ReasonCode = "area_too_large"
+	ReasonWrongCity                  ReasonCode = "wrong_city"
+	ReasonWrongDistrict              ReasonCode = "wrong_district"
+	ReasonWrongPostalCode            ReasonCode = "wrong_postal_code"
+	ReasonNoBalcony                  ReasonCode = "no_balcony"
+	ReasonNoEBK                      ReasonCode = "no_ebk"
+	ReasonNoElevator                 ReasonCode = "no_elevator"
+	ReasonNoPets                     ReasonCode = "no_pets"
+	ReasonNoGuestToilet              ReasonCode = "no_guest_toilet"
+	ReasonNoCellar                   ReasonCode = "no_cellar"
+	ReasonNoSeparateKitchen          ReasonCode = "no_separate_kitchen"
+	ReasonBuildingTooOld             ReasonCode = "building_too_old"
+	ReasonBuildingTooNew             ReasonCode = "building_too_new"
+	ReasonExcludedKeyword            ReasonCode = "excluded_keyword"
+	ReasonExcludedLandlord           ReasonCode = "excluded_landlord"
+	ReasonCooperative                ReasonCode = "cooperative"
+	ReasonFloorType                  ReasonCode = "floor_type"
+	ReasonPricePerSqmTooHigh         ReasonCode = "price_per_sqm_too_high"
+	ReasonPurchasePricePerSqmTooHigh ReasonCode = "purchase_price_per_sqm_too_high"
+)
+
+// Reason explains one failed criterion: what the profile expected ("max
+// 1500", "Berlin, Potsdam", "required") and what the listing has. Detail
+// names the matched keyword or landlord.
+type Reason struct {
+	Code     ReasonCode `json:"code"`
+	Expected string     `json:"expected,omitempty"`
+	Actual   string     `json:"actual,omitempty"`
+	Detail   string     `json:"detail,omitempty"`
+}
+
+// String returns the compact form stored with seen listings, e.g.
+// "price_too_high" or "excluded_keyword:WG".
+func (r Reason) String() string {
+	if r.Detail != "" {
+		return string(r.Code) + ":" + r.Detail
+	}
+	return string(r.Code)
+}
+
+// Explain renders the reason with its values for logs and chat messages,
+// e.g. "price_too_high (max 1500, actual 1700)".
+func (r Reason) Explain() string {
+	var parts []string
+	if r.Expected != "" {
+		parts = append(parts, r.Expected)
+	}
+	if r.Actual != "" {
+		parts = append(parts, "actual "+r.Actual)
+	}
+	if len(parts) == 0 {
+		return r.String()
+	}
+	return r.String() + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// Explain renders all reasons of a result, comma-separated.
+func (r FilterResult) Explain() string {
+	parts := make([]string, len(r.Details))
+	for i, d := range r.Details {
+		parts[i] = d.Explain()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Rejected builds a failed result from the given reasons.
+func Rejected(reasons ...Reason) FilterResult {
+	var result FilterResult
+	for _, r := range reasons {
+		result.add(r)
+	}
+	return result
+}
+
+func (r *FilterResult) add(reason Reason) {
+	r.Passed = false
+	r.Details = append(r.Details, reason)
+	r.Reasons = append(r.Reasons, reason.String())
+}
+
+func minLimit(v int) string { return "min " + strconv.Itoa(v) }
+func maxLimit(v int) string { return "max " + strconv.Itoa(v) }
+
+func formatRooms(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+func tooLow(code ReasonCode, limit, actual int) *Reason {
+	return &Reason{Code: code, Expected: minLimit(limit), Actual: strconv.Itoa(actual)}
+}
+
+func tooHigh(code ReasonCode, limit, actual int) *Reason {
+	return &Reason{Code: code, Expected: maxLimit(limit), Actual: strconv.Itoa(actual)}
+}
+
+func required(code ReasonCode) *Reason {
+	return &Reason{Code: code, Expected: "required", Actual: "missing"}
+}
+
+func oneOf(code ReasonCode, expected []string, actual string) *Reason {
+	return &Reason{Code: code, Expected: strings.Join(expected, ", "), Actual: actual}
+}
+
+func formatPricePerSqm(v float64) string { return fmt.Sprintf("%.2f", v) }
@@ -0,0 +1,83 @@
+package filter
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// ScoreWeights weights the signals of the listing quality score. Only the
+// ratios matter; a zero weight drops the signal.
+type ScoreWeights struct {
+	PricePerSqm float64 // cheaper per m² than the profile average
+	Images      float64 // number of photos
+	FloorPlan   float64 // floor plan attached
+	Private     float64 // private landlord instead of an agent
+	Description float64 // length of the description
+}
+
+// DefaultScoreWeights is used until SetScoreWeights is called.
+var DefaultScoreWeights = ScoreWeights{
+	PricePerSqm: 40,
+	Images:      20,
+	FloorPlan:   10,
+	Private:     15,
+	Description: 15,
+}
+
+const (
+	scoreFullImages      = 10  // photos for a full image signal
+	scoreFullDescription = 800 // description characters for a full signal
+)
+
+// SetScoreWeights sets the quality score weights. All-zero weights are ignored.
+func (e *Engine) SetScoreWeights(w ScoreWeights) {
+	if w.PricePerSqm+w.Images+w.FloorPlan+w.Private+w.Description > 0 {
+		e.scoreWeights = w
+	}
+}
+
+// Score rates a listing 0–100 from the signals in ScoreWeights. avgPricePerSqm
+// is the profile's average Kaltmiete per m²; a listing at the average gets
+// half the price signal, 50% below gets all of it. Unknown price or landlord
+// type count as neutral.
+func (e *Engine) Score(l *domain.Listing, avgPricePerSqm float64) int {
+	w := e.scoreWeights
+	total := w.PricePerSqm + w.Images + w.FloorPlan + w.Private + w.Description
+	if total <= 0 {
+		return 0
+	}
+
+	price := 0.5
+	if l.Price > 0 && l.Area > 0 && avgPricePerSqm > 0 {
+		perSqm := float64(l.Price) / float64(l.Area)
+		price = clamp01(0.5 + (avgPricePerSqm-perSqm)/avgPricePerSqm)
+	}
+
+	private := 0.5
+	switch strings.ToLower(l.LandlordType) {
+	case "":
+	case "privat", "private":
+		private = 1
+	default:
+		private = 0
+	}
+
+	floorPlan := 0.0
+	if l.HasFloorPlan {
+		floorPlan = 1
+	}
+
+	sum := w.PricePerSqm*price +
+		w.Images*clamp01(float64(len(l.ImageURLs))/scoreFullImages) +
+		w.FloorPlan*floorPlan +
+		w.Private*private +
+		w.Description*clamp01(float64(utf8.RuneCountInString(l.Description))/scoreFullDescription)
+	return int(math.Round(sum / total * 100))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,60 @@
+package messenger
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+//go:embed district_info.yaml
+var defaultDistrictInfo []byte
+
+// DistrictInfo holds a short blurb per district (city → district → text) that
+// gives the AI something concrete to say about the location.
+type DistrictInfo map[string]map[string]string
+
+// LoadDistrictInfo reads district blurbs from a YAML file, or the built-in
+// ones when path is empty.
+func LoadDistrictInfo(path string) (DistrictInfo, error) {
+	data := defaultDistrictInfo
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("read district info %q: %w", path, err)
+		}
+	}
+	var raw map[string]map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse district info: %w", err)
+	}
+	info := make(DistrictInfo, len(raw))
+	for city, districts := range raw {
+		m := make(map[string]string, len(districts))
+		for district, text := range districts {
+			m[normalizePlace(district)] = strings.TrimSpace(text)
+		}
+		info[normalizePlace(city)] = m
+	}
+	return info, nil
+}
+
+// placeSuffixRe strips IS24's parenthesized sub-areas ("Schwabing (Schwabing)").
+var placeSuffixRe = regexp.MustCompile(`\s*\(.*\)\s*$`)
+
+func normalizePlace(s string) string {
+	return strings.ToLower(strings.TrimSpace(placeSuffixRe.ReplaceAllString(s, "")))
+}
+
+// Lookup returns the blurb for the listing's district, "" when unknown.
+func (d DistrictInfo) Lookup(l *domain.Listing) string {
+	if l.District == "" {
+		return ""
+	}
+	return d[normalizePlace(l.City)][normalizePlace(l.District)]
+}
@@ -0,0 +1,51 @@
+package messenger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestDistrictInfoLookup(t *testing.T) {
+	info, err := LoadDistrictInfo("")
+	if err != nil {
+		t.Fatalf("built-in district info: %v", err)
+	}
+	if got := info.Lookup(&domain.Listing{City: "Berlin", District: "Prenzlauer Berg (Prenzlauer Berg)"}); got == "" {
+		t.Error("Prenzlauer Berg should have a blurb")
+	}
+	if got := info.Lookup(&domain.Listing{City: "München", District: "Mitte"}); got != "" {
+		t.Errorf("Mitte is a Berlin district, got %q for München", got)
+	}
+	if got := info.Lookup(&domain.Listing{City: "Berlin"}); got != "" {
+		t.Errorf("no district: got %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "districts.yaml")
+	if err := os.WriteFile(path, []byte("Köln:\n  Ehrenfeld: \"Bunt und lebendig.\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	own, err := LoadDistrictInfo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := own.Lookup(&domain.Listing{City: "köln", District: "ehrenfeld"}); got != "Bunt und lebendig." {
+		t.Errorf("own file lookup = %q", got)
+	}
+}
+
+func TestBuildPromptDistrictInfo(t *testing.T) {
+	e := NewOpenAIEnhancer("key", "model", true)
+	l := &domain.Listing{Title: "Altbau", City: "Berlin", District: "Kreuzberg"}
+	if strings.Contains(e.buildPrompt(l, LanguageGerman), "Über das Viertel") {
+		t.Error("district info is off by default")
+	}
+
+	e.SetDistrictInfo(DistrictInfo{"berlin": {"kreuzberg": "Am Landwehrkanal."}})
+	if p := e.buildPrompt(l, LanguageGerman); !strings.Contains(p, "- Über das Viertel: Am Landwehrkanal.") {
+		t.Errorf("prompt lacks district blurb:\n%s", p)
+	}
+}
@@ -0,0 +1,86 @@
+package messenger
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// Message languages. German is the default; other languages are used only
+// when a campaign has a template for them.
+const (
+	LanguageGerman  = "de"
+	LanguageEnglish = "en"
+)
+
+// languageWords are frequent words that tell the languages apart. Words both
+// languages use ("in", "top", "loft") are left out.
+var languageWords = map[string]map[string]bool{
+	LanguageGerman: setOf("und", "die", "der", "das", "mit", "ist", "ein", "eine", "für", "nicht",
+		"wohnung", "zimmer", "küche", "bad", "sehr", "auch", "zur", "im", "sie", "wir"),
+	LanguageEnglish: setOf("the", "and", "with", "is", "a", "for", "of", "to", "this", "are",
+		"apartment", "flat", "room", "rooms", "bedroom", "kitchen", "bathroom", "located", "you", "we"),
+}
+
+// minLanguageWords is how many English words a listing needs before it is
+// treated as English; short or empty texts stay German.
+const minLanguageWords = 3
+
+func setOf(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+// DetectLanguage guesses the language a listing was posted in from its title
+// and description by counting common words. Anything not clearly English is
+// German.
+func DetectLanguage(l *domain.Listing) string {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(l.Title+" "+l.Description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		for lang, set := range languageWords {
+			if set[w] {
+				counts[lang]++
+			}
+		}
+	}
+	if counts[LanguageEnglish] >= minLanguageWords && counts[LanguageEnglish] > counts[LanguageGerman] {
+		return LanguageEnglish
+	}
+	return LanguageGerman
+}
+
+// languageNames are the languages as named in the (German) AI prompts.
+var languageNames = map[string]string{
+	LanguageGerman:  "Deutsch",
+	LanguageEnglish: "Englisch",
+}
+
+// IsSupportedLanguage reports whether lang can be detected and written.
+func IsSupportedLanguage(lang string) bool {
+	_, ok := languageNames[lang]
+	return ok
+}
+
+type languageKey struct{}
+
+// WithLanguage returns a context telling the enhancer which language the
+// message is written in.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// languageFrom returns the language set by WithLanguage, German if none.
+func languageFrom(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return LanguageGerman
+}
@@ -0,0 +1,72 @@
+package messenger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		l    domain.Listing
+		want string
+	}{
+		{"german", domain.Listing{Title: "Helle 2-Zimmer-Wohnung mit Balkon", Description: "Die Wohnung ist frisch renoviert und hat eine neue Küche."}, LanguageGerman},
+		{"english", domain.Listing{Title: "Bright furnished apartment", Description: "This flat is located close to the park and comes with a modern kitchen."}, LanguageEnglish},
+		{"mixed mostly german", domain.Listing{Title: "Loft in Schwabing", Description: "Die Wohnung ist ideal für Expats, the kitchen is new. Das Bad ist mit Wanne und die Küche ist neu."}, LanguageGerman},
+		{"too short", domain.Listing{Title: "Apartment for rent"}, LanguageGerman},
+		{"empty", domain.Listing{}, LanguageGerman},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(&tt.l); got != tt.want {
+			t.Errorf("%s: DetectLanguage = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratorLanguageTemplates(t *testing.T) {
+	de, err := NewGeneratorFromText("Hallo {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	english := &domain.Listing{Title: "Nice flat", Description: "The apartment is located in the heart of the city and has a balcony."}
+
+	if got := de.Language(english); got != LanguageGerman {
+		t.Errorf("without English template: Language = %q, want de", got)
+	}
+
+	en, err := NewGeneratorFromText("Hello {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := de.SetLanguage(LanguageEnglish, en); err != nil {
+		t.Fatal(err)
+	}
+	if err := de.SetLanguage("fr", en); err == nil {
+		t.Error("unsupported language should be an error")
+	}
+
+	if msg, err := de.Generate(english); err != nil || msg != "Hello Nice flat" {
+		t.Errorf("English listing: got %q, %v", msg, err)
+	}
+	if msg, err := de.Generate(&domain.Listing{Title: "Schöne Wohnung"}); err != nil || msg != "Hallo Schöne Wohnung" {
+		t.Errorf("German listing: got %q, %v", msg, err)
+	}
+}
+
+func TestEnhancerFallbackLanguage(t *testing.T) {
+	e := NewOpenAIEnhancer("", "", false)
+	l := &domain.Listing{HasBalcony: true}
+
+	out, err := e.Enhance(WithLanguage(context.Background(), LanguageEnglish), "{{.PersonalizedDetails}}", l, "")
+	if err != nil || !strings.Contains(out, "the balcony") {
+		t.Errorf("English fallback = %q, %v", out, err)
+	}
+	out, err = e.Enhance(context.Background(), "{{.PersonalizedDetails}}", l, "")
+	if err != nil || !strings.Contains(out, "der Balkon") {
+		t.Errorf("German fallback = %q, %v", out, err)
+	}
+}
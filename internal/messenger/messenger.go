@@ -3,14 +3,33 @@ package messenger
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"text/template"
 
 	"github.com/julianbeese/immo_bot/internal/domain"
 )
 
-// Generator creates contact messages from templates
+// Generator creates contact messages from templates. With several template
+// variants it picks one per message (round-robin, or random) so landlords
+// don't all receive byte-identical text.
 type Generator struct {
+	variants []variant
+	random   bool
+	next     atomic.Uint64
+	// languages holds generators for listings posted in another language;
+	// the variants above are German.
+	languages map[string]*Generator
+}
+
+// variant is one parsed template; name identifies it in sent_messages.
+type variant struct {
+	name     string
 	template *template.Template
 }
 
@@ -27,6 +46,7 @@ type TemplateData struct {
 	Area                int
 	Description         string
 	LandlordName        string
+	LandlordCompany     string
 	PersonalizedDetails string // Filled by OpenAI enhancer
 }
 
@@ -40,7 +60,50 @@ func NewGenerator(templatePath, _, _, _ string) (*Generator, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read message template %q: %w", templatePath, err)
 	}
-	return NewGeneratorFromText(string(content))
+	g, err := NewGeneratorFromText(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("message template %q: %w", templatePath, err)
+	}
+	g.variants[0].name = filepath.Base(templatePath)
+	return g, nil
+}
+
+// NewGeneratorFromDir creates a generator rotating over every *.txt template
+// in dir (sorted by file name). random picks a variant at random per message
+// instead of round-robin.
+func NewGeneratorFromDir(dir string, random bool) (*Generator, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.txt message templates in %q", dir)
+	}
+	sort.Strings(paths)
+
+	g := &Generator{random: random}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read message template %q: %w", path, err)
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			continue
+		}
+		name := filepath.Base(path)
+		tmpl, err := template.New(name).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("parse message template %q: %w", path, err)
+		}
+		if err := checkTemplate(tmpl); err != nil {
+			return nil, fmt.Errorf("message template %q: %w", path, err)
+		}
+		g.variants = append(g.variants, variant{name: name, template: tmpl})
+	}
+	if len(g.variants) == 0 {
+		return nil, fmt.Errorf("all message templates in %q are empty", dir)
+	}
+	return g, nil
 }
 
 // NewGeneratorFromText creates a message generator from raw template text.
@@ -53,7 +116,78 @@ func NewGeneratorFromText(text string) (*Generator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Generator{template: tmpl}, nil
+	if err := checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return &Generator{variants: []variant{{template: tmpl}}}, nil
+}
+
+// ValidateTemplate reports whether text is a usable message template: it
+// must parse and only reference TemplateData fields. Empty text is valid
+// (the built-in default is used).
+func ValidateTemplate(text string) error {
+	_, err := NewGeneratorFromText(text)
+	return err
+}
+
+// sampleTemplateData has every field set so a trial run takes the {{if}}
+// branches real listings usually take.
+var sampleTemplateData = TemplateData{
+	Title:               "3-Zimmer-Wohnung",
+	Address:             "Musterstraße 1",
+	City:                "Berlin",
+	District:            "Mitte",
+	PostalCode:          "10115",
+	Price:               1200,
+	Rooms:               3,
+	Area:                80,
+	Description:         "Helle Wohnung",
+	LandlordName:        "Max Mustermann",
+	LandlordCompany:     "Muster GmbH",
+	PersonalizedDetails: "Details",
+}
+
+// checkTemplate executes tmpl against sample data. Parsing accepts any field
+// name; only execution notices a typo like {{.Distrct}}, which would
+// otherwise fail every single message at send time.
+func checkTemplate(tmpl *template.Template) error {
+	if err := tmpl.Execute(io.Discard, sampleTemplateData); err != nil {
+		return fmt.Errorf("invalid template field: %w", err)
+	}
+	return nil
+}
+
+// Variants returns the template variant names in rotation order.
+func (g *Generator) Variants() []string {
+	names := make([]string, len(g.variants))
+	for i, v := range g.variants {
+		names[i] = v.name
+	}
+	return names
+}
+
+// SetLanguage makes listings detected as lang use other's templates.
+func (g *Generator) SetLanguage(lang string, other *Generator) error {
+	if !IsSupportedLanguage(lang) || lang == LanguageGerman {
+		return fmt.Errorf("unsupported message language %q", lang)
+	}
+	if g.languages == nil {
+		g.languages = make(map[string]*Generator)
+	}
+	g.languages[lang] = other
+	return nil
+}
+
+// Language returns the language the message for listing is written in: the
+// detected one when there is a template for it, German otherwise.
+func (g *Generator) Language(listing *domain.Listing) string {
+	if len(g.languages) == 0 {
+		return LanguageGerman
+	}
+	if lang := DetectLanguage(listing); g.languages[lang] != nil {
+		return lang
+	}
+	return LanguageGerman
 }
 
 // DefaultTemplate returns the built-in fallback message template text. The
@@ -62,6 +196,17 @@ func DefaultTemplate() string { return defaultTemplate }
 
 // Generate creates a message for a listing (without personalization)
 func (g *Generator) Generate(listing *domain.Listing) (string, error) {
+	message, _, err := g.GenerateVariant(listing)
+	return message, err
+}
+
+// GenerateVariant is Generate that also returns the name of the template
+// variant used (empty for templates given as text).
+func (g *Generator) GenerateVariant(listing *domain.Listing) (message, variantName string, err error) {
+	if lang := g.Language(listing); lang != LanguageGerman {
+		return g.languages[lang].GenerateVariant(listing)
+	}
+	v := g.pick()
 	data := TemplateData{
 		Title:               listing.Title,
 		Address:             listing.Address,
@@ -73,15 +218,26 @@ func (g *Generator) Generate(listing *domain.Listing) (string, error) {
 		Area:                listing.Area,
 		Description:         listing.Description,
 		LandlordName:        listing.LandlordName,
+		LandlordCompany:     listing.LandlordCompany,
 		PersonalizedDetails: "{{.PersonalizedDetails}}", // Placeholder for enhancer
 	}
 
 	var buf bytes.Buffer
-	if err := g.template.Execute(&buf, data); err != nil {
-		return "", err
+	if err := v.template.Execute(&buf, data); err != nil {
+		return "", v.name, err
 	}
 
-	return buf.String(), nil
+	return buf.String(), v.name, nil
+}
+
+func (g *Generator) pick() variant {
+	if len(g.variants) == 1 {
+		return g.variants[0]
+	}
+	if g.random {
+		return g.variants[rand.IntN(len(g.variants))]
+	}
+	return g.variants[(g.next.Add(1)-1)%uint64(len(g.variants))]
 }
 
 const defaultTemplate = `Sehr geehrte Damen und Herren,
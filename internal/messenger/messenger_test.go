@@ -1,8 +1,15 @@
 package messenger
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/julianbeese/immo_bot/internal/domain"
 )
@@ -50,3 +57,154 @@ func TestNewGeneratorFromTextInvalid(t *testing.T) {
 		t.Error("invalid template should return an error")
 	}
 }
+
+func TestNewGeneratorUnknownField(t *testing.T) {
+	for _, text := range []string{
+		"Wohnung in {{.Distrct}}",
+		"{{if .District}}{{.District}}{{else}}{{.City}}{{end}} {{.PersonalDetails}}",
+	} {
+		_, err := NewGeneratorFromText(text)
+		if err == nil || !strings.Contains(err.Error(), "can't evaluate field") {
+			t.Errorf("%q: err = %v, want unknown field error", text, err)
+		}
+	}
+	if err := ValidateTemplate("{{if .District}}{{.District}}{{else}}{{.City}}{{end}}\n\n{{.PersonalizedDetails}}"); err != nil {
+		t.Errorf("valid template rejected: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "typo.txt")
+	if err := os.WriteFile(path, []byte("{{.Preis}} €"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGenerator(path, "", "", ""); err == nil || !strings.Contains(err.Error(), "typo.txt") {
+		t.Errorf("file template error should name the file, got %v", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestOpenAIEnhancerSetParams(t *testing.T) {
+	e := NewOpenAIEnhancer("sk-test", "gpt-4o-mini", true)
+	e.SetParams(90*time.Second, 600, 0.2)
+	if e.client.Timeout != 90*time.Second {
+		t.Errorf("timeout = %v, want 90s", e.client.Timeout)
+	}
+
+	var sent openAIRequest
+	e.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Fatal(err)
+		}
+		body := `{"choices":[{"message":{"role":"assistant","content":"Details"}}]}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+
+	out, err := e.Enhance(context.Background(), "Hallo {{.PersonalizedDetails}}", &domain.Listing{Title: "Whg"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Hallo Details" {
+		t.Errorf("Enhance = %q", out)
+	}
+	if sent.MaxTokens != 600 || sent.Temperature != 0.2 {
+		t.Errorf("request max_tokens=%d temperature=%v, want 600/0.2", sent.MaxTokens, sent.Temperature)
+	}
+
+	// Invalid values keep the current ones.
+	e.SetParams(0, 0, -1)
+	if e.client.Timeout != 90*time.Second || e.maxTokens != 600 || e.temperature != 0.2 {
+		t.Errorf("SetParams with zero values changed params: %v %d %v", e.client.Timeout, e.maxTokens, e.temperature)
+	}
+}
+
+func TestOpenAIEnhancerStructuredOutput(t *testing.T) {
+	e := NewOpenAIEnhancer("sk-test", "gpt-4o-mini", true)
+	e.SetStructuredOutput(true)
+
+	var sent []openAIRequest
+	answers := []string{
+		`{"opening_sentence":"Die Bilder haben uns sofort angesprochen","highlights":["den großen Balkon"," die hellen Räume.","das Parkett","den Keller"]}`,
+	}
+	e.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, req)
+		content, _ := json.Marshal(answers[len(sent)-1])
+		body := `{"choices":[{"message":{"role":"assistant","content":` + string(content) + `}}]}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+
+	out, err := e.Enhance(context.Background(), "{{.PersonalizedDetails}}", &domain.Listing{Title: "Whg"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Die Bilder haben uns sofort angesprochen. Besonders schön finden wir den großen Balkon, die hellen Räume und das Parkett."
+	if out != want {
+		t.Errorf("Enhance = %q, want %q", out, want)
+	}
+	if len(sent) != 1 || sent[0].ResponseFormat == nil || sent[0].ResponseFormat.Type != "json_object" {
+		t.Fatalf("requests = %+v, want one json_object request", sent)
+	}
+
+	// Unusable JSON falls back to a free-text request.
+	sent = nil
+	answers = []string{`{"foo":1}`, "Freitext."}
+	out, err = e.Enhance(context.Background(), "{{.PersonalizedDetails}}", &domain.Listing{Title: "Whg"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Freitext." || len(sent) != 2 || sent[1].ResponseFormat != nil {
+		t.Errorf("fallback: out %q, %d requests", out, len(sent))
+	}
+}
+
+func TestAssembleDetailsEnglish(t *testing.T) {
+	got := assembleDetails(`{"highlights":["the balcony","the bright rooms"]}`, LanguageEnglish)
+	if got != "We especially like the balcony and the bright rooms." {
+		t.Errorf("assembleDetails = %q", got)
+	}
+	if got := assembleDetails("kein json", LanguageGerman); got != "" {
+		t.Errorf("invalid JSON = %q, want empty", got)
+	}
+}
+
+func TestNewGeneratorFromDirRotates(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"a.txt":     "Variante A: {{.Title}}",
+		"b.txt":     "Variante B: {{.Title}}",
+		"empty.txt": "  \n",
+		"notes.md":  "ignored",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := NewGeneratorFromDir(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Variants(); len(got) != 2 || got[0] != "a.txt" || got[1] != "b.txt" {
+		t.Fatalf("Variants = %v, want [a.txt b.txt]", got)
+	}
+
+	l := &domain.Listing{Title: "Whg"}
+	for _, want := range []string{"a.txt", "b.txt", "a.txt"} {
+		out, variant, err := g.GenerateVariant(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if variant != want || !strings.HasSuffix(out, ": Whg") {
+			t.Errorf("GenerateVariant = %q (%s), want variant %s", out, variant, want)
+		}
+	}
+
+	if _, err := NewGeneratorFromDir(t.TempDir(), false); err == nil {
+		t.Error("empty directory should be an error")
+	}
+}
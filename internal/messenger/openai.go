@@ -15,12 +15,27 @@ import (
 
 const openAIAPIURL = "https://api.openai.com/v1/chat/completions"
 
+// Default request parameters for message personalization.
+const (
+	DefaultOpenAITimeout     = 30 * time.Second
+	DefaultOpenAIMaxTokens   = 150
+	DefaultOpenAITemperature = 0.7
+
+	DefaultOpenAIMaxConcurrent = 2
+	DefaultOpenAIMinInterval   = time.Second
+)
+
 // OpenAIEnhancer uses GPT to personalize messages
 type OpenAIEnhancer struct {
-	apiKey  string
-	model   string
-	enabled bool
-	client  *http.Client
+	apiKey      string
+	model       string
+	enabled     bool
+	client      *http.Client
+	maxTokens   int
+	temperature float64
+	districts   DistrictInfo // nil = no district blurbs in the prompt
+	structured  bool         // request JSON fields instead of free text
+	limiter     *callLimiter
 }
 
 // NewOpenAIEnhancer creates a new OpenAI message enhancer
@@ -30,24 +45,63 @@ func NewOpenAIEnhancer(apiKey, model string, enabled bool) *OpenAIEnhancer {
 		model:   model,
 		enabled: enabled,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultOpenAITimeout,
 		},
+		maxTokens:   DefaultOpenAIMaxTokens,
+		temperature: DefaultOpenAITemperature,
+		limiter:     newCallLimiter(DefaultOpenAIMaxConcurrent, DefaultOpenAIMinInterval),
+	}
+}
+
+// SetParams overrides the request timeout, token limit and sampling
+// temperature. Non-positive timeout/maxTokens and a negative temperature keep
+// the current value.
+func (e *OpenAIEnhancer) SetParams(timeout time.Duration, maxTokens int, temperature float64) {
+	if timeout > 0 {
+		e.client.Timeout = timeout
+	}
+	if maxTokens > 0 {
+		e.maxTokens = maxTokens
+	}
+	if temperature >= 0 {
+		e.temperature = temperature
 	}
 }
 
+// SetRateLimit caps the requests in flight and spaces their starts by
+// minInterval, so a batch of previews doesn't trip OpenAI's rate limits.
+// 0 turns the respective limit off.
+func (e *OpenAIEnhancer) SetRateLimit(maxConcurrent int, minInterval time.Duration) {
+	e.limiter = newCallLimiter(maxConcurrent, minInterval)
+}
+
+// SetDistrictInfo adds the listing district's blurb to the prompt, so the AI
+// can mention the location concretely. nil turns it off.
+func (e *OpenAIEnhancer) SetDistrictInfo(d DistrictInfo) {
+	e.districts = d
+}
+
+// SetStructuredOutput makes the enhancer request JSON (opening sentence plus
+// highlights) and assemble the sentences itself, so the model has no room
+// for greetings or closings. Free text remains the fallback when the JSON
+// is unusable.
+func (e *OpenAIEnhancer) SetStructuredOutput(on bool) {
+	e.structured = on
+}
+
 // Enhance personalizes a message based on listing details. campaignPrompt
 // overrides the default system prompt (empty → built-in default).
 func (e *OpenAIEnhancer) Enhance(ctx context.Context, message string, listing *domain.Listing, campaignPrompt string) (string, error) {
 	if !e.enabled || e.apiKey == "" {
 		// Fallback: use generic details
-		return e.fallbackEnhance(message, listing), nil
+		return e.fallbackEnhance(message, listing, languageFrom(ctx)), nil
 	}
 
 	// Generate personalized details using GPT
 	personalizedDetails, err := e.generatePersonalizedDetails(ctx, listing, campaignPrompt)
 	if err != nil {
 		// Fallback on error
-		return e.fallbackEnhance(message, listing), nil
+		return e.fallbackEnhance(message, listing, languageFrom(ctx)), nil
 	}
 
 	// Replace placeholder in message
@@ -56,13 +110,31 @@ func (e *OpenAIEnhancer) Enhance(ctx context.Context, message string, listing *d
 }
 
 func (e *OpenAIEnhancer) generatePersonalizedDetails(ctx context.Context, listing *domain.Listing, campaignPrompt string) (string, error) {
-	prompt := e.buildPrompt(listing)
+	lang := languageFrom(ctx)
+	prompt := e.buildPrompt(listing, lang)
 
 	sysPrompt := systemPrompt
 	if campaignPrompt != "" {
 		sysPrompt = campaignPrompt
 	}
+	if lang != LanguageGerman {
+		sysPrompt += fmt.Sprintf("\n\nSchreibe die Sätze auf %s.", languageNames[lang])
+	}
+
+	if e.structured {
+		content, err := e.complete(ctx, sysPrompt, prompt+structuredPrompt, &responseFormat{Type: "json_object"})
+		if err == nil {
+			if details := assembleDetails(content, lang); details != "" {
+				return details, nil
+			}
+		}
+		// Unusable JSON: ask again for free text.
+	}
+	return e.complete(ctx, sysPrompt, prompt, nil)
+}
 
+// complete sends one chat request and returns the trimmed answer.
+func (e *OpenAIEnhancer) complete(ctx context.Context, sysPrompt, prompt string, format *responseFormat) (string, error) {
 	request := openAIRequest{
 		Model: e.model,
 		Messages: []openAIMessage{
@@ -75,8 +147,9 @@ func (e *OpenAIEnhancer) generatePersonalizedDetails(ctx context.Context, listin
 				Content: prompt,
 			},
 		},
-		MaxTokens:   150,
-		Temperature: 0.7,
+		MaxTokens:      e.maxTokens,
+		Temperature:    e.temperature,
+		ResponseFormat: format,
 	}
 
 	body, err := json.Marshal(request)
@@ -92,6 +165,12 @@ func (e *OpenAIEnhancer) generatePersonalizedDetails(ctx context.Context, listin
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+e.apiKey)
 
+	release, err := e.limiter.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	resp, err := e.client.Do(req)
 	if err != nil {
 		return "", err
@@ -115,7 +194,60 @@ func (e *OpenAIEnhancer) generatePersonalizedDetails(ctx context.Context, listin
 	return strings.TrimSpace(response.Choices[0].Message.Content), nil
 }
 
-func (e *OpenAIEnhancer) buildPrompt(listing *domain.Listing) string {
+// structuredPrompt replaces the free-text answer format for structured
+// output.
+const structuredPrompt = `
+Antworte stattdessen als JSON-Objekt mit genau diesen Feldern:
+{"opening_sentence": "ein Satz, was uns an der Wohnung angesprochen hat", "highlights": ["2-3 konkrete Aspekte als kurze Stichworte im Akkusativ, z.B. \"den großen Balkon\", \"die hellen Räume\""]}
+Keine Anrede, kein Gruß, nichts außerhalb des JSON.
+`
+
+// structuredDetails is the JSON the model returns with structured output.
+type structuredDetails struct {
+	OpeningSentence string   `json:"opening_sentence"`
+	Highlights      []string `json:"highlights"`
+}
+
+// maxHighlights caps how many highlights go into the message.
+const maxHighlights = 3
+
+// assembleDetails builds the personalized sentences from the model's JSON:
+// the opening sentence, then "Besonders schön finden wir …" with the
+// highlights. Returns "" when the JSON is invalid or empty.
+func assembleDetails(content, lang string) string {
+	var d structuredDetails
+	if err := json.Unmarshal([]byte(content), &d); err != nil {
+		return ""
+	}
+	var highlights []string
+	for _, h := range d.Highlights {
+		if h = strings.Trim(strings.TrimSpace(h), ".,;"); h != "" && len(highlights) < maxHighlights {
+			highlights = append(highlights, h)
+		}
+	}
+
+	var sentences []string
+	if s := strings.TrimSpace(d.OpeningSentence); s != "" {
+		if !strings.ContainsAny(s[len(s)-1:], ".!?") {
+			s += "."
+		}
+		sentences = append(sentences, s)
+	}
+	if len(highlights) > 0 {
+		intro, and := "Besonders schön finden wir ", " und "
+		if lang == LanguageEnglish {
+			intro, and = "We especially like ", " and "
+		}
+		list := highlights[len(highlights)-1]
+		if len(highlights) > 1 {
+			list = strings.Join(highlights[:len(highlights)-1], ", ") + and + list
+		}
+		sentences = append(sentences, intro+list+".")
+	}
+	return strings.Join(sentences, " ")
+}
+
+func (e *OpenAIEnhancer) buildPrompt(listing *domain.Listing, lang string) string {
 	// Collect features
 	var features []string
 	if listing.HasBalcony {
@@ -127,6 +259,15 @@ func (e *OpenAIEnhancer) buildPrompt(listing *domain.Listing) string {
 	if listing.HasElevator {
 		features = append(features, "Aufzug")
 	}
+	if listing.HasGuestToilet {
+		features = append(features, "Gäste-WC")
+	}
+	if listing.HasCellar {
+		features = append(features, "Keller")
+	}
+	if listing.HasSeparateKitchen {
+		features = append(features, "separate Küche")
+	}
 	if listing.Rooms > 0 {
 		features = append(features, fmt.Sprintf("%.0f Zimmer", listing.Rooms))
 	}
@@ -134,10 +275,15 @@ func (e *OpenAIEnhancer) buildPrompt(listing *domain.Listing) string {
 		features = append(features, fmt.Sprintf("%d m²", listing.Area))
 	}
 
+	var district string
+	if blurb := e.districts.Lookup(listing); blurb != "" {
+		district = "\n- Über das Viertel: " + blurb
+	}
+
 	return fmt.Sprintf(`
 Wohnungsinserat:
 - Titel: %s
-- Adresse/Lage: %s %s
+- Adresse/Lage: %s %s%s
 - Features: %s
 - Beschreibung: %s
 
@@ -147,18 +293,24 @@ Beispiel-Stil: "Die Bilder haben uns direkt angesprochen, besonders die hellen R
 WICHTIG:
 - Nenne 2-3 konkrete Aspekte aus dem Inserat (z.B. helle Räume, schönes Parkett, toller Balkon, moderne Küche, etc.)
 - Erwähne KEINE Besichtigung - das kommt später im Text.
-- Sei enthusiastisch aber nicht übertrieben. Schreibe auf Deutsch.
+- Sei enthusiastisch aber nicht übertrieben. Schreibe auf %s.
 - Gib NUR die 1-2 Sätze zurück, keine Anführungszeichen, keine Erklärung.
 `,
 		listing.Title,
 		listing.District,
 		listing.City,
+		district,
 		strings.Join(features, ", "),
 		truncate(listing.Description, 500),
+		languageNames[lang],
 	)
 }
 
-func (e *OpenAIEnhancer) fallbackEnhance(message string, listing *domain.Listing) string {
+func (e *OpenAIEnhancer) fallbackEnhance(message string, listing *domain.Listing, lang string) string {
+	if lang == LanguageEnglish {
+		return strings.Replace(message, "{{.PersonalizedDetails}}", fallbackDetailsEnglish(listing), 1)
+	}
+
 	// Generate generic but reasonable details
 	var details []string
 
@@ -187,6 +339,32 @@ func (e *OpenAIEnhancer) fallbackEnhance(message string, listing *domain.Listing
 	return strings.Replace(message, "{{.PersonalizedDetails}}", personalizedDetails, 1)
 }
 
+// fallbackDetailsEnglish is fallbackEnhance's sentence for English messages.
+func fallbackDetailsEnglish(listing *domain.Listing) string {
+	var details []string
+	if listing.HasBalcony {
+		details = append(details, "the balcony")
+	}
+	if listing.HasEBK {
+		details = append(details, "the fitted kitchen")
+	}
+	if listing.Area > 0 {
+		details = append(details, fmt.Sprintf("the generous %d m² of living space", listing.Area))
+	}
+	if listing.District != "" {
+		details = append(details, fmt.Sprintf("the location in %s", listing.District))
+	}
+
+	switch len(details) {
+	case 0:
+		return "The photos caught our eye straight away and the apartment is exactly what we are looking for."
+	case 1:
+		return fmt.Sprintf("The photos caught our eye straight away, especially %s.", details[0])
+	default:
+		return fmt.Sprintf("The photos caught our eye straight away, especially %s and %s.", details[0], details[1])
+	}
+}
+
 // IsEnabled returns whether the enhancer is enabled
 func (e *OpenAIEnhancer) IsEnabled() bool {
 	return e.enabled && e.apiKey != ""
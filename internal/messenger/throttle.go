@@ -0,0 +1,69 @@
+package messenger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// callLimiter paces OpenAI requests: at most maxConcurrent in flight and
+// request starts at least minInterval apart. Like the IS24 rate limiter,
+// start slots are reserved under the lock and waited for outside of it, so
+// a burst of callers (e.g. a batch of test previews) queues up in call order
+// instead of hitting the API at once.
+type callLimiter struct {
+	sem         chan struct{} // nil = no concurrency cap
+	minInterval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // earliest start of the next request
+}
+
+// newCallLimiter creates a limiter. maxConcurrent 0 and minInterval 0 turn
+// the respective limit off.
+func newCallLimiter(maxConcurrent int, minInterval time.Duration) *callLimiter {
+	l := &callLimiter{minInterval: minInterval}
+	if maxConcurrent > 0 {
+		l.sem = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// acquire blocks until the caller may start its request and returns the
+// function that ends it. Returns ctx.Err() if the context ends first.
+func (l *callLimiter) acquire(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			release = func() { <-l.sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if l.minInterval <= 0 {
+		return release, nil
+	}
+
+	l.mu.Lock()
+	start := time.Now()
+	if start.Before(l.next) {
+		start = l.next
+	}
+	l.next = start.Add(l.minInterval)
+	l.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return release, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
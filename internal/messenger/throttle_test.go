@@ -0,0 +1,90 @@
+package messenger
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestOpenAIEnhancerRateLimit(t *testing.T) {
+	const previews, maxConcurrent, minInterval = 50, 3, 2 * time.Millisecond
+
+	e := NewOpenAIEnhancer("sk-test", "gpt-4o-mini", true)
+	e.SetRateLimit(maxConcurrent, minInterval)
+
+	var mu sync.Mutex
+	var inFlight, peak int
+	var starts []time.Time
+	e.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		starts = append(starts, time.Now())
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		body := `{"choices":[{"message":{"role":"assistant","content":"Details"}}]}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+
+	// A whole batch of queued previews asks for its text at once.
+	begin := time.Now()
+	var wg sync.WaitGroup
+	for range previews {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := e.Enhance(context.Background(), "{{.PersonalizedDetails}}", &domain.Listing{Title: "Whg"}, "")
+			if err != nil || out != "Details" {
+				t.Errorf("Enhance = %q, %v", out, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(starts) != previews {
+		t.Fatalf("%d requests, want %d", len(starts), previews)
+	}
+	if peak > maxConcurrent {
+		t.Errorf("%d requests in flight, want at most %d", peak, maxConcurrent)
+	}
+	if elapsed := starts[len(starts)-1].Sub(begin); elapsed < (previews-1)*minInterval {
+		t.Errorf("last request started after %v, want at least %v", elapsed, (previews-1)*minInterval)
+	}
+}
+
+func TestCallLimiterContextCanceled(t *testing.T) {
+	l := newCallLimiter(1, time.Hour)
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("acquire = %v, want deadline exceeded", err)
+	}
+}
+
+func TestCallLimiterOff(t *testing.T) {
+	l := newCallLimiter(0, 0)
+	for range 100 {
+		release, err := l.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+}
@@ -14,7 +14,8 @@ import (
 type Notifier interface {
 	NotifyNewListing(ctx context.Context, l *domain.Listing) error
 	NotifyContactSent(ctx context.Context, l *domain.Listing) error
-	NotifyContactFailed(ctx context.Context, l *domain.Listing, errMsg string) error
+	NotifyContactFailed(ctx context.Context, l *domain.Listing, f domain.ContactFailure) error
+	NotifyContactUnconfirmed(ctx context.Context, l *domain.Listing) error
 	NotifyError(ctx context.Context, errMsg string) error
 	NotifyMessagePreview(ctx context.Context, l *domain.Listing, message string) error
 	SendRawMessage(ctx context.Context, text string) error
@@ -68,8 +69,12 @@ func (m *Multi) NotifyContactSent(ctx context.Context, l *domain.Listing) error
 	return m.fanOut(func(c Notifier) error { return c.NotifyContactSent(ctx, l) })
 }
 
-func (m *Multi) NotifyContactFailed(ctx context.Context, l *domain.Listing, errMsg string) error {
-	return m.fanOut(func(c Notifier) error { return c.NotifyContactFailed(ctx, l, errMsg) })
+func (m *Multi) NotifyContactFailed(ctx context.Context, l *domain.Listing, f domain.ContactFailure) error {
+	return m.fanOut(func(c Notifier) error { return c.NotifyContactFailed(ctx, l, f) })
+}
+
+func (m *Multi) NotifyContactUnconfirmed(ctx context.Context, l *domain.Listing) error {
+	return m.fanOut(func(c Notifier) error { return c.NotifyContactUnconfirmed(ctx, l) })
 }
 
 func (m *Multi) NotifyError(ctx context.Context, errMsg string) error {
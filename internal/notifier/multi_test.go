@@ -23,7 +23,11 @@ func (f *fakeNotifier) NotifyContactSent(context.Context, *domain.Listing) error
 	f.calls++
 	return f.err
 }
-func (f *fakeNotifier) NotifyContactFailed(context.Context, *domain.Listing, string) error {
+func (f *fakeNotifier) NotifyContactFailed(context.Context, *domain.Listing, domain.ContactFailure) error {
+	f.calls++
+	return f.err
+}
+func (f *fakeNotifier) NotifyContactUnconfirmed(context.Context, *domain.Listing) error {
 	f.calls++
 	return f.err
 }
@@ -63,13 +67,14 @@ func TestMultiFanOutAllMethods(t *testing.T) {
 
 	m.NotifyNewListing(ctx, l)
 	m.NotifyContactSent(ctx, l)
-	m.NotifyContactFailed(ctx, l, "x")
+	m.NotifyContactFailed(ctx, l, domain.ContactFailure{Detail: "x"})
+	m.NotifyContactUnconfirmed(ctx, l)
 	m.NotifyError(ctx, "x")
 	m.NotifyMessagePreview(ctx, l, "x")
 	m.SendRawMessage(ctx, "x")
 
-	if a.calls != 6 || b.calls != 6 {
-		t.Errorf("each channel should get 6 calls, got a=%d b=%d", a.calls, b.calls)
+	if a.calls != 7 || b.calls != 7 {
+		t.Errorf("each channel should get 7 calls, got a=%d b=%d", a.calls, b.calls)
 	}
 }
 
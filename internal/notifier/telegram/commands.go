@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -54,6 +55,10 @@ func (c *BotController) StartCommandListener(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			case update := <-updates:
+				if update.CallbackQuery != nil {
+					c.handleCallback(update.CallbackQuery)
+					continue
+				}
 				if update.Message == nil || !update.Message.IsCommand() {
 					continue
 				}
@@ -70,13 +75,35 @@ func (c *BotController) StartCommandListener(ctx context.Context) {
 }
 
 func (c *BotController) handleCommand(msg *tgbotapi.Message) {
-	response := c.ctrl.HandleCommand(msg.Text)
-	if response == "" {
+	c.sendReply(c.ctrl.HandleCommandReply(msg.Text))
+}
+
+// handleCallback runs the command behind a pressed inline button (see
+// control.Button) and removes the buttons so they can't be pressed twice.
+func (c *BotController) handleCallback(q *tgbotapi.CallbackQuery) {
+	if q.Message == nil || q.Message.Chat.ID != c.chatID {
 		return
 	}
+	c.bot.Request(tgbotapi.NewCallback(q.ID, ""))
+	c.bot.Request(tgbotapi.NewEditMessageReplyMarkup(c.chatID, q.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+	c.sendReply(c.ctrl.HandleCommandReply(q.Data))
+}
 
-	reply := tgbotapi.NewMessage(c.chatID, markupToHTML(response))
+func (c *BotController) sendReply(r control.Reply) {
+	if r.Text == "" {
+		return
+	}
+
+	reply := tgbotapi.NewMessage(c.chatID, markupToHTML(r.Text))
 	reply.ParseMode = tgbotapi.ModeHTML
+	if len(r.Buttons) > 0 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, b := range r.Buttons {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.Label, b.Command))
+		}
+		reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	}
 	c.bot.Send(reply)
 }
 
@@ -104,6 +131,59 @@ func markupToHTML(s string) string {
 	return sb.String()
 }
 
+// SelfTestResult is what SelfTest found out about the bot and its chat.
+type SelfTestResult struct {
+	Username string // bot username, without "@"
+	Chat     string // chat title, or the user's name for a private chat
+}
+
+// SelfTest checks that the bot can write to the configured chat without
+// posting anything: it looks the chat up and sends a typing indicator, which
+// needs the same rights as a message.
+func (c *BotController) SelfTest() (SelfTestResult, error) {
+	if !c.enabled {
+		return SelfTestResult{}, fmt.Errorf("telegram disabled")
+	}
+	res := SelfTestResult{Username: c.bot.Self.UserName}
+	chat, err := c.bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: c.chatID}})
+	if err != nil {
+		return res, selfTestError(c.chatID, err)
+	}
+	res.Chat = chatName(chat)
+	if _, err := c.bot.Request(tgbotapi.NewChatAction(c.chatID, tgbotapi.ChatTyping)); err != nil {
+		return res, selfTestError(c.chatID, err)
+	}
+	return res, nil
+}
+
+// selfTestError adds a setup hint to the errors Telegram returns for a wrong
+// chat ID or missing rights.
+func selfTestError(chatID int64, err error) error {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		switch tgErr.Code {
+		case 400:
+			return fmt.Errorf("chat %d: %w (check telegram.chat_id and that you sent the bot /start)", chatID, err)
+		case 403:
+			return fmt.Errorf("chat %d: %w (bot was blocked, removed from the group or may not post)", chatID, err)
+		}
+	}
+	return fmt.Errorf("chat %d: %w", chatID, err)
+}
+
+// chatName names a chat for humans: the group/channel title, otherwise the
+// user's @username or first name.
+func chatName(chat tgbotapi.Chat) string {
+	switch {
+	case chat.Title != "":
+		return chat.Title
+	case chat.UserName != "":
+		return "@" + chat.UserName
+	default:
+		return strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+	}
+}
+
 // GetBot returns the underlying bot API for notifications.
 func (c *BotController) GetBot() *tgbotapi.BotAPI {
 	return c.bot
@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestChatName(t *testing.T) {
+	tests := []struct {
+		chat tgbotapi.Chat
+		want string
+	}{
+		{tgbotapi.Chat{Title: "Wohnungssuche", UserName: "x"}, "Wohnungssuche"},
+		{tgbotapi.Chat{UserName: "anna", FirstName: "Anna"}, "@anna"},
+		{tgbotapi.Chat{FirstName: "Anna", LastName: "M."}, "Anna M."},
+	}
+	for _, tt := range tests {
+		if got := chatName(tt.chat); got != tt.want {
+			t.Errorf("chatName(%+v) = %q, want %q", tt.chat, got, tt.want)
+		}
+	}
+}
+
+func TestSelfTestErrorHints(t *testing.T) {
+	notFound := &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}
+	err := selfTestError(42, notFound)
+	if !errors.Is(err, notFound) || !strings.Contains(err.Error(), "telegram.chat_id") {
+		t.Errorf("400: got %v", err)
+	}
+	if err := selfTestError(42, &tgbotapi.Error{Code: 403, Message: "Forbidden"}); !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("403: got %v", err)
+	}
+	if err := selfTestError(42, errors.New("timeout")); err.Error() != "chat 42: timeout" {
+		t.Errorf("other: got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram allows about one message per second to the same chat (bursts are
+// tolerated briefly) and answers 429 with retry_after when a bot exceeds it.
+const (
+	sendInterval     = time.Second
+	maxSendRetries   = 3
+	sendRetryBackoff = 2 * time.Second
+)
+
+// pacer serializes sends to the chat, spaces them by interval and retries a
+// send rejected with 429 after the retry_after Telegram asks for, so a
+// cycle with many new listings doesn't lose notifications. Network errors
+// and Telegram 5xx answers are retried too, after backoff, 2×backoff, ...
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	retries  int
+	backoff  time.Duration
+	last     time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func newPacer(interval time.Duration) *pacer {
+	return &pacer{interval: interval, retries: maxSendRetries, backoff: sendRetryBackoff, sleep: sleepContext}
+}
+
+// send runs fn once it is this message's turn.
+func (p *pacer) send(ctx context.Context, fn func() error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if wait := p.interval - time.Since(p.last); wait > 0 {
+			if err := p.sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+		p.last = time.Now()
+
+		err := fn()
+		if err == nil || attempt >= p.retries {
+			return err
+		}
+		var wait time.Duration
+		var tgErr *tgbotapi.Error
+		switch {
+		case errors.As(err, &tgErr) && tgErr.RetryAfter > 0:
+			wait = time.Duration(tgErr.RetryAfter) * time.Second
+		case isTransient(err):
+			wait = p.backoff << attempt
+		default:
+			return err
+		}
+		if err := p.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// isTransient reports errors worth retrying: the request didn't get through
+// (network) or Telegram failed on its side (5xx). A timed-out request may
+// still have been delivered, so a retry can rarely duplicate a message —
+// better than losing it.
+func isTransient(err error) bool {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
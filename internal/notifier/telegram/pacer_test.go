@@ -0,0 +1,105 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestPacerSpacesSendsAndHonorsRetryAfter(t *testing.T) {
+	var slept []time.Duration
+	p := newPacer(time.Second)
+	p.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	ctx := context.Background()
+
+	if err := p.send(ctx, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(slept) != 0 {
+		t.Fatalf("first send should go out immediately, slept %v", slept)
+	}
+
+	// A 429 is retried after retry_after; the retry succeeds.
+	calls := 0
+	err := p.send(ctx, func() error {
+		calls++
+		if calls == 1 {
+			return &tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("expected retry to succeed, err=%v calls=%d", err, calls)
+	}
+	if len(slept) < 2 || slept[0] <= 0 || slept[0] > time.Second || slept[1] != 7*time.Second {
+		t.Errorf("expected spacing then retry_after, slept %v", slept)
+	}
+
+	// Other errors are returned as is.
+	boom := errors.New("boom")
+	calls = 0
+	if err := p.send(ctx, func() error { calls++; return boom }); err != boom || calls != 1 {
+		t.Errorf("non-429 error: err=%v calls=%d", err, calls)
+	}
+
+	// Persistent 429s give up after maxSendRetries.
+	calls = 0
+	err = p.send(ctx, func() error {
+		calls++
+		return &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}}
+	})
+	if err == nil || calls != maxSendRetries+1 {
+		t.Errorf("expected to give up after %d retries, err=%v calls=%d", maxSendRetries, err, calls)
+	}
+}
+
+func TestPacerRetriesTransientErrors(t *testing.T) {
+	var slept []time.Duration
+	p := newPacer(0)
+	p.backoff = time.Second
+	p.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	ctx := context.Background()
+
+	// Network errors and Telegram 5xx back off exponentially, then succeed.
+	calls := 0
+	err := p.send(ctx, func() error {
+		calls++
+		switch calls {
+		case 1:
+			return &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+		case 2:
+			return &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected the third attempt to succeed, err=%v calls=%d", err, calls)
+	}
+	if len(slept) != 2 || slept[0] != time.Second || slept[1] != 2*time.Second {
+		t.Errorf("expected 1s, 2s backoff, slept %v", slept)
+	}
+
+	// A client error (bad HTML, wrong chat) won't get better by retrying.
+	calls = 0
+	if err := p.send(ctx, func() error { calls++; return &tgbotapi.Error{Code: 400, Message: "Bad Request"} }); err == nil || calls != 1 {
+		t.Errorf("400: err=%v calls=%d", err, calls)
+	}
+
+	// Retries are configurable; 0 sends once.
+	p.retries = 0
+	calls = 0
+	if err := p.send(ctx, func() error { calls++; return &tgbotapi.Error{Code: 500} }); err == nil || calls != 1 {
+		t.Errorf("no retries: err=%v calls=%d", err, calls)
+	}
+}
@@ -3,23 +3,34 @@ package telegram
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/julianbeese/immo_bot/internal/domain"
 )
 
+// maxErrorDetail caps the raw error shown in contact-failed notifications,
+// well below Telegram's message limit.
+const maxErrorDetail = 1500
+
 // Notifier sends messages via Telegram
 type Notifier struct {
 	bot     *tgbotapi.BotAPI
 	chatID  int64
 	enabled bool
+	pacer   *pacer
+	logger  *slog.Logger
+	compact bool // new listings as one line instead of the full card
 }
 
 // NewNotifier creates a new Telegram notifier
 func NewNotifier(botToken string, chatID int64, enabled bool) (*Notifier, error) {
 	if !enabled || botToken == "" {
-		return &Notifier{enabled: false}, nil
+		return &Notifier{enabled: false, logger: slog.Default()}, nil
 	}
 
 	bot, err := tgbotapi.NewBotAPI(botToken)
@@ -31,18 +42,22 @@ func NewNotifier(botToken string, chatID int64, enabled bool) (*Notifier, error)
 		bot:     bot,
 		chatID:  chatID,
 		enabled: true,
+		pacer:   newPacer(sendInterval),
+		logger:  slog.Default(),
 	}, nil
 }
 
 // NewNotifierFromController creates a notifier using an existing BotController
 func NewNotifierFromController(controller *BotController) *Notifier {
 	if controller == nil || !controller.IsEnabled() {
-		return &Notifier{enabled: false}
+		return &Notifier{enabled: false, logger: slog.Default()}
 	}
 	return &Notifier{
 		bot:     controller.GetBot(),
 		chatID:  controller.GetChatID(),
 		enabled: true,
+		pacer:   newPacer(sendInterval),
+		logger:  slog.Default(),
 	}
 }
 
@@ -52,22 +67,88 @@ func (n *Notifier) NotifyNewListing(ctx context.Context, listing *domain.Listing
 		return nil
 	}
 
+	if n.compact {
+		msg := tgbotapi.NewMessage(n.chatID, formatCompact(listing))
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.DisableWebPagePreview = true
+		msg.DisableNotification = listing.Silent
+		return n.send(ctx, msg)
+	}
+
 	text := n.formatListing(listing)
 
 	msg := tgbotapi.NewMessage(n.chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 	msg.DisableWebPagePreview = false
+	msg.DisableNotification = listing.Silent
 
-	// Add inline keyboard with link to listing
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("🔗 Auf IS24 ansehen", listing.URL),
-		),
-	)
-	msg.ReplyMarkup = keyboard
+	msg.ReplyMarkup = listingKeyboard(listing)
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
+}
+
+// formatCompact renders a listing as one line for busy markets:
+// "1.450 € · 3 Zi · 82 m² · Mitte — IS24". Unknown values are left out.
+func formatCompact(l *domain.Listing) string {
+	var parts []string
+	if l.Price > 0 {
+		parts = append(parts, formatEuro(l.Price))
+	} else if l.PriceOnRequest {
+		parts = append(parts, "Preis a. A.")
+	}
+	if l.Rooms > 0 {
+		parts = append(parts, strings.Replace(strconv.FormatFloat(l.Rooms, 'f', -1, 64), ".", ",", 1)+" Zi")
+	}
+	if l.Area > 0 {
+		parts = append(parts, fmt.Sprintf("%d m²", l.Area))
+	}
+	if l.District != "" {
+		parts = append(parts, escapeHTML(l.District))
+	} else if l.City != "" {
+		parts = append(parts, escapeHTML(l.City))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, escapeHTML(l.Title))
+	}
+	return "🏠 " + strings.Join(parts, " · ") + duplicateNote(l) + fmt.Sprintf(` — <a href="%s">IS24</a>`, escapeHTML(l.URL))
+}
+
+// duplicateNote marks a listing the landlord posted more than once.
+func duplicateNote(l *domain.Listing) string {
+	if l.Duplicates > 0 {
+		return " (mehrfach inseriert)"
+	}
+	return ""
+}
+
+// formatEuro formats n with German thousands separators: 1450 → "1.450 €".
+func formatEuro(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "." + s[i:]
+	}
+	return s + " €"
+}
+
+// formatSqmPrice renders a price per m²: with cents for rents ("14,57 €"),
+// rounded with thousands dots for purchases ("4.250 €").
+func formatSqmPrice(v float64) string {
+	if v >= 100 {
+		return formatEuro(int(math.Round(v)))
+	}
+	return strings.Replace(fmt.Sprintf("%.2f €", v), ".", ",", 1)
+}
+
+// listingKeyboard links the listing on IS24 and, when its location is known,
+// on the map.
+func listingKeyboard(l *domain.Listing) tgbotapi.InlineKeyboardMarkup {
+	row := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL("🔗 Auf IS24 ansehen", l.URL),
+	)
+	if mapsURL := l.MapsURL(); mapsURL != "" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL("🗺️ Karte", mapsURL))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
 }
 
 // NotifyContactSent sends a confirmation that contact was sent
@@ -89,12 +170,12 @@ func (n *Notifier) NotifyContactSent(ctx context.Context, listing *domain.Listin
 	msg := tgbotapi.NewMessage(n.chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
 }
 
-// NotifyContactFailed sends a notification that contact attempt failed
-func (n *Notifier) NotifyContactFailed(ctx context.Context, listing *domain.Listing, errMsg string) error {
+// NotifyContactFailed sends a notification that contact attempt failed:
+// the error category, a suggested action and the raw error as a code block.
+func (n *Notifier) NotifyContactFailed(ctx context.Context, listing *domain.Listing, f domain.ContactFailure) error {
 	if !n.enabled {
 		return nil
 	}
@@ -108,14 +189,43 @@ func (n *Notifier) NotifyContactFailed(ctx context.Context, listing *domain.List
 		escapeHTML(listing.Title),
 		escapeHTML(listing.Address),
 		listing.URL,
-		escapeHTML(errMsg),
+		escapeHTML(f.Label),
 	)
+	if f.Hint != "" {
+		text += "\n💡 " + escapeHTML(f.Hint)
+	}
+	if f.Detail != "" {
+		text += "\n\n<pre>" + escapeHTML(truncateRunes(f.Detail, maxErrorDetail)) + "</pre>"
+	}
 
 	msg := tgbotapi.NewMessage(n.chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
+}
+
+// NotifyContactUnconfirmed warns that a contact form went out but no
+// confirmation was detected, so it needs a manual check on IS24.
+func (n *Notifier) NotifyContactUnconfirmed(ctx context.Context, listing *domain.Listing) error {
+	if !n.enabled {
+		return nil
+	}
+
+	text := fmt.Sprintf(
+		"⚠️ <b>Gesendet, aber keine Bestätigung erkannt</b>\n\n"+
+			"<b>%s</b>\n"+
+			"📍 %s\n"+
+			"🔗 %s\n\n"+
+			"Bitte auf IS24 prüfen, ob die Anfrage angekommen ist.",
+		escapeHTML(listing.Title),
+		escapeHTML(listing.Address),
+		listing.URL,
+	)
+
+	msg := tgbotapi.NewMessage(n.chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+
+	return n.send(ctx, msg)
 }
 
 // NotifyError sends an error notification to the admin
@@ -129,8 +239,7 @@ func (n *Notifier) NotifyError(ctx context.Context, errMsg string) error {
 	msg := tgbotapi.NewMessage(n.chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
 }
 
 // NotifyStartup sends a notification that the bot has started
@@ -148,8 +257,7 @@ func (n *Notifier) NotifyStartup(ctx context.Context, profileCount int) error {
 	msg := tgbotapi.NewMessage(n.chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
 }
 
 // formatListing creates a formatted message for a listing
@@ -157,7 +265,7 @@ func (n *Notifier) formatListing(l *domain.Listing) string {
 	var sb strings.Builder
 
 	sb.WriteString("🏠 <b>Neue Wohnung gefunden!</b>\n\n")
-	sb.WriteString(fmt.Sprintf("<b>%s</b>\n\n", escapeHTML(l.Title)))
+	sb.WriteString(fmt.Sprintf("<b>%s</b>%s\n\n", escapeHTML(l.Title), duplicateNote(l)))
 
 	// Location
 	if l.Address != "" {
@@ -171,8 +279,32 @@ func (n *Notifier) formatListing(l *domain.Listing) string {
 	sb.WriteString("\n")
 
 	// Key facts
-	if l.Price > 0 {
+	// A purchase listing's price is no rent.
+	if l.Price > 0 && l.IsPurchase() {
+		sb.WriteString(fmt.Sprintf("💰 <b>%d €</b> Kaufpreis\n", l.Price))
+	} else if l.Price > 0 {
 		sb.WriteString(fmt.Sprintf("💰 <b>%d €</b> Kaltmiete\n", l.Price))
+	} else if l.PriceOnRequest {
+		sb.WriteString("💬 Preis auf Anfrage\n")
+	}
+	if l.MonthlyFees > 0 {
+		sb.WriteString(fmt.Sprintf("🏦 %d € Hausgeld/Monat\n", l.MonthlyFees))
+	} else if l.TotalRent > 0 {
+		// Kaltmiete + Nebenkosten is only warm if the Nebenkosten cover heating.
+		heating := ""
+		if l.HeatingExtra() {
+			heating = " zzgl. Heizkosten"
+		} else if l.WarmRent == 0 && l.HeatingIncluded != nil {
+			heating = " inkl. Heizkosten"
+		}
+		if l.RentEstimated {
+			sb.WriteString(fmt.Sprintf("💶 ca. %d € warm (geschätzt)%s\n", l.TotalRent, heating))
+		} else {
+			sb.WriteString(fmt.Sprintf("💶 %d € warm%s\n", l.TotalRent, heating))
+		}
+	}
+	if l.QualityScore > 0 {
+		sb.WriteString(fmt.Sprintf("⭐ Score: %d/100\n", l.QualityScore))
 	}
 	if l.Rooms > 0 {
 		sb.WriteString(fmt.Sprintf("🚪 %.1f Zimmer\n", l.Rooms))
@@ -180,8 +312,18 @@ func (n *Notifier) formatListing(l *domain.Listing) string {
 	if l.Area > 0 {
 		sb.WriteString(fmt.Sprintf("📐 %d m²\n", l.Area))
 	}
+	if l.PricePerSqm > 0 {
+		label := "Kaltmiete/m²"
+		if l.IsPurchase() {
+			label = "Kaufpreis/m²"
+		}
+		sb.WriteString(fmt.Sprintf("📊 %s %s\n", formatSqmPrice(l.PricePerSqm), label))
+	}
 
 	// Features
+	if l.FloorType != "" {
+		sb.WriteString(fmt.Sprintf("🏢 %s\n", escapeHTML(l.FloorType)))
+	}
 	var features []string
 	if l.HasBalcony {
 		features = append(features, "Balkon")
@@ -192,27 +334,99 @@ func (n *Notifier) formatListing(l *domain.Listing) string {
 	if l.HasElevator {
 		features = append(features, "Aufzug")
 	}
+	if l.HasGuestToilet {
+		features = append(features, "Gäste-WC")
+	}
+	if l.HasCellar {
+		features = append(features, "Keller")
+	}
+	if l.HasSeparateKitchen {
+		features = append(features, "sep. Küche")
+	}
 	if len(features) > 0 {
 		sb.WriteString(fmt.Sprintf("✨ %s\n", strings.Join(features, ", ")))
 	}
+	if l.RequiresMembership {
+		sb.WriteString("🏘 Genossenschaft (Anteile/Mitgliedschaft nötig)\n")
+	}
 
 	// Available from
-	if l.AvailableFrom != "" {
-		sb.WriteString(fmt.Sprintf("📅 Ab %s\n", escapeHTML(l.AvailableFrom)))
+	if label, date := l.MoveIn(); date != "" {
+		sb.WriteString(fmt.Sprintf("📅 %s %s\n", label, escapeHTML(date)))
 	}
 
-	// Landlord
-	if l.LandlordName != "" {
-		sb.WriteString(fmt.Sprintf("\n👤 %s", escapeHTML(l.LandlordName)))
-		if l.LandlordType != "" {
-			sb.WriteString(fmt.Sprintf(" (%s)", escapeHTML(l.LandlordType)))
-		}
+	// Landlord (contact person and company on separate lines)
+	if l.LandlordName != "" || l.LandlordCompany != "" {
 		sb.WriteString("\n")
+		if l.LandlordName != "" {
+			sb.WriteString(fmt.Sprintf("👤 %s", escapeHTML(l.LandlordName)))
+			if l.LandlordType != "" && l.LandlordCompany == "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", escapeHTML(l.LandlordType)))
+			}
+			sb.WriteString("\n")
+		}
+		if l.LandlordCompany != "" {
+			sb.WriteString(fmt.Sprintf("🏢 %s", escapeHTML(l.LandlordCompany)))
+			if l.LandlordType != "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", escapeHTML(l.LandlordType)))
+			}
+			sb.WriteString("\n")
+		}
 	}
 
 	return sb.String()
 }
 
+// send delivers msg through the pacer.
+func (n *Notifier) send(ctx context.Context, msg tgbotapi.Chattable) error {
+	err := n.pacer.send(ctx, func() error {
+		_, err := n.bot.Send(msg)
+		return err
+	})
+	if err != nil {
+		n.logger.Error("telegram notification lost", "chat_id", n.chatID, "error", err)
+	}
+	return err
+}
+
+// SetRetry sets how often a failed send is retried (network errors,
+// Telegram 5xx and 429) and the backoff before the first retry, doubled
+// for each further one. retries < 0 or backoff <= 0 keep the defaults.
+func (n *Notifier) SetRetry(retries int, backoff time.Duration) {
+	if n.pacer == nil {
+		return
+	}
+	if retries >= 0 {
+		n.pacer.retries = retries
+	}
+	if backoff > 0 {
+		n.pacer.backoff = backoff
+	}
+}
+
+// SetCompact switches new-listing messages to the one-line format
+// (telegram.style: compact).
+func (n *Notifier) SetCompact(compact bool) {
+	n.compact = compact
+}
+
+// SetLogger sets where lost notifications are reported; nil keeps the
+// default logger.
+func (n *Notifier) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		n.logger = logger
+	}
+}
+
+// truncateRunes cuts s to at most max runes, marking the cut with "…".
+func truncateRunes(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "…"
+}
+
 // escapeHTML escapes HTML special characters for Telegram
 func escapeHTML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
@@ -236,8 +450,23 @@ func (n *Notifier) SendRawMessage(ctx context.Context, text string) error {
 	msg := tgbotapi.NewMessage(n.chatID, markupToHTML(text))
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
+}
+
+// SendDocument sends data as a file named name, with an optional caption
+// (same *bold* markup as SendRawMessage).
+func (n *Notifier) SendDocument(ctx context.Context, name string, data []byte, caption string) error {
+	if !n.enabled {
+		return nil
+	}
+
+	doc := tgbotapi.NewDocument(n.chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	if caption != "" {
+		doc.Caption = markupToHTML(caption)
+		doc.ParseMode = tgbotapi.ModeHTML
+	}
+
+	return n.send(ctx, doc)
 }
 
 // NotifyMessagePreview sends a preview of the message that would be sent to a listing
@@ -265,6 +494,5 @@ func (n *Notifier) NotifyMessagePreview(ctx context.Context, listing *domain.Lis
 	msg := tgbotapi.NewMessage(n.chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	_, err := n.bot.Send(msg)
-	return err
+	return n.send(ctx, msg)
 }
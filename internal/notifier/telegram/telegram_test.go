@@ -0,0 +1,100 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestListingKeyboardMapsLink(t *testing.T) {
+	l := &domain.Listing{URL: "https://is24.de/expose/1", Latitude: 52.52, Longitude: 13.405}
+	row := listingKeyboard(l).InlineKeyboard[0]
+	if len(row) != 2 || row[1].URL == nil || !strings.HasSuffix(*row[1].URL, "query=52.520000,13.405000") {
+		t.Fatalf("expected map button at the coordinates, got %+v", row)
+	}
+
+	l = &domain.Listing{URL: "https://is24.de/expose/1", Address: "Hauptstr. 1, 10115 Berlin", City: "Berlin"}
+	row = listingKeyboard(l).InlineKeyboard[0]
+	if len(row) != 2 || !strings.HasSuffix(*row[1].URL, "query=Hauptstr.+1%2C+10115+Berlin") {
+		t.Fatalf("expected address search, got %+v", row)
+	}
+
+	row = listingKeyboard(&domain.Listing{URL: "https://is24.de/expose/1"}).InlineKeyboard[0]
+	if len(row) != 1 {
+		t.Errorf("no location should mean no map button, got %d buttons", len(row))
+	}
+}
+
+func TestFormatCompact(t *testing.T) {
+	l := &domain.Listing{Price: 1450, Rooms: 2.5, Area: 82, District: "Mitte", City: "Berlin", URL: "https://is24.de/expose/1"}
+	want := `🏠 1.450 € · 2,5 Zi · 82 m² · Mitte — <a href="https://is24.de/expose/1">IS24</a>`
+	if got := formatCompact(l); got != want {
+		t.Errorf("formatCompact = %q, want %q", got, want)
+	}
+
+	l = &domain.Listing{PriceOnRequest: true, Rooms: 3, City: "Köln", URL: "u"}
+	if got := formatCompact(l); !strings.HasPrefix(got, "🏠 Preis a. A. · 3 Zi · Köln — ") {
+		t.Errorf("formatCompact = %q", got)
+	}
+	l.Duplicates = 2
+	if got := formatCompact(l); !strings.Contains(got, "· Köln (mehrfach inseriert) — ") {
+		t.Errorf("formatCompact = %q", got)
+	}
+	if got := formatEuro(1234567); got != "1.234.567 €" {
+		t.Errorf("formatEuro = %q", got)
+	}
+}
+
+func TestFormatListingPricePerSqm(t *testing.T) {
+	n := &Notifier{}
+	rent := n.formatListing(&domain.Listing{Title: "Whg", Price: 1020, Area: 70, PricePerSqm: 14.57})
+	if !strings.Contains(rent, "📊 14,57 € Kaltmiete/m²") {
+		t.Errorf("rental:\n%s", rent)
+	}
+	buy := n.formatListing(&domain.Listing{Title: "Whg", PropertyType: "apartmentbuy", Price: 349000, Area: 74, PricePerSqm: 4716.22})
+	if !strings.Contains(buy, "349000 €</b> Kaufpreis") || !strings.Contains(buy, "📊 4.716 € Kaufpreis/m²") {
+		t.Errorf("purchase:\n%s", buy)
+	}
+}
+
+func TestFormatListingHeating(t *testing.T) {
+	n := &Notifier{}
+	no, yes := false, true
+	tests := []struct {
+		l    domain.Listing
+		want string
+	}{
+		{domain.Listing{Price: 900, ServiceCharge: 150, TotalRent: 1050, HeatingIncluded: &no}, "💶 1050 € warm zzgl. Heizkosten\n"},
+		{domain.Listing{Price: 900, ServiceCharge: 150, TotalRent: 1050, HeatingIncluded: &yes}, "💶 1050 € warm inkl. Heizkosten\n"},
+		{domain.Listing{Price: 900, TotalRent: 1125, RentEstimated: true, HeatingIncluded: &no}, "💶 ca. 1125 € warm (geschätzt) zzgl. Heizkosten\n"},
+		// A listed Warmmiete already includes heating.
+		{domain.Listing{Price: 900, WarmRent: 1130, TotalRent: 1130, HeatingIncluded: &no}, "💶 1130 € warm\n"},
+		{domain.Listing{Price: 900, ServiceCharge: 150, TotalRent: 1050}, "💶 1050 € warm\n"},
+	}
+	for _, tt := range tests {
+		tt.l.Title = "Whg"
+		if got := n.formatListing(&tt.l); !strings.Contains(got, tt.want) {
+			t.Errorf("want %q in:\n%s", tt.want, got)
+		}
+	}
+}
+
+func TestFormatListingMoveIn(t *testing.T) {
+	n := &Notifier{}
+	tests := []struct {
+		l    domain.Listing
+		want string
+	}{
+		{domain.Listing{AvailableFrom: "sofort", VacantFrom: "01.03.2025"}, "📅 Ab sofort\n"},
+		{domain.Listing{VacantFrom: "01.03.2025"}, "📅 Ab 01.03.2025\n"},
+		{domain.Listing{MonthlyFees: 295, AvailableFrom: "sofort", VacantFrom: "vermietet"}, "📅 Bezugsfrei ab vermietet\n"},
+		{domain.Listing{PropertyType: "apartmentbuy", AvailableFrom: "sofort"}, "📅 Verfügbar ab sofort\n"},
+	}
+	for _, tt := range tests {
+		tt.l.Title = "Whg"
+		if got := n.formatListing(&tt.l); !strings.Contains(got, tt.want) {
+			t.Errorf("want %q in:\n%s", tt.want, got)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"math"
+	"strconv"
 	"strings"
 
 	"go.mau.fi/whatsmeow"
@@ -191,12 +193,27 @@ func (c *Client) NotifyContactSent(ctx context.Context, l *domain.Listing) error
 	return c.send(ctx, c.target, text)
 }
 
-func (c *Client) NotifyContactFailed(ctx context.Context, l *domain.Listing, errMsg string) error {
+func (c *Client) NotifyContactFailed(ctx context.Context, l *domain.Listing, f domain.ContactFailure) error {
 	if !c.enabled {
 		return nil
 	}
 	text := fmt.Sprintf("❌ *Kontaktanfrage fehlgeschlagen*\n\n*%s*\n📍 %s\n🔗 %s\n\n*Fehler:* %s",
-		l.Title, l.Address, l.URL, errMsg)
+		l.Title, l.Address, l.URL, f.Label)
+	if f.Hint != "" {
+		text += "\n💡 " + f.Hint
+	}
+	if f.Detail != "" {
+		text += "\n\n```" + f.Detail + "```"
+	}
+	return c.send(ctx, c.target, text)
+}
+
+func (c *Client) NotifyContactUnconfirmed(ctx context.Context, l *domain.Listing) error {
+	if !c.enabled {
+		return nil
+	}
+	text := fmt.Sprintf("⚠️ *Gesendet, aber keine Bestätigung erkannt*\n\n*%s*\n📍 %s\n🔗 %s\n\nBitte auf IS24 prüfen, ob die Anfrage angekommen ist.",
+		l.Title, l.Address, l.URL)
 	return c.send(ctx, c.target, text)
 }
 
@@ -231,7 +248,11 @@ func (c *Client) IsEnabled() bool {
 func formatListing(l *domain.Listing) string {
 	var sb strings.Builder
 	sb.WriteString("🏠 *Neue Wohnung gefunden!*\n\n")
-	sb.WriteString(fmt.Sprintf("*%s*\n\n", l.Title))
+	if l.Duplicates > 0 {
+		sb.WriteString(fmt.Sprintf("*%s* (mehrfach inseriert)\n\n", l.Title))
+	} else {
+		sb.WriteString(fmt.Sprintf("*%s*\n\n", l.Title))
+	}
 
 	switch {
 	case l.Address != "":
@@ -243,8 +264,32 @@ func formatListing(l *domain.Listing) string {
 	}
 	sb.WriteString("\n")
 
-	if l.Price > 0 {
+	// A purchase listing's price is no rent.
+	if l.Price > 0 && l.IsPurchase() {
+		sb.WriteString(fmt.Sprintf("💰 *%d €* Kaufpreis\n", l.Price))
+	} else if l.Price > 0 {
 		sb.WriteString(fmt.Sprintf("💰 *%d €* Kaltmiete\n", l.Price))
+	} else if l.PriceOnRequest {
+		sb.WriteString("💬 Preis auf Anfrage\n")
+	}
+	if l.MonthlyFees > 0 {
+		sb.WriteString(fmt.Sprintf("🏦 %d € Hausgeld/Monat\n", l.MonthlyFees))
+	} else if l.TotalRent > 0 {
+		// Kaltmiete + Nebenkosten is only warm if the Nebenkosten cover heating.
+		heating := ""
+		if l.HeatingExtra() {
+			heating = " zzgl. Heizkosten"
+		} else if l.WarmRent == 0 && l.HeatingIncluded != nil {
+			heating = " inkl. Heizkosten"
+		}
+		if l.RentEstimated {
+			sb.WriteString(fmt.Sprintf("💶 ca. %d € warm (geschätzt)%s\n", l.TotalRent, heating))
+		} else {
+			sb.WriteString(fmt.Sprintf("💶 %d € warm%s\n", l.TotalRent, heating))
+		}
+	}
+	if l.QualityScore > 0 {
+		sb.WriteString(fmt.Sprintf("⭐ Score: %d/100\n", l.QualityScore))
 	}
 	if l.Rooms > 0 {
 		sb.WriteString(fmt.Sprintf("🚪 %.1f Zimmer\n", l.Rooms))
@@ -252,7 +297,17 @@ func formatListing(l *domain.Listing) string {
 	if l.Area > 0 {
 		sb.WriteString(fmt.Sprintf("📐 %d m²\n", l.Area))
 	}
+	if l.PricePerSqm > 0 {
+		label := "Kaltmiete/m²"
+		if l.IsPurchase() {
+			label = "Kaufpreis/m²"
+		}
+		sb.WriteString(fmt.Sprintf("📊 %s %s\n", formatSqmPrice(l.PricePerSqm), label))
+	}
 
+	if l.FloorType != "" {
+		sb.WriteString(fmt.Sprintf("🏢 %s\n", l.FloorType))
+	}
 	var features []string
 	if l.HasBalcony {
 		features = append(features, "Balkon")
@@ -263,19 +318,41 @@ func formatListing(l *domain.Listing) string {
 	if l.HasElevator {
 		features = append(features, "Aufzug")
 	}
+	if l.HasGuestToilet {
+		features = append(features, "Gäste-WC")
+	}
+	if l.HasCellar {
+		features = append(features, "Keller")
+	}
+	if l.HasSeparateKitchen {
+		features = append(features, "sep. Küche")
+	}
 	if len(features) > 0 {
 		sb.WriteString(fmt.Sprintf("✨ %s\n", strings.Join(features, ", ")))
 	}
+	if l.RequiresMembership {
+		sb.WriteString("🏘 Genossenschaft (Anteile/Mitgliedschaft nötig)\n")
+	}
 
-	if l.AvailableFrom != "" {
-		sb.WriteString(fmt.Sprintf("📅 Ab %s\n", l.AvailableFrom))
+	if label, date := l.MoveIn(); date != "" {
+		sb.WriteString(fmt.Sprintf("📅 %s %s\n", label, date))
 	}
-	if l.LandlordName != "" {
-		sb.WriteString(fmt.Sprintf("\n👤 %s", l.LandlordName))
-		if l.LandlordType != "" {
-			sb.WriteString(fmt.Sprintf(" (%s)", l.LandlordType))
-		}
+	if l.LandlordName != "" || l.LandlordCompany != "" {
 		sb.WriteString("\n")
+		if l.LandlordName != "" {
+			sb.WriteString(fmt.Sprintf("👤 %s", l.LandlordName))
+			if l.LandlordType != "" && l.LandlordCompany == "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", l.LandlordType))
+			}
+			sb.WriteString("\n")
+		}
+		if l.LandlordCompany != "" {
+			sb.WriteString(fmt.Sprintf("🏢 %s", l.LandlordCompany))
+			if l.LandlordType != "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", l.LandlordType))
+			}
+			sb.WriteString("\n")
+		}
 	}
 
 	if l.URL != "" {
@@ -295,3 +372,16 @@ func onlyDigits(s string) string {
 	}
 	return b.String()
 }
+
+// formatSqmPrice renders a price per m²: with cents for rents ("14,57 €"),
+// rounded with thousands dots for purchases ("4.250 €").
+func formatSqmPrice(v float64) string {
+	if v < 100 {
+		return strings.Replace(fmt.Sprintf("%.2f €", v), ".", ",", 1)
+	}
+	s := strconv.Itoa(int(math.Round(v)))
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "." + s[i:]
+	}
+	return s + " €"
+}
@@ -61,6 +61,25 @@ func TestFormatListingContainsKeyFacts(t *testing.T) {
 	}
 }
 
+func TestFormatListingPurchase(t *testing.T) {
+	got := formatListing(&domain.Listing{Title: "ETW", Price: 350000, MonthlyFees: 280, TotalRent: 437500, RentEstimated: true})
+	for _, want := range []string{"350000 €* Kaufpreis", "280 € Hausgeld/Monat"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatListing missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "warm") || strings.Contains(got, "Kaltmiete") {
+		t.Errorf("purchase listing should not show rent:\n%s", got)
+	}
+}
+
+func TestFormatListingPriceOnRequest(t *testing.T) {
+	got := formatListing(&domain.Listing{Title: "Penthouse", PriceOnRequest: true, Rooms: 4})
+	if !strings.Contains(got, "💬 Preis auf Anfrage") || strings.Contains(got, "Kaltmiete") {
+		t.Errorf("price on request not flagged:\n%s", got)
+	}
+}
+
 func TestDisabledClientIsNoOp(t *testing.T) {
 	c, err := New(context.Background(), config.WhatsAppConfig{Enabled: false}, control.New(nil, nil, control.Defaults{QuietHoursEnabled: true, QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Europe/Berlin"}), nil)
 	if err != nil {
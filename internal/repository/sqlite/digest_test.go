@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestDigestStats(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", City: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	for i, price := range []int{1200, 0, 800, 950, 1100} {
+		l := &domain.Listing{IS24ID: string(rune('a' + i)), Title: "L", URL: "u", Price: price, SearchProfileID: sp.ID}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatalf("CreateListing: %v", err)
+		}
+		repo.LogActivity(ctx, &domain.ActivityLog{Action: domain.ActionListingFound, EntityType: "listing", EntityID: l.ID})
+	}
+	for _, a := range []domain.ActivityLog{
+		{Action: domain.ActionNotificationSent},
+		{Action: domain.ActionNotificationSent},
+		{Action: domain.ActionNotificationSent, Details: "test_mode_preview"},
+		{Action: domain.ActionContactSent},
+		{Action: domain.ActionContactFailed, ErrorMsg: "timeout"},
+		{Action: domain.ActionError, Details: domain.ErrorDetailCookie, ErrorMsg: "3 empty polls"},
+		{Action: domain.ActionError, Details: "poll", ErrorMsg: "boom"},
+	} {
+		if err := repo.LogActivity(ctx, &a); err != nil {
+			t.Fatalf("LogActivity: %v", err)
+		}
+	}
+
+	d, err := repo.GetDigestStats(ctx, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetDigestStats: %v", err)
+	}
+	if d.Found != 5 || d.Notified != 2 || d.Contacted != 1 || d.ContactFailed != 1 {
+		t.Errorf("counts = found %d, notified %d, contacted %d, failed %d; want 5, 2, 1, 1",
+			d.Found, d.Notified, d.Contacted, d.ContactFailed)
+	}
+	if d.Errors != 2 || d.CookieWarnings != 1 || d.LastError != "boom" {
+		t.Errorf("errors = %d, cookie %d, last %q; want 2, 1, \"boom\"", d.Errors, d.CookieWarnings, d.LastError)
+	}
+	var prices []int
+	for _, l := range d.Cheapest {
+		prices = append(prices, l.Price)
+	}
+	if len(prices) != 3 || prices[0] != 800 || prices[1] != 950 || prices[2] != 1100 {
+		t.Errorf("cheapest prices = %v, want [800 950 1100]", prices)
+	}
+}
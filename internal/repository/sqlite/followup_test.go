@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestFollowUpDueListings(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", City: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	l := &domain.Listing{IS24ID: "fu1", Title: "W", URL: "https://x", SearchProfileID: sp.ID, BuildYear: 2000}
+	if err := repo.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkListingContacted(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Just contacted: not due yet.
+	due, err := repo.GetFollowUpDueListings(ctx, 3*24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetFollowUpDueListings: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("fresh contact should not be due, got %d", len(due))
+	}
+
+	// Backdate the contact past the threshold.
+	if _, err := repo.DB().ExecContext(ctx,
+		`UPDATE listings SET contacted_at = datetime('now', '-4 days') WHERE id = ?`, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	due, err = repo.GetFollowUpDueListings(ctx, 3*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].IS24ID != "fu1" || due[0].FollowedUp {
+		t.Fatalf("expected fu1 due, got %+v", due)
+	}
+
+	// Reminds once only.
+	if err := repo.MarkListingFollowedUp(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	due, err = repo.GetFollowUpDueListings(ctx, 3*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("followed-up listing should not be due again, got %d", len(due))
+	}
+	got, err := repo.GetListingByIS24ID(ctx, "fu1")
+	if err != nil || got == nil || !got.FollowedUp {
+		t.Fatalf("FollowedUp not persisted: %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestFunnelStats(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", City: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Three seen: one passes, two are dropped for the same reason.
+	if err := repo.RecordSeenListings(ctx, []SeenListing{
+		{IS24ID: "1", SearchProfileID: sp.ID, Passed: true},
+		{IS24ID: "2", SearchProfileID: sp.ID, DropReason: "price_too_high"},
+		{IS24ID: "3", SearchProfileID: sp.ID, DropReason: "price_too_high"},
+	}); err != nil {
+		t.Fatalf("RecordSeenListings: %v", err)
+	}
+	// Re-sighting must not create a second row; a later pass wins.
+	if err := repo.RecordSeenListing(ctx, "3", sp.ID, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if seen, err := repo.SeenListingIDs(ctx, []string{"1", "3", "9"}); err != nil || len(seen) != 2 || !seen["1"] || !seen["3"] {
+		t.Errorf("SeenListingIDs = %v, %v; want 1 and 3", seen, err)
+	}
+
+	l := &domain.Listing{IS24ID: "1", Title: "W", URL: "https://x", SearchProfileID: sp.ID}
+	if err := repo.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := repo.GetFunnelStats(ctx, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetFunnelStats: %v", err)
+	}
+	if f.Seen != 3 || f.Passed != 2 || f.Notified != 1 || f.Contacted != 0 {
+		t.Errorf("funnel = %+v, want seen=3 passed=2 notified=1 contacted=0", f)
+	}
+	if len(f.DropReasons) != 1 || f.DropReasons[0].Reason != "price_too_high" || f.DropReasons[0].Count != 1 {
+		t.Errorf("drop reasons = %+v", f.DropReasons)
+	}
+}
@@ -0,0 +1,13 @@
+-- Every distinct is24_id the scraper has seen, including listings dropped by
+-- the filters. Feeds the /funnel command (seen → passed → notified →
+-- contacted) so filter settings can be tuned against real traffic.
+CREATE TABLE IF NOT EXISTS seen_listings (
+    is24_id           TEXT PRIMARY KEY,
+    search_profile_id INTEGER,
+    passed            INTEGER NOT NULL DEFAULT 0,
+    drop_reason       TEXT NOT NULL DEFAULT '',
+    first_seen_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_seen_listings_first_seen ON seen_listings (first_seen_at);
@@ -0,0 +1,7 @@
+-- Follow-up reminders: contacted_at records when the contact form was sent,
+-- followed_up marks that the "no reply yet?" reminder went out (once per
+-- listing). Listings contacted before this migration are marked as followed up
+-- so upgrading doesn't fire a burst of stale reminders.
+ALTER TABLE listings ADD COLUMN contacted_at DATETIME;
+ALTER TABLE listings ADD COLUMN followed_up INTEGER NOT NULL DEFAULT 0;
+UPDATE listings SET followed_up = 1 WHERE contacted = 1;
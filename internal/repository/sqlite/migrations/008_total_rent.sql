@@ -0,0 +1,8 @@
+-- Warm-rent budget: parsed Warmmiete / Nebenkosten, the total rent the
+-- MaxTotalRent filter evaluated (estimated = Kaltmiete × factor when neither
+-- was available), and the per-profile budget itself.
+ALTER TABLE listings ADD COLUMN warm_rent INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN service_charge INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN total_rent INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN total_rent_estimated INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN max_total_rent INTEGER;
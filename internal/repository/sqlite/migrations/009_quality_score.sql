@@ -0,0 +1,5 @@
+-- Listing quality score (0-100, see filter.Engine.Score) and the floor plan
+-- signal it uses. Image count comes from image_urls, private vs agent from
+-- landlord_type.
+ALTER TABLE listings ADD COLUMN has_floor_plan INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN quality_score INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Family-relevant features from the expose criteria list (Gäste-WC, Keller,
+-- separate Küche) and the optional per-profile requirements for them.
+ALTER TABLE listings ADD COLUMN has_guest_toilet INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN has_cellar INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN has_separate_kitchen INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN has_guest_toilet INTEGER;
+ALTER TABLE search_profiles ADD COLUMN has_cellar INTEGER;
+ALTER TABLE search_profiles ADD COLUMN has_separate_kitchen INTEGER;
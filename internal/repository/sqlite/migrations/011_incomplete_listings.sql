@@ -0,0 +1,5 @@
+-- Listings saved from sparse search data after the expose fetch failed
+-- (is24.on_expose_failure = retry_next_cycle). They are held back from
+-- notification and contact until a later cycle fetches the expose.
+ALTER TABLE listings ADD COLUMN incomplete INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE listings ADD COLUMN expose_attempts INTEGER NOT NULL DEFAULT 0;
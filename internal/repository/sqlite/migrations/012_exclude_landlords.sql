@@ -0,0 +1,3 @@
+-- Per-profile landlord/agency blocklist (case-insensitive substring match on
+-- the listing's landlord name). Extended at runtime via /block_landlord.
+ALTER TABLE search_profiles ADD COLUMN exclude_landlords TEXT; -- JSON array
@@ -0,0 +1,3 @@
+-- Which message template variant was used (file name; NULL for a single
+-- template given as text, e.g. a dashboard override).
+ALTER TABLE sent_messages ADD COLUMN template_variant TEXT;
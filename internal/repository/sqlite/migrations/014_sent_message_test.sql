@@ -0,0 +1,3 @@
+-- Contacts recorded by contact.dry_run: accounted like real ones, but no form
+-- was submitted.
+ALTER TABLE sent_messages ADD COLUMN test INTEGER NOT NULL DEFAULT 0;
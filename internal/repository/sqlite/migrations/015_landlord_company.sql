@@ -0,0 +1,3 @@
+-- Company of the expose's contact person ("Ansprechpartner"), shown next to
+-- the landlord name and matched by the landlord blocklist.
+ALTER TABLE listings ADD COLUMN landlord_company TEXT;
@@ -0,0 +1,4 @@
+-- Map position of the listing (WGS84) for the maps link in notifications.
+-- NULL when the expose hides the exact address.
+ALTER TABLE listings ADD COLUMN latitude REAL;
+ALTER TABLE listings ADD COLUMN longitude REAL;
@@ -0,0 +1,4 @@
+-- Whether min_rooms / max_rooms are exclusive bounds ("more than 2 rooms" =
+-- 2.5 and up) instead of inclusive ones (the default).
+ALTER TABLE search_profiles ADD COLUMN min_rooms_exclusive INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN max_rooms_exclusive INTEGER NOT NULL DEFAULT 0;
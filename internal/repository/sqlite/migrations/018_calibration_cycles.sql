@@ -0,0 +1,3 @@
+-- Remaining poll cycles in which a profile reports filtered-out listings with
+-- their drop reasons (calibration mode). Counts down to 0 = normal behavior.
+ALTER TABLE search_profiles ADD COLUMN calibration_cycles INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- The listings that passed the filters in a poll cycle (JSON array of
+-- {is24_id, title, price}), so /diff can show what changed between the last
+-- two cycles. Only the newest few are kept.
+CREATE TABLE IF NOT EXISTS poll_snapshots (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    listings   TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Cities a listing may be in besides the profile's city, for search URLs that
+-- cover a metro area (case-insensitive exact match on the listing's city).
+ALTER TABLE search_profiles ADD COLUMN allowed_cities TEXT; -- JSON array
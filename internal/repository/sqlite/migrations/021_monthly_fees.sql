@@ -0,0 +1,4 @@
+-- Purchase listings: monthly Hausgeld/Wohngeld (0 = unknown) and the
+-- per-profile upper limit for it.
+ALTER TABLE listings ADD COLUMN monthly_fees INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN max_monthly_fees INTEGER;
@@ -0,0 +1,3 @@
+-- Temporarily paused search profiles: kept active (and listed) but skipped
+-- by the scheduler until resumed.
+ALTER TABLE search_profiles ADD COLUMN paused INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Listings without a price by design ("Preis auf Anfrage"), as opposed to a
+-- price the parser could not read.
+ALTER TABLE listings ADD COLUMN price_on_request INTEGER NOT NULL DEFAULT 0;
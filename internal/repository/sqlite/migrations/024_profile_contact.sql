@@ -0,0 +1,4 @@
+-- Per-profile phone and e-mail for the contact form, overriding the
+-- campaign's (NULL = use the campaign's).
+ALTER TABLE search_profiles ADD COLUMN contact_phone TEXT;
+ALTER TABLE search_profiles ADD COLUMN contact_email TEXT;
@@ -0,0 +1,5 @@
+-- Price the user last heard about (from the new-listing or the last
+-- price-change message) and the time of that price-change message, for the
+-- price_alerts threshold and cooldown.
+ALTER TABLE listings ADD COLUMN alerted_price INTEGER;
+ALTER TABLE listings ADD COLUMN alerted_price_at DATETIME;
@@ -0,0 +1,4 @@
+-- Genossenschaft listings need shares and membership before moving in;
+-- profiles can drop them with exclude_cooperatives.
+ALTER TABLE listings ADD COLUMN requires_membership INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN exclude_cooperatives INTEGER NOT NULL DEFAULT 0;
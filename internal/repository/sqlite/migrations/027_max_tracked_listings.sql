@@ -0,0 +1,3 @@
+-- Caps how many listings a profile keeps; the scheduler prunes the oldest
+-- uncontacted ones beyond it. 0 = no cap.
+ALTER TABLE search_profiles ADD COLUMN max_tracked_listings INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Per-profile thresholds for auto-contact, stricter than the notify filters:
+-- listings below them are still notified but never contacted. 0 = off.
+ALTER TABLE search_profiles ADD COLUMN contact_min_rooms REAL NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN contact_min_area INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE search_profiles ADD COLUMN contact_max_price INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Qualitative floor type (Erdgeschoss, Dachgeschoss, Souterrain, ...) and the
+-- per-profile list of types to drop.
+ALTER TABLE listings ADD COLUMN floor_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE search_profiles ADD COLUMN exclude_floor_types TEXT;
@@ -0,0 +1,3 @@
+-- Kaufpreis per m² limit for purchase profiles, kept apart from rental
+-- prices (0 = off).
+ALTER TABLE search_profiles ADD COLUMN max_purchase_price_per_sqm REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Whether the heating costs are part of the Nebenkosten.
+ALTER TABLE listings ADD COLUMN heating_included INTEGER; -- nullable boolean
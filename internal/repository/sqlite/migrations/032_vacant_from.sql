@@ -0,0 +1,2 @@
+-- "Bezugsfrei ab", kept apart from available_from ("Verfügbar ab").
+ALTER TABLE listings ADD COLUMN vacant_from TEXT;
@@ -0,0 +1,2 @@
+-- Telegram notifications for this profile's listings arrive without sound.
+ALTER TABLE search_profiles ADD COLUMN silent INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Further postings of the same flat by the same landlord, collapsed into
+-- this listing when it was found.
+ALTER TABLE listings ADD COLUMN duplicates INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Listings ignored via /ignore: never saved, notified or contacted again,
+-- whichever profile finds them.
+CREATE TABLE IF NOT EXISTS ignored_listings (
+    is24_id    TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
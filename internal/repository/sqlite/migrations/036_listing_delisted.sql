@@ -0,0 +1,4 @@
+-- When an expose fetch found the listing removed from IS24 ("Objekt nicht
+-- mehr verfügbar"). Delisted listings are no longer retried, notified or
+-- contacted.
+ALTER TABLE listings ADD COLUMN delisted_at DATETIME;
@@ -0,0 +1,4 @@
+-- When max_tracked_listings pruned the listing. A pruned listing keeps only
+-- a stub (no description or images) so it is never reported as new again,
+-- and its sent_messages stay.
+ALTER TABLE listings ADD COLUMN pruned_at DATETIME;
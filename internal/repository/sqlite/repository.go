@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -12,7 +13,8 @@ import (
 	"time"
 
 	"github.com/julianbeese/immo_bot/internal/domain"
-	_ "modernc.org/sqlite"
+	sqlitedrv "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 //go:embed migrations/*.sql
@@ -22,6 +24,10 @@ var migrationsFS embed.FS
 // successful poll cycle; used by the container health check.
 const MetaLastPollOK = "last_poll_ok"
 
+// MetaLastNewListing is the meta key holding the RFC3339 timestamp of the last
+// poll that found a new listing; used by the no-new-listings alert.
+const MetaLastNewListing = "last_new_listing"
+
 // MetaIS24Cookie is the meta key holding a hot-reloaded IS24 cookie override.
 // If set (non-empty), it takes precedence over IS24_COOKIE at startup; updates
 // happen via the dashboard or the /cookie chat command.
@@ -39,22 +45,58 @@ type Repository struct {
 	db *sql.DB
 }
 
-// New creates a new SQLite repository and runs migrations
+// Options tune the connection pool. Zero values use the defaults.
+type Options struct {
+	BusyTimeout  time.Duration // how long a statement waits for a lock held by another connection
+	MaxOpenConns int           // pool size; WAL lets readers run next to the one writer
+}
+
+const (
+	defaultBusyTimeout  = 5 * time.Second
+	defaultMaxOpenConns = 4
+
+	// busyRetries/busyBackoff retry writes that still find the database
+	// locked after the busy timeout (1×, 2×, 3× the backoff).
+	busyRetries = 3
+	busyBackoff = 100 * time.Millisecond
+)
+
+// New creates a new SQLite repository with the default Options and runs
+// migrations
 func New(dbPath string) (*Repository, error) {
+	return NewWithOptions(dbPath, Options{})
+}
+
+// NewWithOptions creates a new SQLite repository and runs migrations.
+func NewWithOptions(dbPath string, opts Options) (*Repository, error) {
 	// Ensure directory exists
 	dir := filepath.Dir(dbPath)
 	if dir != "" && dir != "." {
 		// Directory creation handled by caller
 	}
+	if opts.BusyTimeout <= 0 {
+		opts.BusyTimeout = defaultBusyTimeout
+	}
+	if opts.MaxOpenConns <= 0 {
+		opts.MaxOpenConns = defaultMaxOpenConns
+	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// Pragmas go into the DSN so every pooled connection gets them, not just
+	// the first one. Transactions start IMMEDIATE: they take the write lock
+	// up front (waiting out the busy timeout) instead of failing with
+	// "database is locked" when upgrading from a read lock.
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		dbPath, opts.BusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxOpenConns)
 
-	// Enable foreign keys and WAL mode
-	if _, err := db.Exec("PRAGMA foreign_keys = ON; PRAGMA journal_mode = WAL;"); err != nil {
-		return nil, fmt.Errorf("enable pragmas: %w", err)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open database: %w", err)
 	}
 
 	repo := &Repository{db: db}
@@ -65,6 +107,60 @@ func New(dbPath string) (*Repository, error) {
 	return repo, nil
 }
 
+// isBusy reports whether err is SQLite's "database is locked" (SQLITE_BUSY,
+// including its extended codes).
+func isBusy(err error) bool {
+	var se *sqlitedrv.Error
+	return errors.As(err, &se) && se.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+// retryBusy runs fn and retries it with a growing backoff while the
+// database stays busy.
+func retryBusy(ctx context.Context, fn func() error) error {
+	err := fn()
+	for i := 1; i <= busyRetries && isBusy(err); i++ {
+		t := time.NewTimer(time.Duration(i) * busyBackoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		err = fn()
+	}
+	return err
+}
+
+// exec is db.ExecContext for writes, retried while the database is busy.
+func (r *Repository) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := retryBusy(ctx, func() error {
+		var err error
+		res, err = r.db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// execReturning runs a write with a RETURNING clause and scans its row into
+// dest, retried while the database is busy.
+func (r *Repository) execReturning(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	return retryBusy(ctx, func() error {
+		return r.db.QueryRowContext(ctx, query, args...).Scan(dest...)
+	})
+}
+
+// beginTx starts a write transaction, retried while the database is busy.
+func (r *Repository) beginTx(ctx context.Context) (*sql.Tx, error) {
+	var tx *sql.Tx
+	err := retryBusy(ctx, func() error {
+		var err error
+		tx, err = r.db.BeginTx(ctx, nil)
+		return err
+	})
+	return tx, err
+}
+
 // Close closes the database connection
 func (r *Repository) Close() error {
 	return r.db.Close()
@@ -130,14 +226,22 @@ func (r *Repository) CreateSearchProfile(ctx context.Context, sp *domain.SearchP
 	districts, _ := json.Marshal(sp.Districts)
 	postalCodes, _ := json.Marshal(sp.PostalCodes)
 	excludeKeywords, _ := json.Marshal(sp.ExcludeKeywords)
+	excludeLandlords, _ := json.Marshal(sp.ExcludeLandlords)
+	allowedCities, _ := json.Marshal(sp.AllowedCities)
+	excludeFloorTypes, _ := json.Marshal(sp.ExcludeFloorTypes)
 
-	result, err := r.db.ExecContext(ctx, `
+	result, err := r.exec(ctx, `
 		INSERT INTO search_profiles (
 			name, city, districts, postal_codes, min_price, max_price,
 			min_rooms, max_rooms, min_area, max_area, has_balcony, has_ebk,
 			has_elevator, pets_allowed, min_build_year, max_build_year,
-			exclude_keywords, search_url, category, active
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			exclude_keywords, search_url, category, active, max_total_rent,
+			has_guest_toilet, has_cellar, has_separate_kitchen, exclude_landlords,
+			min_rooms_exclusive, max_rooms_exclusive, calibration_cycles, allowed_cities,
+			max_monthly_fees, paused, contact_phone, contact_email, exclude_cooperatives,
+			max_tracked_listings, contact_min_rooms, contact_min_area, contact_max_price,
+			exclude_floor_types, max_purchase_price_per_sqm, silent
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		sp.Name, sp.City, string(districts), string(postalCodes),
 		nullableInt(sp.MinPrice), nullableInt(sp.MaxPrice),
@@ -147,6 +251,14 @@ func (r *Repository) CreateSearchProfile(ctx context.Context, sp *domain.SearchP
 		nullableBool(sp.HasElevator), nullableBool(sp.PetsAllowed),
 		nullableInt(sp.MinBuildYear), nullableInt(sp.MaxBuildYear),
 		string(excludeKeywords), sp.SearchURL, nullableString(sp.Category), sp.Active,
+		nullableInt(sp.MaxTotalRent), nullableBool(sp.HasGuestToilet),
+		nullableBool(sp.HasCellar), nullableBool(sp.HasSeparateKitchen),
+		string(excludeLandlords), sp.MinRoomsExclusive, sp.MaxRoomsExclusive,
+		sp.CalibrationCycles, string(allowedCities), nullableInt(sp.MaxMonthlyFees),
+		sp.Paused, nullableString(sp.ContactPhone), nullableString(sp.ContactEmail),
+		sp.ExcludeCooperatives, sp.MaxTrackedListings,
+		sp.ContactMinRooms, sp.ContactMinArea, sp.ContactMaxPrice,
+		string(excludeFloorTypes), sp.MaxPurchasePricePerSqm, sp.Silent,
 	)
 	if err != nil {
 		return err
@@ -168,7 +280,13 @@ func (r *Repository) GetActiveSearchProfiles(ctx context.Context) ([]domain.Sear
 		SELECT id, name, city, districts, postal_codes, min_price, max_price,
 			min_rooms, max_rooms, min_area, max_area, has_balcony, has_ebk,
 			has_elevator, pets_allowed, min_build_year, max_build_year,
-			exclude_keywords, search_url, category, active, created_at, updated_at
+			exclude_keywords, search_url, category, active, max_total_rent,
+			has_guest_toilet, has_cellar, has_separate_kitchen, exclude_landlords,
+			min_rooms_exclusive, max_rooms_exclusive, calibration_cycles,
+			allowed_cities, max_monthly_fees, paused, contact_phone, contact_email,
+			exclude_cooperatives, max_tracked_listings, contact_min_rooms,
+			contact_min_area, contact_max_price, exclude_floor_types,
+			max_purchase_price_per_sqm, silent, created_at, updated_at
 		FROM search_profiles WHERE active = 1
 	`)
 	if err != nil {
@@ -193,7 +311,13 @@ func (r *Repository) ListAllSearchProfiles(ctx context.Context) ([]domain.Search
 		SELECT id, name, city, districts, postal_codes, min_price, max_price,
 			min_rooms, max_rooms, min_area, max_area, has_balcony, has_ebk,
 			has_elevator, pets_allowed, min_build_year, max_build_year,
-			exclude_keywords, search_url, category, active, created_at, updated_at
+			exclude_keywords, search_url, category, active, max_total_rent,
+			has_guest_toilet, has_cellar, has_separate_kitchen, exclude_landlords,
+			min_rooms_exclusive, max_rooms_exclusive, calibration_cycles,
+			allowed_cities, max_monthly_fees, paused, contact_phone, contact_email,
+			exclude_cooperatives, max_tracked_listings, contact_min_rooms,
+			contact_min_area, contact_max_price, exclude_floor_types,
+			max_purchase_price_per_sqm, silent, created_at, updated_at
 		FROM search_profiles ORDER BY active DESC, id
 	`)
 	if err != nil {
@@ -216,7 +340,7 @@ func (r *Repository) ListAllSearchProfiles(ctx context.Context) ([]domain.Search
 // listings are kept but detached (search_profile_id set NULL) to satisfy the
 // foreign key; they fall back to the default campaign in the dashboard.
 func (r *Repository) DeleteSearchProfile(ctx context.Context, id int64) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := r.beginTx(ctx)
 	if err != nil {
 		return err
 	}
@@ -240,18 +364,91 @@ func (r *Repository) DeleteSearchProfile(ctx context.Context, id int64) error {
 	return tx.Commit()
 }
 
+// CountProfileListings returns how many stored listings belong to a search
+// profile and how many of them were already contacted.
+func (r *Repository) CountProfileListings(ctx context.Context, profileID int64) (total, contacted int, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(contacted), 0) FROM listings
+		WHERE search_profile_id = ?
+	`, profileID).Scan(&total, &contacted)
+	return total, contacted, err
+}
+
 // GetSearchProfileByID returns a single search profile (active or not) by ID.
 func (r *Repository) GetSearchProfileByID(ctx context.Context, id int64) (*domain.SearchProfile, error) {
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, name, city, districts, postal_codes, min_price, max_price,
 			min_rooms, max_rooms, min_area, max_area, has_balcony, has_ebk,
 			has_elevator, pets_allowed, min_build_year, max_build_year,
-			exclude_keywords, search_url, category, active, created_at, updated_at
+			exclude_keywords, search_url, category, active, max_total_rent,
+			has_guest_toilet, has_cellar, has_separate_kitchen, exclude_landlords,
+			min_rooms_exclusive, max_rooms_exclusive, calibration_cycles,
+			allowed_cities, max_monthly_fees, paused, contact_phone, contact_email,
+			exclude_cooperatives, max_tracked_listings, contact_min_rooms,
+			contact_min_area, contact_max_price, exclude_floor_types,
+			max_purchase_price_per_sqm, silent, created_at, updated_at
 		FROM search_profiles WHERE id = ?
 	`, id)
 	return scanSearchProfile(row)
 }
 
+// AddExcludedLandlord appends name to the landlord blocklist of every active
+// search profile that does not already contain it (case-insensitive). Returns
+// the number of profiles changed.
+func (r *Repository) AddExcludedLandlord(ctx context.Context, name string) (int, error) {
+	profiles, err := r.GetActiveSearchProfiles(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	changed := 0
+	for _, sp := range profiles {
+		if containsFold(sp.ExcludeLandlords, name) {
+			continue
+		}
+		list, _ := json.Marshal(append(sp.ExcludeLandlords, name))
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE search_profiles SET exclude_landlords = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, string(list), sp.ID); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	return changed, tx.Commit()
+}
+
+// DecrementCalibrationCycles counts down a profile's remaining calibration
+// cycles and returns how many are left.
+func (r *Repository) DecrementCalibrationCycles(ctx context.Context, id int64) (int, error) {
+	var left int
+	err := r.execReturning(ctx, `
+		UPDATE search_profiles SET calibration_cycles = calibration_cycles - 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND calibration_cycles > 0
+		RETURNING calibration_cycles
+	`, []interface{}{id}, &left)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return left, err
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // rowScanner is satisfied by both *sql.Row and *sql.Rows.
 type rowScanner interface {
 	Scan(dest ...interface{}) error
@@ -261,9 +458,11 @@ type rowScanner interface {
 // SELECTs above) into a domain.SearchProfile.
 func scanSearchProfile(s rowScanner) (*domain.SearchProfile, error) {
 	var sp domain.SearchProfile
-	var districts, postalCodes, excludeKeywords, searchURL, category sql.NullString
+	var districts, postalCodes, excludeKeywords, excludeLandlords, allowedCities, searchURL, category sql.NullString
+	var contactPhone, contactEmail, excludeFloorTypes sql.NullString
 	var hasBalcony, hasEBK, hasElevator, petsAllowed sql.NullBool
-	var minPrice, maxPrice, minArea, maxArea, minBuildYear, maxBuildYear sql.NullInt64
+	var hasGuestToilet, hasCellar, hasSeparateKitchen sql.NullBool
+	var minPrice, maxPrice, minArea, maxArea, minBuildYear, maxBuildYear, maxTotalRent, maxMonthlyFees sql.NullInt64
 	var minRooms, maxRooms sql.NullFloat64
 
 	err := s.Scan(
@@ -271,7 +470,13 @@ func scanSearchProfile(s rowScanner) (*domain.SearchProfile, error) {
 		&minPrice, &maxPrice, &minRooms, &maxRooms,
 		&minArea, &maxArea, &hasBalcony, &hasEBK,
 		&hasElevator, &petsAllowed, &minBuildYear, &maxBuildYear,
-		&excludeKeywords, &searchURL, &category, &sp.Active, &sp.CreatedAt, &sp.UpdatedAt,
+		&excludeKeywords, &searchURL, &category, &sp.Active, &maxTotalRent,
+		&hasGuestToilet, &hasCellar, &hasSeparateKitchen, &excludeLandlords,
+		&sp.MinRoomsExclusive, &sp.MaxRoomsExclusive, &sp.CalibrationCycles,
+		&allowedCities, &maxMonthlyFees, &sp.Paused, &contactPhone, &contactEmail,
+		&sp.ExcludeCooperatives, &sp.MaxTrackedListings, &sp.ContactMinRooms,
+		&sp.ContactMinArea, &sp.ContactMaxPrice, &excludeFloorTypes, &sp.MaxPurchasePricePerSqm,
+		&sp.Silent, &sp.CreatedAt, &sp.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -279,6 +484,8 @@ func scanSearchProfile(s rowScanner) (*domain.SearchProfile, error) {
 
 	sp.MinPrice = int(minPrice.Int64)
 	sp.MaxPrice = int(maxPrice.Int64)
+	sp.MaxTotalRent = int(maxTotalRent.Int64)
+	sp.MaxMonthlyFees = int(maxMonthlyFees.Int64)
 	sp.MinRooms = minRooms.Float64
 	sp.MaxRooms = maxRooms.Float64
 	sp.MinArea = int(minArea.Int64)
@@ -287,6 +494,8 @@ func scanSearchProfile(s rowScanner) (*domain.SearchProfile, error) {
 	sp.MaxBuildYear = int(maxBuildYear.Int64)
 	sp.SearchURL = searchURL.String
 	sp.Category = category.String
+	sp.ContactPhone = contactPhone.String
+	sp.ContactEmail = contactEmail.String
 
 	if districts.Valid {
 		json.Unmarshal([]byte(districts.String), &sp.Districts)
@@ -297,10 +506,22 @@ func scanSearchProfile(s rowScanner) (*domain.SearchProfile, error) {
 	if excludeKeywords.Valid {
 		json.Unmarshal([]byte(excludeKeywords.String), &sp.ExcludeKeywords)
 	}
+	if excludeLandlords.Valid {
+		json.Unmarshal([]byte(excludeLandlords.String), &sp.ExcludeLandlords)
+	}
+	if allowedCities.Valid {
+		json.Unmarshal([]byte(allowedCities.String), &sp.AllowedCities)
+	}
+	if excludeFloorTypes.Valid {
+		json.Unmarshal([]byte(excludeFloorTypes.String), &sp.ExcludeFloorTypes)
+	}
 	sp.HasBalcony = nullBoolPtr(hasBalcony)
 	sp.HasEBK = nullBoolPtr(hasEBK)
 	sp.HasElevator = nullBoolPtr(hasElevator)
 	sp.PetsAllowed = nullBoolPtr(petsAllowed)
+	sp.HasGuestToilet = nullBoolPtr(hasGuestToilet)
+	sp.HasCellar = nullBoolPtr(hasCellar)
+	sp.HasSeparateKitchen = nullBoolPtr(hasSeparateKitchen)
 
 	return &sp, nil
 }
@@ -314,13 +535,13 @@ func scanSearchProfile(s rowScanner) (*domain.SearchProfile, error) {
 // of the SQL string literal (paths under our control, but cheap to be safe).
 func (r *Repository) VacuumInto(ctx context.Context, path string) error {
 	quoted := strings.ReplaceAll(path, "'", "''")
-	_, err := r.db.ExecContext(ctx, fmt.Sprintf("VACUUM INTO '%s'", quoted))
+	_, err := r.exec(ctx, fmt.Sprintf("VACUUM INTO '%s'", quoted))
 	return err
 }
 
 // SetMeta upserts a key/value pair in the meta table.
 func (r *Repository) SetMeta(ctx context.Context, key, value string) error {
-	_, err := r.db.ExecContext(ctx,
+	_, err := r.exec(ctx,
 		`INSERT INTO meta (key, value) VALUES (?, ?)
 		 ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
 		key, value)
@@ -339,7 +560,7 @@ func (r *Repository) GetMeta(ctx context.Context, key string) (string, error) {
 
 // SetSearchProfileActive enables or disables a search profile by ID.
 func (r *Repository) SetSearchProfileActive(ctx context.Context, id int64, active bool) error {
-	res, err := r.db.ExecContext(ctx,
+	res, err := r.exec(ctx,
 		`UPDATE search_profiles SET active = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
 		active, id)
 	if err != nil {
@@ -355,32 +576,80 @@ func (r *Repository) SetSearchProfileActive(ctx context.Context, id int64, activ
 	return nil
 }
 
-// Listing methods
+// SetSearchProfilePaused pauses or resumes a search profile by ID. A paused
+// profile stays active but is skipped by the scheduler.
+func (r *Repository) SetSearchProfilePaused(ctx context.Context, id int64, paused bool) error {
+	res, err := r.exec(ctx,
+		`UPDATE search_profiles SET paused = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		paused, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no search profile with id %d", id)
+	}
+	return nil
+}
 
-// CreateListing inserts a new listing if it doesn't exist
-func (r *Repository) CreateListing(ctx context.Context, l *domain.Listing) error {
-	imageURLs, _ := json.Marshal(l.ImageURLs)
+// Listing methods
 
-	result, err := r.db.ExecContext(ctx, `
+// insertListingSQL inserts a listing unless its is24_id is already stored.
+const insertListingSQL = `
 		INSERT OR IGNORE INTO listings (
 			is24_id, title, url, address, city, district, postal_code,
 			price, price_per_sqm, rooms, area, has_balcony, has_ebk,
 			has_elevator, pets_allowed, build_year, available_from,
 			description, landlord_name, landlord_type, image_urls,
-			contact_form_url, search_profile_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
+			contact_form_url, search_profile_id, warm_rent, service_charge,
+			total_rent, total_rent_estimated, has_floor_plan, quality_score,
+			has_guest_toilet, has_cellar, has_separate_kitchen, incomplete,
+			landlord_company, latitude, longitude, monthly_fees, price_on_request,
+			requires_membership, floor_type, heating_included, vacant_from,
+			duplicates
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+// insertListingArgs returns the insertListingSQL arguments for a listing.
+func insertListingArgs(l *domain.Listing) []interface{} {
+	imageURLs, _ := json.Marshal(l.ImageURLs)
+	return []interface{}{
 		l.IS24ID, l.Title, l.URL, l.Address, l.City, l.District, l.PostalCode,
 		l.Price, l.PricePerSqm, l.Rooms, l.Area, l.HasBalcony, l.HasEBK,
 		l.HasElevator, nullableBool(l.PetsAllowed), nullableInt(l.BuildYear),
 		l.AvailableFrom, l.Description, l.LandlordName, l.LandlordType,
-		string(imageURLs), l.ContactFormURL, l.SearchProfileID,
-	)
+		string(imageURLs), l.ContactFormURL, nullableInt64(l.SearchProfileID), l.WarmRent,
+		l.ServiceCharge, l.TotalRent, l.RentEstimated, l.HasFloorPlan,
+		l.QualityScore, l.HasGuestToilet, l.HasCellar, l.HasSeparateKitchen,
+		l.Incomplete, nullableString(l.LandlordCompany),
+		nullableFloat(l.Latitude), nullableFloat(l.Longitude), l.MonthlyFees,
+		l.PriceOnRequest, l.RequiresMembership, l.FloorType,
+		nullableBool(l.HeatingIncluded), nullableString(l.VacantFrom),
+		l.Duplicates,
+	}
+}
+
+// insertedID returns the row ID of an insertListingSQL result, 0 when the
+// listing already existed (LastInsertId would still report an older row).
+func insertedID(result sql.Result) (int64, error) {
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// CreateListing inserts a new listing if it doesn't exist
+func (r *Repository) CreateListing(ctx context.Context, l *domain.Listing) error {
+	result, err := r.exec(ctx, insertListingSQL, insertListingArgs(l)...)
 	if err != nil {
 		return err
 	}
 
-	id, err := result.LastInsertId()
+	id, err := insertedID(result)
 	if err != nil {
 		return err
 	}
@@ -392,40 +661,89 @@ func (r *Repository) CreateListing(ctx context.Context, l *domain.Listing) error
 	return nil
 }
 
-// GetListingByIS24ID retrieves a listing by its IS24 ID
-func (r *Repository) GetListingByIS24ID(ctx context.Context, is24ID string) (*domain.Listing, error) {
-	var l domain.Listing
-	var imageURLs sql.NullString
-	var petsAllowed sql.NullBool
+// CreateListings inserts a cycle's new listings in one transaction, skipping
+// those already stored like CreateListing. Inserted listings get their ID;
+// skipped ones keep ID 0. On error nothing is stored and no ID is set.
+func (r *Repository) CreateListings(ctx context.Context, listings []*domain.Listing) (inserted int, err error) {
+	return r.createListings(ctx, listings, false)
+}
 
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, is24_id, title, url, address, city, district, postal_code,
-			price, price_per_sqm, rooms, area, has_balcony, has_ebk,
-			has_elevator, pets_allowed, build_year, available_from,
-			description, landlord_name, landlord_type, image_urls,
-			contact_form_url, search_profile_id, contacted, notified, skipped,
-			created_at, updated_at
-		FROM listings WHERE is24_id = ?
-	`, is24ID).Scan(
-		&l.ID, &l.IS24ID, &l.Title, &l.URL, &l.Address, &l.City, &l.District,
-		&l.PostalCode, &l.Price, &l.PricePerSqm, &l.Rooms, &l.Area,
-		&l.HasBalcony, &l.HasEBK, &l.HasElevator, &petsAllowed, &l.BuildYear,
-		&l.AvailableFrom, &l.Description, &l.LandlordName, &l.LandlordType,
-		&imageURLs, &l.ContactFormURL, &l.SearchProfileID, &l.Contacted,
-		&l.Notified, &l.Skipped, &l.CreatedAt, &l.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
+// CreateSkippedListings is CreateListings for listings that must never be
+// notified or contacted (collapsed duplicates): they are inserted already
+// marked notified and skipped, in the same transaction.
+func (r *Repository) CreateSkippedListings(ctx context.Context, listings []*domain.Listing) (inserted int, err error) {
+	return r.createListings(ctx, listings, true)
+}
+
+func (r *Repository) createListings(ctx context.Context, listings []*domain.Listing, skipped bool) (inserted int, err error) {
+	if len(listings) == 0 {
+		return 0, nil
 	}
+	tx, err := r.beginTx(ctx)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	if imageURLs.Valid {
-		json.Unmarshal([]byte(imageURLs.String), &l.ImageURLs)
+	stmt, err := tx.PrepareContext(ctx, insertListingSQL)
+	if err != nil {
+		return 0, err
 	}
-	l.PetsAllowed = nullBoolPtr(petsAllowed)
-	return &l, nil
+	defer stmt.Close()
+
+	ids := make([]int64, len(listings))
+	for i, l := range listings {
+		result, err := stmt.ExecContext(ctx, insertListingArgs(l)...)
+		if err != nil {
+			return 0, fmt.Errorf("insert listing %s: %w", l.IS24ID, err)
+		}
+		if ids[i], err = insertedID(result); err != nil {
+			return 0, err
+		}
+		if skipped && ids[i] > 0 {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE listings SET notified = 1, skipped = 1 WHERE id = ?`, ids[i]); err != nil {
+				return 0, fmt.Errorf("mark listing %s skipped: %w", l.IS24ID, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for i, l := range listings {
+		if ids[i] > 0 {
+			l.ID, l.CreatedAt, l.UpdatedAt = ids[i], now, now
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+// GetListingByIS24ID retrieves a listing by its IS24 ID. Returns nil, nil
+// when there is none.
+func (r *Repository) GetListingByIS24ID(ctx context.Context, is24ID string) (*domain.Listing, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+listingColumns+` FROM listings WHERE is24_id = ?`, is24ID)
+	l, err := scanListing(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return l, err
+}
+
+// AveragePricePerSqm returns the mean Kaltmiete (Kaufpreis on purchase
+// profiles) per m² of the listings stored for a search profile, or 0 when none have both price and area.
+func (r *Repository) AveragePricePerSqm(ctx context.Context, profileID int64) (float64, error) {
+	var avg sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT AVG(CAST(price AS REAL) / area) FROM listings
+		WHERE search_profile_id = ? AND price > 0 AND area > 0
+	`, profileID).Scan(&avg)
+	if err != nil {
+		return 0, err
+	}
+	return avg.Float64, nil
 }
 
 // Inbox methods
@@ -450,7 +768,7 @@ func (r *Repository) CreateInboxMessage(ctx context.Context, m *domain.InboxMess
 	if m.ListingID > 0 {
 		listingID = m.ListingID
 	}
-	res, err := r.db.ExecContext(ctx, `
+	res, err := r.exec(ctx, `
 		INSERT OR IGNORE INTO inbox_messages (
 			message_id, from_addr, subject, snippet, is24_id, listing_id,
 			is_landlord_reply, summary, notified, received_at
@@ -512,20 +830,90 @@ func (r *Repository) ListInboxMessages(ctx context.Context, limit int, landlordO
 	return out, rows.Err()
 }
 
-// GetUnnotifiedListings returns listings that haven't been notified
+// GetUnnotifiedListings returns listings that haven't been notified.
+// Incomplete listings wait for their expose retry.
 func (r *Repository) GetUnnotifiedListings(ctx context.Context) ([]domain.Listing, error) {
-	return r.getListingsByCondition(ctx, "notified = 0", "")
+	return r.getListingsByCondition(ctx, "notified = 0 AND incomplete = 0 AND delisted_at IS NULL", "")
 }
 
 // GetUncontactedListings returns listings eligible for auto-contact: notified,
-// not yet contacted, and not manually skipped by the user.
+// complete, not yet contacted, and not manually skipped by the user.
 func (r *Repository) GetUncontactedListings(ctx context.Context) ([]domain.Listing, error) {
-	return r.getListingsByCondition(ctx, "contacted = 0 AND notified = 1 AND skipped = 0", "")
+	return r.getListingsByCondition(ctx, "contacted = 0 AND notified = 1 AND skipped = 0 AND incomplete = 0 AND delisted_at IS NULL", "")
+}
+
+// GetIncompleteListings returns listings whose expose fetch failed and that
+// have had fewer than maxAttempts retries.
+func (r *Repository) GetIncompleteListings(ctx context.Context, maxAttempts int) ([]domain.Listing, error) {
+	return r.getListingsByCondition(ctx,
+		fmt.Sprintf("incomplete = 1 AND expose_attempts < %d AND delisted_at IS NULL", maxAttempts), "")
+}
+
+// MarkListingDelisted records that a listing's expose is gone from IS24.
+func (r *Repository) MarkListingDelisted(ctx context.Context, id int64) error {
+	_, err := r.exec(ctx, `
+		UPDATE listings SET delisted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND delisted_at IS NULL
+	`, id)
+	return err
+}
+
+// RecordExposeAttempt counts a failed expose retry and returns the new total.
+func (r *Repository) RecordExposeAttempt(ctx context.Context, id int64) (int, error) {
+	var attempts int
+	err := r.execReturning(ctx, `
+		UPDATE listings SET expose_attempts = expose_attempts + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? RETURNING expose_attempts
+	`, []interface{}{id}, &attempts)
+	return attempts, err
+}
+
+// UpdateListingDetails overwrites a stored listing's data with re-fetched
+// expose details, including its incomplete flag (used by the expose retry).
+func (r *Repository) UpdateListingDetails(ctx context.Context, l *domain.Listing) error {
+	imageURLs, _ := json.Marshal(l.ImageURLs)
+	_, err := r.exec(ctx, `
+		UPDATE listings SET
+			title = ?, url = ?, address = ?, city = ?, district = ?, postal_code = ?,
+			price = ?, price_per_sqm = ?, rooms = ?, area = ?, has_balcony = ?,
+			has_ebk = ?, has_elevator = ?, pets_allowed = ?, build_year = ?,
+			available_from = ?, description = ?, landlord_name = ?,
+			landlord_type = ?, image_urls = ?, contact_form_url = ?,
+			warm_rent = ?, service_charge = ?, total_rent = ?,
+			total_rent_estimated = ?, has_floor_plan = ?, quality_score = ?,
+			has_guest_toilet = ?, has_cellar = ?, has_separate_kitchen = ?,
+			incomplete = ?, landlord_company = ?, latitude = ?, longitude = ?,
+			monthly_fees = ?, price_on_request = ?, requires_membership = ?,
+			floor_type = ?, heating_included = ?, vacant_from = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`,
+		l.Title, l.URL, l.Address, l.City, l.District, l.PostalCode,
+		l.Price, l.PricePerSqm, l.Rooms, l.Area, l.HasBalcony,
+		l.HasEBK, l.HasElevator, nullableBool(l.PetsAllowed), nullableInt(l.BuildYear),
+		l.AvailableFrom, l.Description, l.LandlordName,
+		l.LandlordType, string(imageURLs), l.ContactFormURL,
+		l.WarmRent, l.ServiceCharge, l.TotalRent,
+		l.RentEstimated, l.HasFloorPlan, l.QualityScore,
+		l.HasGuestToilet, l.HasCellar, l.HasSeparateKitchen, l.Incomplete,
+		nullableString(l.LandlordCompany), nullableFloat(l.Latitude),
+		nullableFloat(l.Longitude), l.MonthlyFees, l.PriceOnRequest,
+		l.RequiresMembership, l.FloorType, nullableBool(l.HeatingIncluded),
+		nullableString(l.VacantFrom), l.ID,
+	)
+	return err
+}
+
+// DeleteListing removes a listing that was never notified, e.g. an incomplete
+// one whose full details no longer pass the filters.
+func (r *Repository) DeleteListing(ctx context.Context, id int64) error {
+	_, err := r.exec(ctx, `DELETE FROM listings WHERE id = ? AND notified = 0`, id)
+	return err
 }
 
 // SetListingSkipped sets/clears the manual skip flag on a listing.
 func (r *Repository) SetListingSkipped(ctx context.Context, id int64, skipped bool) error {
-	res, err := r.db.ExecContext(ctx,
+	res, err := r.exec(ctx,
 		`UPDATE listings SET skipped = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
 		skipped, id)
 	if err != nil {
@@ -556,6 +944,7 @@ func (r *Repository) GetPreviewableListings(ctx context.Context) ([]domain.Listi
 		contacted = 0
 		AND notified = 1
 		AND skipped = 0
+		AND incomplete = 0
 		AND NOT EXISTS (
 			SELECT 1 FROM sent_messages
 			WHERE sent_messages.listing_id = listings.id
@@ -566,14 +955,8 @@ func (r *Repository) GetPreviewableListings(ctx context.Context) ([]domain.Listi
 
 func (r *Repository) getListingsByCondition(ctx context.Context, condition, suffix string) ([]domain.Listing, error) {
 	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
-		SELECT id, is24_id, title, url, address, city, district, postal_code,
-			price, price_per_sqm, rooms, area, has_balcony, has_ebk,
-			has_elevator, pets_allowed, build_year, available_from,
-			description, landlord_name, landlord_type, image_urls,
-			contact_form_url, search_profile_id, contacted, notified, skipped,
-			created_at, updated_at
-		FROM listings WHERE %s ORDER BY created_at DESC %s
-	`, condition, suffix))
+		SELECT %s FROM listings WHERE %s ORDER BY created_at DESC %s
+	`, listingColumns, condition, suffix))
 	if err != nil {
 		return nil, err
 	}
@@ -581,57 +964,336 @@ func (r *Repository) getListingsByCondition(ctx context.Context, condition, suff
 
 	var listings []domain.Listing
 	for rows.Next() {
-		var l domain.Listing
-		var imageURLs, address, city, district, postalCode, availableFrom, description sql.NullString
-		var landlordName, landlordType, contactFormURL sql.NullString
-		var petsAllowed sql.NullBool
-		var buildYear sql.NullInt64
-		var pricePerSqm sql.NullFloat64
-
-		err := rows.Scan(
-			&l.ID, &l.IS24ID, &l.Title, &l.URL, &address, &city, &district,
-			&postalCode, &l.Price, &pricePerSqm, &l.Rooms, &l.Area,
-			&l.HasBalcony, &l.HasEBK, &l.HasElevator, &petsAllowed, &buildYear,
-			&availableFrom, &description, &landlordName, &landlordType,
-			&imageURLs, &contactFormURL, &l.SearchProfileID, &l.Contacted,
-			&l.Notified, &l.Skipped, &l.CreatedAt, &l.UpdatedAt,
-		)
+		l, err := scanListing(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		l.Address = address.String
-		l.City = city.String
-		l.District = district.String
-		l.PostalCode = postalCode.String
-		l.PricePerSqm = pricePerSqm.Float64
-		l.BuildYear = int(buildYear.Int64)
-		l.AvailableFrom = availableFrom.String
-		l.Description = description.String
-		l.LandlordName = landlordName.String
-		l.LandlordType = landlordType.String
-		l.ContactFormURL = contactFormURL.String
-		if imageURLs.Valid {
-			json.Unmarshal([]byte(imageURLs.String), &l.ImageURLs)
-		}
-		l.PetsAllowed = nullBoolPtr(petsAllowed)
-		listings = append(listings, l)
+		listings = append(listings, *l)
 	}
 	return listings, rows.Err()
 }
 
+// listingColumns is the column list scanListing expects, in order.
+const listingColumns = `id, is24_id, title, url, address, city, district, postal_code,
+	price, price_per_sqm, rooms, area, has_balcony, has_ebk,
+	has_elevator, pets_allowed, build_year, available_from,
+	description, landlord_name, landlord_type, image_urls,
+	contact_form_url, search_profile_id, contacted, notified, skipped,
+	followed_up, warm_rent, service_charge, total_rent,
+	total_rent_estimated, has_floor_plan, quality_score,
+	has_guest_toilet, has_cellar, has_separate_kitchen, incomplete,
+	landlord_company, latitude, longitude, monthly_fees, price_on_request,
+	requires_membership, floor_type, heating_included, vacant_from,
+	duplicates, created_at, updated_at`
+
+// scanListing scans one listings row selected with listingColumns.
+func scanListing(s rowScanner) (*domain.Listing, error) {
+	var l domain.Listing
+	var imageURLs, address, city, district, postalCode, availableFrom, vacantFrom, description sql.NullString
+	var landlordName, landlordCompany, landlordType, contactFormURL sql.NullString
+	var petsAllowed, heatingIncluded sql.NullBool
+	var searchProfileID sql.NullInt64
+	var buildYear sql.NullInt64
+	var pricePerSqm, latitude, longitude sql.NullFloat64
+
+	err := s.Scan(
+		&l.ID, &l.IS24ID, &l.Title, &l.URL, &address, &city, &district,
+		&postalCode, &l.Price, &pricePerSqm, &l.Rooms, &l.Area,
+		&l.HasBalcony, &l.HasEBK, &l.HasElevator, &petsAllowed, &buildYear,
+		&availableFrom, &description, &landlordName, &landlordType,
+		&imageURLs, &contactFormURL, &searchProfileID, &l.Contacted,
+		&l.Notified, &l.Skipped, &l.FollowedUp, &l.WarmRent, &l.ServiceCharge,
+		&l.TotalRent, &l.RentEstimated, &l.HasFloorPlan, &l.QualityScore,
+		&l.HasGuestToilet, &l.HasCellar, &l.HasSeparateKitchen, &l.Incomplete,
+		&landlordCompany, &latitude, &longitude, &l.MonthlyFees, &l.PriceOnRequest,
+		&l.RequiresMembership, &l.FloorType, &heatingIncluded, &vacantFrom,
+		&l.Duplicates, &l.CreatedAt, &l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Address = address.String
+	l.City = city.String
+	l.District = district.String
+	l.PostalCode = postalCode.String
+	l.PricePerSqm = pricePerSqm.Float64
+	l.Latitude = latitude.Float64
+	l.Longitude = longitude.Float64
+	l.BuildYear = int(buildYear.Int64)
+	l.AvailableFrom = availableFrom.String
+	l.VacantFrom = vacantFrom.String
+	l.Description = description.String
+	l.LandlordName = landlordName.String
+	l.LandlordCompany = landlordCompany.String
+	l.LandlordType = landlordType.String
+	l.ContactFormURL = contactFormURL.String
+	if imageURLs.Valid {
+		json.Unmarshal([]byte(imageURLs.String), &l.ImageURLs)
+	}
+	l.PetsAllowed = nullBoolPtr(petsAllowed)
+	l.HeatingIncluded = nullBoolPtr(heatingIncluded)
+	l.SearchProfileID = searchProfileID.Int64 // NULL: profile deleted, or saved from /scan
+	return &l, nil
+}
+
 // MarkListingNotified marks a listing as notified
 func (r *Repository) MarkListingNotified(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, `
+	_, err := r.exec(ctx, `
 		UPDATE listings SET notified = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 	`, id)
 	return err
 }
 
-// MarkListingContacted marks a listing as contacted
+// MarkListingContacted marks a listing as contacted and records when, so a
+// follow-up reminder can be scheduled from it.
 func (r *Repository) MarkListingContacted(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE listings SET contacted = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	_, err := r.exec(ctx, `
+		UPDATE listings SET contacted = 1, contacted_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, id)
+	return err
+}
+
+// MarkListingUncontacted clears the contacted flag again (and the follow-up
+// that hangs off it), making the listing eligible for auto-contact.
+func (r *Repository) MarkListingUncontacted(ctx context.Context, id int64) error {
+	_, err := r.exec(ctx, `
+		UPDATE listings SET contacted = 0, contacted_at = NULL, followed_up = 0,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, id)
+	return err
+}
+
+// UpdateListingPrice stores a new Kaltmiete (and €/m²) for a known listing.
+// It returns the listing's ID and previous price; changed is false when the
+// listing is unknown, had no price yet, or the price is unchanged. The first
+// change of a notified listing keeps the price the user was told about as
+// the price-alert baseline.
+func (r *Repository) UpdateListingPrice(ctx context.Context, is24ID string, price int) (id int64, oldPrice int, changed bool, err error) {
+	changes, err := r.UpdateListingPrices(ctx, map[string]int{is24ID: price})
+	if err != nil || len(changes) == 0 {
+		return 0, 0, false, err
+	}
+	return changes[0].ID, changes[0].OldPrice, true, nil
+}
+
+// PriceChange is a stored listing whose price UpdateListingPrices changed.
+type PriceChange struct {
+	ID       int64
+	IS24ID   string
+	OldPrice int
+}
+
+// UpdateListingPrices is UpdateListingPrice for a whole search result
+// (IS24 ID → price): the stored prices are read in one query and only the
+// changed ones are written, all in one transaction. Returns the changes.
+func (r *Repository) UpdateListingPrices(ctx context.Context, prices map[string]int) ([]PriceChange, error) {
+	if len(prices) == 0 {
+		return nil, nil
+	}
+	ids := make([]interface{}, 0, len(prices))
+	for is24ID := range prices {
+		ids = append(ids, is24ID)
+	}
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, is24_id, COALESCE(price, 0), COALESCE(area, 0) FROM listings
+		WHERE is24_id IN (?%s)
+	`, strings.Repeat(", ?", len(ids)-1)), ids...)
+	if err != nil {
+		return nil, err
+	}
+	type stored struct {
+		PriceChange
+		area int
+	}
+	var changed []stored
+	for rows.Next() {
+		var s stored
+		if err := rows.Scan(&s.ID, &s.IS24ID, &s.OldPrice, &s.area); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if s.OldPrice != 0 && s.OldPrice != prices[s.IS24ID] {
+			changed = append(changed, s)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	changes := make([]PriceChange, 0, len(changed))
+	for _, s := range changed {
+		price := prices[s.IS24ID]
+		var pricePerSqm float64
+		if s.area > 0 {
+			pricePerSqm = float64(price) / float64(s.area)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE listings SET
+				alerted_price = CASE WHEN notified = 1 THEN COALESCE(alerted_price, price) ELSE alerted_price END,
+				price = ?, price_per_sqm = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, price, nullableFloat(pricePerSqm), s.ID); err != nil {
+			return nil, fmt.Errorf("update price of listing %s: %w", s.IS24ID, err)
+		}
+		changes = append(changes, s.PriceChange)
+	}
+	return changes, tx.Commit()
+}
+
+// ResetListing removes a listing and its sent messages so the next poll
+// treats it as new. deleted is false when no listing has the IS24 ID.
+func (r *Repository) ResetListing(ctx context.Context, is24ID string) (deleted bool, err error) {
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM sent_messages WHERE listing_id IN (SELECT id FROM listings WHERE is24_id = ?)
+	`, is24ID); err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM listings WHERE is24_id = ?`, is24ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// PruneProfileListings enforces a profile's listing cap: of its listings
+// beyond the keep newest, it prunes the uncontacted ones that were already
+// notified. A pruned listing is cut down to a skipped stub without
+// description and images rather than deleted, so a later search doesn't
+// report it as new again, and its sent_messages are kept. IS24 IDs in
+// current (this cycle's search hits) are left alone. Returns the number of
+// listings pruned.
+func (r *Repository) PruneProfileListings(ctx context.Context, profileID int64, keep int, current map[string]bool) (int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, is24_id FROM (
+			SELECT id, is24_id, contacted, notified,
+				ROW_NUMBER() OVER (ORDER BY created_at DESC, id DESC) AS rn
+			FROM listings WHERE search_profile_id = ? AND pruned_at IS NULL
+		) WHERE rn > ? AND contacted = 0 AND notified = 1
+	`, profileID, keep)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var is24ID string
+		if err := rows.Scan(&id, &is24ID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !current[is24ID] {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE listings SET pruned_at = CURRENT_TIMESTAMP, skipped = 1,
+				description = '', image_urls = '[]', updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), tx.Commit()
+}
+
+// PriceAlertState returns whether a listing was notified, the price the user
+// last heard about (0 if unknown) and the time of the last price-change
+// message (zero if none yet).
+func (r *Repository) PriceAlertState(ctx context.Context, id int64) (notified bool, price int, at time.Time, err error) {
+	var alertedPrice sql.NullInt64
+	var alertedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, `
+		SELECT notified, alerted_price, alerted_price_at FROM listings WHERE id = ?
+	`, id).Scan(&notified, &alertedPrice, &alertedAt)
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+	return notified, int(alertedPrice.Int64), alertedAt.Time, nil
+}
+
+// RecordPriceAlert stores the price a price-change message was just sent for.
+func (r *Repository) RecordPriceAlert(ctx context.Context, id int64, price int) error {
+	_, err := r.exec(ctx, `
+		UPDATE listings SET alerted_price = ?, alerted_price_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, price, id)
+	return err
+}
+
+// notDryRunContacted excludes listings whose only sent messages are dry-run
+// (test) ones: dry-run contacts set contacted too, but nothing reached the
+// landlord.
+const notDryRunContacted = `(
+		EXISTS (SELECT 1 FROM sent_messages WHERE sent_messages.listing_id = listings.id AND sent_messages.test = 0)
+		OR NOT EXISTS (SELECT 1 FROM sent_messages WHERE sent_messages.listing_id = listings.id)
+	)`
+
+// GetFollowUpDueListings returns contacted listings whose contact is at least
+// `after` old and that haven't had a follow-up reminder yet. Skipped listings
+// are excluded (the user already handled them).
+func (r *Repository) GetFollowUpDueListings(ctx context.Context, after time.Duration) ([]domain.Listing, error) {
+	return r.getListingsByCondition(ctx, fmt.Sprintf(`
+		contacted = 1
+		AND %s
+		AND followed_up = 0
+		AND skipped = 0
+		AND contacted_at IS NOT NULL
+		AND contacted_at <= datetime('now', '-%d seconds')
+	`, notDryRunContacted, int64(after.Seconds())), "")
+}
+
+// GetContactedListingsSince returns the listings contacted within the last
+// window that have an address, for the re-contact check.
+func (r *Repository) GetContactedListingsSince(ctx context.Context, window time.Duration) ([]domain.Listing, error) {
+	return r.getListingsByCondition(ctx, fmt.Sprintf(`
+		contacted = 1
+		AND %s
+		AND contacted_at IS NOT NULL
+		AND contacted_at >= datetime('now', '-%d seconds')
+		AND COALESCE(address, '') != ''
+	`, notDryRunContacted, int64(window.Seconds())), "")
+}
+
+// MarkListingFollowedUp records that the follow-up reminder was sent.
+func (r *Repository) MarkListingFollowedUp(ctx context.Context, id int64) error {
+	_, err := r.exec(ctx, `
+		UPDATE listings SET followed_up = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 	`, id)
 	return err
 }
@@ -646,14 +1308,267 @@ func (r *Repository) ListingExists(ctx context.Context, is24ID string) (bool, er
 	return err == nil, err
 }
 
+// ListingIDs returns the is24_ids of all stored listings, for checking many
+// search hits without one ListingExists query each.
+func (r *Repository) ListingIDs(ctx context.Context) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT is24_id FROM listings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// IgnoreListing adds an is24_id to ignored_listings and takes a stored,
+// uncontacted listing with it out of notifications and the contact queue.
+// added is false when it was ignored already.
+func (r *Repository) IgnoreListing(ctx context.Context, is24ID string) (added bool, err error) {
+	res, err := r.exec(ctx, `INSERT OR IGNORE INTO ignored_listings (is24_id) VALUES (?)`, is24ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	_, err = r.exec(ctx, `
+		UPDATE listings SET skipped = 1, notified = 1, updated_at = CURRENT_TIMESTAMP
+		WHERE is24_id = ? AND contacted = 0
+	`, is24ID)
+	return n > 0, err
+}
+
+// IgnoredListingIDs returns the is24_ids added with IgnoreListing.
+func (r *Repository) IgnoredListingIDs(ctx context.Context) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT is24_id FROM ignored_listings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// Seen-listing methods
+
+// RecordSeenListing upserts one search hit into seen_listings. first_seen_at
+// is kept from the first sighting; passed/drop_reason reflect the latest
+// filter verdict so a listing that later passes (e.g. after a price drop) is
+// counted as passed.
+func (r *Repository) RecordSeenListing(ctx context.Context, is24ID string, searchProfileID int64, passed bool, dropReason string) error {
+	return r.RecordSeenListings(ctx, []SeenListing{{
+		IS24ID: is24ID, SearchProfileID: searchProfileID, Passed: passed, DropReason: dropReason,
+	}})
+}
+
+// SeenListing is one search hit's filter verdict for RecordSeenListings.
+type SeenListing struct {
+	IS24ID          string
+	SearchProfileID int64
+	Passed          bool
+	DropReason      string
+}
+
+// RecordSeenListings is RecordSeenListing for a whole search result, in one
+// transaction instead of one write per hit.
+func (r *Repository) RecordSeenListings(ctx context.Context, seen []SeenListing) error {
+	if len(seen) == 0 {
+		return nil
+	}
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO seen_listings (is24_id, search_profile_id, passed, drop_reason)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(is24_id) DO UPDATE SET
+			passed = excluded.passed,
+			drop_reason = excluded.drop_reason,
+			last_seen_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, s := range seen {
+		var profileID any
+		if s.SearchProfileID > 0 {
+			profileID = s.SearchProfileID
+		}
+		if _, err := stmt.ExecContext(ctx, s.IS24ID, profileID, s.Passed, s.DropReason); err != nil {
+			return fmt.Errorf("record seen listing %s: %w", s.IS24ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SeenListingIDs returns which of the given search hits were recorded in
+// seen_listings before, in one query.
+func (r *Repository) SeenListingIDs(ctx context.Context, is24IDs []string) (map[string]bool, error) {
+	seen := make(map[string]bool)
+	if len(is24IDs) == 0 {
+		return seen, nil
+	}
+	args := make([]interface{}, len(is24IDs))
+	for i, id := range is24IDs {
+		args[i] = id
+	}
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT is24_id FROM seen_listings WHERE is24_id IN (?%s)`, strings.Repeat(", ?", len(args)-1)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		seen[id] = true
+	}
+	return seen, rows.Err()
+}
+
+// FunnelStats is the seen → passed → notified → contacted conversion for
+// listings first seen within a time window.
+type FunnelStats struct {
+	Seen        int
+	Passed      int
+	Notified    int
+	Contacted   int
+	DropReasons []DropReasonCount // most frequent first
+}
+
+// DropReasonCount is how many seen listings were dropped for one reason.
+type DropReasonCount struct {
+	Reason string
+	Count  int
+}
+
+// GetFunnelStats returns funnel counts for listings first seen since now-window.
+// Notified/contacted are counted on listings created in the same window.
+func (r *Repository) GetFunnelStats(ctx context.Context, window time.Duration) (*FunnelStats, error) {
+	since := fmt.Sprintf("-%d seconds", int64(window.Seconds()))
+	var f FunnelStats
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(passed), 0)
+		FROM seen_listings WHERE first_seen_at >= datetime('now', ?)
+	`, since).Scan(&f.Seen, &f.Passed); err != nil {
+		return nil, err
+	}
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(notified), 0), COALESCE(SUM(contacted), 0)
+		FROM listings WHERE created_at >= datetime('now', ?)
+	`, since).Scan(&f.Notified, &f.Contacted); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT drop_reason, COUNT(*) AS n
+		FROM seen_listings
+		WHERE first_seen_at >= datetime('now', ?) AND passed = 0 AND drop_reason != ''
+		GROUP BY drop_reason ORDER BY n DESC, drop_reason LIMIT 5
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d DropReasonCount
+		if err := rows.Scan(&d.Reason, &d.Count); err != nil {
+			return nil, err
+		}
+		f.DropReasons = append(f.DropReasons, d)
+	}
+	return &f, rows.Err()
+}
+
+// DigestStats is the activity summary for the daily digest.
+type DigestStats struct {
+	Found          int // listings saved (passed the filters)
+	Notified       int // new-listing notifications (test previews excluded)
+	Contacted      int
+	ContactFailed  int
+	Errors         int
+	CookieWarnings int
+	LastError      string
+	Cheapest       []domain.Listing // cheapest new listings, at most 3
+}
+
+// GetDigestStats summarizes the activity since now-window. Counts come from
+// the activity log, so they reflect when things happened, not when a listing
+// was first saved.
+func (r *Repository) GetDigestStats(ctx context.Context, window time.Duration) (*DigestStats, error) {
+	since := fmt.Sprintf("-%d seconds", int64(window.Seconds()))
+	var d DigestStats
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(CASE WHEN action = ? THEN 1 END),
+			COUNT(CASE WHEN action = ? AND COALESCE(details, '') != 'test_mode_preview' THEN 1 END),
+			COUNT(CASE WHEN action = ? THEN 1 END),
+			COUNT(CASE WHEN action = ? THEN 1 END),
+			COUNT(CASE WHEN action = ? THEN 1 END),
+			COUNT(CASE WHEN action = ? AND details = ? THEN 1 END)
+		FROM activity_log WHERE created_at >= datetime('now', ?)
+	`, domain.ActionListingFound, domain.ActionNotificationSent, domain.ActionContactSent,
+		domain.ActionContactFailed, domain.ActionError, domain.ActionError, domain.ErrorDetailCookie,
+		since).Scan(&d.Found, &d.Notified, &d.Contacted, &d.ContactFailed, &d.Errors, &d.CookieWarnings); err != nil {
+		return nil, err
+	}
+
+	var lastError sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT error_msg FROM activity_log
+		WHERE action = ? AND created_at >= datetime('now', ?) AND COALESCE(error_msg, '') != ''
+		ORDER BY created_at DESC, id DESC LIMIT 1
+	`, domain.ActionError, since).Scan(&lastError)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	d.LastError = lastError.String
+
+	listings, err := r.getListingsByCondition(ctx, fmt.Sprintf(
+		"price > 0 AND created_at >= datetime('now', '%s')", since), "")
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(listings, func(i, j int) bool { return listings[i].Price < listings[j].Price })
+	if len(listings) > 3 {
+		listings = listings[:3]
+	}
+	d.Cheapest = listings
+	return &d, nil
+}
+
 // SentMessage methods
 
 // CreateSentMessage records a sent contact message
 func (r *Repository) CreateSentMessage(ctx context.Context, sm *domain.SentMessage) error {
-	result, err := r.db.ExecContext(ctx, `
-		INSERT INTO sent_messages (listing_id, is24_id, message, status, error_msg, sent_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, sm.ListingID, sm.IS24ID, sm.Message, sm.Status, sm.ErrorMsg, sm.SentAt)
+	result, err := r.exec(ctx, `
+		INSERT INTO sent_messages (listing_id, is24_id, message, template_variant, status, error_msg, sent_at, test)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, sm.ListingID, sm.IS24ID, sm.Message, nullableString(sm.Variant), sm.Status, sm.ErrorMsg, sm.SentAt, sm.Test)
 	if err != nil {
 		return err
 	}
@@ -669,7 +1584,7 @@ func (r *Repository) CreateSentMessage(ctx context.Context, sm *domain.SentMessa
 
 // UpdateSentMessageStatus updates the status of a sent message
 func (r *Repository) UpdateSentMessageStatus(ctx context.Context, id int64, status, errorMsg string) error {
-	_, err := r.db.ExecContext(ctx, `
+	_, err := r.exec(ctx, `
 		UPDATE sent_messages SET status = ?, error_msg = ?, sent_at = CURRENT_TIMESTAMP WHERE id = ?
 	`, status, errorMsg, id)
 	return err
@@ -693,7 +1608,7 @@ func (r *Repository) GetValidSession(ctx context.Context) (*domain.Session, erro
 // SaveSession creates or updates a session
 func (r *Repository) SaveSession(ctx context.Context, s *domain.Session) error {
 	if s.ID == 0 {
-		result, err := r.db.ExecContext(ctx, `
+		result, err := r.exec(ctx, `
 			INSERT INTO sessions (name, cookies, user_agent, valid, expires_at)
 			VALUES (?, ?, ?, ?, ?)
 		`, s.Name, s.Cookies, s.UserAgent, s.Valid, s.ExpiresAt)
@@ -705,7 +1620,7 @@ func (r *Repository) SaveSession(ctx context.Context, s *domain.Session) error {
 		return nil
 	}
 
-	_, err := r.db.ExecContext(ctx, `
+	_, err := r.exec(ctx, `
 		UPDATE sessions SET cookies = ?, user_agent = ?, valid = ?, expires_at = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`, s.Cookies, s.UserAgent, s.Valid, s.ExpiresAt, s.ID)
@@ -716,7 +1631,7 @@ func (r *Repository) SaveSession(ctx context.Context, s *domain.Session) error {
 
 // LogActivity records an activity
 func (r *Repository) LogActivity(ctx context.Context, log *domain.ActivityLog) error {
-	result, err := r.db.ExecContext(ctx, `
+	result, err := r.exec(ctx, `
 		INSERT INTO activity_log (action, entity_type, entity_id, details, error_msg)
 		VALUES (?, ?, ?, ?, ?)
 	`, log.Action, log.EntityType, log.EntityID, log.Details, log.ErrorMsg)
@@ -730,6 +1645,102 @@ func (r *Repository) LogActivity(ctx context.Context, log *domain.ActivityLog) e
 	return nil
 }
 
+// ListListingActivity returns the latest limit activity entries of a
+// listing, oldest first.
+func (r *Repository) ListListingActivity(ctx context.Context, listingID int64, limit int) ([]domain.ActivityLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, action, COALESCE(details, ''), COALESCE(error_msg, ''), created_at
+		FROM activity_log
+		WHERE entity_type = 'listing' AND entity_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, listingID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []domain.ActivityLog
+	for rows.Next() {
+		a := domain.ActivityLog{EntityType: "listing", EntityID: listingID}
+		if err := rows.Scan(&a.ID, &a.Action, &a.Details, &a.ErrorMsg, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+	return logs, nil
+}
+
+// SnapshotListing is one listing in a poll snapshot.
+type SnapshotListing struct {
+	IS24ID string `json:"is24_id"`
+	Title  string `json:"title"`
+	Price  int    `json:"price"`
+}
+
+// PollSnapshot is the set of listings that passed the filters in one cycle.
+type PollSnapshot struct {
+	ID        int64
+	CreatedAt time.Time
+	Listings  []SnapshotListing
+}
+
+// SavePollSnapshot stores a cycle's listings and drops all but the newest
+// keep snapshots.
+func (r *Repository) SavePollSnapshot(ctx context.Context, listings []SnapshotListing, keep int) error {
+	data, err := json.Marshal(listings)
+	if err != nil {
+		return err
+	}
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO poll_snapshots (listings) VALUES (?)`, string(data)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM poll_snapshots
+		WHERE id NOT IN (SELECT id FROM poll_snapshots ORDER BY id DESC LIMIT ?)
+	`, keep); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPollSnapshots returns the newest limit snapshots, newest first.
+func (r *Repository) GetPollSnapshots(ctx context.Context, limit int) ([]PollSnapshot, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, listings, created_at FROM poll_snapshots ORDER BY id DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snaps []PollSnapshot
+	for rows.Next() {
+		var snap PollSnapshot
+		var data string
+		if err := rows.Scan(&snap.ID, &data, &snap.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &snap.Listings); err != nil {
+			return nil, fmt.Errorf("poll snapshot %d: %w", snap.ID, err)
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, rows.Err()
+}
+
 // Helper functions
 
 func nullableInt(v int) interface{} {
@@ -739,6 +1750,13 @@ func nullableInt(v int) interface{} {
 	return v
 }
 
+func nullableInt64(v int64) interface{} {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
 func nullableFloat(v float64) interface{} {
 	if v == 0 {
 		return nil
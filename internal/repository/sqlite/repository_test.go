@@ -2,13 +2,103 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/julianbeese/immo_bot/internal/domain"
 )
 
+func TestBusyTimeoutOnEveryConnection(t *testing.T) {
+	repo, err := NewWithOptions(filepath.Join(t.TempDir(), "test.db"), Options{BusyTimeout: 1234 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	// Hold two connections at once so the second comes from the pool too.
+	ctx := context.Background()
+	c1, err := repo.DB().Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Close()
+	c2, err := repo.DB().Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+	for i, c := range []interface {
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+	}{c1, c2} {
+		var ms int
+		if err := c.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&ms); err != nil {
+			t.Fatal(err)
+		}
+		if ms != 1234 {
+			t.Errorf("conn %d: busy_timeout = %d, want 1234", i, ms)
+		}
+	}
+}
+
+func TestWriteRetriesWhileLocked(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	holder, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Close()
+	// A second process-like handle that gives up waiting quickly, so only
+	// the retry can get the write through.
+	writer, err := NewWithOptions(dbPath, Options{BusyTimeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	ctx := context.Background()
+	tx, err := holder.beginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO meta (key, value) VALUES ('lock', '1')`); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		tx.Commit()
+	}()
+
+	if err := writer.SetMeta(ctx, "k", "v"); err != nil {
+		t.Fatalf("SetMeta while locked: %v", err)
+	}
+	if v, _ := writer.GetMeta(ctx, "k"); v != "v" {
+		t.Errorf("meta k = %q", v)
+	}
+
+	// Writes with RETURNING go through the same retry.
+	l := &domain.Listing{IS24ID: "1", Title: "W", URL: "u1"}
+	if err := holder.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = holder.beginTx(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE meta SET value = '2' WHERE key = 'lock'`); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		tx.Commit()
+	}()
+	if n, err := writer.RecordExposeAttempt(ctx, l.ID); err != nil || n != 1 {
+		t.Fatalf("RecordExposeAttempt while locked = %d, %v", n, err)
+	}
+}
+
 func TestMigrationsAndCategoryRoundTrip(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	repo, err := New(dbPath)
@@ -24,6 +114,12 @@ func TestMigrationsAndCategoryRoundTrip(t *testing.T) {
 		SearchURL: "https://is24.de/Suche/x",
 		Category:  "wg",
 		Active:    true,
+
+		ExcludeCooperatives: true,
+		ExcludeFloorTypes:   []string{"EG", "Souterrain"},
+
+		MaxPurchasePricePerSqm: 5200.5,
+		Silent:                 true,
 	}
 	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
 		t.Fatalf("CreateSearchProfile: %v", err)
@@ -39,9 +135,18 @@ func TestMigrationsAndCategoryRoundTrip(t *testing.T) {
 	if got.Category != "wg" {
 		t.Errorf("category = %q, want wg", got.Category)
 	}
-	if got.SearchURL != sp.SearchURL || got.Name != sp.Name {
+	if got.SearchURL != sp.SearchURL || got.Name != sp.Name || !got.ExcludeCooperatives {
 		t.Errorf("round-trip mismatch: %+v", got)
 	}
+	if len(got.ExcludeFloorTypes) != 2 || got.ExcludeFloorTypes[1] != "Souterrain" {
+		t.Errorf("exclude_floor_types = %v", got.ExcludeFloorTypes)
+	}
+	if got.MaxPurchasePricePerSqm != 5200.5 {
+		t.Errorf("max_purchase_price_per_sqm = %v", got.MaxPurchasePricePerSqm)
+	}
+	if !got.Silent {
+		t.Error("silent not stored")
+	}
 
 	active, err := repo.GetActiveSearchProfiles(ctx)
 	if err != nil {
@@ -126,6 +231,18 @@ func TestListRecentListingsAndProfiles(t *testing.T) {
 		t.Errorf("deactivated profile must not be active, got %d", len(active))
 	}
 
+	// Count before deleting (confirmation prompt).
+	if err := repo.MarkListingContacted(ctx, recent[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	total, contacted, err := repo.CountProfileListings(ctx, sp.ID)
+	if err != nil {
+		t.Fatalf("CountProfileListings: %v", err)
+	}
+	if total != 3 || contacted != 1 {
+		t.Errorf("CountProfileListings = %d/%d, want 3/1", total, contacted)
+	}
+
 	// Delete.
 	if err := repo.DeleteSearchProfile(ctx, sp.ID); err != nil {
 		t.Fatalf("DeleteSearchProfile: %v", err)
@@ -191,6 +308,81 @@ func TestSetSearchProfileActive(t *testing.T) {
 	}
 }
 
+func TestSetSearchProfilePaused(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	repo, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "X", City: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetSearchProfilePaused(ctx, sp.ID, true); err != nil {
+		t.Fatalf("SetSearchProfilePaused: %v", err)
+	}
+	// Paused is not deactivated: still listed as active, marked paused.
+	active, _ := repo.GetActiveSearchProfiles(ctx)
+	if len(active) != 1 || !active[0].Paused {
+		t.Fatalf("paused profile should stay active and be marked paused, got %+v", active)
+	}
+	if err := repo.SetSearchProfilePaused(ctx, sp.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetSearchProfileByID(ctx, sp.ID); got.Paused {
+		t.Error("resumed profile still paused")
+	}
+	if err := repo.SetSearchProfilePaused(ctx, 999, true); err == nil {
+		t.Error("expected error for missing id")
+	}
+}
+
+func TestAddExcludedLandlord(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	repo, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	a := &domain.SearchProfile{Name: "A", City: "Berlin", Active: true, ExcludeLandlords: []string{"Spam Immobilien"},
+		AllowedCities: []string{"Potsdam"}}
+	b := &domain.SearchProfile{Name: "B", City: "Berlin", Active: true}
+	off := &domain.SearchProfile{Name: "Off", City: "Berlin", Active: false}
+	for _, sp := range []*domain.SearchProfile{a, b, off} {
+		if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := repo.AddExcludedLandlord(ctx, "spam immobilien")
+	if err != nil {
+		t.Fatalf("AddExcludedLandlord: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("changed = %d, want 1 (A already blocks it, Off is inactive)", n)
+	}
+	got, _ := repo.GetSearchProfileByID(ctx, b.ID)
+	if len(got.ExcludeLandlords) != 1 || got.ExcludeLandlords[0] != "spam immobilien" {
+		t.Errorf("B landlords = %v", got.ExcludeLandlords)
+	}
+	got, _ = repo.GetSearchProfileByID(ctx, a.ID)
+	if len(got.ExcludeLandlords) != 1 {
+		t.Errorf("A landlords should be unchanged, got %v", got.ExcludeLandlords)
+	}
+	if len(got.AllowedCities) != 1 || got.AllowedCities[0] != "Potsdam" {
+		t.Errorf("A allowed cities = %v", got.AllowedCities)
+	}
+	got, _ = repo.GetSearchProfileByID(ctx, off.ID)
+	if len(got.ExcludeLandlords) != 0 {
+		t.Errorf("inactive profile should be untouched, got %v", got.ExcludeLandlords)
+	}
+}
+
 func TestPreviewableListingsDoNotConsumeContactState(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	repo, err := New(dbPath)
@@ -249,3 +441,407 @@ func TestPreviewableListingsDoNotConsumeContactState(t *testing.T) {
 		t.Fatalf("preview must not mark listing contacted, got %d uncontacted", len(uncontacted))
 	}
 }
+
+func TestMarkListingUncontacted(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "X", City: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	listing := &domain.Listing{IS24ID: "123", Title: "Wohnung", URL: "https://is24.de/expose/123", SearchProfileID: sp.ID}
+	if err := repo.CreateListing(ctx, listing); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.MarkListingContacted(ctx, listing.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetListingByIS24ID(ctx, "123")
+	if err != nil || !got.Contacted {
+		t.Fatalf("expected contacted, got %+v (err %v)", got, err)
+	}
+
+	if err := repo.MarkListingUncontacted(ctx, listing.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetListingByIS24ID(ctx, "123")
+	if err != nil || got.Contacted {
+		t.Fatalf("expected uncontacted, got %+v (err %v)", got, err)
+	}
+}
+
+func TestDryRunContactsSkipFollowUpAndRecontactCheck(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "X", City: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		id   string
+		test bool
+	}{{"1", false}, {"2", true}} {
+		l := &domain.Listing{IS24ID: tc.id, Title: "Wohnung", URL: "u" + tc.id, Address: "Hauptstr. " + tc.id, SearchProfileID: sp.ID}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingContacted(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.CreateSentMessage(ctx, &domain.SentMessage{
+			ListingID: l.ID, IS24ID: tc.id, Message: "Hallo", Status: domain.MessageStatusSent, SentAt: time.Now(), Test: tc.test,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	due, err := repo.GetFollowUpDueListings(ctx, 0)
+	if err != nil || len(due) != 1 || due[0].IS24ID != "1" {
+		t.Errorf("GetFollowUpDueListings = %+v, %v; want only the real contact", due, err)
+	}
+	recent, err := repo.GetContactedListingsSince(ctx, time.Hour)
+	if err != nil || len(recent) != 1 || recent[0].IS24ID != "1" {
+		t.Errorf("GetContactedListingsSince = %+v, %v; want only the real contact", recent, err)
+	}
+}
+
+func TestCreateListingsBatch(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	existing := &domain.Listing{IS24ID: "1", Title: "Alt", URL: "u1"}
+	if err := repo.CreateListing(ctx, existing); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := []*domain.Listing{
+		{IS24ID: "2", Title: "Neu A", URL: "u2"},
+		{IS24ID: "1", Title: "Alt, erneut gefunden", URL: "u1"},
+		{IS24ID: "3", Title: "Neu B", URL: "u3", FloorType: "Dachgeschoss"},
+	}
+	inserted, err := repo.CreateListings(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != 2 || batch[0].ID == 0 || batch[2].ID == 0 || batch[0].ID == batch[2].ID {
+		t.Errorf("inserted %d, IDs %d/%d; want 2 distinct new IDs", inserted, batch[0].ID, batch[2].ID)
+	}
+	if batch[1].ID != 0 {
+		t.Errorf("already stored listing got ID %d, want 0", batch[1].ID)
+	}
+	got, err := repo.GetListingByIS24ID(ctx, "1")
+	if err != nil || got.ID != existing.ID || got.Title != "Alt" {
+		t.Errorf("stored listing = %+v (err %v), want the original", got, err)
+	}
+
+	if got, err := repo.GetListingByIS24ID(ctx, "3"); err != nil || got.FloorType != "Dachgeschoss" {
+		t.Errorf("floor type = %+v (err %v)", got, err)
+	}
+
+	again := &domain.Listing{IS24ID: "3", Title: "Neu B", URL: "u3"}
+	if err := repo.CreateListing(ctx, again); err != nil || again.ID != 0 {
+		t.Errorf("CreateListing of a stored listing: ID %d (err %v), want 0", again.ID, err)
+	}
+}
+
+func TestCreateSkippedListings(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	existing := &domain.Listing{IS24ID: "1", Title: "Alt", URL: "u1"}
+	if err := repo.CreateListing(ctx, existing); err != nil {
+		t.Fatal(err)
+	}
+	batch := []*domain.Listing{
+		{IS24ID: "1", Title: "Alt", URL: "u1"},
+		{IS24ID: "2", Title: "Repost", URL: "u2"},
+	}
+	if inserted, err := repo.CreateSkippedListings(ctx, batch); err != nil || inserted != 1 {
+		t.Fatalf("inserted %d (err %v), want 1", inserted, err)
+	}
+	if got, _ := repo.GetListingByIS24ID(ctx, "2"); got == nil || !got.Notified || !got.Skipped {
+		t.Errorf("twin = %+v, want notified and skipped", got)
+	}
+	if got, _ := repo.GetListingByIS24ID(ctx, "1"); got == nil || got.Notified || got.Skipped {
+		t.Errorf("already stored listing = %+v, want it untouched", got)
+	}
+}
+
+func TestResetListing(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	listing := &domain.Listing{IS24ID: "123", Title: "Wohnung", URL: "https://is24.de/expose/123"}
+	if err := repo.CreateListing(ctx, listing); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateSentMessage(ctx, &domain.SentMessage{ListingID: listing.ID, IS24ID: "123", Message: "Hallo", Status: "sent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted, err := repo.ResetListing(ctx, "123"); err != nil || !deleted {
+		t.Fatalf("ResetListing = %v, %v; want true", deleted, err)
+	}
+	if exists, err := repo.ListingExists(ctx, "123"); err != nil || exists {
+		t.Errorf("listing still exists (err %v)", err)
+	}
+	var n int
+	if err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sent_messages`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("sent_messages = %d (err %v), want 0", n, err)
+	}
+	if deleted, err := repo.ResetListing(ctx, "123"); err != nil || deleted {
+		t.Errorf("second ResetListing = %v, %v; want false", deleted, err)
+	}
+}
+
+func TestPruneProfileListings(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "breit", City: "Berlin", Active: true, MaxTrackedListings: 2}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	// 1 oldest … 6 newest. 2 is contacted, 3 still in the search results,
+	// 4 not notified yet; 5 and 6 are within the cap.
+	var oldest int64
+	for i := 1; i <= 6; i++ {
+		id := strconv.Itoa(i)
+		l := &domain.Listing{IS24ID: id, Title: "Wohnung " + id, URL: "u" + id, SearchProfileID: sp.ID}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.db.ExecContext(ctx, `
+			UPDATE listings SET created_at = datetime('now', ?), notified = ?, contacted = ? WHERE id = ?
+		`, fmt.Sprintf("-%d hours", 10-i), i != 4, i == 2, l.ID); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			oldest = l.ID
+		}
+	}
+	if err := repo.CreateSentMessage(ctx, &domain.SentMessage{ListingID: oldest, IS24ID: "1", Message: "x", Status: "failed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := repo.PruneProfileListings(ctx, sp.ID, sp.MaxTrackedListings, map[string]bool{"3": true})
+	if err != nil || n != 1 {
+		t.Fatalf("PruneProfileListings = %d, %v; want 1", n, err)
+	}
+	pruned := func(id string) bool {
+		var at sql.NullTime
+		if err := repo.db.QueryRowContext(ctx, `SELECT pruned_at FROM listings WHERE is24_id = ?`, id).Scan(&at); err != nil {
+			t.Fatal(err)
+		}
+		return at.Valid
+	}
+	for id, want := range map[string]bool{"1": true, "2": false, "3": false, "4": false, "5": false, "6": false} {
+		if got := pruned(id); got != want {
+			t.Errorf("listing %s pruned = %v, want %v", id, got, want)
+		}
+	}
+	l1, err := repo.GetListingByIS24ID(ctx, "1")
+	if err != nil || !l1.Skipped || l1.Description != "" || len(l1.ImageURLs) != 0 {
+		t.Errorf("pruned listing = %+v (err %v), want a skipped stub", l1, err)
+	}
+	var msgs int
+	if err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sent_messages WHERE listing_id = ?`, oldest).Scan(&msgs); err != nil || msgs != 1 {
+		t.Errorf("sent messages of the pruned listing = %d (err %v), want kept", msgs, err)
+	}
+	again := &domain.Listing{IS24ID: "1", Title: "Wohnung 1", URL: "u1", SearchProfileID: sp.ID}
+	if err := repo.CreateListing(ctx, again); err != nil || again.ID != 0 {
+		t.Errorf("pruned listing found again: ID %d (err %v), want it still known", again.ID, err)
+	}
+	if n, err := repo.PruneProfileListings(ctx, sp.ID, sp.MaxTrackedListings, nil); err != nil || n != 1 {
+		t.Errorf("second prune = %d, %v; want 1 (listing 3 left the results)", n, err)
+	}
+	got, err := repo.GetSearchProfileByID(ctx, sp.ID)
+	if err != nil || got.MaxTrackedListings != 2 {
+		t.Errorf("MaxTrackedListings = %+v, %v", got, err)
+	}
+}
+
+func TestUpdateListingPriceAndActivity(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	listing := &domain.Listing{IS24ID: "123", Title: "Wohnung", URL: "u", Price: 1200, Area: 60}
+	if err := repo.CreateListing(ctx, listing); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, changed, err := repo.UpdateListingPrice(ctx, "123", 1200); err != nil || changed {
+		t.Errorf("same price: changed=%v err=%v", changed, err)
+	}
+	if _, _, changed, err := repo.UpdateListingPrice(ctx, "999", 800); err != nil || changed {
+		t.Errorf("unknown listing: changed=%v err=%v", changed, err)
+	}
+	id, old, changed, err := repo.UpdateListingPrice(ctx, "123", 1050)
+	if err != nil || !changed || id != listing.ID || old != 1200 {
+		t.Fatalf("price change: id=%d old=%d changed=%v err=%v", id, old, changed, err)
+	}
+	got, err := repo.GetListingByIS24ID(ctx, "123")
+	if err != nil || got.Price != 1050 || got.PricePerSqm != 17.5 {
+		t.Fatalf("stored listing = %+v (err %v)", got, err)
+	}
+
+	for _, a := range []string{domain.ActionListingFound, domain.ActionPriceChanged, domain.ActionMarkedContacted} {
+		if err := repo.LogActivity(ctx, &domain.ActivityLog{Action: a, EntityType: "listing", EntityID: listing.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.LogActivity(ctx, &domain.ActivityLog{Action: domain.ActionError, EntityType: "listing", EntityID: listing.ID + 1}); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := repo.ListListingActivity(ctx, listing.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Action != domain.ActionPriceChanged || logs[1].Action != domain.ActionMarkedContacted {
+		t.Errorf("activity = %+v, want the latest two oldest first", logs)
+	}
+}
+
+func TestUpdateListingPrices(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for i, price := range []int{1000, 800, 0} {
+		id := strconv.Itoa(i + 1)
+		if err := repo.CreateListing(ctx, &domain.Listing{IS24ID: id, Title: "W", URL: "u" + id, Price: price}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changes, err := repo.UpdateListingPrices(ctx, map[string]int{"1": 950, "2": 800, "3": 700, "9": 500})
+	if err != nil || len(changes) != 1 || changes[0].IS24ID != "1" || changes[0].OldPrice != 1000 {
+		t.Fatalf("UpdateListingPrices = %+v, %v; want only listing 1 changed from 1000", changes, err)
+	}
+	if got, err := repo.GetListingByIS24ID(ctx, "1"); err != nil || got.Price != 950 {
+		t.Errorf("listing 1 = %+v (err %v), want price 950", got, err)
+	}
+	if got, err := repo.GetListingByIS24ID(ctx, "3"); err != nil || got.Price != 0 {
+		t.Errorf("listing 3 = %+v (err %v), want no price set", got, err)
+	}
+}
+
+func TestPollSnapshotsKeepNewest(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for _, price := range []int{900, 950, 1000} {
+		if err := repo.SavePollSnapshot(ctx, []SnapshotListing{{IS24ID: "1", Title: "Whg", Price: price}}, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snaps, err := repo.GetPollSnapshots(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("%d snapshots kept, want 2", len(snaps))
+	}
+	if snaps[0].Listings[0].Price != 1000 || snaps[1].Listings[0].Price != 950 {
+		t.Errorf("snapshots = %+v, want newest first", snaps)
+	}
+}
+
+func TestListingHeatingAndVacantFromRoundTrip(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	no := false
+	l := &domain.Listing{IS24ID: "1", Title: "Whg", URL: "u1", HeatingIncluded: &no, VacantFrom: "vermietet"}
+	if err := repo.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetListingByIS24ID(ctx, "1")
+	if err != nil || got.HeatingIncluded == nil || *got.HeatingIncluded {
+		t.Fatalf("heating_included = %v (err %v), want false", got.HeatingIncluded, err)
+	}
+	if got.VacantFrom != "vermietet" {
+		t.Errorf("vacant_from = %q", got.VacantFrom)
+	}
+
+	got.HeatingIncluded = nil
+	if err := repo.UpdateListingDetails(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = repo.GetListingByIS24ID(ctx, "1"); got.HeatingIncluded != nil {
+		t.Errorf("heating_included = %v after update, want unknown", *got.HeatingIncluded)
+	}
+}
+
+func TestIgnoreListing(t *testing.T) {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	l := &domain.Listing{IS24ID: "77", Title: "Scam", URL: "u77"}
+	if err := repo.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := repo.IgnoreListing(ctx, "77"); err != nil || !added {
+		t.Fatalf("IgnoreListing = %v, %v", added, err)
+	}
+	if added, _ := repo.IgnoreListing(ctx, "77"); added {
+		t.Error("second IgnoreListing should report it as known")
+	}
+	if _, err := repo.IgnoreListing(ctx, "78"); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.IgnoredListingIDs(ctx)
+	if err != nil || len(ids) != 2 || !ids["77"] || !ids["78"] {
+		t.Errorf("IgnoredListingIDs = %v, %v", ids, err)
+	}
+	got, _ := repo.GetListingByIS24ID(ctx, "77")
+	if !got.Skipped || !got.Notified {
+		t.Errorf("stored listing skipped=%v notified=%v, want both", got.Skipped, got.Notified)
+	}
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+var addressPostalCodeRe = regexp.MustCompile(`\b\d{4,5}\b`)
+
+// addressKey identifies the building a listing is in: postal code, street
+// and house number, normalized so "Musterstraße 12a" and "Musterstr. 12 A"
+// match. "" when street or house number is unknown; IS24 often hides them
+// until contact, and "10115 Berlin" alone must not make listings duplicates.
+func addressKey(l *domain.Listing) string {
+	street, rest, _ := strings.Cut(l.Address, ",")
+	street = strings.ToLower(strings.TrimSpace(street))
+	first, _ := utf8.DecodeRuneInString(street)
+	if !unicode.IsLetter(first) || !strings.ContainsAny(street, "0123456789") {
+		return ""
+	}
+	street = strings.ReplaceAll(street, "ß", "ss")
+	street = strings.ReplaceAll(street, "strasse", "str")
+	street = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, street)
+
+	area := l.PostalCode
+	if area == "" {
+		area = addressPostalCodeRe.FindString(rest)
+	}
+	if area == "" {
+		area = strings.ToLower(strings.TrimSpace(l.City))
+	}
+	if area == "" {
+		return ""
+	}
+	return area + "|" + street
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// duplicateKey identifies one flat posted several times by the same
+// landlord: agencies repost it under new titles for visibility. Same
+// landlord (company, else contact name), price, rooms and area. "" when the
+// landlord or core data is unknown; without them listings never match.
+func duplicateKey(l *domain.Listing) string {
+	landlord := l.LandlordCompany
+	if landlord == "" {
+		landlord = l.LandlordName
+	}
+	landlord = strings.ToLower(strings.Join(strings.Fields(landlord), " "))
+	if landlord == "" || l.Incomplete || !l.HasCoreData() {
+		return ""
+	}
+	return fmt.Sprintf("%s|%d|%g|%d", landlord, l.Price, l.Rooms, l.Area)
+}
+
+// collapseDuplicates keeps one listing per duplicateKey within a search's new
+// listings: the best scored, the first on ties. The keeper counts the others
+// in Duplicates; they are returned as twins, in order.
+func collapseDuplicates(listings []*domain.Listing) (keep, twins []*domain.Listing) {
+	best := make(map[string]*domain.Listing)
+	for _, l := range listings {
+		key := duplicateKey(l)
+		if key == "" {
+			continue
+		}
+		if b, ok := best[key]; !ok || l.QualityScore > b.QualityScore {
+			best[key] = l
+		}
+	}
+	for _, l := range listings {
+		b, ok := best[duplicateKey(l)]
+		if !ok || b == l {
+			keep = append(keep, l)
+			continue
+		}
+		b.Duplicates++
+		twins = append(twins, l)
+	}
+	return keep, twins
+}
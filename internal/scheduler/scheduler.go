@@ -2,11 +2,19 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/julianbeese/immo_bot/internal/antidetect"
 	"github.com/julianbeese/immo_bot/internal/config"
 	"github.com/julianbeese/immo_bot/internal/contact"
 	"github.com/julianbeese/immo_bot/internal/domain"
@@ -18,7 +26,7 @@ import (
 
 // IS24Client interface for scraping
 type IS24Client interface {
-	Search(ctx context.Context, profile *domain.SearchProfile) ([]domain.Listing, error)
+	Search(ctx context.Context, profile *domain.SearchProfile) (*domain.SearchResult, error)
 	FetchExpose(ctx context.Context, is24ID string) (*domain.Listing, error)
 	// SetCookie applies a new IS24 session cookie at runtime so cookies can be
 	// rotated without restarting the bot. Implementations may return errors
@@ -31,13 +39,20 @@ type IS24Client interface {
 type Notifier interface {
 	NotifyNewListing(ctx context.Context, l *domain.Listing) error
 	NotifyContactSent(ctx context.Context, l *domain.Listing) error
-	NotifyContactFailed(ctx context.Context, l *domain.Listing, errMsg string) error
+	NotifyContactFailed(ctx context.Context, l *domain.Listing, f domain.ContactFailure) error
+	NotifyContactUnconfirmed(ctx context.Context, l *domain.Listing) error
 	NotifyError(ctx context.Context, errMsg string) error
 	NotifyMessagePreview(ctx context.Context, l *domain.Listing, message string) error
 	SendRawMessage(ctx context.Context, text string) error
 	IsEnabled() bool
 }
 
+// ContactSubmitter submits a contact form for a listing. Implemented by
+// *contact.Submitter and, for contact.dry_run, *contact.NoopSubmitter.
+type ContactSubmitter interface {
+	Submit(ctx context.Context, listing *domain.Listing, message string, profile contact.Profile) error
+}
+
 // Scheduler coordinates the search, filter, notify, contact workflow
 type Scheduler struct {
 	cfg       *config.Config
@@ -47,14 +62,14 @@ type Scheduler struct {
 	notifier  Notifier
 	campaigns CampaignResolver
 	enhancer  MessageEnhancer
-	contacter *contact.Submitter
+	contacter ContactSubmitter
 	emailMon  *email.Monitor // optional inbox monitor (nil = disabled)
 	logger    *slog.Logger
 
 	// Callbacks to check contact mode
 	isAutoContactEnabled func() bool
 	isTestModeEnabled    func() bool
-	isNotifyEnabled      func() bool // false (mode=off) suppresses new-listing notifications
+	isNotifyEnabled      func() bool  // false (mode=off) suppresses new-listing notifications
 	isQuietHoursEnabled  func() *bool // nil = use config, non-nil = override
 	// Returns true if the given time falls inside the active quiet-hours
 	// window. When nil, the scheduler falls back to cfg.IsWithinQuietHours.
@@ -62,15 +77,51 @@ type Scheduler struct {
 
 	mu      sync.Mutex
 	running bool
-	stopCh  chan struct{}
-	doneCh  chan struct{}
+	// pollMu is held for the duration of a poll cycle so scheduled and
+	// on-demand (/poll) cycles never overlap.
+	pollMu sync.Mutex
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	// Listings that passed the filters in the running cycle, saved as its
+	// poll snapshot for /diff. nil outside pollLocked; guarded by pollMu.
+	snapshot map[string]sqlite.SnapshotListing
+
+	// is24_ids of the stored listings, loaded once per cycle so search hits
+	// need no ListingExists query each. nil outside pollLocked (or when the
+	// load failed); guarded by pollMu.
+	known map[string]bool
+
+	// is24_ids from filter.ignore_listing_ids and /ignore, loaded once per
+	// cycle; guarded by pollMu.
+	ignored map[string]bool
+
+	// IDs of profiles whose search was cut off by the page limit and that
+	// were already reported; guarded by pollMu.
+	truncated map[int64]bool
+
+	// Unknown listings of the last /scan, kept for SaveScan. Guarded by mu.
+	lastScan []domain.Listing
 
 	// Cookie-health tracking: consecutive polls where every search returned
 	// nothing usually means the IS24 cookie expired.
 	emptyPolls  int
 	cookieAlert bool
+
+	// No-new-listings alert: time of the last find (zero = not loaded yet)
+	// and whether the alert for the current dry spell was sent. Guarded by pollMu.
+	lastNewAt    time.Time
+	silenceAlert bool
 }
 
+// ErrPollInProgress is returned when a poll is requested while another cycle
+// is still running.
+var ErrPollInProgress = errors.New("poll already in progress")
+
+// exposeRetryLimit is how many cycles an incomplete listing's expose is
+// re-fetched before giving up on it.
+const exposeRetryLimit = 5
+
 // cookieWarnThreshold is the number of consecutive empty/failed polls before
 // warning that the IS24 cookie likely expired.
 const cookieWarnThreshold = 3
@@ -108,7 +159,7 @@ func NewScheduler(
 	notifier Notifier,
 	campaigns CampaignResolver,
 	enhancer MessageEnhancer,
-	contacter *contact.Submitter,
+	contacter ContactSubmitter,
 	logger *slog.Logger,
 ) *Scheduler {
 	return &Scheduler{
@@ -206,7 +257,8 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler and waits for the running poll cycle to finish.
+// Cancel the context passed to Start first to cut the cycle short.
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	if !s.running {
@@ -218,25 +270,81 @@ func (s *Scheduler) Stop() {
 	s.mu.Unlock()
 
 	<-s.doneCh
+	// Wait for an on-demand (/poll) cycle too, so no contact is left half
+	// recorded when the process exits. Closing stopCh above already cancelled
+	// its context (see untilStop), so this only waits for it to wind down.
+	s.pollMu.Lock()
+	s.pollMu.Unlock()
 }
 
-// RunOnce performs a single poll cycle (useful for testing)
+// RunOnce performs a single poll cycle (useful for testing). Returns
+// ErrPollInProgress if another cycle is running.
 func (s *Scheduler) RunOnce(ctx context.Context) error {
-	return s.poll(ctx)
+	_, err := s.poll(ctx)
+	return err
+}
+
+// untilStop derives a context for an on-demand cycle that is also cancelled
+// by Stop, so a /poll, /scan or /selftest started with a background context
+// cannot keep Stop waiting on pollMu.
+func (s *Scheduler) untilStop(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	stop := s.stopCh
+	s.mu.Unlock()
+	if stop != nil {
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
+}
+
+// TriggerPoll starts an out-of-band poll cycle in the background (the /poll
+// command). Returns false without starting anything if a cycle is already
+// running. done, if non-nil, receives the number of newly saved listings once
+// the cycle finishes.
+func (s *Scheduler) TriggerPoll(ctx context.Context, done func(newListings int, err error)) bool {
+	if !s.pollMu.TryLock() {
+		return false
+	}
+	ctx, cancel := s.untilStop(ctx)
+	go func() {
+		defer s.pollMu.Unlock()
+		defer cancel()
+		n, err := s.pollLocked(ctx)
+		if done != nil {
+			done(n, err)
+		}
+	}()
+	return true
 }
 
 func (s *Scheduler) run(ctx context.Context) {
 	defer close(s.doneCh)
 
-	// Run immediately on start
-	if err := s.poll(ctx); err != nil {
-		s.logger.Error("poll failed", "error", err)
-		s.notifyError(ctx, err)
+	// Let a fresh start settle before the first search; 0 = poll right away.
+	if !s.settle(ctx) {
+		return
 	}
+	s.scheduledPoll(ctx)
 
 	ticker := time.NewTicker(s.cfg.PollInterval)
 	defer ticker.Stop()
 
+	// Daily digest; a nil channel never fires when it is disabled.
+	var digestTimer *time.Timer
+	var digestC <-chan time.Time
+	if s.cfg.Digest.Enabled {
+		digestTimer = time.NewTimer(time.Until(s.cfg.NextDigestAt(time.Now())))
+		defer digestTimer.Stop()
+		digestC = digestTimer.C
+	}
+
 	for {
 		select {
 		case <-s.stopCh:
@@ -244,15 +352,203 @@ func (s *Scheduler) run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := s.poll(ctx); err != nil {
-				s.logger.Error("poll failed", "error", err)
-				s.notifyError(ctx, err)
+			s.scheduledPoll(ctx)
+		case <-digestC:
+			s.sendDigest(ctx)
+			digestTimer.Reset(time.Until(s.cfg.NextDigestAt(time.Now())))
+		}
+	}
+}
+
+// scheduledPoll runs one ticker-driven cycle. A tick that lands while an
+// on-demand poll is still running is skipped silently.
+func (s *Scheduler) scheduledPoll(ctx context.Context) {
+	if _, err := s.poll(ctx); err != nil {
+		if errors.Is(err, ErrPollInProgress) {
+			s.logger.Info("poll skipped, previous cycle still running")
+			return
+		}
+		s.logger.Error("poll failed", "error", err)
+		s.notifyError(ctx, err)
+	}
+}
+
+// poll runs one cycle unless another is in progress. Returns the number of
+// newly saved listings.
+func (s *Scheduler) poll(ctx context.Context) (int, error) {
+	if !s.pollMu.TryLock() {
+		return 0, ErrPollInProgress
+	}
+	defer s.pollMu.Unlock()
+	return s.pollLocked(ctx)
+}
+
+// ScanHit is one listing found by a /scan search.
+type ScanHit struct {
+	Listing domain.Listing
+	Known   bool // already stored by a regular search
+}
+
+// TriggerScan runs a one-off search against an arbitrary IS24 search URL in
+// the background, bypassing profiles and filters, and hands the hits to done.
+// Nothing is saved; the unknown hits are kept for SaveScan. Returns false
+// when a poll or scan is already running.
+func (s *Scheduler) TriggerScan(ctx context.Context, searchURL string, done func(hits []ScanHit, err error)) bool {
+	if !s.pollMu.TryLock() {
+		return false
+	}
+	ctx, cancel := s.untilStop(ctx)
+	go func() {
+		defer s.pollMu.Unlock()
+		defer cancel()
+		hits, err := s.scan(ctx, searchURL)
+		done(hits, err)
+	}()
+	return true
+}
+
+func (s *Scheduler) scan(ctx context.Context, searchURL string) ([]ScanHit, error) {
+	res, err := s.client.Search(ctx, &domain.SearchProfile{Name: "scan", SearchURL: searchURL})
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]ScanHit, 0, len(res.Listings))
+	var unknown []domain.Listing
+	for _, l := range res.Listings {
+		l.SearchProfileID = 0
+		known, err := s.repo.ListingExists(ctx, l.IS24ID)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, ScanHit{Listing: l, Known: known})
+		if !known {
+			unknown = append(unknown, l)
+		}
+	}
+	s.mu.Lock()
+	s.lastScan = unknown
+	s.mu.Unlock()
+	s.logger.Info("scan complete", "url", searchURL, "found", len(hits), "new", len(unknown),
+		"total", res.TotalHits, "truncated", res.Truncated)
+	return hits, nil
+}
+
+// SaveScan stores the unknown listings of the last scan with their expose
+// details. They count as notified (the scan report showed them) and from
+// then on are handled like any found listing, auto-contact included. Returns
+// the number saved.
+func (s *Scheduler) SaveScan(ctx context.Context) (int, error) {
+	if !s.pollMu.TryLock() {
+		return 0, ErrPollInProgress
+	}
+	defer s.pollMu.Unlock()
+
+	s.mu.Lock()
+	pending := s.lastScan
+	s.lastScan = nil
+	s.mu.Unlock()
+
+	saved := 0
+	for _, l := range s.fetchExposes(ctx, pending) {
+		l.Incomplete = false // explicitly kept; the search data will do
+		l.TotalRent, l.RentEstimated = s.filter.TotalRent(l)
+		l.QualityScore = s.filter.Score(l, 0)
+		s.trimDescription(l)
+		if err := s.repo.CreateListing(ctx, l); err != nil {
+			return saved, fmt.Errorf("save %s: %w", l.IS24ID, err)
+		}
+		if l.ID == 0 {
+			continue // stored by a poll in the meantime
+		}
+		if err := s.repo.MarkListingNotified(ctx, l.ID); err != nil {
+			s.logger.Error("mark notified failed", "id", l.ID, "error", err)
+		}
+		s.repo.LogActivity(ctx, &domain.ActivityLog{
+			Action:     domain.ActionListingFound,
+			EntityType: "listing",
+			EntityID:   l.ID,
+			Details:    "scan: " + l.Title,
+		})
+		saved++
+	}
+	return saved, nil
+}
+
+// SelfTestReport is the outcome of a /selftest run.
+type SelfTestReport struct {
+	URL       string
+	SearchErr error // browser start, page load or WAF challenge failed
+	Hits      int   // listings parsed from the search page
+	TotalHits int   // IS24's result count, 0 if not shown
+	Complete  int   // hits with price, rooms and area
+	ExposeID  string
+	ExposeErr error
+	Expose    *domain.Listing // parsed expose of the first hit
+	Duration  time.Duration
+}
+
+// TriggerSelfTest runs one search and one expose fetch in the background,
+// saving and notifying nothing, and hands the report to done. The URL is
+// is24.selftest_url or the first active profile's. Returns false when a poll
+// or scan is already running.
+func (s *Scheduler) TriggerSelfTest(ctx context.Context, done func(SelfTestReport)) bool {
+	if !s.pollMu.TryLock() {
+		return false
+	}
+	ctx, cancel := s.untilStop(ctx)
+	go func() {
+		defer s.pollMu.Unlock()
+		defer cancel()
+		done(s.selfTest(ctx))
+	}()
+	return true
+}
+
+func (s *Scheduler) selfTest(ctx context.Context) SelfTestReport {
+	start := time.Now()
+	r := SelfTestReport{URL: s.cfg.IS24.SelfTestURL}
+	if r.URL == "" {
+		profiles, err := s.repo.GetActiveSearchProfiles(ctx)
+		if err != nil {
+			r.SearchErr = err
+			return r
+		}
+		for _, p := range profiles {
+			if p.SearchURL != "" {
+				r.URL = p.SearchURL
+				break
 			}
 		}
 	}
+	if r.URL == "" {
+		r.SearchErr = errors.New("no search URL: set is24.selftest_url or add a profile")
+		return r
+	}
+
+	res, err := s.client.Search(ctx, &domain.SearchProfile{Name: "selftest", SearchURL: r.URL})
+	r.Duration = time.Since(start)
+	if err != nil {
+		r.SearchErr = err
+		return r
+	}
+	r.Hits, r.TotalHits = len(res.Listings), res.TotalHits
+	for _, l := range res.Listings {
+		if l.HasCoreData() {
+			r.Complete++
+		}
+	}
+	if len(res.Listings) > 0 {
+		r.ExposeID = res.Listings[0].IS24ID
+		r.Expose, r.ExposeErr = s.client.FetchExpose(ctx, r.ExposeID)
+	}
+	r.Duration = time.Since(start)
+	s.logger.Info("self-test complete", "url", r.URL, "hits", r.Hits, "complete", r.Complete,
+		"expose_error", r.ExposeErr, "duration", r.Duration)
+	return r
 }
 
-func (s *Scheduler) poll(ctx context.Context) error {
+// pollLocked is the poll cycle itself; the caller holds pollMu.
+func (s *Scheduler) pollLocked(ctx context.Context) (int, error) {
 	s.logger.Info("starting poll cycle")
 
 	quietNow := s.quietHoursActive()
@@ -265,22 +561,43 @@ func (s *Scheduler) poll(ctx context.Context) error {
 	// Get active search profiles
 	profiles, err := s.repo.GetActiveSearchProfiles(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	profiles = s.skipPaused(profiles)
 
 	s.logger.Info("processing profiles", "count", len(profiles))
 
-	totalRaw, failures := 0, 0
-	for _, profile := range profiles {
-		raw, err := s.processProfile(ctx, &profile)
+	s.snapshot = make(map[string]sqlite.SnapshotListing)
+	defer func() { s.snapshot = nil }()
+	if s.known, err = s.repo.ListingIDs(ctx); err != nil {
+		s.logger.Warn("loading known listings failed, checking one by one", "error", err)
+	}
+	defer func() { s.known = nil }()
+	s.ignored = s.ignoredListings(ctx)
+	defer func() { s.ignored = nil }()
+
+	totalRaw, totalNew, failures := 0, 0, 0
+	for i, profile := range profiles {
+		if i > 0 && !s.pauseBetweenProfiles(ctx) {
+			break // shutting down
+		}
+		raw, saved, err := s.processProfile(ctx, &profile)
 		if err != nil {
 			s.logger.Error("profile processing failed", "profile", profile.Name, "error", err)
+			s.recordError(ctx, "profile "+profile.Name, err.Error())
 			failures++
 			continue // try other profiles
 		}
 		totalRaw += raw
+		totalNew += saved
 	}
 	s.checkCookieHealth(ctx, len(profiles), totalRaw, failures, quietNow)
+	s.checkNewListings(ctx, len(profiles), totalNew, quietNow, time.Now())
+	s.savePollSnapshot(ctx, failures)
+
+	// Listings saved without expose details get another fetch. Runs in every
+	// mode so none are stranded after switching away from retry_next_cycle.
+	s.retryIncompleteListings(ctx)
 
 	if !quietNow {
 		// Process notifications for unnotified listings (suppressed in Off mode).
@@ -288,18 +605,15 @@ func (s *Scheduler) poll(ctx context.Context) error {
 			if err := s.sendNotifications(ctx); err != nil {
 				s.logger.Error("notification sending failed", "error", err)
 			}
+			if s.cfg.Contact.FollowUpDays > 0 {
+				if err := s.sendFollowUpReminders(ctx); err != nil {
+					s.logger.Error("follow-up reminders failed", "error", err)
+				}
+			}
 		} else {
 			s.logger.Info("notifications paused (contact mode off)")
 		}
 
-		// Process auto-contact for uncontacted listings (only if enabled via Telegram)
-		if s.cfg.Contact.Enabled && s.isAutoContactEnabled() {
-			s.logger.Info("auto-contact enabled, processing uncontacted listings")
-			if err := s.sendContacts(ctx); err != nil {
-				s.logger.Error("contact sending failed", "error", err)
-			}
-		}
-
 		// Process test mode: show message previews without sending
 		if s.cfg.Contact.Enabled && s.isTestModeEnabled() {
 			s.logger.Info("test mode enabled, showing message previews")
@@ -309,6 +623,20 @@ func (s *Scheduler) poll(ctx context.Context) error {
 		}
 	}
 
+	// Process auto-contact for uncontacted listings (only if enabled via Telegram)
+	if s.cfg.Contact.Enabled && s.isAutoContactEnabled() {
+		if s.contactAllowed(quietNow, time.Now()) {
+			s.logger.Info("auto-contact enabled, processing uncontacted listings")
+			if err := s.sendContacts(ctx); err != nil {
+				s.logger.Error("contact sending failed", "error", err)
+			}
+		} else if s.cfg.Contact.Window.Enabled {
+			s.logger.Info("outside contact window, deferring contacts",
+				"start", s.cfg.Contact.Window.Start,
+				"end", s.cfg.Contact.Window.End)
+		}
+	}
+
 	// Scan the mailbox for IS24 provider replies. Runs regardless of quiet
 	// hours: it only reads mail and alerts on genuine inbound replies, which are
 	// time-sensitive and low-volume (the AI classifier filters out noise).
@@ -323,8 +651,8 @@ func (s *Scheduler) poll(ctx context.Context) error {
 		s.logger.Warn("failed to record poll heartbeat", "error", err)
 	}
 
-	s.logger.Info("poll cycle complete")
-	return nil
+	s.logger.Info("poll cycle complete", "new_listings", totalNew)
+	return totalNew, nil
 }
 
 func (s *Scheduler) quietHoursActive() bool {
@@ -342,9 +670,17 @@ func (s *Scheduler) quietHoursActive() bool {
 	return s.cfg.IsQuietTime()
 }
 
-// processProfile searches, filters and stores listings for one profile.
-// Returns the number of raw listings the search returned (used to detect a
-// likely-expired IS24 cookie when searches keep coming back empty).
+// contactAllowed decides whether contact forms may be submitted now. With a
+// contact window configured it alone governs contacting, so notifications
+// (quiet hours) and contacts run on independent schedules; without one,
+// contacts share the notification quiet hours.
+func (s *Scheduler) contactAllowed(quietNow bool, now time.Time) bool {
+	if s.cfg.Contact.Window.Enabled {
+		return s.cfg.IsContactAllowedAt(now)
+	}
+	return !quietNow
+}
+
 // checkCookieHealth warns once when searches keep returning nothing across all
 // active profiles (or all fail), the typical symptom of an expired IS24 cookie.
 // It resets and clears the warning as soon as listings come back.
@@ -353,107 +689,828 @@ func (s *Scheduler) checkCookieHealth(ctx context.Context, profileCount, totalRa
 		return // nothing to search, not a cookie problem
 	}
 
-	// A poll is "empty" if no search returned any listing (all empty or all failed).
+	// A poll is "empty" if no search returned any listing (all empty or all
+	// failed). The state is shared with SetIS24Cookie and Digest, hence s.mu.
+	s.mu.Lock()
 	if totalRaw > 0 {
 		s.emptyPolls = 0
 		s.cookieAlert = false
+		s.mu.Unlock()
 		return
 	}
-
 	s.emptyPolls++
-	if s.emptyPolls >= cookieWarnThreshold && !s.cookieAlert {
-		if quietNow {
-			s.logger.Warn("possible expired IS24 cookie, notification deferred by quiet hours",
-				"empty_polls", s.emptyPolls, "failures", failures)
-			return
-		}
+	emptyPolls := s.emptyPolls
+	alert := emptyPolls >= cookieWarnThreshold && !s.cookieAlert && !quietNow
+	if alert {
 		s.cookieAlert = true
-		msg := fmt.Sprintf(
-			"⚠️ *Keine Inserate seit %d Durchläufen* (%d/%d Profile mit Fehler).\n\n"+
-				"IS24-Cookie evtl. abgelaufen — bitte `IS24_COOKIE` aktualisieren und Bot neu starten.",
-			s.emptyPolls, failures, profileCount)
-		if s.notifier != nil {
-			s.notifier.SendRawMessage(ctx, msg)
+	}
+	deferred := emptyPolls >= cookieWarnThreshold && !s.cookieAlert && quietNow
+	s.mu.Unlock()
+
+	if deferred {
+		s.logger.Warn("possible expired IS24 cookie, notification deferred by quiet hours",
+			"empty_polls", emptyPolls, "failures", failures)
+		return
+	}
+	if !alert {
+		return
+	}
+	s.recordError(ctx, domain.ErrorDetailCookie, fmt.Sprintf("%d empty polls", emptyPolls))
+	msg := fmt.Sprintf(
+		"⚠️ *Keine Inserate seit %d Durchläufen* (%d/%d Profile mit Fehler).\n\n"+
+			"IS24-Cookie evtl. abgelaufen — bitte `IS24_COOKIE` aktualisieren und Bot neu starten.",
+		emptyPolls, failures, profileCount)
+	if s.notifier != nil {
+		s.notifier.SendRawMessage(ctx, msg)
+	}
+	s.logger.Warn("possible expired IS24 cookie", "empty_polls", emptyPolls, "failures", failures)
+}
+
+// checkNewListings warns once when no profile has found a new listing for
+// cfg.NoNewListingsAlert: a dead market and a broken scraper look the same
+// from the outside. The time of the last find is kept in the meta table so a
+// restart doesn't restart the clock; a new find re-arms the alert.
+func (s *Scheduler) checkNewListings(ctx context.Context, profileCount, totalNew int, quietNow bool, now time.Time) {
+	if s.cfg.NoNewListingsAlert <= 0 || profileCount == 0 {
+		return
+	}
+
+	if totalNew > 0 {
+		s.lastNewAt = now
+		s.silenceAlert = false
+		if err := s.repo.SetMeta(ctx, sqlite.MetaLastNewListing, now.UTC().Format(time.RFC3339)); err != nil {
+			s.logger.Warn("failed to record last new listing", "error", err)
 		}
-		s.logger.Warn("possible expired IS24 cookie", "empty_polls", s.emptyPolls, "failures", failures)
+		return
+	}
+
+	if s.lastNewAt.IsZero() {
+		s.lastNewAt = now
+		if v, err := s.repo.GetMeta(ctx, sqlite.MetaLastNewListing); err != nil {
+			s.logger.Warn("failed to load last new listing", "error", err)
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			s.lastNewAt = t
+		}
+	}
+
+	silence := now.Sub(s.lastNewAt)
+	if silence < s.cfg.NoNewListingsAlert || s.silenceAlert {
+		return
+	}
+	if quietNow {
+		s.logger.Warn("no new listings, notification deferred by quiet hours", "since", s.lastNewAt)
+		return
+	}
+	s.silenceAlert = true
+	hours := int(silence.Hours())
+	if s.notifier != nil {
+		s.notifier.SendRawMessage(ctx, fmt.Sprintf("🔕 Seit %d Stunden keine neuen Wohnungen – Konfiguration prüfen?", hours))
 	}
+	s.logger.Warn("no new listings", "since", s.lastNewAt, "hours", hours)
 }
 
-func (s *Scheduler) processProfile(ctx context.Context, profile *domain.SearchProfile) (int, error) {
+// processProfile searches, filters and stores listings for one profile.
+// Returns the number of raw listings the search returned (used to detect a
+// likely-expired IS24 cookie when searches keep coming back empty) and the
+// number of newly saved listings.
+func (s *Scheduler) processProfile(ctx context.Context, profile *domain.SearchProfile) (int, int, error) {
 	s.logger.Info("searching", "profile", profile.Name, "city", profile.City)
 
+	// Search and expose requests use the profile's rate override, if any.
+	if key := s.rateKeyFor(profile); key != "" {
+		ctx = antidetect.WithRateKey(ctx, key)
+		s.logger.Debug("using rate override", "profile", profile.Name, "key", key)
+	}
+
 	// Search IS24
-	listings, err := s.client.Search(ctx, profile)
+	res, err := s.client.Search(ctx, profile)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
+	}
+	listings := s.dropIgnored(res.Listings)
+
+	s.logger.Info("found listings", "count", len(listings), "total", res.TotalHits,
+		"pages", res.PagesCrawled, "profile", profile.Name)
+	s.reportTruncated(ctx, profile, res)
+
+	// Calibration mode reports drops too, each listing once: the ones not in
+	// seen_listings before this cycle.
+	calibrating := profile.CalibrationCycles > 0 && s.isNotifyEnabled() && !s.quietHoursActive()
+	var unseen map[string]bool
+	if calibrating {
+		unseen = s.unseenListings(ctx, listings)
 	}
 
-	s.logger.Info("found listings", "count", len(listings), "profile", profile.Name)
+	// An implausible price cleared to unknown must not reach the filters,
+	// the price tracking or the database as a real one.
+	for i := range listings {
+		s.filter.SanitizePrice(&listings[i])
+	}
 
 	// Filter listings with debug logging
 	var filtered []domain.Listing
+	batch := make([]sqlite.SeenListing, 0, len(listings))
 	for _, l := range listings {
 		result := s.filter.Filter(&l, profile)
+		batch = append(batch, seenListing(&l, result))
 		if result.Passed {
 			filtered = append(filtered, l)
+			if s.snapshot != nil {
+				s.snapshot[l.IS24ID] = sqlite.SnapshotListing{IS24ID: l.IS24ID, Title: l.Title, Price: l.Price}
+			}
 		} else {
 			s.logger.Debug("listing filtered", "is24_id", l.IS24ID, "title", l.Title,
-				"price", l.Price, "rooms", l.Rooms, "reasons", result.Reasons)
+				"price", l.Price, "rooms", l.Rooms, "reasons", result.Explain())
+			if unseen[l.IS24ID] {
+				s.reportCalibrationDrop(ctx, profile, &l, result)
+			}
 		}
 	}
+	if err := s.repo.RecordSeenListings(ctx, batch); err != nil {
+		s.logger.Warn("record seen listings failed", "profile", profile.Name, "error", err)
+	}
+	s.trackPriceChanges(ctx, listings)
 	s.logger.Info("after filtering", "count", len(filtered), "profile", profile.Name)
 
-	// Process each listing
-	newCount := 0
+	// Skip listings we already know about
+	var fresh []domain.Listing
 	for _, listing := range filtered {
-		// Check if already exists
-		exists, err := s.repo.ListingExists(ctx, listing.IS24ID)
+		exists, err := s.listingKnown(ctx, listing.IS24ID)
 		if err != nil {
 			s.logger.Error("existence check failed", "is24_id", listing.IS24ID, "error", err)
 			continue
 		}
+		if !exists {
+			fresh = append(fresh, listing)
+		}
+	}
 
-		if exists {
-			continue
+	// Fetch full expose details (in parallel, bounded by config)
+	details := s.fetchExposes(ctx, fresh)
+
+	// Profile average €/m² for the quality score (0 → neutral price signal)
+	var avgPricePerSqm float64
+	if len(details) > 0 {
+		if avgPricePerSqm, err = s.repo.AveragePricePerSqm(ctx, profile.ID); err != nil {
+			s.logger.Warn("average price per sqm failed", "profile", profile.Name, "error", err)
 		}
+	}
 
-		// Optionally fetch full expose details
-		detailed, err := s.client.FetchExpose(ctx, listing.IS24ID)
-		if err != nil {
-			s.logger.Warn("expose fetch failed", "is24_id", listing.IS24ID, "error", err)
-			// Use basic listing data
-			detailed = &listing
-		} else {
-			// Preserve search profile ID
-			detailed.SearchProfileID = listing.SearchProfileID
+	// Process each listing; the ones to keep are saved together below.
+	var toSave []*domain.Listing
+	for _, detailed := range details {
+		// In retry mode, an expose without price, rooms or area is held back
+		// just like a failed fetch.
+		if s.cfg.IS24.OnExposeFailure == config.ExposeFailureRetry && !detailed.HasCoreData() {
+			detailed.Incomplete = true
+		}
+		if detailed.Incomplete {
+			switch s.cfg.IS24.OnExposeFailure {
+			case config.ExposeFailureSkip:
+				s.recordSeen(ctx, detailed, filter.Rejected(filter.Reason{Code: filter.ReasonExposeFetchFailed}))
+				s.logger.Info("listing skipped, expose fetch failed", "is24_id", detailed.IS24ID)
+				continue
+			case config.ExposeFailureRetry:
+				// Saved below but held back until retryIncompleteListings
+				// completes it.
+			default:
+				detailed.Incomplete = false
+			}
 		}
 
 		// Re-filter with full details
-		if !s.filter.Filter(detailed, profile).Passed {
-			s.logger.Debug("listing filtered after detail fetch", "is24_id", listing.IS24ID)
+		s.filter.SanitizePrice(detailed)
+		if result := s.filter.Filter(detailed, profile); !result.Passed {
+			s.recordSeen(ctx, detailed, result)
+			s.logger.Debug("listing filtered after detail fetch", "is24_id", detailed.IS24ID)
+			if unseen[detailed.IS24ID] {
+				s.reportCalibrationDrop(ctx, profile, detailed, result)
+			}
 			continue
 		}
 
-		// Save to database
-		if err := s.repo.CreateListing(ctx, detailed); err != nil {
-			s.logger.Error("listing save failed", "is24_id", detailed.IS24ID, "error", err)
+		detailed.TotalRent, detailed.RentEstimated = s.filter.TotalRent(detailed)
+		detailed.QualityScore = s.filter.Score(detailed, avgPricePerSqm)
+
+		s.trimDescription(detailed)
+		toSave = append(toSave, detailed)
+	}
+
+	toSave, twins := collapseDuplicates(toSave)
+	newCount := s.saveListings(ctx, toSave)
+	s.logger.Info("new listings saved", "count", newCount, "profile", profile.Name)
+	s.saveTwins(ctx, twins)
+	s.pruneListings(ctx, profile, listings)
+	if calibrating {
+		s.finishCalibrationCycle(ctx, profile)
+	}
+	return len(listings), newCount, nil
+}
+
+// ignoredListings merges filter.ignore_listing_ids with the IDs ignored via
+// /ignore. A failed lookup is logged and leaves only the configured ones.
+func (s *Scheduler) ignoredListings(ctx context.Context) map[string]bool {
+	ignored, err := s.repo.IgnoredListingIDs(ctx)
+	if err != nil {
+		s.logger.Warn("loading ignored listings failed", "error", err)
+		ignored = make(map[string]bool)
+	}
+	for _, id := range s.cfg.Filter.IgnoreListingIDs {
+		ignored[id] = true
+	}
+	return ignored
+}
+
+// dropIgnored removes the cycle's ignored listings from search hits before
+// anything else looks at them.
+func (s *Scheduler) dropIgnored(hits []domain.Listing) []domain.Listing {
+	if len(s.ignored) == 0 {
+		return hits
+	}
+	kept := make([]domain.Listing, 0, len(hits))
+	for _, l := range hits {
+		if s.ignored[l.IS24ID] {
+			s.logger.Debug("listing ignored", "is24_id", l.IS24ID)
 			continue
 		}
+		kept = append(kept, l)
+	}
+	return kept
+}
+
+// pruneListings applies the profile's MaxTrackedListings cap after a search.
+// Listings in hits stay, whatever their age.
+func (s *Scheduler) pruneListings(ctx context.Context, profile *domain.SearchProfile, hits []domain.Listing) {
+	if profile.MaxTrackedListings <= 0 {
+		return
+	}
+	current := make(map[string]bool, len(hits))
+	for _, l := range hits {
+		current[l.IS24ID] = true
+	}
+	n, err := s.repo.PruneProfileListings(ctx, profile.ID, profile.MaxTrackedListings, current)
+	if err != nil {
+		s.logger.Warn("pruning listings failed", "profile", profile.Name, "error", err)
+		return
+	}
+	if n > 0 {
+		s.logger.Info("old listings pruned", "count", n, "profile", profile.Name,
+			"max_tracked_listings", profile.MaxTrackedListings)
+	}
+}
 
-		s.logger.Info("new listing saved", "is24_id", detailed.IS24ID, "title", detailed.Title)
-		newCount++
+// saveListings stores a cycle's new listings in one transaction and logs them
+// as found. If the batch fails, they are saved one by one so a single bad
+// listing doesn't cost the others. Returns the number stored.
+//
+// The insert decides what is new: a listing stored in the meantime (another
+// profile, a /scan save) is ignored and keeps ID 0, so it is neither logged
+// nor notified twice. Notifications are sent from the stored rows.
+func (s *Scheduler) saveListings(ctx context.Context, listings []*domain.Listing) int {
+	if _, err := s.repo.CreateListings(ctx, listings); err != nil {
+		s.logger.Warn("batch listing save failed, saving one by one", "count", len(listings), "error", err)
+		for _, l := range listings {
+			if err := s.repo.CreateListing(ctx, l); err != nil {
+				s.logger.Error("listing save failed", "is24_id", l.IS24ID, "error", err)
+			}
+		}
+	}
 
-		// Log activity
+	saved := 0
+	for _, l := range listings {
+		if l.ID == 0 {
+			continue // failed, or stored in the meantime
+		}
+		s.logger.Info("new listing saved", "is24_id", l.IS24ID, "title", l.Title,
+			"score", l.QualityScore)
+		saved++
+		if s.known != nil {
+			s.known[l.IS24ID] = true
+		}
 		s.repo.LogActivity(ctx, &domain.ActivityLog{
 			Action:     domain.ActionListingFound,
 			EntityType: "listing",
-			EntityID:   detailed.ID,
-			Details:    detailed.Title,
+			EntityID:   l.ID,
+			Details:    l.Title,
 		})
 	}
+	return saved
+}
 
-	s.logger.Info("new listings saved", "count", newCount, "profile", profile.Name)
-	return len(listings), nil
+// saveTwins stores the reposts collapseDuplicates dropped as notified and
+// skipped, so they are neither notified nor contacted and later searches
+// don't fetch their exposes again.
+func (s *Scheduler) saveTwins(ctx context.Context, twins []*domain.Listing) {
+	if _, err := s.repo.CreateSkippedListings(ctx, twins); err != nil {
+		s.logger.Error("duplicate listings save failed", "count", len(twins), "error", err)
+		return
+	}
+	for _, l := range twins {
+		if l.ID == 0 {
+			continue // stored in the meantime
+		}
+		if s.known != nil {
+			s.known[l.IS24ID] = true
+		}
+		s.logger.Info("duplicate listing collapsed", "is24_id", l.IS24ID, "title", l.Title,
+			"key", duplicateKey(l))
+	}
+}
+
+// listingKnown reports whether a listing is stored, from the cycle's known
+// set when loaded. Misses are still checked in the database, which is only
+// queried for the genuinely new ones. This only saves expose fetches for
+// known listings; saveListings decides newness.
+func (s *Scheduler) listingKnown(ctx context.Context, is24ID string) (bool, error) {
+	if s.known[is24ID] {
+		return true, nil
+	}
+	exists, err := s.repo.ListingExists(ctx, is24ID)
+	if exists && s.known != nil {
+		s.known[is24ID] = true
+	}
+	return exists, err
+}
+
+// trackPriceChanges updates the stored price of known listings whose search
+// result shows a different one, in one batch per search, and records each
+// change.
+func (s *Scheduler) trackPriceChanges(ctx context.Context, listings []domain.Listing) {
+	prices := make(map[string]int, len(listings))
+	hits := make(map[string]*domain.Listing, len(listings))
+	for i := range listings {
+		l := &listings[i]
+		if l.Price <= 0 {
+			continue // unknown or cleared as implausible
+		}
+		prices[l.IS24ID] = l.Price
+		hits[l.IS24ID] = l
+	}
+	changes, err := s.repo.UpdateListingPrices(ctx, prices)
+	if err != nil {
+		s.logger.Warn("price update failed", "count", len(prices), "error", err)
+		return
+	}
+	for _, c := range changes {
+		l := hits[c.IS24ID]
+		s.logger.Info("price changed", "is24_id", l.IS24ID, "old", c.OldPrice, "new", l.Price)
+		s.repo.LogActivity(ctx, &domain.ActivityLog{
+			Action:     domain.ActionPriceChanged,
+			EntityType: "listing",
+			EntityID:   c.ID,
+			Details:    fmt.Sprintf("%d € → %d €", c.OldPrice, l.Price),
+		})
+		s.notifyPriceChange(ctx, c.ID, c.OldPrice, l)
+	}
+}
+
+// notifyPriceChange tells the user about a new price of a listing they were
+// notified about, subject to the price_alerts threshold and cooldown. The
+// threshold is measured against the last reported price so a slow drift is
+// reported once it adds up.
+func (s *Scheduler) notifyPriceChange(ctx context.Context, id int64, oldPrice int, l *domain.Listing) {
+	if s.cfg == nil || !s.cfg.PriceAlerts.Enabled || !s.isNotifyEnabled() || s.quietHoursActive() {
+		return
+	}
+	notified, alertedPrice, alertedAt, err := s.repo.PriceAlertState(ctx, id)
+	if err != nil {
+		s.logger.Warn("price alert state failed", "is24_id", l.IS24ID, "error", err)
+		return
+	}
+	if !notified {
+		return
+	}
+	base := oldPrice // notified before the baseline was tracked
+	if alertedPrice > 0 {
+		base = alertedPrice
+	}
+	if !priceAlertDue(s.cfg.PriceAlerts, base, l.Price, alertedAt, time.Now()) {
+		s.logger.Debug("price change below alert threshold or in cooldown", "is24_id", l.IS24ID, "base", base, "new", l.Price)
+		return
+	}
+	msg := fmt.Sprintf("💶 *Preis geändert*: %s\n%d € → %d € (%+.0f %%)\n🔗 %s",
+		l.Title, base, l.Price, float64(l.Price-base)*100/float64(base), l.URL)
+	if err := s.notifier.SendRawMessage(ctx, msg); err != nil {
+		s.logger.Error("price alert failed", "is24_id", l.IS24ID, "error", err)
+		return
+	}
+	if err := s.repo.RecordPriceAlert(ctx, id, l.Price); err != nil {
+		s.logger.Warn("recording price alert failed", "is24_id", l.IS24ID, "error", err)
+	}
+}
+
+// priceAlertDue reports whether a move from base to price is worth a message:
+// it must reach min_change or min_change_percent (any change when both are
+// 0), and the last message for the listing must be at least cooldown ago.
+func priceAlertDue(cfg config.PriceAlertConfig, base, price int, lastAlert, now time.Time) bool {
+	if base <= 0 || price == base {
+		return false
+	}
+	if !lastAlert.IsZero() && now.Sub(lastAlert) < cfg.Cooldown {
+		return false
+	}
+	diff := price - base
+	if diff < 0 {
+		diff = -diff
+	}
+	if cfg.MinChange <= 0 && cfg.MinChangePercent <= 0 {
+		return true
+	}
+	if cfg.MinChange > 0 && diff >= cfg.MinChange {
+		return true
+	}
+	return cfg.MinChangePercent > 0 && float64(diff)*100 >= cfg.MinChangePercent*float64(base)
+}
+
+// reportTruncated warns when a profile's result list is longer than the
+// scraper's page limit: listings beyond it are never seen, so the search
+// should be narrowed. The message goes out once until the profile fits again.
+func (s *Scheduler) reportTruncated(ctx context.Context, profile *domain.SearchProfile, res *domain.SearchResult) {
+	if !res.Truncated {
+		delete(s.truncated, profile.ID)
+		return
+	}
+	s.logger.Warn("search truncated by page limit", "profile", profile.Name,
+		"total", res.TotalHits, "pages", res.PagesCrawled, "listings", len(res.Listings))
+	if s.truncated[profile.ID] || !s.isNotifyEnabled() || s.quietHoursActive() {
+		return
+	}
+	msg := fmt.Sprintf("⚠️ *Suche abgeschnitten: %s*\nNur %d Seiten (%d Wohnungen) gelesen",
+		profile.Name, res.PagesCrawled, len(res.Listings))
+	if res.TotalHits > 0 {
+		msg += fmt.Sprintf(", IS24 meldet %d Treffer", res.TotalHits)
+	}
+	msg += ".\nSuche eingrenzen (Preis, Zimmer, Gebiet), sonst fehlen Wohnungen."
+	if err := s.notifier.SendRawMessage(ctx, msg); err != nil {
+		s.logger.Error("truncation warning failed", "profile", profile.Name, "error", err)
+		return
+	}
+	if s.truncated == nil {
+		s.truncated = make(map[int64]bool)
+	}
+	s.truncated[profile.ID] = true
+}
+
+// pauseBetweenProfiles waits is24.profile_delay plus jitter before the next
+// profile of a cycle. It returns false when ctx ends during the pause.
+func (s *Scheduler) pauseBetweenProfiles(ctx context.Context) bool {
+	if s.cfg == nil {
+		return true
+	}
+	d := profileDelay(s.cfg.IS24.ProfileDelay, s.cfg.IS24.ProfileJitter, rand.Int63n)
+	if d <= 0 {
+		return true
+	}
+	s.logger.Debug("pausing before next profile", "delay", d.Round(time.Second))
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// warmUpper is implemented by IS24 clients that can visit the homepage
+// before the first search (is24.warm_up).
+type warmUpper interface {
+	WarmUp(ctx context.Context) error
+}
+
+// settle waits is24.startup_delay plus jitter and, if configured, warms up
+// the client. Returns false if the scheduler was stopped meanwhile. A failed
+// warm-up is only logged.
+func (s *Scheduler) settle(ctx context.Context) bool {
+	if d := profileDelay(s.cfg.IS24.StartupDelay, s.cfg.IS24.StartupJitter, rand.Int63n); d > 0 {
+		s.logger.Info("waiting before first poll", "delay", d.Round(time.Second))
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-s.stopCh:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-t.C:
+		}
+	}
+	if w, ok := s.client.(warmUpper); ok && s.cfg.IS24.WarmUp {
+		if err := w.WarmUp(ctx); err != nil {
+			s.logger.Warn("warm-up failed, searching anyway", "error", err)
+		} else {
+			s.logger.Info("warm-up done")
+		}
+	}
+	return true
+}
+
+// profileDelay returns base plus a random 0..jitter drawn with randInt63n.
+func profileDelay(base, jitter time.Duration, randInt63n func(int64) int64) time.Duration {
+	if jitter > 0 {
+		base += time.Duration(randInt63n(int64(jitter)))
+	}
+	return base
+}
+
+// rateKeyFor returns the is24.rate_overrides key matching the profile's
+// name, city or a path segment of its search URL, or "" for the global limit.
+func (s *Scheduler) rateKeyFor(profile *domain.SearchProfile) string {
+	if s.cfg == nil || len(s.cfg.IS24.RateOverrides) == 0 {
+		return ""
+	}
+	names := []string{profile.Name, profile.City}
+	if u, err := url.Parse(profile.SearchURL); err == nil {
+		names = append(names, strings.Split(u.Path, "/")...)
+	}
+	// Sorted keys keep the choice stable when several match.
+	keys := make([]string, 0, len(s.cfg.IS24.RateOverrides))
+	for key := range s.cfg.IS24.RateOverrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, name := range names {
+			if name != "" && strings.EqualFold(strings.TrimSpace(name), key) {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// skipPaused drops the profiles paused via /pause_profile.
+func (s *Scheduler) skipPaused(profiles []domain.SearchProfile) []domain.SearchProfile {
+	running := profiles[:0]
+	for _, p := range profiles {
+		if p.Paused {
+			s.logger.Debug("profile paused, skipped", "profile", p.Name)
+			continue
+		}
+		running = append(running, p)
+	}
+	return running
+}
+
+// reportCalibrationDrop tells the user about a listing a calibrating profile
+// filtered out, with the reasons. Listings that pass are notified as usual.
+func (s *Scheduler) reportCalibrationDrop(ctx context.Context, profile *domain.SearchProfile, l *domain.Listing, result filter.FilterResult) {
+	msg := fmt.Sprintf("🧪 *Kalibrierung %s* — aussortiert: %s\n\n*%s*\n💰 %d € | 🚪 %.1f Zimmer | 📐 %d m²\n🔗 %s",
+		profile.Name, result.Explain(), l.Title, l.Price, l.Rooms, l.Area, l.URL)
+	if err := s.notifier.SendRawMessage(ctx, msg); err != nil {
+		s.logger.Error("calibration report failed", "is24_id", l.IS24ID, "error", err)
+	}
+}
+
+// finishCalibrationCycle counts down the profile's calibration cycles and
+// announces the switch back to normal behavior after the last one.
+func (s *Scheduler) finishCalibrationCycle(ctx context.Context, profile *domain.SearchProfile) {
+	left, err := s.repo.DecrementCalibrationCycles(ctx, profile.ID)
+	if err != nil {
+		s.logger.Error("calibration countdown failed", "profile", profile.Name, "error", err)
+		return
+	}
+	s.logger.Info("calibration cycle done", "profile", profile.Name, "cycles_left", left)
+	if left == 0 {
+		msg := fmt.Sprintf("🧪 *Kalibrierung %s beendet* — ab jetzt nur noch passende Wohnungen.", profile.Name)
+		if err := s.notifier.SendRawMessage(ctx, msg); err != nil {
+			s.logger.Error("calibration end notice failed", "profile", profile.Name, "error", err)
+		}
+	}
+}
+
+// fetchExposes loads full expose details for the given listings with at most
+// cfg.IS24.ExposeConcurrency fetches in flight; pacing is still enforced by
+// the client's rate limiter. The result keeps the input order. A failed fetch
+// falls back to the basic search data, marked Incomplete; listings taken down
+// since the search are left out.
+func (s *Scheduler) fetchExposes(ctx context.Context, listings []domain.Listing) []*domain.Listing {
+	limit := s.cfg.IS24.ExposeConcurrency
+	if limit < 1 {
+		limit = 1
+	}
+	start := time.Now()
+
+	details := make([]*domain.Listing, len(listings))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range listings {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			listing := &listings[i]
+			detailed, err := s.client.FetchExpose(ctx, listing.IS24ID)
+			if errors.Is(err, domain.ErrListingGone) {
+				s.logger.Info("listing gone before expose fetch", "is24_id", listing.IS24ID)
+				return
+			}
+			if err != nil {
+				s.logger.Warn("expose fetch failed", "is24_id", listing.IS24ID, "error", err)
+				// Use basic listing data
+				basic := *listing
+				basic.Incomplete = true
+				details[i] = &basic
+				return
+			}
+			mergeExpose(detailed, listing)
+			details[i] = detailed
+		}(i)
+	}
+	wg.Wait()
+
+	if len(listings) > 0 {
+		s.logger.Info("expose details fetched", "count", len(listings),
+			"concurrency", limit, "elapsed", time.Since(start).Round(time.Millisecond))
+	}
+	kept := details[:0]
+	for _, d := range details {
+		if d != nil {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// mergeExpose carries over what only the search result knows: the search
+// profile ID, the search-only score signals and a Genossenschaft hint. The
+// images, landlord name, company and type, floor type, heating-included flag
+// and coordinates fill in where the expose page had none.
+func mergeExpose(detailed, basic *domain.Listing) {
+	detailed.SearchProfileID = basic.SearchProfileID
+	if len(detailed.ImageURLs) == 0 {
+		detailed.ImageURLs = basic.ImageURLs
+	}
+	if detailed.LandlordType == "" {
+		detailed.LandlordType = basic.LandlordType
+	}
+	if detailed.FloorType == "" {
+		detailed.FloorType = basic.FloorType
+	}
+	if detailed.HeatingIncluded == nil {
+		detailed.HeatingIncluded = basic.HeatingIncluded
+	}
+	if detailed.LandlordName == "" && detailed.LandlordCompany == "" {
+		detailed.LandlordName, detailed.LandlordCompany = basic.LandlordName, basic.LandlordCompany
+	}
+	if !detailed.HasCoordinates() {
+		detailed.Latitude, detailed.Longitude = basic.Latitude, basic.Longitude
+	}
+	detailed.HasFloorPlan = detailed.HasFloorPlan || basic.HasFloorPlan
+	detailed.RequiresMembership = detailed.RequiresMembership || basic.RequiresMembership
+}
+
+// retryIncompleteListings re-fetches the exposes of listings held back as
+// incomplete (on_expose_failure: retry_next_cycle) until price, rooms and area
+// are known or exposeRetryLimit cycles have passed. Then the filters are
+// re-evaluated on the best data available: a listing that passes is released
+// for notification, one that fails is dropped like any other.
+func (s *Scheduler) retryIncompleteListings(ctx context.Context) {
+	listings, err := s.repo.GetIncompleteListings(ctx, exposeRetryLimit)
+	if err != nil {
+		s.logger.Error("loading incomplete listings failed", "error", err)
+		return
+	}
+
+	for i := range listings {
+		current := &listings[i]
+		detailed, err := s.client.FetchExpose(ctx, current.IS24ID)
+		if errors.Is(err, domain.ErrListingGone) {
+			s.markDelisted(ctx, current)
+			continue
+		}
+		if err != nil {
+			s.logger.Warn("expose retry failed", "is24_id", current.IS24ID, "error", err)
+		} else {
+			mergeExpose(detailed, current)
+			s.filter.SanitizePrice(detailed)
+			detailed.ID = current.ID
+			detailed.Incomplete = true
+			current = detailed
+		}
+
+		if !current.HasCoreData() {
+			attempts, err := s.repo.RecordExposeAttempt(ctx, current.ID)
+			if err != nil {
+				s.logger.Error("record expose attempt failed", "id", current.ID, "error", err)
+				continue
+			}
+			if attempts < exposeRetryLimit {
+				if detailed != nil {
+					s.trimDescription(current)
+					if err := s.repo.UpdateListingDetails(ctx, current); err != nil {
+						s.logger.Error("saving partial expose failed", "is24_id", current.IS24ID, "error", err)
+					}
+				}
+				continue
+			}
+			s.logger.Warn("expose still incomplete, evaluating available data",
+				"is24_id", current.IS24ID, "attempts", attempts)
+		}
+		s.releaseIncomplete(ctx, current)
+	}
+}
+
+// markDelisted stops retrying a held-back listing whose expose IS24 has taken
+// down; its stored data stays as it was.
+func (s *Scheduler) markDelisted(ctx context.Context, l *domain.Listing) {
+	if err := s.repo.MarkListingDelisted(ctx, l.ID); err != nil {
+		s.logger.Error("mark delisted failed", "id", l.ID, "error", err)
+		return
+	}
+	s.logger.Info("listing delisted", "is24_id", l.IS24ID)
+	s.repo.LogActivity(ctx, &domain.ActivityLog{
+		Action:     domain.ActionDelisted,
+		EntityType: "listing",
+		EntityID:   l.ID,
+		Details:    "Exposé nicht mehr verfügbar",
+	})
+}
+
+// releaseIncomplete re-filters a held-back listing and either releases it for
+// notification or deletes it.
+func (s *Scheduler) releaseIncomplete(ctx context.Context, l *domain.Listing) {
+	if profile, err := s.repo.GetSearchProfileByID(ctx, l.SearchProfileID); err == nil && profile != nil {
+		if result := s.filter.Filter(l, profile); !result.Passed {
+			s.recordSeen(ctx, l, result)
+			if err := s.repo.DeleteListing(ctx, l.ID); err != nil {
+				s.logger.Error("delete filtered listing failed", "id", l.ID, "error", err)
+			}
+			s.logger.Debug("incomplete listing filtered after detail fetch", "is24_id", l.IS24ID)
+			return
+		}
+	}
+
+	avgPricePerSqm, err := s.repo.AveragePricePerSqm(ctx, l.SearchProfileID)
+	if err != nil {
+		s.logger.Warn("average price per sqm failed", "is24_id", l.IS24ID, "error", err)
+	}
+	l.TotalRent, l.RentEstimated = s.filter.TotalRent(l)
+	l.QualityScore = s.filter.Score(l, avgPricePerSqm)
+	l.Incomplete = false
+
+	s.trimDescription(l)
+	if err := s.repo.UpdateListingDetails(ctx, l); err != nil {
+		s.logger.Error("releasing incomplete listing failed", "is24_id", l.IS24ID, "error", err)
+		return
+	}
+	s.logger.Info("incomplete listing released", "is24_id", l.IS24ID, "score", l.QualityScore)
+}
+
+// trimDescription caps the description at max_stored_description_length
+// characters before it is saved. Filtering and scoring run on the full text.
+func (s *Scheduler) trimDescription(l *domain.Listing) {
+	if s.cfg == nil {
+		return
+	}
+	limit := s.cfg.MaxStoredDescriptionLength
+	if limit <= 0 || utf8.RuneCountInString(l.Description) <= limit {
+		return
+	}
+	l.Description = string([]rune(l.Description)[:limit]) + "…"
+}
+
+// recordSeen stores the filter verdict for a search hit in seen_listings (the
+// /funnel analytics). Failures are logged only; analytics must never block
+// the poll.
+func (s *Scheduler) recordSeen(ctx context.Context, l *domain.Listing, result filter.FilterResult) {
+	seen := seenListing(l, result)
+	if err := s.repo.RecordSeenListing(ctx, seen.IS24ID, seen.SearchProfileID, seen.Passed, seen.DropReason); err != nil {
+		s.logger.Warn("record seen listing failed", "is24_id", l.IS24ID, "error", err)
+	}
+}
+
+// unseenListings returns the IS24 IDs of the search hits not recorded in
+// seen_listings yet. A failed lookup is logged and reports none, so
+// calibration never repeats drops it already reported.
+func (s *Scheduler) unseenListings(ctx context.Context, listings []domain.Listing) map[string]bool {
+	ids := make([]string, len(listings))
+	for i, l := range listings {
+		ids[i] = l.IS24ID
+	}
+	seen, err := s.repo.SeenListingIDs(ctx, ids)
+	if err != nil {
+		s.logger.Warn("loading seen listings failed", "error", err)
+		return map[string]bool{}
+	}
+	unseen := make(map[string]bool)
+	for _, id := range ids {
+		if !seen[id] {
+			unseen[id] = true
+		}
+	}
+	return unseen
+}
+
+// seenListing is the seen_listings row for a search hit's filter verdict.
+func seenListing(l *domain.Listing, result filter.FilterResult) sqlite.SeenListing {
+	return sqlite.SeenListing{
+		IS24ID:          l.IS24ID,
+		SearchProfileID: l.SearchProfileID,
+		Passed:          result.Passed,
+		DropReason:      strings.Join(result.Reasons, ","),
+	}
 }
 
 func (s *Scheduler) sendNotifications(ctx context.Context) error {
@@ -462,6 +1519,11 @@ func (s *Scheduler) sendNotifications(ctx context.Context) error {
 		return err
 	}
 
+	// Best listings first, so the most promising ones are on top of the chat.
+	sort.SliceStable(listings, func(i, j int) bool {
+		return listings[i].QualityScore > listings[j].QualityScore
+	})
+
 	testMode := s.isTestModeEnabled()
 	sent := 0
 	for _, listing := range listings {
@@ -469,6 +1531,12 @@ func (s *Scheduler) sendNotifications(ctx context.Context) error {
 			s.logger.Info("test mode notification cap reached", "limit", testModeCycleLimit)
 			break
 		}
+		// Stored before its ID was added to filter.ignore_listing_ids.
+		if s.ignored[listing.IS24ID] {
+			s.logger.Debug("notification skipped, listing ignored", "is24_id", listing.IS24ID)
+			continue
+		}
+		listing.Silent = s.listingProfile(ctx, &listing).Silent
 		if err := s.notifier.NotifyNewListing(ctx, &listing); err != nil {
 			s.logger.Error("notification failed", "is24_id", listing.IS24ID, "error", err)
 			continue
@@ -489,20 +1557,63 @@ func (s *Scheduler) sendNotifications(ctx context.Context) error {
 	return nil
 }
 
+// sendFollowUpReminders nudges the user once per contacted listing when
+// FollowUpDays have passed since the contact. Replies aren't tracked, so this
+// is purely time based.
+func (s *Scheduler) sendFollowUpReminders(ctx context.Context) error {
+	after := time.Duration(s.cfg.Contact.FollowUpDays) * 24 * time.Hour
+	listings, err := s.repo.GetFollowUpDueListings(ctx, after)
+	if err != nil {
+		return err
+	}
+
+	for _, listing := range listings {
+		msg := fmt.Sprintf("📨 Noch keine Antwort auf *%s*? Vielleicht nachfassen.\n🔗 %s",
+			listing.Title, listing.URL)
+		if err := s.notifier.SendRawMessage(ctx, msg); err != nil {
+			s.logger.Error("follow-up reminder failed", "is24_id", listing.IS24ID, "error", err)
+			continue
+		}
+		if err := s.repo.MarkListingFollowedUp(ctx, listing.ID); err != nil {
+			s.logger.Error("mark followed up failed", "id", listing.ID, "error", err)
+		}
+		s.logger.Info("follow-up reminder sent", "is24_id", listing.IS24ID)
+	}
+
+	return nil
+}
+
 // campaignFor resolves the campaign for a listing via its search profile's
 // category, falling back to the default campaign when the profile or category
-// is missing.
+// is missing. The profile's contact phone and e-mail, if set, replace the
+// campaign's.
 func (s *Scheduler) campaignFor(ctx context.Context, listing *domain.Listing) Campaign {
-	category := ""
+	profile := s.listingProfile(ctx, listing)
+	camp := s.applyCampaignOverrides(ctx, s.campaigns.Resolve(profile.Category))
+	// Per-search phone/e-mail, e.g. one number per city to see which search
+	// brings the calls.
+	if profile.ContactPhone != "" {
+		camp.Contact.Phone = profile.ContactPhone
+	}
+	if profile.ContactEmail != "" {
+		camp.Contact.Email = profile.ContactEmail
+	}
+	return camp
+}
+
+// listingProfile returns the search profile a listing was found by, or an
+// empty profile (default campaign, no thresholds) if it has none or the
+// lookup fails.
+func (s *Scheduler) listingProfile(ctx context.Context, listing *domain.Listing) *domain.SearchProfile {
 	if listing.SearchProfileID != 0 {
-		if p, err := s.repo.GetSearchProfileByID(ctx, listing.SearchProfileID); err == nil {
-			category = p.Category
-		} else {
-			s.logger.Warn("profile lookup failed, using default campaign",
-				"search_profile_id", listing.SearchProfileID, "error", err)
+		p, err := s.repo.GetSearchProfileByID(ctx, listing.SearchProfileID)
+		if err == nil {
+			return p
 		}
+		s.logger.Warn("profile lookup failed, using default campaign",
+			"search_profile_id", listing.SearchProfileID, "error", err)
 	}
-	return s.applyCampaignOverrides(ctx, s.campaigns.Resolve(category))
+	return &domain.SearchProfile{}
 }
 
 // applyCampaignOverrides layers dashboard-edited AI prompt / message template
@@ -525,54 +1636,194 @@ func (s *Scheduler) applyCampaignOverrides(ctx context.Context, camp Campaign) C
 	return camp
 }
 
+// contactShutdownGrace is how long a contact submission already under way may
+// keep running after shutdown cancels the poll.
+var contactShutdownGrace = 2 * time.Minute
+
+// graceContext returns a context that is canceled grace after ctx is, giving
+// work in progress time to finish cleanly.
+func graceContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	gctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		t := time.NewTimer(grace)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			cancel()
+		case <-gctx.Done():
+		}
+	})
+	return gctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// ContactQueue returns the listings the next auto-contact run would write to,
+// in order (the /queue command). Skipped listings (/cancel, dashboard) are
+// not in it.
+func (s *Scheduler) ContactQueue(ctx context.Context) ([]domain.Listing, error) {
+	return s.contactQueue(ctx)
+}
+
+// contactQueue loads the uncontacted listings in contact.queue_order and
+// drops the ones the contact settings and profile thresholds rule out.
+func (s *Scheduler) contactQueue(ctx context.Context) ([]domain.Listing, error) {
+	listings, err := s.repo.GetUncontactedListings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Sorted before the address check, so of two relistings the better one goes out.
+	sortContactQueue(listings, s.cfg.Contact.QueueOrder, time.Now())
+	contacted := s.contactedAddresses(ctx)
+	// Loaded here rather than taken from the cycle: /queue runs outside it.
+	ignored := s.ignoredListings(ctx)
+	queue := listings[:0]
+	for _, listing := range listings {
+		reason := s.contactSkipReason(ctx, &listing, ignored)
+		key := addressKey(&listing)
+		if prev, ok := contacted[key]; reason == "" && key != "" && ok {
+			reason = "address already contacted (" + prev + ")"
+		}
+		if reason != "" {
+			s.logger.Debug("skipping listing for contact", "is24_id", listing.IS24ID, "reason", reason)
+			continue
+		}
+		// Two relistings of one flat in the same queue: only the first goes out.
+		if contacted != nil && key != "" {
+			contacted[key] = listing.IS24ID
+		}
+		queue = append(queue, listing)
+	}
+	return queue, nil
+}
+
+// contactQueueHalfLife is the age at which ContactOrderBalanced counts a
+// listing's quality score half.
+const contactQueueHalfLife = 24 * time.Hour
+
+// sortContactQueue orders listings, which come newest first, by the
+// contact.queue_order mode. Ties keep the newest-first order.
+func sortContactQueue(listings []domain.Listing, order string, now time.Time) {
+	var priority func(l *domain.Listing) float64
+	switch order {
+	case config.ContactOrderScore:
+		priority = func(l *domain.Listing) float64 { return float64(l.QualityScore) }
+	case config.ContactOrderBalanced:
+		priority = func(l *domain.Listing) float64 {
+			age := now.Sub(l.CreatedAt)
+			if age < 0 {
+				age = 0
+			}
+			return float64(l.QualityScore) * math.Exp2(-age.Hours()/contactQueueHalfLife.Hours())
+		}
+	default:
+		return
+	}
+	sort.SliceStable(listings, func(i, j int) bool {
+		return priority(&listings[i]) > priority(&listings[j])
+	})
+}
+
+// contactedAddresses maps the addressKey of every listing contacted within
+// contact.address_dedup_window to its IS24 ID; nil when the check is off.
+// A failed lookup is logged and disables the check for this round.
+func (s *Scheduler) contactedAddresses(ctx context.Context) map[string]string {
+	window := s.cfg.Contact.AddressDedupWindow
+	if window <= 0 {
+		return nil
+	}
+	listings, err := s.repo.GetContactedListingsSince(ctx, window)
+	if err != nil {
+		s.logger.Warn("loading contacted addresses failed", "error", err)
+		return nil
+	}
+	contacted := make(map[string]string, len(listings))
+	for _, l := range listings {
+		if key := addressKey(&l); key != "" {
+			contacted[key] = l.IS24ID
+		}
+	}
+	return contacted
+}
+
+// contactSkipReason reports why an uncontacted listing must not be contacted
+// automatically, "" when it may be. ignored is the set from ignoredListings.
+func (s *Scheduler) contactSkipReason(ctx context.Context, l *domain.Listing, ignored map[string]bool) string {
+	if ignored[l.IS24ID] {
+		return "ignored"
+	}
+	if s.cfg.Contact.AutoContactPrivateOnly && !l.IsPrivateLandlord() {
+		return "not private (" + l.LandlordType + ")"
+	}
+	if s.cfg.Contact.RequirePhotosForContact && len(l.ImageURLs) == 0 {
+		return "no photos"
+	}
+	if l.PriceOnRequest && s.cfg.Filter.PriceOnRequest != config.PriceOnRequestInclude {
+		return "price on request"
+	}
+	// CreatedAt is when the bot first stored the listing; IS24's own
+	// publish date isn't available on every page.
+	if maxAge := s.cfg.Contact.MaxAge; maxAge > 0 && time.Since(l.CreatedAt) > maxAge {
+		return "older than contact max age"
+	}
+	if block := s.listingProfile(ctx, l).ContactBlock(l); block != "" {
+		return "below profile contact threshold: " + block
+	}
+	return ""
+}
+
 func (s *Scheduler) sendContacts(ctx context.Context) error {
 	if s.contacter == nil {
 		return nil
 	}
 
-	listings, err := s.repo.GetUncontactedListings(ctx)
+	listings, err := s.contactQueue(ctx)
 	if err != nil {
 		return err
 	}
 
 	for _, listing := range listings {
 		camp := s.campaignFor(ctx, &listing)
-
-		// Generate message
-		message, err := camp.Generator.Generate(&listing)
+		message, variant, err := s.composeMessage(ctx, &listing, camp)
 		if err != nil {
 			s.logger.Error("message generation failed", "is24_id", listing.IS24ID, "error", err)
 			continue
 		}
 
-		// Enhance with AI if available
-		if s.enhancer != nil {
-			enhanced, err := s.enhancer.Enhance(ctx, message, &listing, camp.AIPrompt)
-			if err != nil {
-				s.logger.Warn("message enhancement failed, using base message", "error", err)
-			} else {
-				message = enhanced
-			}
+		// On shutdown stop between listings. Once a submission has started,
+		// its outcome is stored even after ctx is canceled, or the
+		// sent_message would stay pending and the listing unmarked.
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+		rctx := context.WithoutCancel(ctx)
 
 		// Record message attempt
 		sentMsg := &domain.SentMessage{
 			ListingID: listing.ID,
 			IS24ID:    listing.IS24ID,
 			Message:   message,
+			Variant:   variant,
 			Status:    domain.MessageStatusPending,
+			Test:      s.cfg.Contact.DryRun,
 		}
-		if err := s.repo.CreateSentMessage(ctx, sentMsg); err != nil {
+		if err := s.repo.CreateSentMessage(rctx, sentMsg); err != nil {
 			s.logger.Error("message record failed", "error", err)
 		}
 
-		// Submit contact form
-		if err := s.contacter.Submit(ctx, &listing, message, camp.Contact); err != nil {
+		// Submit contact form. An unconfirmed submission may have reached the
+		// landlord, so it counts as contacted and is never resubmitted.
+		submitCtx, cancel := graceContext(ctx, contactShutdownGrace)
+		err = s.contacter.Submit(submitCtx, &listing, message, camp.Contact)
+		cancel()
+		unconfirmed := errors.Is(err, contact.ErrUnconfirmed)
+		if err != nil && !unconfirmed {
 			s.logger.Error("contact submission failed", "is24_id", listing.IS24ID, "error", err)
-			s.repo.UpdateSentMessageStatus(ctx, sentMsg.ID, domain.MessageStatusFailed, err.Error())
-			s.notifier.NotifyContactFailed(ctx, &listing, err.Error())
+			s.repo.UpdateSentMessageStatus(rctx, sentMsg.ID, domain.MessageStatusFailed, err.Error())
+			s.notifier.NotifyContactFailed(rctx, &listing, contact.Classify(err, s.cfg.Contact.FailureHints))
 
-			s.repo.LogActivity(ctx, &domain.ActivityLog{
+			s.repo.LogActivity(rctx, &domain.ActivityLog{
 				Action:     domain.ActionContactFailed,
 				EntityType: "listing",
 				EntityID:   listing.ID,
@@ -582,20 +1833,40 @@ func (s *Scheduler) sendContacts(ctx context.Context) error {
 		}
 
 		// Mark as contacted
-		if err := s.repo.MarkListingContacted(ctx, listing.ID); err != nil {
+		if err := s.repo.MarkListingContacted(rctx, listing.ID); err != nil {
 			s.logger.Error("mark contacted failed", "id", listing.ID, "error", err)
 		}
 
-		s.repo.UpdateSentMessageStatus(ctx, sentMsg.ID, domain.MessageStatusSent, "")
-		s.notifier.NotifyContactSent(ctx, &listing)
+		if unconfirmed {
+			s.logger.Warn("contact submitted without confirmation", "is24_id", listing.IS24ID, "error", err)
+			s.repo.UpdateSentMessageStatus(rctx, sentMsg.ID, domain.MessageStatusUnconfirmed, err.Error())
+			if s.cfg.Contact.NotifyUnconfirmed {
+				s.notifier.NotifyContactUnconfirmed(rctx, &listing)
+			} else {
+				s.notifier.NotifyContactSent(rctx, &listing)
+			}
+			s.repo.LogActivity(rctx, &domain.ActivityLog{
+				Action:     domain.ActionContactUnconfirmed,
+				EntityType: "listing",
+				EntityID:   listing.ID,
+				Details:    listing.ContactFormURL,
+				ErrorMsg:   err.Error(),
+			})
+			continue
+		}
+
+		s.repo.UpdateSentMessageStatus(rctx, sentMsg.ID, domain.MessageStatusSent, "")
+		s.notifier.NotifyContactSent(rctx, &listing)
 
-		s.repo.LogActivity(ctx, &domain.ActivityLog{
+		// Details records the contact entry point that worked.
+		s.repo.LogActivity(rctx, &domain.ActivityLog{
 			Action:     domain.ActionContactSent,
 			EntityType: "listing",
 			EntityID:   listing.ID,
+			Details:    listing.ContactFormURL,
 		})
 
-		s.logger.Info("contact sent", "is24_id", listing.IS24ID)
+		s.logger.Info("contact sent", "is24_id", listing.IS24ID, "contact_url", listing.ContactFormURL)
 	}
 
 	return nil
@@ -612,24 +1883,12 @@ func (s *Scheduler) sendTestPreviews(ctx context.Context) error {
 
 	for _, listing := range listings {
 		camp := s.campaignFor(ctx, &listing)
-
-		// Generate message
-		message, err := camp.Generator.Generate(&listing)
+		message, variant, err := s.composeMessage(ctx, &listing, camp)
 		if err != nil {
 			s.logger.Error("message generation failed", "is24_id", listing.IS24ID, "error", err)
 			continue
 		}
 
-		// Enhance with AI if available
-		if s.enhancer != nil {
-			enhanced, err := s.enhancer.Enhance(ctx, message, &listing, camp.AIPrompt)
-			if err != nil {
-				s.logger.Warn("message enhancement failed, using base message", "error", err)
-			} else {
-				message = enhanced
-			}
-		}
-
 		// Send preview to Telegram
 		if err := s.notifier.NotifyMessagePreview(ctx, &listing, message); err != nil {
 			s.logger.Error("message preview notification failed", "is24_id", listing.IS24ID, "error", err)
@@ -640,6 +1899,7 @@ func (s *Scheduler) sendTestPreviews(ctx context.Context) error {
 			ListingID: listing.ID,
 			IS24ID:    listing.IS24ID,
 			Message:   message,
+			Variant:   variant,
 			Status:    domain.MessageStatusPreview,
 		}
 		if err := s.repo.CreateSentMessage(ctx, previewMsg); err != nil {
@@ -659,8 +1919,199 @@ func (s *Scheduler) sendTestPreviews(ctx context.Context) error {
 	return nil
 }
 
+// composeMessage renders the campaign template for a listing and, when
+// OpenAI is configured, enhances it. A failed enhancement falls back to the
+// template text. variant names the template variant used.
+func (s *Scheduler) composeMessage(ctx context.Context, listing *domain.Listing, camp Campaign) (message, variant string, err error) {
+	message, variant, err = camp.Generator.GenerateVariant(listing)
+	if err != nil {
+		return "", variant, err
+	}
+	if s.enhancer != nil {
+		ctx := messenger.WithLanguage(ctx, camp.Generator.Language(listing))
+		enhanced, err := s.enhancer.Enhance(ctx, message, listing, camp.AIPrompt)
+		if err != nil {
+			s.logger.Warn("message enhancement failed, using base message", "error", err)
+		} else {
+			message = enhanced
+		}
+	}
+	return message, variant, nil
+}
+
+// PreviewMessage sends the message that would go out for one listing as a
+// preview, without touching the contact form (the /preview command). Listings
+// not in the database are fetched from IS24 first. Nothing is recorded, so
+// test mode still previews the listing on its own.
+func (s *Scheduler) PreviewMessage(ctx context.Context, is24ID string) error {
+	listing, err := s.repo.GetListingByIS24ID(ctx, is24ID)
+	if err != nil {
+		return fmt.Errorf("load listing: %w", err)
+	}
+	if listing == nil {
+		if listing, err = s.client.FetchExpose(ctx, is24ID); err != nil {
+			return fmt.Errorf("fetch expose: %w", err)
+		}
+	}
+
+	message, _, err := s.composeMessage(ctx, listing, s.campaignFor(ctx, listing))
+	if err != nil {
+		return fmt.Errorf("generate message: %w", err)
+	}
+	return s.notifier.NotifyMessagePreview(ctx, listing, message)
+}
+
 func (s *Scheduler) notifyError(ctx context.Context, err error) {
+	s.recordError(ctx, "poll", err.Error())
 	if s.notifier != nil {
 		s.notifier.NotifyError(ctx, err.Error())
 	}
 }
+
+// recordError adds an error to the activity log, where the daily digest
+// counts it.
+func (s *Scheduler) recordError(ctx context.Context, details, errMsg string) {
+	if s.repo == nil {
+		return
+	}
+	s.repo.LogActivity(ctx, &domain.ActivityLog{
+		Action:   domain.ActionError,
+		Details:  details,
+		ErrorMsg: errMsg,
+	})
+}
+
+// pollSnapshotKeep is how many poll snapshots are kept; /diff needs two.
+const pollSnapshotKeep = 2
+
+// savePollSnapshot stores the cycle's passed listings for /diff. A cycle with
+// a failed search is not stored: its listings would all look delisted.
+func (s *Scheduler) savePollSnapshot(ctx context.Context, failures int) {
+	if failures > 0 {
+		s.logger.Info("poll snapshot skipped, a search failed", "failures", failures)
+		return
+	}
+	listings := make([]sqlite.SnapshotListing, 0, len(s.snapshot))
+	for _, l := range s.snapshot {
+		listings = append(listings, l)
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].IS24ID < listings[j].IS24ID })
+	if err := s.repo.SavePollSnapshot(ctx, listings, pollSnapshotKeep); err != nil {
+		s.logger.Warn("save poll snapshot failed", "error", err)
+	}
+}
+
+// CycleDiff is what changed between two poll snapshots.
+type CycleDiff struct {
+	From, To     time.Time
+	New          []sqlite.SnapshotListing
+	Gone         []sqlite.SnapshotListing // no longer in the results (delisted or filtered out)
+	PriceChanges []PriceChange
+}
+
+// PriceChange is a listing whose price differs between two snapshots.
+type PriceChange struct {
+	sqlite.SnapshotListing
+	OldPrice int
+}
+
+// diffSnapshots compares prev with cur. Lists are sorted by IS24 ID.
+func diffSnapshots(prev, cur sqlite.PollSnapshot) CycleDiff {
+	d := CycleDiff{From: prev.CreatedAt, To: cur.CreatedAt}
+	before := make(map[string]sqlite.SnapshotListing, len(prev.Listings))
+	for _, l := range prev.Listings {
+		before[l.IS24ID] = l
+	}
+	for _, l := range cur.Listings {
+		old, ok := before[l.IS24ID]
+		switch {
+		case !ok:
+			d.New = append(d.New, l)
+		case old.Price != l.Price && old.Price > 0 && l.Price > 0:
+			d.PriceChanges = append(d.PriceChanges, PriceChange{SnapshotListing: l, OldPrice: old.Price})
+		}
+		delete(before, l.IS24ID)
+	}
+	for _, l := range before {
+		d.Gone = append(d.Gone, l)
+	}
+	sort.Slice(d.New, func(i, j int) bool { return d.New[i].IS24ID < d.New[j].IS24ID })
+	sort.Slice(d.Gone, func(i, j int) bool { return d.Gone[i].IS24ID < d.Gone[j].IS24ID })
+	sort.Slice(d.PriceChanges, func(i, j int) bool { return d.PriceChanges[i].IS24ID < d.PriceChanges[j].IS24ID })
+	return d
+}
+
+// Diff compares the last two stored poll cycles (/diff). nil when fewer
+// than two cycles were recorded yet.
+func (s *Scheduler) Diff(ctx context.Context) (*CycleDiff, error) {
+	snaps, err := s.repo.GetPollSnapshots(ctx, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(snaps) < 2 {
+		return nil, nil
+	}
+	d := diffSnapshots(snaps[1], snaps[0])
+	return &d, nil
+}
+
+// Digest renders the summary of the last 24 hours: counts, the cheapest new
+// listings and any errors or cookie trouble (markup, /summary and daily).
+func (s *Scheduler) Digest(ctx context.Context) (string, error) {
+	d, err := s.repo.GetDigestStats(ctx, 24*time.Hour)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	cookieAlert := s.cookieAlert
+	s.mu.Unlock()
+	return formatDigest(d, cookieAlert), nil
+}
+
+// sendDigest sends the daily digest. Quiet hours don't apply: the user picked
+// the time.
+func (s *Scheduler) sendDigest(ctx context.Context) {
+	text, err := s.Digest(ctx)
+	if err != nil {
+		s.logger.Error("daily digest failed", "error", err)
+		return
+	}
+	if err := s.notifier.SendRawMessage(ctx, text); err != nil {
+		s.logger.Error("daily digest send failed", "error", err)
+		return
+	}
+	s.logger.Info("daily digest sent")
+}
+
+func formatDigest(d *sqlite.DigestStats, cookieAlert bool) string {
+	var sb strings.Builder
+	sb.WriteString("🗓 *Tageszusammenfassung (24h)*\n")
+	sb.WriteString(fmt.Sprintf("\n*Gefunden:* %d", d.Found))
+	sb.WriteString(fmt.Sprintf("\n*Benachrichtigt:* %d", d.Notified))
+	sb.WriteString(fmt.Sprintf("\n*Kontaktiert:* %d", d.Contacted))
+	if d.ContactFailed > 0 {
+		sb.WriteString(fmt.Sprintf(" (%d fehlgeschlagen)", d.ContactFailed))
+	}
+
+	if len(d.Cheapest) > 0 {
+		sb.WriteString("\n\n*Günstigste neue Wohnungen:*")
+		for i, l := range d.Cheapest {
+			sb.WriteString(fmt.Sprintf("\n%d. %d € · %.1f Zi. · %d m² — %s\n🔗 %s",
+				i+1, l.Price, l.Rooms, l.Area, l.Title, l.URL))
+		}
+	}
+
+	switch {
+	case cookieAlert || d.CookieWarnings > 0:
+		sb.WriteString("\n\n⚠️ *Cookie:* Suchen liefern nichts mehr — IS24-Cookie evtl. abgelaufen (/cookie).")
+	case d.Errors == 0:
+		sb.WriteString("\n\n✅ Keine Fehler.")
+	}
+	if d.Errors > 0 {
+		sb.WriteString(fmt.Sprintf("\n\n❌ *Fehler:* %d", d.Errors))
+		if d.LastError != "" {
+			sb.WriteString("\nZuletzt: " + d.LastError)
+		}
+	}
+	return sb.String()
+}
@@ -2,22 +2,58 @@ package scheduler
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/julianbeese/immo_bot/internal/config"
+	"github.com/julianbeese/immo_bot/internal/contact"
 	"github.com/julianbeese/immo_bot/internal/domain"
+	"github.com/julianbeese/immo_bot/internal/filter"
+	"github.com/julianbeese/immo_bot/internal/messenger"
+	"github.com/julianbeese/immo_bot/internal/repository/sqlite"
 )
 
-// fakeNotifier records SendRawMessage calls for cookie-health assertions.
-type fakeNotifier struct{ raw []string }
+// fakeNotifier records SendRawMessage calls for cookie-health assertions and
+// message previews.
+type fakeNotifier struct {
+	raw         []string
+	previews    []string
+	found       []string
+	silent      []string
+	sent        int
+	unconfirmed int
+}
 
-func (f *fakeNotifier) NotifyNewListing(context.Context, *domain.Listing) error  { return nil }
-func (f *fakeNotifier) NotifyContactSent(context.Context, *domain.Listing) error { return nil }
-func (f *fakeNotifier) NotifyContactFailed(context.Context, *domain.Listing, string) error {
+func (f *fakeNotifier) NotifyNewListing(_ context.Context, l *domain.Listing) error {
+	f.found = append(f.found, l.IS24ID)
+	if l.Silent {
+		f.silent = append(f.silent, l.IS24ID)
+	}
+	return nil
+}
+func (f *fakeNotifier) NotifyContactSent(context.Context, *domain.Listing) error {
+	f.sent++
+	return nil
+}
+func (f *fakeNotifier) NotifyContactFailed(context.Context, *domain.Listing, domain.ContactFailure) error {
+	return nil
+}
+func (f *fakeNotifier) NotifyContactUnconfirmed(context.Context, *domain.Listing) error {
+	f.unconfirmed++
 	return nil
 }
 func (f *fakeNotifier) NotifyError(context.Context, string) error { return nil }
-func (f *fakeNotifier) NotifyMessagePreview(context.Context, *domain.Listing, string) error {
+func (f *fakeNotifier) NotifyMessagePreview(_ context.Context, _ *domain.Listing, message string) error {
+	f.previews = append(f.previews, message)
 	return nil
 }
 func (f *fakeNotifier) SendRawMessage(_ context.Context, text string) error {
@@ -97,3 +133,1788 @@ func TestCookieHealthDefersWarningDuringQuietHours(t *testing.T) {
 		t.Fatalf("warning should send after quiet hours, got %d", len(fn.raw))
 	}
 }
+
+func TestNoNewListingsAlertOnceUntilNextFind(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	fn := &fakeNotifier{}
+	cfg := &config.Config{NoNewListingsAlert: 12 * time.Hour}
+	s := &Scheduler{cfg: cfg, repo: repo, notifier: fn, logger: slog.Default()}
+	start := time.Date(2026, 6, 15, 8, 0, 0, 0, time.UTC)
+
+	s.checkNewListings(ctx, 2, 3, false, start)
+	s.checkNewListings(ctx, 2, 0, false, start.Add(11*time.Hour))
+	if len(fn.raw) != 0 {
+		t.Fatalf("alerted too early: %v", fn.raw)
+	}
+
+	// Deferred during quiet hours, sent once afterwards.
+	s.checkNewListings(ctx, 2, 0, true, start.Add(13*time.Hour))
+	s.checkNewListings(ctx, 2, 0, false, start.Add(14*time.Hour))
+	s.checkNewListings(ctx, 2, 0, false, start.Add(20*time.Hour))
+	if len(fn.raw) != 1 || !strings.Contains(fn.raw[0], "Seit 14 Stunden keine neuen Wohnungen") {
+		t.Fatalf("want one alert after 14h, got %v", fn.raw)
+	}
+
+	// A restart picks the last find up from the meta table.
+	s = &Scheduler{cfg: cfg, repo: repo, notifier: fn, logger: slog.Default()}
+	s.checkNewListings(ctx, 2, 0, false, start.Add(21*time.Hour))
+	if len(fn.raw) != 2 || !strings.Contains(fn.raw[1], "Seit 21 Stunden") {
+		t.Fatalf("restart should keep the last find, got %v", fn.raw)
+	}
+
+	// A new find re-arms the alert.
+	s.checkNewListings(ctx, 2, 1, false, start.Add(22*time.Hour))
+	s.checkNewListings(ctx, 2, 0, false, start.Add(30*time.Hour))
+	if len(fn.raw) != 2 {
+		t.Fatalf("new find should reset the clock, got %v", fn.raw)
+	}
+	s.checkNewListings(ctx, 2, 0, false, start.Add(34*time.Hour))
+	if len(fn.raw) != 3 {
+		t.Fatalf("want a new alert after the next dry spell, got %v", fn.raw)
+	}
+}
+
+func TestNoNewListingsAlertOff(t *testing.T) {
+	fn := &fakeNotifier{}
+	s := &Scheduler{cfg: &config.Config{}, notifier: fn, logger: slog.Default()}
+	s.checkNewListings(context.Background(), 2, 0, false, time.Now())
+	if len(fn.raw) != 0 {
+		t.Fatalf("alert is off by default, got %v", fn.raw)
+	}
+}
+
+func TestContactAllowedIndependentOfQuietHours(t *testing.T) {
+	cfg := &config.Config{QuietHours: config.QuietHoursConfig{Timezone: "UTC"}}
+	s := &Scheduler{cfg: cfg, logger: slog.Default()}
+	noon := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2026, 6, 15, 3, 0, 0, 0, time.UTC)
+
+	// Without a contact window, contacts follow quiet hours.
+	if s.contactAllowed(true, noon) {
+		t.Error("no window: quiet hours should block contacts")
+	}
+	if !s.contactAllowed(false, night) {
+		t.Error("no window: outside quiet hours contacts should run")
+	}
+
+	// With a window, only the window counts.
+	cfg.Contact.Window = config.ContactWindowConfig{Enabled: true, Start: "08:00", End: "21:00"}
+	if !s.contactAllowed(true, noon) {
+		t.Error("window: contacts should run inside the window even during quiet hours")
+	}
+	if s.contactAllowed(false, night) {
+		t.Error("window: contacts should wait outside the window even when notifications run")
+	}
+}
+
+// slowClient simulates expose round trips and records peak concurrency.
+type slowClient struct {
+	delay    time.Duration
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *slowClient) Search(context.Context, *domain.SearchProfile) (*domain.SearchResult, error) {
+	return &domain.SearchResult{}, nil
+}
+func (c *slowClient) SetCookie(string) error { return nil }
+func (c *slowClient) FetchExpose(_ context.Context, id string) (*domain.Listing, error) {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	c.mu.Unlock()
+	time.Sleep(c.delay)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	if id == "fail" {
+		return nil, errors.New("boom")
+	}
+	return &domain.Listing{IS24ID: id, Title: "detail " + id}, nil
+}
+
+func TestFetchExposesParallelKeepsOrder(t *testing.T) {
+	var listings []domain.Listing
+	for i := 0; i < 12; i++ {
+		listings = append(listings, domain.Listing{IS24ID: strconv.Itoa(i), SearchProfileID: 7})
+	}
+	listings[5].IS24ID = "fail"
+
+	run := func(concurrency int) (time.Duration, *slowClient, []*domain.Listing) {
+		client := &slowClient{delay: 20 * time.Millisecond}
+		cfg := &config.Config{IS24: config.IS24Config{ExposeConcurrency: concurrency}}
+		s := &Scheduler{cfg: cfg, client: client, logger: slog.Default()}
+		start := time.Now()
+		got := s.fetchExposes(context.Background(), listings)
+		return time.Since(start), client, got
+	}
+
+	serial, serialClient, _ := run(1)
+	if serialClient.peak != 1 {
+		t.Errorf("concurrency 1 should fetch serially, peak = %d", serialClient.peak)
+	}
+
+	parallel, client, got := run(4)
+	if client.peak < 2 || client.peak > 4 {
+		t.Errorf("peak concurrency = %d, want 2..4", client.peak)
+	}
+	t.Logf("12 exposes: serial %v, concurrency 4 %v", serial, parallel)
+	if parallel >= serial {
+		t.Errorf("parallel fetch (%v) should beat serial (%v)", parallel, serial)
+	}
+
+	for i, l := range got {
+		if l.IS24ID != listings[i].IS24ID {
+			t.Fatalf("order not preserved at %d: %s != %s", i, l.IS24ID, listings[i].IS24ID)
+		}
+		if l.SearchProfileID != 7 {
+			t.Errorf("search profile ID lost for %s", l.IS24ID)
+		}
+	}
+	if got[5].Title != "" || !got[5].Incomplete {
+		t.Errorf("failed fetch should fall back to basic data marked incomplete, got %+v", got[5])
+	}
+	if got[0].Incomplete {
+		t.Errorf("fetched expose marked incomplete: %+v", got[0])
+	}
+	if got[0].Title != "detail 0" {
+		t.Errorf("detail not used: %+v", got[0])
+	}
+}
+
+// exposeClient serves canned exposes; gone IDs are removed listings, unknown
+// IDs fail.
+type exposeClient struct {
+	exposes map[string]domain.Listing
+	gone    map[string]bool
+}
+
+func (c *exposeClient) Search(context.Context, *domain.SearchProfile) (*domain.SearchResult, error) {
+	return &domain.SearchResult{}, nil
+}
+func (c *exposeClient) SetCookie(string) error { return nil }
+func (c *exposeClient) FetchExpose(_ context.Context, id string) (*domain.Listing, error) {
+	if c.gone[id] {
+		return nil, domain.ErrListingGone
+	}
+	l, ok := c.exposes[id]
+	if !ok {
+		return nil, errors.New("blocked")
+	}
+	return &l, nil
+}
+
+func TestRetryIncompleteListings(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", Active: true, MaxPrice: 1000}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"complete", "partial", "blocked", "expensive"} {
+		l := &domain.Listing{IS24ID: id, Title: "basic", URL: "u", SearchProfileID: sp.ID, Incomplete: true}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatalf("CreateListing: %v", err)
+		}
+	}
+	if pending, _ := repo.GetUnnotifiedListings(ctx); len(pending) != 0 {
+		t.Fatalf("incomplete listings must not be notified, got %d", len(pending))
+	}
+
+	client := &exposeClient{exposes: map[string]domain.Listing{
+		"complete":  {IS24ID: "complete", Title: "full", URL: "u", Price: 900, Rooms: 2, Area: 60},
+		"partial":   {IS24ID: "partial", Title: "no area", URL: "u", Price: 800, Rooms: 2},
+		"expensive": {IS24ID: "expensive", Title: "pricey", URL: "u", Price: 1500, Rooms: 3, Area: 80},
+	}}
+	s := &Scheduler{repo: repo, client: client, filter: filter.NewEngine(), logger: slog.Default()}
+	s.retryIncompleteListings(ctx)
+
+	// Complete data is released right away; filtered-out listings are dropped.
+	pending, err := repo.GetUnnotifiedListings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].IS24ID != "complete" || pending[0].Title != "full" || pending[0].Incomplete {
+		t.Fatalf("only the complete listing should be released, got %+v", pending)
+	}
+	if l, _ := repo.GetListingByIS24ID(ctx, "expensive"); l != nil {
+		t.Errorf("listing failing the filters after the fetch should be deleted, got %+v", l)
+	}
+	held, err := repo.GetIncompleteListings(ctx, exposeRetryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(held) != 2 {
+		t.Fatalf("partial and blocked should stay held back, got %+v", held)
+	}
+	for _, l := range held {
+		if l.IS24ID == "partial" && l.Title != "no area" {
+			t.Errorf("partial expose data should be saved while held back, got %+v", l)
+		}
+	}
+
+	// At the attempt limit the filters decide on the data available.
+	for i := 1; i < exposeRetryLimit; i++ {
+		s.retryIncompleteListings(ctx)
+	}
+	if held, _ := repo.GetIncompleteListings(ctx, exposeRetryLimit+1); len(held) != 0 {
+		t.Errorf("listings should be released after %d attempts, still held: %+v", exposeRetryLimit, held)
+	}
+	if pending, _ := repo.GetUnnotifiedListings(ctx); len(pending) != 3 {
+		t.Errorf("expected 3 releasable listings after the limit, got %d", len(pending))
+	}
+}
+
+func TestExposeGone(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	held := &domain.Listing{IS24ID: "removed", Title: "basic", URL: "u", Incomplete: true}
+	if err := repo.CreateListing(ctx, held); err != nil {
+		t.Fatal(err)
+	}
+	client := &exposeClient{gone: map[string]bool{"removed": true, "new_removed": true}}
+	s := &Scheduler{cfg: config.DefaultConfig(), repo: repo, client: client, filter: filter.NewEngine(), logger: slog.Default()}
+
+	s.retryIncompleteListings(ctx)
+	if pending, _ := repo.GetIncompleteListings(ctx, exposeRetryLimit); len(pending) != 0 {
+		t.Errorf("delisted listing should not be retried, got %+v", pending)
+	}
+	if pending, _ := repo.GetUnnotifiedListings(ctx); len(pending) != 0 {
+		t.Errorf("delisted listing should not be notified, got %+v", pending)
+	}
+	entries, err := repo.ListListingActivity(ctx, held.ID, 10)
+	if err != nil || len(entries) != 1 || entries[0].Action != domain.ActionDelisted {
+		t.Errorf("activity = %+v (err %v), want one delisted entry", entries, err)
+	}
+
+	details := s.fetchExposes(ctx, []domain.Listing{{IS24ID: "new_removed"}, {IS24ID: "blocked"}})
+	if len(details) != 1 || details[0].IS24ID != "blocked" || !details[0].Incomplete {
+		t.Errorf("details = %+v, want only the blocked listing, incomplete", details)
+	}
+}
+
+type fixedCampaign struct{ camp Campaign }
+
+func (f fixedCampaign) Resolve(string) Campaign { return f.camp }
+
+func TestPreviewMessage(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateListing(ctx, &domain.Listing{IS24ID: "1", Title: "Gespeichert", URL: "u", SearchProfileID: sp.ID}); err != nil {
+		t.Fatal(err)
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := &fakeNotifier{}
+	s := &Scheduler{
+		repo:      repo,
+		client:    &exposeClient{exposes: map[string]domain.Listing{"2": {IS24ID: "2", Title: "Frisch geladen"}}},
+		notifier:  fn,
+		campaigns: fixedCampaign{Campaign{Generator: gen}},
+		logger:    slog.Default(),
+	}
+
+	for _, id := range []string{"1", "2"} {
+		if err := s.PreviewMessage(ctx, id); err != nil {
+			t.Fatalf("PreviewMessage(%s): %v", id, err)
+		}
+	}
+	if len(fn.previews) != 2 || fn.previews[0] != "Anfrage zu Gespeichert" || fn.previews[1] != "Anfrage zu Frisch geladen" {
+		t.Errorf("previews = %q", fn.previews)
+	}
+	if err := s.PreviewMessage(ctx, "3"); err == nil {
+		t.Error("unknown listing whose expose can't be fetched should fail")
+	}
+}
+
+// photos lets a test listing pass contact.require_photos_for_contact.
+var photos = []string{"https://pictures.immobilienscout24.de/listings/1.jpg"}
+
+func TestSendContactsDryRun(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	repo, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	l := &domain.Listing{IS24ID: "1", Title: "Altbau", URL: "u", SearchProfileID: sp.ID, ImageURLs: photos}
+	if err := repo.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Contact.DryRun = true
+	s := &Scheduler{
+		cfg:       cfg,
+		repo:      repo,
+		notifier:  &fakeNotifier{},
+		campaigns: fixedCampaign{Campaign{Generator: gen}},
+		contacter: contact.NewNoopSubmitter(nil),
+		logger:    slog.Default(),
+	}
+
+	if err := s.sendContacts(ctx); err != nil {
+		t.Fatalf("sendContacts: %v", err)
+	}
+	if pending, _ := repo.GetUncontactedListings(ctx); len(pending) != 0 {
+		t.Errorf("dry-run contact should mark the listing contacted, %d left", len(pending))
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var status, message string
+	var test bool
+	if err := db.QueryRow(`SELECT status, message, test FROM sent_messages WHERE listing_id = ?`, l.ID).Scan(&status, &message, &test); err != nil {
+		t.Fatalf("sent_messages row: %v", err)
+	}
+	if status != domain.MessageStatusSent || !test || message != "Anfrage zu Altbau" {
+		t.Errorf("sent_messages = %s/%v/%q, want sent, test, generated message", status, test, message)
+	}
+}
+
+// unconfirmedSubmitter submits without seeing a confirmation.
+type unconfirmedSubmitter struct{ calls int }
+
+func (u *unconfirmedSubmitter) Submit(context.Context, *domain.Listing, string, contact.Profile) error {
+	u.calls++
+	return fmt.Errorf("%w: http status 200", contact.ErrUnconfirmed)
+}
+
+func TestSendContactsUnconfirmed(t *testing.T) {
+	for _, notify := range []bool{true, false} {
+		dbPath := filepath.Join(t.TempDir(), "test.db")
+		repo, err := sqlite.New(dbPath)
+		if err != nil {
+			t.Fatalf("sqlite.New: %v", err)
+		}
+		defer repo.Close()
+		ctx := context.Background()
+
+		l := &domain.Listing{IS24ID: "1", Title: "Altbau", URL: "u", ImageURLs: photos}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+		gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.DefaultConfig()
+		cfg.Contact.NotifyUnconfirmed = notify
+		fn := &fakeNotifier{}
+		sub := &unconfirmedSubmitter{}
+		s := &Scheduler{
+			cfg:       cfg,
+			repo:      repo,
+			notifier:  fn,
+			campaigns: fixedCampaign{Campaign{Generator: gen}},
+			contacter: sub,
+			logger:    slog.Default(),
+		}
+
+		// The second run must not resubmit: the message may have gone out.
+		for i := 0; i < 2; i++ {
+			if err := s.sendContacts(ctx); err != nil {
+				t.Fatalf("sendContacts: %v", err)
+			}
+		}
+		if sub.calls != 1 {
+			t.Errorf("notify=%v: submitted %d times, want 1", notify, sub.calls)
+		}
+		if notify && (fn.unconfirmed != 1 || fn.sent != 0) || !notify && (fn.unconfirmed != 0 || fn.sent != 1) {
+			t.Errorf("notify=%v: unconfirmed=%d sent=%d notifications", notify, fn.unconfirmed, fn.sent)
+		}
+
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var status string
+		if err := db.QueryRow(`SELECT status FROM sent_messages WHERE listing_id = ?`, l.ID).Scan(&status); err != nil {
+			t.Fatalf("sent_messages row: %v", err)
+		}
+		db.Close()
+		if status != domain.MessageStatusUnconfirmed {
+			t.Errorf("notify=%v: status = %q, want %q", notify, status, domain.MessageStatusUnconfirmed)
+		}
+	}
+}
+
+// recordingSubmitter records which listings were submitted.
+type recordingSubmitter struct{ ids []string }
+
+func (r *recordingSubmitter) Submit(_ context.Context, l *domain.Listing, _ string, _ contact.Profile) error {
+	r.ids = append(r.ids, l.IS24ID)
+	return nil
+}
+
+func TestCampaignForProfileContact(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	withPhone := &domain.SearchProfile{Name: "Köln", Active: true, ContactPhone: "+49 151 2222222"}
+	plain := &domain.SearchProfile{Name: "Bonn", Active: true}
+	for _, sp := range []*domain.SearchProfile{withPhone, plain} {
+		if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Scheduler{
+		repo:      repo,
+		campaigns: fixedCampaign{Campaign{Contact: contact.Profile{FirstName: "Max", Phone: "+49 151 1111111", Email: "max@example.com"}}},
+		logger:    slog.Default(),
+	}
+
+	got := s.campaignFor(ctx, &domain.Listing{SearchProfileID: withPhone.ID}).Contact
+	if got.Phone != "+49 151 2222222" || got.Email != "max@example.com" || got.FirstName != "Max" {
+		t.Errorf("profile phone not applied: %+v", got)
+	}
+	if got := s.campaignFor(ctx, &domain.Listing{SearchProfileID: plain.ID}).Contact; got.Phone != "+49 151 1111111" {
+		t.Errorf("profile without phone should keep the campaign's, got %+v", got)
+	}
+}
+
+func TestSendContactsPrivateOnly(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for _, l := range []*domain.Listing{
+		{IS24ID: "1", Title: "Privat", URL: "u1", LandlordType: "privat", ImageURLs: photos},
+		{IS24ID: "2", Title: "Makler", URL: "u2", LandlordType: "gewerblich", ImageURLs: photos},
+		{IS24ID: "3", Title: "Unbekannt", URL: "u3", ImageURLs: photos},
+	} {
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Contact.AutoContactPrivateOnly = true
+	sub := &recordingSubmitter{}
+	s := &Scheduler{
+		cfg:       cfg,
+		repo:      repo,
+		notifier:  &fakeNotifier{},
+		campaigns: fixedCampaign{Campaign{Generator: gen}},
+		contacter: sub,
+		logger:    slog.Default(),
+	}
+	if err := s.sendContacts(ctx); err != nil {
+		t.Fatalf("sendContacts: %v", err)
+	}
+	if len(sub.ids) != 1 || sub.ids[0] != "1" {
+		t.Errorf("submitted %v, want only the private listing", sub.ids)
+	}
+
+	remaining, err := repo.GetUncontactedListings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 {
+		t.Errorf("%d listings left uncontacted, want 2", len(remaining))
+	}
+}
+
+func TestSendContactsMaxAge(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for _, l := range []*domain.Listing{
+		{IS24ID: "1", Title: "Neu", URL: "u1", ImageURLs: photos},
+		{IS24ID: "2", Title: "Alt", URL: "u2", ImageURLs: photos},
+	} {
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.DB().ExecContext(ctx, `UPDATE listings SET created_at = datetime('now', '-3 days') WHERE is24_id = '2'`); err != nil {
+		t.Fatal(err)
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Contact.MaxAge = 24 * time.Hour
+	sub := &recordingSubmitter{}
+	s := &Scheduler{
+		cfg:       cfg,
+		repo:      repo,
+		notifier:  &fakeNotifier{},
+		campaigns: fixedCampaign{Campaign{Generator: gen}},
+		contacter: sub,
+		logger:    slog.Default(),
+	}
+	if err := s.sendContacts(ctx); err != nil {
+		t.Fatalf("sendContacts: %v", err)
+	}
+	if len(sub.ids) != 1 || sub.ids[0] != "1" {
+		t.Errorf("submitted %v, want only the fresh listing", sub.ids)
+	}
+}
+
+func TestListingKnown(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	if err := repo.CreateListing(ctx, &domain.Listing{IS24ID: "1", Title: "Alt", URL: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	s := &Scheduler{repo: repo, logger: slog.Default()}
+	if s.known, err = repo.ListingIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !s.known["1"] || len(s.known) != 1 {
+		t.Fatalf("ListingIDs = %v", s.known)
+	}
+
+	// Stored after the set was loaded: found in the database and cached.
+	if err := repo.CreateListing(ctx, &domain.Listing{IS24ID: "2", Title: "Neu", URL: "u2"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if known, err := s.listingKnown(ctx, id); err != nil || !known {
+			t.Errorf("%s: known=%v err=%v", id, known, err)
+		}
+	}
+	if !s.known["2"] {
+		t.Error("database hit should be cached")
+	}
+	if known, _ := s.listingKnown(ctx, "3"); known || s.known["3"] {
+		t.Error("unknown listing reported as known")
+	}
+}
+
+func TestSaveListingsInsertDecidesNewness(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	notifier := &fakeNotifier{}
+	s := &Scheduler{
+		cfg:               config.DefaultConfig(),
+		repo:              repo,
+		notifier:          notifier,
+		isTestModeEnabled: func() bool { return false },
+		logger:            slog.Default(),
+	}
+
+	// Two profiles found the same new listing and both passed the existence
+	// check before either stored it.
+	profiles := []*domain.SearchProfile{{Name: "A", Active: true}, {Name: "B", Active: true}}
+	for _, sp := range profiles {
+		if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := make([]int, len(profiles))
+	var wg sync.WaitGroup
+	for i, sp := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := &domain.Listing{IS24ID: "7", Title: "Neu", URL: "u7", SearchProfileID: sp.ID}
+			saved[i] = s.saveListings(ctx, []*domain.Listing{l})
+		}()
+	}
+	wg.Wait()
+	if saved[0]+saved[1] != 1 {
+		t.Fatalf("saved %v, want the listing stored once", saved)
+	}
+
+	if err := s.sendNotifications(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.found) != 1 || notifier.found[0] != "7" {
+		t.Errorf("notified %v, want one notification", notifier.found)
+	}
+}
+
+func TestSendNotificationsSilentProfile(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	notifier := &fakeNotifier{}
+	s := &Scheduler{
+		cfg:               config.DefaultConfig(),
+		repo:              repo,
+		notifier:          notifier,
+		isTestModeEnabled: func() bool { return false },
+		logger:            slog.Default(),
+	}
+	hot := &domain.SearchProfile{Name: "Hot", Active: true}
+	watch := &domain.SearchProfile{Name: "Watch", Active: true, Silent: true}
+	for _, sp := range []*domain.SearchProfile{hot, watch} {
+		if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, sp := range map[string]*domain.SearchProfile{"1": hot, "2": watch} {
+		l := &domain.Listing{IS24ID: id, Title: "Whg", URL: "u" + id, SearchProfileID: sp.ID}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.sendNotifications(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.found) != 2 || fmt.Sprint(notifier.silent) != "[2]" {
+		t.Errorf("notified %v, silent %v, want only the watch profile's listing silent", notifier.found, notifier.silent)
+	}
+}
+
+func TestSendContactsPriceOnRequest(t *testing.T) {
+	for _, tt := range []struct {
+		mode string
+		want int
+	}{
+		{config.PriceOnRequestNotify, 1},
+		{config.PriceOnRequestInclude, 2},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatalf("sqlite.New: %v", err)
+			}
+			defer repo.Close()
+			ctx := context.Background()
+
+			for _, l := range []*domain.Listing{
+				{IS24ID: "1", Title: "Mit Preis", URL: "u1", Price: 900, ImageURLs: photos},
+				{IS24ID: "2", Title: "Auf Anfrage", URL: "u2", PriceOnRequest: true, ImageURLs: photos},
+			} {
+				if err := repo.CreateListing(ctx, l); err != nil {
+					t.Fatal(err)
+				}
+				if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.DefaultConfig()
+			cfg.Filter.PriceOnRequest = tt.mode
+			sub := &recordingSubmitter{}
+			s := &Scheduler{
+				cfg:       cfg,
+				repo:      repo,
+				notifier:  &fakeNotifier{},
+				campaigns: fixedCampaign{Campaign{Generator: gen}},
+				contacter: sub,
+				logger:    slog.Default(),
+			}
+			if err := s.sendContacts(ctx); err != nil {
+				t.Fatalf("sendContacts: %v", err)
+			}
+			if len(sub.ids) != tt.want {
+				t.Errorf("submitted %v, want %d", sub.ids, tt.want)
+			}
+		})
+	}
+}
+
+// shutdownSubmitter cancels the poll (like SIGTERM) during the first
+// submission. With wait it then blocks until its own context ends, as a
+// submission cut off by the shutdown grace period would.
+type shutdownSubmitter struct {
+	cancel context.CancelFunc
+	wait   bool
+	calls  int
+}
+
+func (u *shutdownSubmitter) Submit(ctx context.Context, _ *domain.Listing, _ string, _ contact.Profile) error {
+	u.calls++
+	u.cancel()
+	if u.wait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestSendContactsRequiresPhotos(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for _, l := range []*domain.Listing{
+		{IS24ID: "1", Title: "Mit Fotos", URL: "u1", ImageURLs: photos},
+		{IS24ID: "2", Title: "Ohne Fotos", URL: "u2"},
+	} {
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, require := range []bool{true, false} {
+		cfg := config.DefaultConfig()
+		cfg.Contact.RequirePhotosForContact = require
+		sub := &recordingSubmitter{}
+		s := &Scheduler{
+			cfg:       cfg,
+			repo:      repo,
+			notifier:  &fakeNotifier{},
+			campaigns: fixedCampaign{Campaign{Generator: gen}},
+			contacter: sub,
+			logger:    slog.Default(),
+		}
+		if err := s.sendContacts(ctx); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"1"}
+		if !require {
+			want = []string{"2"} // "1" was contacted in the first run
+		}
+		if fmt.Sprint(sub.ids) != fmt.Sprint(want) {
+			t.Errorf("require=%v: submitted %v, want %v", require, sub.ids, want)
+		}
+	}
+}
+
+func TestSendContactsProfileThresholds(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "streng", City: "Berlin", Active: true,
+		ContactMinRooms: 2, ContactMinArea: 50, ContactMaxPrice: 1500}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []*domain.Listing{
+		{IS24ID: "1", Rooms: 2, Area: 60, Price: 1400},
+		{IS24ID: "2", Rooms: 1.5, Area: 60, Price: 1400},
+		{IS24ID: "3", Rooms: 3, Area: 45, Price: 1400},
+		{IS24ID: "4", Rooms: 3, Area: 80, Price: 1600},
+		{IS24ID: "5", Rooms: 3, Area: 80, PriceOnRequest: true},
+	} {
+		l.Title, l.URL, l.ImageURLs, l.SearchProfileID = "Whg "+l.IS24ID, "u"+l.IS24ID, photos, sp.ID
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Filter.PriceOnRequest = config.PriceOnRequestInclude
+	sub := &recordingSubmitter{}
+	s := &Scheduler{
+		cfg:       cfg,
+		repo:      repo,
+		notifier:  &fakeNotifier{},
+		campaigns: fixedCampaign{Campaign{Generator: gen}},
+		contacter: sub,
+		logger:    slog.Default(),
+	}
+	if err := s.sendContacts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(sub.ids) != "[1]" {
+		t.Errorf("submitted %v, want [1]", sub.ids)
+	}
+}
+
+func TestContactQueue(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for _, l := range []*domain.Listing{
+		{IS24ID: "1", ImageURLs: photos},
+		{IS24ID: "2", ImageURLs: photos},
+		{IS24ID: "3"}, // no photos
+		{IS24ID: "4", ImageURLs: photos},
+	} {
+		l.Title, l.URL = "Whg "+l.IS24ID, "u"+l.IS24ID
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+		if l.IS24ID == "4" {
+			if err := repo.MarkListingContacted(ctx, l.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Contact.RequirePhotosForContact = true
+	sub := &recordingSubmitter{}
+	s := &Scheduler{
+		cfg:       cfg,
+		repo:      repo,
+		notifier:  &fakeNotifier{},
+		campaigns: fixedCampaign{Campaign{Generator: gen}},
+		contacter: sub,
+		logger:    slog.Default(),
+	}
+
+	queue, err := s.ContactQueue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, l := range queue {
+		ids = append(ids, l.IS24ID)
+	}
+	if fmt.Sprint(ids) != "[1 2]" {
+		t.Fatalf("queue = %v, want [1 2]", ids)
+	}
+
+	// /cancel takes a listing out; the contact run follows the queue.
+	if err := repo.SetListingSkipped(ctx, queue[0].ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.sendContacts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(sub.ids) != "[2]" {
+		t.Errorf("submitted %v, want [2]", sub.ids)
+	}
+	if queue, err := s.ContactQueue(ctx); err != nil || len(queue) != 0 {
+		t.Errorf("queue after contacting = %v, %v", queue, err)
+	}
+}
+
+func TestAddressKey(t *testing.T) {
+	same := []domain.Listing{
+		{Address: "Musterstraße 12a, 10115, Berlin", PostalCode: "10115"},
+		{Address: "Musterstr. 12 A, 10115 Berlin"},
+		{Address: "musterstrasse 12A", PostalCode: "10115"},
+	}
+	want := addressKey(&same[0])
+	if want != "10115|musterstr12a" {
+		t.Fatalf("addressKey = %q", want)
+	}
+	for _, l := range same[1:] {
+		if got := addressKey(&l); got != want {
+			t.Errorf("%q: addressKey = %q, want %q", l.Address, got, want)
+		}
+	}
+
+	for _, l := range []domain.Listing{
+		{Address: "10115, Berlin", PostalCode: "10115"}, // street hidden
+		{Address: "Musterstraße, 10115 Berlin"},         // no house number
+		{Address: "Musterstraße 12"},                    // no area
+		{},
+	} {
+		if got := addressKey(&l); got != "" {
+			t.Errorf("%q: addressKey = %q, want none", l.Address, got)
+		}
+	}
+	if addressKey(&domain.Listing{Address: "Musterstraße 12", PostalCode: "10115"}) == addressKey(&domain.Listing{Address: "Musterstraße 12", PostalCode: "10117"}) {
+		t.Error("same street in another postal code must not match")
+	}
+}
+
+func TestContactQueueSkipsContactedAddress(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	for _, l := range []*domain.Listing{
+		{IS24ID: "old", Address: "Musterstraße 12, 10115 Berlin", PostalCode: "10115"},
+		{IS24ID: "relisted", Address: "Musterstr. 12, 10115 Berlin", PostalCode: "10115"},
+		{IS24ID: "twin1", Address: "Beispielweg 3, 10115 Berlin", PostalCode: "10115"},
+		{IS24ID: "twin2", Address: "Beispielweg 3, 10115 Berlin", PostalCode: "10115"},
+		{IS24ID: "hidden", Address: "10115 Berlin", PostalCode: "10115"},
+	} {
+		l.Title, l.URL, l.ImageURLs = "Whg "+l.IS24ID, "u"+l.IS24ID, photos
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+			t.Fatal(err)
+		}
+		if l.IS24ID == "old" {
+			if err := repo.MarkListingContacted(ctx, l.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	s := &Scheduler{cfg: cfg, repo: repo, logger: slog.Default()}
+	queueIDs := func() string {
+		t.Helper()
+		queue, err := s.ContactQueue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, l := range queue {
+			ids = append(ids, l.IS24ID)
+		}
+		sort.Strings(ids)
+		return fmt.Sprint(ids)
+	}
+
+	if got := queueIDs(); got != "[hidden twin1]" && got != "[hidden twin2]" {
+		t.Errorf("queue = %v, want hidden and one of the twins", got)
+	}
+
+	cfg.Contact.AddressDedupWindow = 0
+	if got := queueIDs(); got != "[hidden relisted twin1 twin2]" {
+		t.Errorf("check off: queue = %v", got)
+	}
+}
+
+func TestIgnoredListings(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+	if _, err := repo.IgnoreListing(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Filter.IgnoreListingIDs = []string{"3"}
+	s := &Scheduler{cfg: cfg, repo: repo, logger: slog.Default()}
+	s.ignored = s.ignoredListings(ctx)
+
+	var ids []string
+	for _, l := range s.dropIgnored([]domain.Listing{{IS24ID: "1"}, {IS24ID: "2"}, {IS24ID: "3"}, {IS24ID: "4"}}) {
+		ids = append(ids, l.IS24ID)
+	}
+	if got := fmt.Sprint(ids); got != "[1 4]" {
+		t.Errorf("kept %s, want [1 4]", got)
+	}
+	if got := s.contactSkipReason(ctx, &domain.Listing{IS24ID: "3", ImageURLs: photos}, s.ignored); got == "" {
+		t.Error("configured ID should never be auto-contacted")
+	}
+}
+
+func TestIgnoredListingAlreadyStored(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+	for _, id := range []string{"1", "2"} {
+		l := &domain.Listing{IS24ID: id, Title: "Whg", URL: "u" + id, ImageURLs: photos}
+		if err := repo.CreateListing(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// "2" was stored before it was added to the config.
+	cfg := config.DefaultConfig()
+	cfg.Filter.IgnoreListingIDs = []string{"2"}
+	notifier := &fakeNotifier{}
+	s := &Scheduler{
+		cfg:               cfg,
+		repo:              repo,
+		notifier:          notifier,
+		isTestModeEnabled: func() bool { return false },
+		logger:            slog.Default(),
+	}
+	s.ignored = s.ignoredListings(ctx)
+
+	if err := s.sendNotifications(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(notifier.found); got != "[1]" {
+		t.Errorf("notified %s, want [1]", got)
+	}
+	queue, err := s.contactQueue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 1 || queue[0].IS24ID != "1" {
+		t.Errorf("queue = %v, want only 1", queue)
+	}
+}
+
+func TestCollapseDuplicates(t *testing.T) {
+	flat := func(id, company, name string, score int) *domain.Listing {
+		return &domain.Listing{IS24ID: id, LandlordCompany: company, LandlordName: name,
+			Price: 1200, Rooms: 2.5, Area: 68, QualityScore: score}
+	}
+	listings := []*domain.Listing{
+		flat("a1", "Muster Immobilien GmbH", "", 60),
+		flat("b", "Andere Makler AG", "", 50),
+		flat("a2", "Muster  Immobilien gmbh", "", 75),
+		flat("p1", "", "Frau Schmidt", 40),
+		flat("p2", "", "Frau Schmidt", 40),
+		flat("anon1", "", "", 30),
+		flat("anon2", "", "", 30),
+	}
+	other := flat("a3", "Muster Immobilien GmbH", "", 90)
+	other.Area = 80
+	listings = append(listings, other)
+
+	keep, twins := collapseDuplicates(listings)
+	var keptIDs, twinIDs []string
+	for _, l := range keep {
+		keptIDs = append(keptIDs, fmt.Sprintf("%s:%d", l.IS24ID, l.Duplicates))
+	}
+	for _, l := range twins {
+		twinIDs = append(twinIDs, l.IS24ID)
+	}
+	if got := fmt.Sprint(keptIDs); got != "[b:0 a2:1 p1:1 anon1:0 anon2:0 a3:0]" {
+		t.Errorf("kept %s", got)
+	}
+	if got := fmt.Sprint(twinIDs); got != "[a1 p2]" {
+		t.Errorf("twins %s", got)
+	}
+}
+
+func TestSortContactQueue(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	// Newest first, as GetUncontactedListings returns them.
+	listings := func() []domain.Listing {
+		return []domain.Listing{
+			{IS24ID: "fresh_weak", QualityScore: 40, CreatedAt: now.Add(-time.Hour)},
+			{IS24ID: "fresh_good", QualityScore: 70, CreatedAt: now.Add(-2 * time.Hour)},
+			{IS24ID: "old_best", QualityScore: 95, CreatedAt: now.Add(-48 * time.Hour)},
+			{IS24ID: "old_good", QualityScore: 70, CreatedAt: now.Add(-72 * time.Hour)},
+		}
+	}
+	for order, want := range map[string]string{
+		config.ContactOrderNewest:   "[fresh_weak fresh_good old_best old_good]",
+		config.ContactOrderScore:    "[old_best fresh_good old_good fresh_weak]",
+		config.ContactOrderBalanced: "[fresh_good fresh_weak old_best old_good]",
+	} {
+		l := listings()
+		sortContactQueue(l, order, now)
+		var ids []string
+		for _, x := range l {
+			ids = append(ids, x.IS24ID)
+		}
+		if got := fmt.Sprint(ids); got != want {
+			t.Errorf("%s: order = %s, want %s", order, got, want)
+		}
+	}
+}
+
+func TestSendContactsShutdownMidBatch(t *testing.T) {
+	defer func(g time.Duration) { contactShutdownGrace = g }(contactShutdownGrace)
+	contactShutdownGrace = 10 * time.Millisecond
+
+	for _, wait := range []bool{false, true} {
+		dbPath := filepath.Join(t.TempDir(), "test.db")
+		repo, err := sqlite.New(dbPath)
+		if err != nil {
+			t.Fatalf("sqlite.New: %v", err)
+		}
+		for _, id := range []string{"1", "2"} {
+			l := &domain.Listing{IS24ID: id, Title: "Whg " + id, URL: "u" + id, ImageURLs: photos}
+			if err := repo.CreateListing(context.Background(), l); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.MarkListingNotified(context.Background(), l.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		gen, err := messenger.NewGeneratorFromText("Anfrage zu {{.Title}}")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		sub := &shutdownSubmitter{cancel: cancel, wait: wait}
+		s := &Scheduler{
+			cfg:       config.DefaultConfig(),
+			repo:      repo,
+			notifier:  &fakeNotifier{},
+			campaigns: fixedCampaign{Campaign{Generator: gen}},
+			contacter: sub,
+			logger:    slog.Default(),
+		}
+		if err := s.sendContacts(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("wait=%v: sendContacts = %v, want context.Canceled", wait, err)
+		}
+		if sub.calls != 1 {
+			t.Errorf("wait=%v: %d submissions after shutdown, want 1", wait, sub.calls)
+		}
+
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pending, rows int
+		if err := db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(status = ?), 0) FROM sent_messages`, domain.MessageStatusPending).Scan(&rows, &pending); err != nil {
+			t.Fatal(err)
+		}
+		var contacted int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM listings WHERE contacted = 1`).Scan(&contacted); err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+		repo.Close()
+
+		if rows != 1 || pending != 0 {
+			t.Errorf("wait=%v: %d sent_messages, %d pending; want 1, none pending", wait, rows, pending)
+		}
+		// A finished submission counts; an aborted one is retried next start.
+		if want := map[bool]int{false: 1, true: 0}[wait]; contacted != want {
+			t.Errorf("wait=%v: %d listings contacted, want %d", wait, contacted, want)
+		}
+	}
+}
+
+// searchClient returns fixed search results; exposes are the search data.
+type searchClient struct {
+	results   []domain.Listing
+	truncated bool
+}
+
+func (c *searchClient) Search(context.Context, *domain.SearchProfile) (*domain.SearchResult, error) {
+	return &domain.SearchResult{Listings: c.results, PagesCrawled: 1, Truncated: c.truncated}, nil
+}
+func (c *searchClient) SetCookie(string) error { return nil }
+func (c *searchClient) FetchExpose(_ context.Context, id string) (*domain.Listing, error) {
+	for _, l := range c.results {
+		if l.IS24ID == id {
+			return &l, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func TestSelfTestSavesNothing(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	cfg := config.DefaultConfig()
+	s := &Scheduler{cfg: cfg, repo: repo, client: &searchClient{}, logger: slog.Default()}
+	if r := s.selfTest(ctx); r.SearchErr == nil {
+		t.Error("self-test without URL or profile should fail")
+	}
+
+	sp := &domain.SearchProfile{Name: "Berlin", Active: true, SearchURL: "https://www.immobilienscout24.de/Suche/de/berlin/wohnung-mieten"}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	s.client = &searchClient{results: []domain.Listing{
+		{IS24ID: "1", Title: "Komplett", Price: 1000, Rooms: 2, Area: 50},
+		{IS24ID: "2", Title: "Ohne Fläche", Price: 900, Rooms: 2},
+	}}
+	r := s.selfTest(ctx)
+	if r.SearchErr != nil || r.URL != sp.SearchURL || r.Hits != 2 || r.Complete != 1 {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.ExposeID != "1" || r.ExposeErr != nil || r.Expose == nil || r.Expose.Title != "Komplett" {
+		t.Errorf("expose = %q %v %+v", r.ExposeID, r.ExposeErr, r.Expose)
+	}
+	if exists, _ := repo.ListingExists(ctx, "1"); exists {
+		t.Error("self-test stored a listing")
+	}
+}
+
+func TestCalibrationReportsDrops(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "Neu", Active: true, MaxPrice: 1000, CalibrationCycles: 2}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	client := &searchClient{results: []domain.Listing{
+		{IS24ID: "1", Title: "passt", URL: "u1", Price: 900, Rooms: 2, Area: 50, SearchProfileID: sp.ID},
+		{IS24ID: "2", Title: "zu teuer", URL: "u2", Price: 1500, Rooms: 2, Area: 50, SearchProfileID: sp.ID},
+	}}
+	notif := &fakeNotifier{}
+	quietOff := false
+	s := &Scheduler{
+		cfg: config.DefaultConfig(), repo: repo, client: client, filter: filter.NewEngine(),
+		notifier: notif, logger: slog.Default(),
+		isNotifyEnabled:     func() bool { return true },
+		isQuietHoursEnabled: func() *bool { return &quietOff },
+	}
+
+	profile := func() *domain.SearchProfile {
+		p, err := repo.GetSearchProfileByID(ctx, sp.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	if _, _, err := s.processProfile(ctx, profile()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notif.raw) != 1 || !strings.Contains(notif.raw[0], "zu teuer") || !strings.Contains(notif.raw[0], "price_too_high") {
+		t.Fatalf("expected one drop report with its reason, got %q", notif.raw)
+	}
+	if got := profile().CalibrationCycles; got != 1 {
+		t.Errorf("CalibrationCycles = %d, want 1", got)
+	}
+
+	// Already reported drops are not repeated; the last cycle announces the end.
+	notif.raw = nil
+	if _, _, err := s.processProfile(ctx, profile()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notif.raw) != 1 || !strings.Contains(notif.raw[0], "beendet") {
+		t.Fatalf("expected only the end notice, got %q", notif.raw)
+	}
+
+	notif.raw = nil
+	client.results = append(client.results, domain.Listing{IS24ID: "3", Title: "auch zu teuer", URL: "u3", Price: 2000, SearchProfileID: sp.ID})
+	if _, _, err := s.processProfile(ctx, profile()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notif.raw) != 0 {
+		t.Errorf("after calibration drops must not be reported, got %q", notif.raw)
+	}
+}
+
+func TestScanAndSave(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	known := &domain.Listing{IS24ID: "1", Title: "bekannt", URL: "u1"}
+	if err := repo.CreateListing(ctx, known); err != nil {
+		t.Fatal(err)
+	}
+	client := &searchClient{results: []domain.Listing{
+		{IS24ID: "1", Title: "bekannt", URL: "u1", Price: 900},
+		{IS24ID: "2", Title: "neu", URL: "u2", Price: 5},
+	}}
+	s := &Scheduler{cfg: config.DefaultConfig(), repo: repo, client: client, filter: filter.NewEngine(), logger: slog.Default()}
+
+	hits, err := s.scan(ctx, "https://www.immobilienscout24.de/Suche/de/berlin/berlin/wohnung-mieten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || !hits[0].Known || hits[1].Known {
+		t.Fatalf("hits = %+v", hits)
+	}
+	if l, _ := repo.GetListingByIS24ID(ctx, "2"); l != nil {
+		t.Fatal("scan must not save anything")
+	}
+
+	n, err := s.SaveScan(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("SaveScan = %d, %v; want 1 (no filters applied)", n, err)
+	}
+	l, err := repo.GetListingByIS24ID(ctx, "2")
+	if err != nil || l == nil || !l.Notified || l.SearchProfileID != 0 {
+		t.Fatalf("saved listing = %+v (err %v)", l, err)
+	}
+	if n, _ := s.SaveScan(ctx); n != 0 {
+		t.Errorf("a scan is saved once, second save stored %d", n)
+	}
+}
+
+func TestTrimDescription(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxStoredDescriptionLength = 5
+	s := &Scheduler{cfg: cfg}
+
+	l := &domain.Listing{Description: "Schöne Wohnung"}
+	s.trimDescription(l)
+	if l.Description != "Schön…" {
+		t.Errorf("Description = %q, want 5 characters plus ellipsis", l.Description)
+	}
+
+	short := &domain.Listing{Description: "Kurz"}
+	s.trimDescription(short)
+	if short.Description != "Kurz" {
+		t.Errorf("short description changed: %q", short.Description)
+	}
+
+	cfg.MaxStoredDescriptionLength = 0
+	l.Description = "Schöne Wohnung"
+	s.trimDescription(l)
+	if l.Description != "Schöne Wohnung" {
+		t.Errorf("0 should keep the full text, got %q", l.Description)
+	}
+}
+
+func TestTriggerPollRefusesOverlap(t *testing.T) {
+	s := &Scheduler{logger: slog.Default()}
+	s.pollMu.Lock()
+	if s.TriggerPoll(context.Background(), nil) {
+		t.Fatal("TriggerPoll should refuse while a cycle is running")
+	}
+	if _, err := s.poll(context.Background()); !errors.Is(err, ErrPollInProgress) {
+		t.Fatalf("poll during running cycle: err = %v, want ErrPollInProgress", err)
+	}
+	s.pollMu.Unlock()
+}
+
+// blockingClient's Search blocks until its context is cancelled.
+type blockingClient struct {
+	searchClient
+	started chan struct{}
+}
+
+func (c *blockingClient) Search(ctx context.Context, _ *domain.SearchProfile) (*domain.SearchResult, error) {
+	close(c.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestStopCancelsTriggeredPoll(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	sp := &domain.SearchProfile{Name: "Berlin", Active: true, SearchURL: "https://www.immobilienscout24.de/Suche/de/berlin/wohnung-mieten"}
+	if err := repo.CreateSearchProfile(context.Background(), sp); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &blockingClient{started: make(chan struct{})}
+	s := NewScheduler(config.DefaultConfig(), repo, client, nil, &fakeNotifier{}, nil, nil, nil, slog.Default())
+	// Stand in for Start without a scheduled cycle.
+	s.running = true
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	close(s.doneCh)
+
+	finished := make(chan struct{})
+	if !s.TriggerPoll(context.Background(), func(int, error) { close(finished) }) {
+		t.Fatal("TriggerPoll refused on an idle scheduler")
+	}
+	<-client.started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop blocked on a triggered poll")
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("Stop returned before the triggered poll finished")
+	}
+}
+
+func TestFormatDigest(t *testing.T) {
+	d := &sqlite.DigestStats{
+		Found: 4, Notified: 3, Contacted: 1, ContactFailed: 2,
+		Cheapest: []domain.Listing{{Title: "Altbau", URL: "https://x/1", Price: 800, Rooms: 2, Area: 55}},
+	}
+	got := formatDigest(d, false)
+	for _, want := range []string{"*Gefunden:* 4", "*Kontaktiert:* 1 (2 fehlgeschlagen)", "1. 800 € · 2.0 Zi. · 55 m² — Altbau", "Keine Fehler"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("digest missing %q:\n%s", want, got)
+		}
+	}
+
+	d.Errors, d.LastError = 1, "boom"
+	got = formatDigest(d, true)
+	for _, want := range []string{"Cookie", "*Fehler:* 1", "Zuletzt: boom"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("digest missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Keine Fehler") {
+		t.Errorf("digest with errors claims none:\n%s", got)
+	}
+}
+
+func TestRateKeyFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.IS24.RateOverrides = map[string]config.RateLimit{
+		"berlin":  {MaxRequestsPerMinute: 30},
+		"Familie": {MinDelay: 5 * time.Second},
+	}
+	s := &Scheduler{cfg: cfg}
+	tests := []struct {
+		profile domain.SearchProfile
+		want    string
+	}{
+		{domain.SearchProfile{SearchURL: "https://www.immobilienscout24.de/Suche/de/berlin/berlin/wohnung-mieten?price=-1500"}, "berlin"},
+		{domain.SearchProfile{City: "Berlin"}, "berlin"},
+		{domain.SearchProfile{Name: "familie", SearchURL: "https://www.immobilienscout24.de/Suche/de/hamburg/hamburg/wohnung-mieten"}, "Familie"},
+		{domain.SearchProfile{Name: "Single", SearchURL: "https://www.immobilienscout24.de/Suche/de/bayern/muenchen/wohnung-mieten"}, ""},
+	}
+	for _, tt := range tests {
+		if got := s.rateKeyFor(&tt.profile); got != tt.want {
+			t.Errorf("rateKeyFor(%+v) = %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestPriceChangeRecorded(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	client := &searchClient{results: []domain.Listing{
+		{IS24ID: "1", Title: "Altbau", URL: "u1", Price: 900, Rooms: 2, Area: 50, SearchProfileID: sp.ID},
+	}}
+	s := &Scheduler{
+		cfg: config.DefaultConfig(), repo: repo, client: client, filter: filter.NewEngine(),
+		notifier: &fakeNotifier{}, logger: slog.Default(),
+		isNotifyEnabled: func() bool { return true },
+	}
+
+	if _, _, err := s.processProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	client.results[0].Price = 850
+	if _, _, err := s.processProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := repo.GetListingByIS24ID(ctx, "1")
+	if err != nil || l.Price != 850 {
+		t.Fatalf("stored listing = %+v (err %v), want price 850", l, err)
+	}
+	logs, err := repo.ListListingActivity(ctx, l.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[1].Action != domain.ActionPriceChanged || logs[1].Details != "900 € → 850 €" {
+		t.Errorf("activity = %+v, want listing_found then the price change", logs)
+	}
+}
+
+func TestPriceAlertDue(t *testing.T) {
+	now := time.Now()
+	cfg := config.PriceAlertConfig{MinChange: 50, MinChangePercent: 5, Cooldown: 24 * time.Hour}
+	tests := []struct {
+		name        string
+		cfg         config.PriceAlertConfig
+		base, price int
+		last        time.Time
+		want        bool
+	}{
+		{"absolute threshold", cfg, 2000, 1950, time.Time{}, true},
+		{"percent threshold", cfg, 800, 760, time.Time{}, true},
+		{"below both", cfg, 2000, 1980, time.Time{}, false},
+		{"in cooldown", cfg, 1000, 900, now.Add(-time.Hour), false},
+		{"after cooldown", cfg, 1000, 900, now.Add(-25 * time.Hour), true},
+		{"no thresholds", config.PriceAlertConfig{}, 1000, 999, time.Time{}, true},
+		{"unchanged", config.PriceAlertConfig{}, 1000, 1000, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		if got := priceAlertDue(tt.cfg, tt.base, tt.price, tt.last, now); got != tt.want {
+			t.Errorf("%s: priceAlertDue = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPriceAlertThrottled(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	l := &domain.Listing{IS24ID: "1", Title: "Altbau", URL: "u1", Price: 1000}
+	if err := repo.CreateListing(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkListingNotified(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.PriceAlerts.Enabled = true
+	notifier := &fakeNotifier{}
+	quietOff := false
+	s := &Scheduler{
+		cfg: cfg, repo: repo, notifier: notifier, logger: slog.Default(),
+		isNotifyEnabled:     func() bool { return true },
+		isQuietHoursEnabled: func() *bool { return &quietOff },
+	}
+
+	for _, price := range []int{990, 900, 850} {
+		l.Price = price
+		s.trackPriceChanges(ctx, []domain.Listing{*l})
+	}
+	// 990 is below the threshold, 900 is reported, 850 falls in the cooldown.
+	if len(notifier.raw) != 1 || !strings.Contains(notifier.raw[0], "1000 € → 900 €") {
+		t.Fatalf("messages = %q, want one for 1000 € → 900 €", notifier.raw)
+	}
+	_, price, at, err := repo.PriceAlertState(ctx, l.ID)
+	if err != nil || price != 900 || at.IsZero() {
+		t.Errorf("alert state = %d at %v (err %v), want 900 with a time", price, at, err)
+	}
+}
+
+func TestTruncatedSearchReportedOnce(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "Berlin", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	client := &searchClient{truncated: true}
+	notif := &fakeNotifier{}
+	quietOff := false
+	s := &Scheduler{
+		cfg: config.DefaultConfig(), repo: repo, client: client, filter: filter.NewEngine(),
+		notifier: notif, logger: slog.Default(),
+		isNotifyEnabled:     func() bool { return true },
+		isQuietHoursEnabled: func() *bool { return &quietOff },
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := s.processProfile(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(notif.raw) != 1 || !strings.Contains(notif.raw[0], "Suche abgeschnitten: Berlin") {
+		t.Fatalf("messages = %q, want one truncation warning", notif.raw)
+	}
+
+	// Once the profile fits again, a new truncation is reported again.
+	client.truncated = false
+	if _, _, err := s.processProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	client.truncated = true
+	if _, _, err := s.processProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if len(notif.raw) != 2 {
+		t.Errorf("messages = %d, want a second warning after the profile recovered", len(notif.raw))
+	}
+}
+
+func TestProfileDelay(t *testing.T) {
+	if got := profileDelay(0, 0, nil); got != 0 {
+		t.Errorf("default delay = %v, want 0", got)
+	}
+	half := func(n int64) int64 { return n / 2 }
+	if got := profileDelay(10*time.Second, 20*time.Second, half); got != 20*time.Second {
+		t.Errorf("delay = %v, want 10s + 10s jitter", got)
+	}
+	if got := profileDelay(5*time.Second, 0, half); got != 5*time.Second {
+		t.Errorf("delay without jitter = %v, want 5s", got)
+	}
+}
+
+func TestPauseBetweenProfilesStopsOnShutdown(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.IS24.ProfileDelay = time.Hour
+	s := &Scheduler{cfg: cfg, logger: slog.Default()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s.pauseBetweenProfiles(ctx) {
+		t.Error("pause should end with false once the context is done")
+	}
+}
+
+type warmUpClient struct {
+	searchClient
+	warmUps int
+}
+
+func (c *warmUpClient) WarmUp(context.Context) error {
+	c.warmUps++
+	return errors.New("blocked")
+}
+
+func TestSettle(t *testing.T) {
+	cfg := config.DefaultConfig()
+	client := &warmUpClient{}
+	s := &Scheduler{cfg: cfg, client: client, logger: slog.Default(), stopCh: make(chan struct{})}
+	if !s.settle(context.Background()) || client.warmUps != 0 {
+		t.Fatalf("default settle should neither wait nor warm up (warm-ups %d)", client.warmUps)
+	}
+
+	// A failed warm-up doesn't stop the first poll.
+	cfg.IS24.WarmUp = true
+	if !s.settle(context.Background()) || client.warmUps != 1 {
+		t.Errorf("warm-ups = %d, want 1", client.warmUps)
+	}
+
+	cfg.IS24.StartupDelay = time.Hour
+	close(s.stopCh)
+	if s.settle(context.Background()) {
+		t.Error("settle should end with false once the scheduler stops")
+	}
+	if client.warmUps != 1 {
+		t.Error("warm-up ran after stop")
+	}
+}
+
+func TestCycleDiff(t *testing.T) {
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	sp := &domain.SearchProfile{Name: "P", Active: true}
+	if err := repo.CreateSearchProfile(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	listing := func(id string, price int) domain.Listing {
+		return domain.Listing{IS24ID: id, Title: "Whg " + id, URL: "u" + id, Price: price, Rooms: 2, Area: 50, SearchProfileID: sp.ID}
+	}
+	client := &searchClient{}
+	s := NewScheduler(config.DefaultConfig(), repo, client, filter.NewEngine(), &fakeNotifier{}, nil, nil, nil, slog.Default())
+
+	if d, err := s.Diff(ctx); err != nil || d != nil {
+		t.Fatalf("Diff before two cycles = %+v, %v; want nil", d, err)
+	}
+	for _, results := range [][]domain.Listing{
+		{listing("1", 900), listing("2", 1000)},
+		{listing("2", 950), listing("3", 800)},
+	} {
+		client.results = results
+		if err := s.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := s.Diff(ctx)
+	if err != nil || d == nil {
+		t.Fatalf("Diff = %+v, %v", d, err)
+	}
+	if len(d.New) != 1 || d.New[0].IS24ID != "3" {
+		t.Errorf("New = %+v, want listing 3", d.New)
+	}
+	if len(d.Gone) != 1 || d.Gone[0].IS24ID != "1" {
+		t.Errorf("Gone = %+v, want listing 1", d.Gone)
+	}
+	if len(d.PriceChanges) != 1 || d.PriceChanges[0].IS24ID != "2" || d.PriceChanges[0].OldPrice != 1000 || d.PriceChanges[0].Price != 950 {
+		t.Errorf("PriceChanges = %+v, want listing 2 from 1000 to 950", d.PriceChanges)
+	}
+}
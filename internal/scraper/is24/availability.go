@@ -0,0 +1,30 @@
+package is24
+
+import (
+	"regexp"
+)
+
+// IS24 has two move-in dates: "Verfügbar ab" (availableFrom) and "Bezugsfrei
+// ab" (freeFrom). On purchases the latter says whether the flat comes vacant
+// or stays let ("vermietet").
+var (
+	availableFromJSONRe = regexp.MustCompile(`"availableFrom"\s*:\s*"([^"]+)"`)
+	vacantFromJSONRe    = regexp.MustCompile(`"freeFrom"\s*:\s*"([^"]+)"`)
+	availableFromHTMLRe = regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-verfuegbar-ab\b[^"]*"[^>]*>\s*([^<]+?)\s*</dd>`)
+	vacantFromHTMLRe    = regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-bezugsfrei-ab\b[^"]*"[^>]*>\s*([^<]+?)\s*</dd>`)
+)
+
+// moveInDates returns a realEstate object's "Verfügbar ab" and "Bezugsfrei
+// ab" texts, "" for the ones it doesn't have.
+func moveInDates(realEstate map[string]interface{}) (available, vacant string) {
+	available, _ = realEstate["availableFrom"].(string)
+	vacant, _ = realEstate["freeFrom"].(string)
+	return cleanText(available), cleanText(vacant)
+}
+
+// exposeMoveInDates finds the "Verfügbar ab" and "Bezugsfrei ab" texts in an
+// expose page's embedded data or its criteria list.
+func exposeMoveInDates(page string) (available, vacant string) {
+	return firstMatch(page, []*regexp.Regexp{availableFromJSONRe, availableFromHTMLRe}),
+		firstMatch(page, []*regexp.Regexp{vacantFromJSONRe, vacantFromHTMLRe})
+}
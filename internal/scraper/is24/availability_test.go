@@ -0,0 +1,51 @@
+package is24
+
+import "testing"
+
+// kaufExpose is a trimmed purchase expose: Kaufpreis, Hausgeld and both
+// move-in criteria, the flat still let.
+const kaufExpose = `<html><body>
+<h1 id="expose-title">Vermietete 3-Zimmer-Wohnung als Kapitalanlage</h1>
+<dl class="grid"><dt>Kaufpreis</dt><dd class="is24qa-kaufpreis grid-item three-fifths">329.000 €</dd></dl>
+<dl class="grid"><dt>Hausgeld</dt><dd class="is24qa-hausgeld grid-item three-fifths">295 €</dd></dl>
+<dl class="grid"><dt>Verfügbar ab</dt><dd class="is24qa-verfuegbar-ab grid-item three-fifths"> sofort </dd></dl>
+<dl class="grid"><dt>Bezugsfrei ab</dt><dd class="is24qa-bezugsfrei-ab grid-item three-fifths">vermietet</dd></dl>
+</body></html>`
+
+func TestParseExposeMoveInDates(t *testing.T) {
+	l, err := NewParser().ParseExpose([]byte(kaufExpose), "904")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.AvailableFrom != "sofort" || l.VacantFrom != "vermietet" {
+		t.Errorf("available = %q, vacant = %q", l.AvailableFrom, l.VacantFrom)
+	}
+	if label, date := l.MoveIn(); label != "Bezugsfrei ab" || date != "vermietet" {
+		t.Errorf("MoveIn = %q %q, want the Bezugsfrei date on a purchase", label, date)
+	}
+
+	l, err = NewParser().ParseExpose([]byte(`<script>{"freeFrom":"01.03.2025"}</script>`), "905")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.AvailableFrom != "" || l.VacantFrom != "01.03.2025" {
+		t.Errorf("embedded: available = %q, vacant = %q", l.AvailableFrom, l.VacantFrom)
+	}
+}
+
+func TestMoveInDatesFromResult(t *testing.T) {
+	l := NewParser().resultToListing(map[string]interface{}{
+		"@id": "/expose/906",
+		"realEstate": map[string]interface{}{
+			"@xsi.type":     "search:ApartmentRent",
+			"availableFrom": "nach Vereinbarung",
+			"freeFrom":      " 1.5.2025 ",
+		},
+	})
+	if l.AvailableFrom != "nach Vereinbarung" || l.VacantFrom != "1.5.2025" {
+		t.Errorf("available = %q, vacant = %q", l.AvailableFrom, l.VacantFrom)
+	}
+	if label, date := l.MoveIn(); label != "Ab" || date != "nach Vereinbarung" {
+		t.Errorf("MoveIn = %q %q, want the Verfügbar date on a rental", label, date)
+	}
+}
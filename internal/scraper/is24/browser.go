@@ -3,6 +3,7 @@ package is24
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 	"sync"
@@ -21,6 +22,8 @@ type BrowserClient struct {
 	rateLimiter *antidetect.RateLimiter
 	parser      *Parser
 	chromePath  string
+	site        Site
+	challenge   Challenge
 	debug       bool
 }
 
@@ -38,25 +41,52 @@ func NewBrowserClient(cookie string, rateLimiter *antidetect.RateLimiter, chrome
 		rateLimiter: rateLimiter,
 		parser:      NewParser(),
 		chromePath:  chromePath,
+		site:        DefaultSite,
+		challenge:   DefaultChallenge,
 		debug:       os.Getenv("DEBUG_HTML") == "1",
 	}
 }
 
-// Search performs a search using browser automation with pagination
-func (c *BrowserClient) Search(ctx context.Context, profile *domain.SearchProfile) ([]domain.Listing, error) {
+// SetSite switches the client to another IS24 country site (empty fields
+// keep the German defaults). Call before the first request.
+func (c *BrowserClient) SetSite(site Site) error {
+	site = site.withDefaults()
+	if err := site.Validate(); err != nil {
+		return err
+	}
+	c.site = site
+	c.parser.baseURL = site.BaseURL
+	return nil
+}
+
+// SetChallenge changes how the WAF challenge page and the real page behind
+// it are recognized (empty fields keep the defaults). Call before the first
+// request.
+func (c *BrowserClient) SetChallenge(ch Challenge) {
+	c.challenge = ch.withDefaults()
+}
+
+// Search performs a search using browser automation with pagination. The
+// result is Truncated when the result list has more than maxPages pages.
+func (c *BrowserClient) Search(ctx context.Context, profile *domain.SearchProfile) (*domain.SearchResult, error) {
 	searchURL := profile.SearchURL
 	if searchURL == "" {
-		searchURL = fmt.Sprintf("https://www.immobilienscout24.de/Suche/de/%s/wohnung-mieten", profile.City)
+		searchURL = c.site.CitySearchURL(profile.City)
 	}
 
-	var allListings []domain.Listing
+	result := &domain.SearchResult{}
 	seenIDs := make(map[string]bool)
 	maxPages := 5 // Limit to avoid too many requests
 
-	for page := 1; page <= maxPages; page++ {
+	// lastPage comes from the result list's paging metadata once page 1 is
+	// parsed; without it the walk stops on a short page.
+	lastPage, pagingKnown, ended := maxPages, false, false
+	for page := 1; page <= lastPage; page++ {
 		pageURL := c.buildPageURL(searchURL, page)
 
-		c.rateLimiter.Wait()
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
 
 		html, err := c.fetchPage(ctx, pageURL)
 		if err != nil {
@@ -73,9 +103,22 @@ func (c *BrowserClient) Search(ctx context.Context, profile *domain.SearchProfil
 		if err != nil {
 			return nil, fmt.Errorf("parse search page %d: %w", page, err)
 		}
+		result.PagesCrawled = page
+
+		if page == 1 {
+			if paging := parseSearchPaging(html, len(listings)); paging.Pages > 0 {
+				pagingKnown = true
+				lastPage = min(paging.Pages, maxPages)
+				result.TotalHits = paging.Total
+				result.Truncated = paging.Pages > maxPages
+				slog.Info("search paging", "profile", profile.Name, "total", paging.Total,
+					"pages", paging.Pages, "fetching", lastPage)
+			}
+		}
 
 		// No more results on this page
 		if len(listings) == 0 {
+			ended = true
 			break
 		}
 
@@ -85,18 +128,23 @@ func (c *BrowserClient) Search(ctx context.Context, profile *domain.SearchProfil
 			if !seenIDs[l.IS24ID] {
 				seenIDs[l.IS24ID] = true
 				l.SearchProfileID = profile.ID
-				allListings = append(allListings, l)
+				result.Listings = append(result.Listings, l)
 				newOnPage++
 			}
 		}
 
 		// If we got very few new results, probably last page
-		if newOnPage < 5 {
+		if !pagingKnown && newOnPage < 5 {
+			ended = true
 			break
 		}
 	}
+	// Without paging metadata, full pages up to the limit mean there is more.
+	if !pagingKnown && !ended {
+		result.Truncated = true
+	}
 
-	return allListings, nil
+	return result, nil
 }
 
 // buildPageURL adds pagination parameter to the URL
@@ -115,21 +163,54 @@ func (c *BrowserClient) buildPageURL(baseURL string, page int) string {
 
 // FetchExpose fetches detailed listing info
 func (c *BrowserClient) FetchExpose(ctx context.Context, is24ID string) (*domain.Listing, error) {
-	exposeURL := fmt.Sprintf("https://www.immobilienscout24.de/expose/%s", is24ID)
+	html, err := c.FetchExposeHTML(ctx, is24ID)
+	if err != nil {
+		return nil, err
+	}
+	return c.parser.ParseExpose(html, is24ID)
+}
 
-	c.rateLimiter.Wait()
+// FetchExposeHTML downloads an expose page without parsing it, for looking
+// at the source of a listing that parsed wrong (/refetch). With DEBUG_HTML=1
+// the page is also kept in data/debug.
+func (c *BrowserClient) FetchExposeHTML(ctx context.Context, is24ID string) ([]byte, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 
-	html, err := c.fetchPage(ctx, exposeURL)
+	html, err := c.fetchPage(ctx, c.site.ExposeURL(is24ID))
 	if err != nil {
 		return nil, fmt.Errorf("fetch expose: %w", err)
 	}
 
 	if c.debug {
 		_ = os.MkdirAll("data/debug", 0o755)
-		_ = os.WriteFile(fmt.Sprintf("data/debug/is24_expose_%s.html", is24ID), []byte(html), 0o644)
+		_ = os.WriteFile(debugExposePath(is24ID), []byte(html), 0o644)
 	}
+	return []byte(html), nil
+}
+
+// debugExposePath is where DEBUG_HTML=1 keeps a fetched expose page.
+func debugExposePath(is24ID string) string {
+	return fmt.Sprintf("data/debug/is24_expose_%s.html", is24ID)
+}
+
+// StoredExposeHTML returns the expose page kept by DEBUG_HTML=1, an error
+// wrapping os.ErrNotExist when there is none.
+func StoredExposeHTML(is24ID string) ([]byte, error) {
+	return os.ReadFile(debugExposePath(is24ID))
+}
 
-	return c.parser.ParseExpose([]byte(html), is24ID)
+// WarmUp loads the site's homepage once, like a visitor arriving before they
+// search. The page is discarded.
+func (c *BrowserClient) WarmUp(ctx context.Context) error {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	if _, err := c.fetchPage(ctx, c.site.BaseURL+"/"); err != nil {
+		return fmt.Errorf("warm-up: %w", err)
+	}
+	return nil
 }
 
 func (c *BrowserClient) fetchPage(ctx context.Context, url string) (string, error) {
@@ -152,8 +233,8 @@ func (c *BrowserClient) fetchPage(ctx context.Context, url string) (string, erro
 	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
 	defer browserCancel()
 
-	// Set timeout
-	browserCtx, cancel := context.WithTimeout(browserCtx, 60*time.Second)
+	// Set timeout: page load plus the time the WAF challenge may take
+	browserCtx, cancel := context.WithTimeout(browserCtx, 30*time.Second+c.challenge.Timeout)
 	defer cancel()
 
 	var html string
@@ -167,7 +248,7 @@ func (c *BrowserClient) fetchPage(ctx context.Context, url string) (string, erro
 		for _, cookie := range cookies {
 			actions = append(actions, chromedp.ActionFunc(func(ctx context.Context) error {
 				return network.SetCookie(cookie.Name, cookie.Value).
-					WithDomain(".immobilienscout24.de").
+					WithDomain(c.site.CookieDomain()).
 					WithPath("/").
 					Do(ctx)
 			}))
@@ -180,22 +261,14 @@ func (c *BrowserClient) fetchPage(ctx context.Context, url string) (string, erro
 		chromedp.Sleep(3*time.Second),
 		// Wait for actual content
 		chromedp.WaitVisible(`body`, chromedp.ByQuery),
-		// Check if we're still on challenge page, wait more if needed
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var title string
-			if err := chromedp.Title(&title).Do(ctx); err != nil {
-				return err
-			}
-			// If still on robot check page, wait more
-			if title == "Ich bin kein Roboter - ImmobilienScout24" {
-				time.Sleep(5 * time.Second)
-			}
-			return nil
-		}),
-		// Wait for search results or expose content
-		chromedp.Sleep(2*time.Second),
-		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+		// Wait until the challenge is gone and the real page is there
+		c.challenge.waitReady(),
 	)
+	if c.challenge.ReadySelector == "" {
+		// No content marker: give search results or expose content time to render
+		actions = append(actions, chromedp.Sleep(2*time.Second))
+	}
+	actions = append(actions, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
 
 	if err := chromedp.Run(browserCtx, actions...); err != nil {
 		return "", err
@@ -0,0 +1,92 @@
+package is24
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrChallenge is returned when the WAF challenge page is still shown (or the
+// real page never appeared) after Challenge.Timeout.
+var ErrChallenge = errors.New("WAF challenge not solved")
+
+// challengePoll is how often fetchPage re-checks a page during the challenge.
+const challengePoll = 500 * time.Millisecond
+
+// Challenge tells fetchPage how to recognize IS24's WAF interstitial and the
+// real page behind it. The page counts as loaded once its title contains none
+// of Titles and, if set, ReadySelector matches an element.
+type Challenge struct {
+	Titles        []string      // title substrings of the challenge page, case-insensitive
+	ReadySelector string        // CSS selector only the real page has; empty = title check only
+	Timeout       time.Duration // how long to wait for the real page
+}
+
+// DefaultChallenge matches the German and English robot-check pages.
+var DefaultChallenge = Challenge{
+	Titles:  []string{"Ich bin kein Roboter", "I am not a robot", "I'm not a robot"},
+	Timeout: 30 * time.Second,
+}
+
+// withDefaults fills empty fields from DefaultChallenge.
+func (ch Challenge) withDefaults() Challenge {
+	if len(ch.Titles) == 0 {
+		ch.Titles = DefaultChallenge.Titles
+	}
+	if ch.Timeout <= 0 {
+		ch.Timeout = DefaultChallenge.Timeout
+	}
+	ch.ReadySelector = strings.TrimSpace(ch.ReadySelector)
+	return ch
+}
+
+// isChallengeTitle reports whether a page title belongs to the challenge.
+func (ch Challenge) isChallengeTitle(title string) bool {
+	title = strings.ToLower(title)
+	for _, t := range ch.Titles {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" && strings.Contains(title, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitReady polls the page until the challenge is gone and the real content
+// is there, or fails with ErrChallenge after the timeout.
+func (ch Challenge) waitReady() chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		deadline := time.Now().Add(ch.Timeout)
+		for {
+			var title string
+			if err := chromedp.Title(&title).Do(ctx); err != nil {
+				return err
+			}
+			if !ch.isChallengeTitle(title) {
+				if ch.ReadySelector == "" {
+					return nil
+				}
+				var found bool
+				js := "document.querySelector(" + strconv.Quote(ch.ReadySelector) + ") !== null"
+				if err := chromedp.Evaluate(js, &found).Do(ctx); err != nil {
+					return err
+				}
+				if found {
+					return nil
+				}
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%w after %s (title %q)", ErrChallenge, ch.Timeout, title)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(challengePoll):
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package is24
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChallengeTitles(t *testing.T) {
+	ch := Challenge{}.withDefaults()
+	if ch.Timeout != DefaultChallenge.Timeout || len(ch.Titles) == 0 {
+		t.Fatalf("empty challenge = %+v, want the defaults", ch)
+	}
+	for title, want := range map[string]bool{
+		"Ich bin kein Roboter - ImmobilienScout24": true,
+		"I AM NOT A ROBOT - ImmobilienScout24":     true,
+		"Wohnung mieten in Berlin":                 false,
+		"":                                         false,
+	} {
+		if got := ch.isChallengeTitle(title); got != want {
+			t.Errorf("isChallengeTitle(%q) = %v, want %v", title, got, want)
+		}
+	}
+
+	custom := Challenge{Titles: []string{"Sicherheitsprüfung", " "}, Timeout: time.Minute}.withDefaults()
+	if !custom.isChallengeTitle("Kurze Sicherheitsprüfung") || custom.isChallengeTitle("Ich bin kein Roboter") {
+		t.Error("configured titles should replace the defaults")
+	}
+	if custom.isChallengeTitle("Wohnung") {
+		t.Error("blank title entries must not match every page")
+	}
+}
@@ -2,6 +2,7 @@ package is24
 
 import (
 	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"fmt"
 	"io"
@@ -9,60 +10,64 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/julianbeese/immo_bot/internal/antidetect"
 	"github.com/julianbeese/immo_bot/internal/domain"
 )
 
-const (
-	baseURL     = "https://www.immobilienscout24.de"
-	searchPath  = "/Suche/de/%s/wohnung-mieten"
-	exposePath  = "/expose/%s"
-)
-
 // Client handles HTTP requests to ImmobilienScout24
 type Client struct {
 	httpClient  *http.Client
+	jar         *sessionJar
 	rateLimiter *antidetect.RateLimiter
 	uaRotator   *antidetect.UserAgentRotator
+	mu          sync.RWMutex // guards cookie for hot-reload via SetCookie
 	cookie      string
 	parser      *Parser
+	site        Site
+}
+
+// currentCookie returns a snapshot of the current cookie value under RLock.
+func (c *Client) currentCookie() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cookie
 }
 
 // NewClient creates a new IS24 client
 func NewClient(cookie string, rateLimiter *antidetect.RateLimiter, uaRotator *antidetect.UserAgentRotator) (*Client, error) {
-	jar, err := cookiejar.New(nil)
-	if err != nil {
+	jar := &sessionJar{}
+	if err := jar.reset(DefaultSite.BaseURL, cookie); err != nil {
 		return nil, err
 	}
 
-	// Parse and set cookies if provided
-	if cookie != "" {
-		u, _ := url.Parse(baseURL)
-		cookies := parseCookieString(cookie)
-		jar.SetCookies(u, cookies)
-	}
-
 	return &Client{
 		httpClient: &http.Client{
 			Jar:     jar,
 			Timeout: 30 * time.Second,
 		},
+		jar:         jar,
 		rateLimiter: rateLimiter,
 		uaRotator:   uaRotator,
 		cookie:      cookie,
 		parser:      NewParser(),
+		site:        DefaultSite,
 	}, nil
 }
 
-// Search performs a search and returns found listings
-func (c *Client) Search(ctx context.Context, profile *domain.SearchProfile) ([]domain.Listing, error) {
+// Search fetches the first result page of a search; the result is Truncated
+// when the result list has more pages.
+func (c *Client) Search(ctx context.Context, profile *domain.SearchProfile) (*domain.SearchResult, error) {
 	// Build search URL
 	searchURL := c.buildSearchURL(profile)
 
 	// Respect rate limits
-	c.rateLimiter.Wait()
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 
 	// Fetch search results page
 	body, err := c.fetch(ctx, searchURL)
@@ -81,14 +86,22 @@ func (c *Client) Search(ctx context.Context, profile *domain.SearchProfile) ([]d
 		listings[i].SearchProfileID = profile.ID
 	}
 
-	return listings, nil
+	paging := parseSearchPaging(string(body), len(listings))
+	return &domain.SearchResult{
+		Listings:     listings,
+		TotalHits:    paging.Total,
+		PagesCrawled: 1,
+		Truncated:    paging.Pages > 1,
+	}, nil
 }
 
 // FetchExpose fetches detailed information for a single listing
 func (c *Client) FetchExpose(ctx context.Context, is24ID string) (*domain.Listing, error) {
-	exposeURL := fmt.Sprintf(baseURL+exposePath, is24ID)
+	exposeURL := c.site.ExposeURL(is24ID)
 
-	c.rateLimiter.Wait()
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 
 	body, err := c.fetch(ctx, exposeURL)
 	if err != nil {
@@ -113,7 +126,7 @@ func (c *Client) buildSearchURL(profile *domain.SearchProfile) string {
 
 	// Build URL from profile criteria
 	city := strings.ToLower(strings.ReplaceAll(profile.City, " ", "-"))
-	u := fmt.Sprintf(baseURL+searchPath, city)
+	u := c.site.CitySearchURL(city)
 
 	params := url.Values{}
 
@@ -131,14 +144,16 @@ func (c *Client) buildSearchURL(profile *domain.SearchProfile) string {
 		}
 	}
 
-	if profile.MinRooms > 0 {
-		params.Set("numberofrooms", fmt.Sprintf("%.1f-", profile.MinRooms))
+	// IS24 treats both ends of numberofrooms as inclusive half-room steps.
+	minRooms, maxRooms := profile.RoomBounds()
+	if minRooms > 0 {
+		params.Set("numberofrooms", fmt.Sprintf("%.1f-", minRooms))
 	}
-	if profile.MaxRooms > 0 {
-		if profile.MinRooms > 0 {
-			params.Set("numberofrooms", fmt.Sprintf("%.1f-%.1f", profile.MinRooms, profile.MaxRooms))
+	if maxRooms > 0 {
+		if minRooms > 0 {
+			params.Set("numberofrooms", fmt.Sprintf("%.1f-%.1f", minRooms, maxRooms))
 		} else {
-			params.Set("numberofrooms", fmt.Sprintf("-%.1f", profile.MaxRooms))
+			params.Set("numberofrooms", fmt.Sprintf("-%.1f", maxRooms))
 		}
 	}
 
@@ -207,18 +222,40 @@ func (c *Client) fetch(ctx context.Context, urlStr string) ([]byte, error) {
 		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
 	}
 
-	// Handle gzip encoding
-	var reader io.Reader = resp.Body
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzReader, err := gzip.NewReader(resp.Body)
+	reader, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// acceptEncoding is what desktop browsers send. A request without it stands
+// out; setting it also turns off net/http's transparent gzip, so decodeBody
+// has to undo whatever encoding the server picked.
+const acceptEncoding = "gzip, deflate, br"
+
+// decodeBody returns a reader over the decoded response body.
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	switch enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+		return resp.Body, nil
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
 		if err != nil {
 			return nil, fmt.Errorf("gzip reader: %w", err)
 		}
-		defer gzReader.Close()
-		reader = gzReader
+		return r, nil
+	case "deflate":
+		r, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("deflate reader: %w", err)
+		}
+		return r, nil
+	case "br":
+		return brotli.NewReader(resp.Body), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", enc)
 	}
-
-	return io.ReadAll(reader)
 }
 
 func (c *Client) setHeaders(req *http.Request) {
@@ -226,13 +263,15 @@ func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("User-Agent", ua)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "de-DE,de;q=0.9")
-	// Don't set Accept-Encoding - Go handles gzip automatically when not set
-	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if t, ok := c.httpClient.Transport.(*http.Transport); !ok || !t.DisableKeepAlives {
+		req.Header.Set("Connection", "keep-alive")
+	}
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
 
-	// Add cookie header if set
-	if c.cookie != "" {
-		req.Header.Set("Cookie", c.cookie)
+	// Add cookie header if set (snapshot under lock to allow hot-reload)
+	if cookie := c.currentCookie(); cookie != "" {
+		req.Header.Set("Cookie", cookie)
 	}
 }
 
@@ -259,13 +298,70 @@ func parseCookieString(cookieStr string) []*http.Cookie {
 	return cookies
 }
 
-// SetCookie updates the client's cookie
+// sessionJar is the Client's cookie jar. SetCookie replaces its contents
+// while requests may be in flight, so the inner jar is swapped under a lock
+// instead of reassigning http.Client.Jar, which net/http reads unguarded.
+type sessionJar struct {
+	mu  sync.RWMutex
+	jar *cookiejar.Jar
+}
+
+// reset replaces the jar's cookies with the ones in a cookie header string,
+// set for baseURL.
+func (j *sessionJar) reset(baseURL, cookie string) error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	if cookie != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		jar.SetCookies(u, parseCookieString(cookie))
+	}
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+	return nil
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
+}
+
+// SetCookie updates the client's cookie. Safe while a poll is running: a
+// request in flight keeps the cookie it started with, the next one uses the
+// new one.
 func (c *Client) SetCookie(cookie string) error {
+	c.mu.Lock()
 	c.cookie = cookie
-	jar, _ := cookiejar.New(nil)
-	u, _ := url.Parse(baseURL)
-	cookies := parseCookieString(cookie)
-	jar.SetCookies(u, cookies)
-	c.httpClient.Jar = jar
-	return nil
+	c.mu.Unlock()
+	return c.jar.reset(c.site.BaseURL, cookie)
+}
+
+// SetSite switches the client to another IS24 country site (empty fields
+// keep the German defaults) and moves the session cookie there.
+func (c *Client) SetSite(site Site) error {
+	site = site.withDefaults()
+	if err := site.Validate(); err != nil {
+		return err
+	}
+	c.site = site
+	c.parser.baseURL = site.BaseURL
+	return c.SetCookie(c.currentCookie())
+}
+
+// SetTransport replaces the HTTP transport, e.g. one built by
+// antidetect.NewTransport to bind a source IP or tune connection reuse.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = rt
 }
@@ -0,0 +1,152 @@
+package is24
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/julianbeese/immo_bot/internal/antidetect"
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestBuildSearchURLRooms(t *testing.T) {
+	c := &Client{}
+	tests := []struct {
+		name    string
+		profile domain.SearchProfile
+		want    string
+	}{
+		{"at least 2", domain.SearchProfile{MinRooms: 2}, "2.0-"},
+		{"more than 2", domain.SearchProfile{MinRooms: 2, MinRoomsExclusive: true}, "2.5-"},
+		{"at most 3", domain.SearchProfile{MaxRooms: 3}, "-3.0"},
+		{"less than 3", domain.SearchProfile{MaxRooms: 3, MaxRoomsExclusive: true}, "-2.5"},
+		{"2.5 to 3", domain.SearchProfile{MinRooms: 2.5, MaxRooms: 3}, "2.5-3.0"},
+		{"between steps", domain.SearchProfile{MinRooms: 2.2, MaxRooms: 3.7}, "2.5-3.5"},
+		{"unbounded", domain.SearchProfile{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.profile.City = "Berlin"
+			u, err := url.Parse(c.buildSearchURL(&tt.profile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := u.Query().Get("numberofrooms"); got != tt.want {
+				t.Errorf("numberofrooms = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchDecodesContentEncoding(t *testing.T) {
+	const page = "<html><body>Wohnung mit Balkon</body></html>"
+	encoders := map[string]func(io.Writer) io.WriteCloser{
+		"br":   func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"":     nil,
+	}
+	var gotAccept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept-Encoding")
+		enc := r.URL.Query().Get("enc")
+		newEncoder := encoders[enc]
+		if newEncoder == nil {
+			io.WriteString(w, page)
+			return
+		}
+		var buf bytes.Buffer
+		zw := newEncoder(&buf)
+		io.WriteString(zw, page)
+		zw.Close()
+		w.Header().Set("Content-Encoding", enc)
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("", nil, antidetect.NewUserAgentRotator(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for enc := range encoders {
+		body, err := c.fetch(context.Background(), srv.URL+"/?enc="+enc)
+		if err != nil {
+			t.Fatalf("%q: %v", enc, err)
+		}
+		if string(body) != page {
+			t.Errorf("%q: body = %q", enc, body)
+		}
+	}
+	if gotAccept != acceptEncoding {
+		t.Errorf("Accept-Encoding = %q, want %q", gotAccept, acceptEncoding)
+	}
+}
+
+func TestSetCookieDuringFetch(t *testing.T) {
+	cookies := []string{"session=aaaa; reese84=1111", "session=bbbb; reese84=2222"}
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Header.Get("Cookie")]++
+		mu.Unlock()
+		io.WriteString(w, "<html></html>")
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(cookies[0], nil, antidetect.NewUserAgentRotator(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBrowserClient(cookies[0], nil, "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				if _, err := c.fetch(context.Background(), srv.URL); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				b.currentCookie()
+			}
+		}()
+	}
+	for j := 0; j < 50; j++ {
+		next := cookies[j%2]
+		if err := c.SetCookie(next); err != nil {
+			t.Fatal(err)
+		}
+		b.SetCookie(next)
+	}
+	wg.Wait()
+
+	for got := range seen {
+		if got != cookies[0] && got != cookies[1] {
+			t.Errorf("request sent cookie %q, want one of the set values", got)
+		}
+	}
+	if _, err := c.fetch(context.Background(), srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if seen[cookies[1]] == 0 || c.currentCookie() != cookies[1] || b.currentCookie() != cookies[1] {
+		t.Errorf("last SetCookie not applied: client %q, browser %q", c.currentCookie(), b.currentCookie())
+	}
+	u, _ := url.Parse(DefaultSite.BaseURL)
+	if jar := c.jar.Cookies(u); len(jar) != 2 || jar[0].Value != "bbbb" {
+		t.Errorf("jar cookies = %v, want the last cookie", jar)
+	}
+}
@@ -0,0 +1,26 @@
+package is24
+
+import (
+	"regexp"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// Genossenschaft flats are only let to members who first buy shares
+// (Genossenschafts- or Geschäftsanteile), often with a waiting list. IS24 has
+// no field for it, so the listing text, the landlord's legal form ("eG") and
+// the expose's criteria give it away.
+var (
+	cooperativeTextRe    = regexp.MustCompile(`(?i)genossenschaft|geschäftsanteil`)
+	cooperativeCompanyRe = regexp.MustCompile(`\beG\b`)
+	cooperativePageRe    = regexp.MustCompile(`(?i)is24qa-genossenschaft|"(?:cooperativeShares|genossenschaftsanteile?)"\s*:`)
+)
+
+// requiresMembership reports whether a listing's title, description or
+// landlord marks it as a Genossenschaft flat.
+func requiresMembership(l *domain.Listing) bool {
+	return cooperativeTextRe.MatchString(l.Title) ||
+		cooperativeTextRe.MatchString(l.Description) ||
+		cooperativeTextRe.MatchString(l.LandlordCompany) ||
+		cooperativeCompanyRe.MatchString(l.LandlordCompany)
+}
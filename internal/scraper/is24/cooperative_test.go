@@ -0,0 +1,38 @@
+package is24
+
+import (
+	"testing"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+func TestRequiresMembership(t *testing.T) {
+	tests := []struct {
+		name    string
+		listing domain.Listing
+		want    bool
+	}{
+		{"title", domain.Listing{Title: "Genossenschaftswohnung mit Balkon"}, true},
+		{"description", domain.Listing{Description: "Voraussetzung: Erwerb von 3 Geschäftsanteilen"}, true},
+		{"company", domain.Listing{LandlordCompany: "Wohnungsbaugenossenschaft Nord"}, true},
+		{"legal form", domain.Listing{LandlordCompany: "WG Lipsia eG"}, true},
+		{"plain flat", domain.Listing{Title: "Helle 2-Zimmer-Wohnung im EG", LandlordCompany: "Degewo AG"}, false},
+	}
+	for _, tt := range tests {
+		if got := requiresMembership(&tt.listing); got != tt.want {
+			t.Errorf("%s: requiresMembership = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseExposeCooperativeCriteria(t *testing.T) {
+	html := `<html><head><title>Wohnung</title></head><body>
+<dd class="is24qa-genossenschaftsanteile grid-item">1.500 €</dd></body></html>`
+	l, err := NewParser().ParseExpose([]byte(html), "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.RequiresMembership {
+		t.Error("expose listing Genossenschaftsanteile should require membership")
+	}
+}
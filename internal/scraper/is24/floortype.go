@@ -0,0 +1,78 @@
+package is24
+
+import (
+	"regexp"
+	"strings"
+)
+
+// IS24 records the kind of flat (Wohnungstyp) as an enum: ground floor,
+// roof storey, penthouse and so on. The parser stores the German label shown
+// on the expose so profiles can exclude e.g. "Erdgeschoss" or "Souterrain".
+var floorTypeLabels = map[string]string{
+	"roofstorey":        "Dachgeschoss",
+	"groundfloor":       "Erdgeschoss",
+	"raisedgroundfloor": "Hochparterre",
+	"halfbasement":      "Souterrain",
+	"penthouse":         "Penthouse",
+	"maisonette":        "Maisonette",
+	"loft":              "Loft",
+	"terracedflat":      "Terrassenwohnung",
+	"apartment":         "Etagenwohnung",
+}
+
+var (
+	exposeFloorTypeRes = []*regexp.Regexp{
+		regexp.MustCompile(`"apartmentType"\s*:\s*"([A-Za-z_]+)"`),
+		regexp.MustCompile(`"obj_typeOfFlat"\s*:\s*"([a-z_]+)"`),
+	}
+	floorTypeHTMLRe  = regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-typ\b[^"]*"[^>]*>\s*([^<]+?)\s*</dd>`)
+	floorTypeTitleRe = regexp.MustCompile(`(?i)\b(dachgescho(?:ss|ß)|erdgescho(?:ss|ß)|hochparterre|souterrain|penthouse|maisonette|loft|terrassenwohnung)`)
+)
+
+// floorTypeLabel maps an IS24 enum ("ROOF_STOREY", "roof_storey") or one of
+// the German labels to the label stored in Listing.FloorType, "" when it is
+// unknown.
+func floorTypeLabel(s string) string {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "_", ""))
+	if label, ok := floorTypeLabels[key]; ok {
+		return label
+	}
+	key = strings.ReplaceAll(key, "ß", "ss")
+	for _, label := range floorTypeLabels {
+		if strings.ToLower(label) == key {
+			return label
+		}
+	}
+	return ""
+}
+
+// floorType returns the floor type of a realEstate object, "" when it has none.
+func floorType(realEstate map[string]interface{}) string {
+	return floorTypeLabel(getString(realEstate, "apartmentType"))
+}
+
+// exposeFloorType finds the floor type in an expose page's embedded data or
+// its "Typ" criterion, "" when it has none.
+func exposeFloorType(html string) string {
+	for _, re := range exposeFloorTypeRes {
+		if m := re.FindStringSubmatch(html); m != nil {
+			if label := floorTypeLabel(m[1]); label != "" {
+				return label
+			}
+		}
+	}
+	if m := floorTypeHTMLRe.FindStringSubmatch(html); m != nil {
+		return floorTypeLabel(m[1])
+	}
+	return ""
+}
+
+// floorTypeFromTitle recognizes the floor type spelled out in a title
+// ("Helle Dachgeschosswohnung mit Blick"). Abbreviations like "EG" are too
+// ambiguous and left alone.
+func floorTypeFromTitle(title string) string {
+	if m := floorTypeTitleRe.FindStringSubmatch(title); m != nil {
+		return floorTypeLabel(m[1])
+	}
+	return ""
+}
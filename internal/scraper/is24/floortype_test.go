@@ -0,0 +1,68 @@
+package is24
+
+import "testing"
+
+func TestFloorTypeLabel(t *testing.T) {
+	tests := map[string]string{
+		"ROOF_STOREY":         "Dachgeschoss",
+		"raised_ground_floor": "Hochparterre",
+		"HALF_BASEMENT":       "Souterrain",
+		"Erdgeschoß":          "Erdgeschoss",
+		"penthouse":           "Penthouse",
+		"NO_INFORMATION":      "",
+		"":                    "",
+	}
+	for in, want := range tests {
+		if got := floorTypeLabel(in); got != want {
+			t.Errorf("floorTypeLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseExposeFloorType(t *testing.T) {
+	tests := []struct {
+		name, html, want string
+	}{
+		{"embedded enum", `<script>{"apartmentType":"GROUND_FLOOR"}</script>`, "Erdgeschoss"},
+		{"tracking data", `<script>var utag_data = {"obj_typeOfFlat":"roof_storey"};</script>`, "Dachgeschoss"},
+		{"criterion", `<dl><dt>Typ</dt><dd class="is24qa-typ grid-item three-fifths"> Souterrain </dd></dl>`, "Souterrain"},
+		{"none", `<html><body>Wohnung</body></html>`, ""},
+	}
+	for _, tt := range tests {
+		l, err := NewParser().ParseExpose([]byte(tt.html), "42")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.FloorType != tt.want {
+			t.Errorf("%s: FloorType = %q, want %q", tt.name, l.FloorType, tt.want)
+		}
+	}
+}
+
+func TestFloorTypeFromResult(t *testing.T) {
+	p := NewParser()
+	l := p.resultToListing(map[string]interface{}{
+		"@id":        "/expose/1",
+		"realEstate": map[string]interface{}{"title": "Wohnung im Dachgeschoss", "apartmentType": "MAISONETTE"},
+	})
+	if l.FloorType != "Maisonette" {
+		t.Errorf("structured type should win over the title, got %q", l.FloorType)
+	}
+
+	tests := map[string]string{
+		"Helle Dachgeschosswohnung mit Blick":  "Dachgeschoss",
+		"Erdgeschoßwohnung mit Garten":         "Erdgeschoss",
+		"Loft-Atmosphäre: 3 Zimmer im Altbau":  "Loft",
+		"2-Zimmer-Wohnung im EG":               "",
+		"Schöne Wohnung mit Terrasse in Mitte": "",
+	}
+	for title, want := range tests {
+		l := p.resultToListing(map[string]interface{}{
+			"@id":        "/expose/1",
+			"realEstate": map[string]interface{}{"title": title},
+		})
+		if l.FloorType != want {
+			t.Errorf("%q: FloorType = %q, want %q", title, l.FloorType, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package is24
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// Coordinates come from the address's wgs84Coordinate in search results and
+// the embedded state, from JSON-LD "geo", or from the expose's map widget.
+// Listings whose landlord hides the exact address have none.
+var coordinateRes = []*regexp.Regexp{
+	regexp.MustCompile(`"wgs84Coordinate"\s*:\s*\{\s*"latitude"\s*:\s*(-?\d+\.\d+)\s*,\s*"longitude"\s*:\s*(-?\d+\.\d+)`),
+	regexp.MustCompile(`"lat(?:itude)?"\s*:\s*"?(-?\d+\.\d+)"?\s*,\s*"(?:lng|lon|longitude)"\s*:\s*"?(-?\d+\.\d+)`),
+	regexp.MustCompile(`data-lat="(-?\d+\.\d+)"[^>]*data-lng="(-?\d+\.\d+)"`),
+}
+
+// setCoordinates stores lat/lng on l unless l already has coordinates or
+// they are out of range. 0,0 counts as missing.
+func setCoordinates(l *domain.Listing, lat, lng float64) {
+	if l.HasCoordinates() || lat == 0 || lng == 0 || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return
+	}
+	l.Latitude, l.Longitude = lat, lng
+}
+
+// coordinatesFromJSON reads the coordinates of a realEstate object (or a
+// JSON-LD document).
+func coordinatesFromJSON(l *domain.Listing, m map[string]interface{}) {
+	for _, c := range []map[string]interface{}{
+		nestedMap(nestedMap(m, "address"), "wgs84Coordinate"),
+		nestedMap(m, "wgs84Coordinate"),
+		nestedMap(m, "geo"),
+		nestedMap(m, "coordinates"),
+	} {
+		if c == nil {
+			continue
+		}
+		lat := firstFloat(c, "latitude", "lat")
+		lng := firstFloat(c, "longitude", "lng", "lon")
+		setCoordinates(l, lat, lng)
+	}
+}
+
+// coordinatesFromHTML searches the expose page for the map's coordinates.
+func coordinatesFromHTML(l *domain.Listing, page string) {
+	for _, re := range coordinateRes {
+		if l.HasCoordinates() {
+			return
+		}
+		if m := re.FindStringSubmatch(page); len(m) > 2 {
+			lat, _ := strconv.ParseFloat(m[1], 64)
+			lng, _ := strconv.ParseFloat(m[2], 64)
+			setCoordinates(l, lat, lng)
+		}
+	}
+}
+
+func firstFloat(m map[string]interface{}, keys ...string) float64 {
+	for _, key := range keys {
+		if v := getFloat(m, key); v != 0 {
+			return v
+		}
+	}
+	return 0
+}
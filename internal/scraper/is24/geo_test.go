@@ -0,0 +1,42 @@
+package is24
+
+import "testing"
+
+func TestResultCoordinates(t *testing.T) {
+	result := map[string]interface{}{
+		"@id": "/expose/123",
+		"realEstate": map[string]interface{}{
+			"title": "Wohnung",
+			"address": map[string]interface{}{
+				"city":            "Berlin",
+				"wgs84Coordinate": map[string]interface{}{"latitude": 52.52, "longitude": 13.405},
+			},
+		},
+	}
+	l := NewParser().resultToListing(result)
+	if l.Latitude != 52.52 || l.Longitude != 13.405 {
+		t.Errorf("coordinates = %v,%v", l.Latitude, l.Longitude)
+	}
+}
+
+func TestExposeCoordinates(t *testing.T) {
+	for name, html := range map[string]string{
+		"state":  `<script>var s = {"wgs84Coordinate":{"latitude":52.52,"longitude":13.405}};</script>`,
+		"map":    `<script>keyValues = {"lat":52.52,"lng":13.405,"zoom":15};</script>`,
+		"attrs":  `<div id="map" data-lat="52.52" data-lng="13.405"></div>`,
+		"jsonld": `<script type="application/ld+json">{"@type":"Apartment","name":"W","geo":{"latitude":"52.52","longitude":"13.405"}}</script>`,
+	} {
+		l, err := NewParser().ParseExpose([]byte(html), "123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Latitude != 52.52 || l.Longitude != 13.405 {
+			t.Errorf("%s: coordinates = %v,%v", name, l.Latitude, l.Longitude)
+		}
+	}
+
+	l, _ := NewParser().ParseExpose([]byte(`<div data-lat="0.0" data-lng="0.0"></div>`), "123")
+	if l.HasCoordinates() {
+		t.Errorf("0,0 should count as missing, got %v,%v", l.Latitude, l.Longitude)
+	}
+}
@@ -0,0 +1,33 @@
+package is24
+
+import (
+	"regexp"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// A removed expose answers with IS24's "Objekt nicht mehr verfügbar" page
+// instead of a 404. It has no listing data, so parsing it would only yield an
+// empty listing. Real exposes can mention the phrase in recommendations, so
+// a page that still has a price criterion, or that embeds this expose's own
+// listing JSON, is never taken for gone by its text. Only the
+// expose's own state fields count: a bare "status" also turns up in nested
+// objects (contact forms, agents, recommendations) of live pages.
+var (
+	goneTextRe = regexp.MustCompile(`(?i)(?:objekt|angebot|inserat|exposé|expose)\s+(?:ist\s+)?(?:leider\s+)?nicht\s+mehr\s+verf(?:ü|&uuml;|&#252;|ue)gbar`)
+	goneMarkRe = regexp.MustCompile(`(?i)"(?:exposeStatus|realEstateState)"\s*:\s*"(?:INACTIVE|DEACTIVATED|DELETED|ARCHIVED)"|is24-expose-(?:inactive|not-found)`)
+	livePageRe = regexp.MustCompile(`is24qa-(?:kaltmiete|kaufpreis|warmmiete)\b`)
+)
+
+// exposeGone reports whether an expose page is IS24's removed-listing page.
+// embedded is the expose's listing from the page's SPA state, or nil; a
+// JSON-rendered live expose has none of the is24qa criteria classes.
+func exposeGone(html string, embedded *domain.Listing) bool {
+	if livePageRe.MatchString(html) {
+		return false
+	}
+	if goneMarkRe.MatchString(html) {
+		return true
+	}
+	return embedded == nil && goneTextRe.MatchString(html)
+}
@@ -0,0 +1,50 @@
+package is24
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// goneExpose is a trimmed IS24 removed-listing page.
+const goneExpose = `<!DOCTYPE html><html lang="de"><head><title>Objekt nicht mehr verfügbar | ImmobilienScout24</title></head>
+<body><div class="is24-expose-inactive">
+<h2>Dieses Objekt ist leider nicht mehr verf&uuml;gbar.</h2>
+<p>Der Anbieter hat das Inserat deaktiviert. Hier finden Sie ähnliche Angebote:</p>
+<a href="/Suche/de/berlin/berlin/wohnung-mieten">Weitere Wohnungen in Berlin</a>
+</div></body></html>`
+
+func TestParseExposeGone(t *testing.T) {
+	l, err := NewParser().ParseExpose([]byte(goneExpose), "150000001")
+	if !errors.Is(err, domain.ErrListingGone) || l != nil {
+		t.Fatalf("ParseExpose = %+v, %v, want ErrListingGone", l, err)
+	}
+	if _, err := NewParser().ParseExpose([]byte(`<script>{"exposeStatus":"INACTIVE"}</script>`), "1"); !errors.Is(err, domain.ErrListingGone) {
+		t.Errorf("embedded status: err = %v, want ErrListingGone", err)
+	}
+
+	// A live expose whose recommendations mention a removed listing.
+	live := `<dd class="is24qa-kaltmiete grid-item three-fifths">950 €</dd>
+<aside>Ein ähnliches Angebot ist nicht mehr verfügbar.</aside>`
+	l, err = NewParser().ParseExpose([]byte(live), "2")
+	if err != nil || l.Price != 950 {
+		t.Errorf("live expose: %+v, %v", l, err)
+	}
+
+	// A JSON-rendered live expose with an unrelated inactive status.
+	live = `<script>{"realEstate":{"baseRent":1200,"contactDetails":{"status":"INACTIVE"}}}</script>`
+	l, err = NewParser().ParseExpose([]byte(live), "3")
+	if err != nil || l.Price != 1200 {
+		t.Errorf("JSON live expose: %+v, %v", l, err)
+	}
+
+	// A __NEXT_DATA__ expose whose recommendations mention a removed listing.
+	live = `<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{
+"expose":{"id":"4","title":"Altbau mit Balkon","price":{"value":1100}},
+"recommendations":[{"teaser":"Dieses Angebot ist nicht mehr verfügbar."}]}}}</script>`
+	l, err = NewParser().ParseExpose([]byte(live), "4")
+	if err != nil || l.Title != "Altbau mit Balkon" {
+		t.Errorf("__NEXT_DATA__ live expose: %+v, %v", l, err)
+	}
+}
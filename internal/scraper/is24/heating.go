@@ -0,0 +1,62 @@
+package is24
+
+import (
+	"regexp"
+	"strings"
+)
+
+// IS24 states whether the heating costs are part of the Nebenkosten
+// ("Heizkosten: in Nebenkosten enthalten"). Without them, Kaltmiete +
+// Nebenkosten understates the real monthly cost.
+var (
+	exposeHeatingRe = regexp.MustCompile(`"heatingCostsInServiceCharge"\s*:\s*"?([A-Za-z_]+)"?`)
+	heatingHTMLRe   = regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-heizkosten\b[^"]*"[^>]*>\s*([^<]+?)\s*</dd>`)
+)
+
+// heatingIncludedValue maps an IS24 enum ("YES", "NO") or the expose's
+// Heizkosten text to whether heating is included, nil when it can't tell.
+// A stated amount ("85 €") means heating is paid on top.
+func heatingIncludedValue(s string) *bool {
+	v := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case v == "yes" || v == "true":
+		return boolPtr(true)
+	case v == "no" || v == "false":
+		return boolPtr(false)
+	case strings.Contains(v, "nicht in nebenkosten") || strings.Contains(v, "nicht enthalten"):
+		return boolPtr(false)
+	case strings.Contains(v, "enthalten") || strings.Contains(v, "inklusive"):
+		return boolPtr(true)
+	case parsePrice(v) > 0:
+		return boolPtr(false)
+	}
+	return nil
+}
+
+// heatingIncluded returns whether a realEstate object's heating costs are
+// part of the Nebenkosten, nil when it doesn't say.
+func heatingIncluded(realEstate map[string]interface{}) *bool {
+	switch v := realEstate["heatingCostsInServiceCharge"].(type) {
+	case bool:
+		return boolPtr(v)
+	case string:
+		return heatingIncludedValue(v)
+	}
+	return nil
+}
+
+// exposeHeatingIncluded finds the heating flag in an expose page's embedded
+// data or its "Heizkosten" criterion, nil when it has none.
+func exposeHeatingIncluded(html string) *bool {
+	if m := exposeHeatingRe.FindStringSubmatch(html); m != nil {
+		if v := heatingIncludedValue(m[1]); v != nil {
+			return v
+		}
+	}
+	if m := heatingHTMLRe.FindStringSubmatch(html); m != nil {
+		return heatingIncludedValue(m[1])
+	}
+	return nil
+}
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,48 @@
+package is24
+
+import "testing"
+
+func TestParseExposeHeatingIncluded(t *testing.T) {
+	tests := []struct {
+		name, html string
+		want       *bool
+	}{
+		{"embedded enum", `<script>{"heatingCostsInServiceCharge":"YES"}</script>`, boolPtr(true)},
+		{"criterion included", `<dl><dt>Heizkosten</dt><dd class="is24qa-heizkosten grid-item three-fifths"> in Nebenkosten enthalten </dd></dl>`, boolPtr(true)},
+		{"criterion excluded", `<dd class="is24qa-heizkosten grid-item three-fifths">nicht in Nebenkosten enthalten</dd>`, boolPtr(false)},
+		{"criterion amount", `<dd class="is24qa-heizkosten grid-item three-fifths">85 €</dd>`, boolPtr(false)},
+		{"none", `<html><body>Wohnung</body></html>`, nil},
+	}
+	for _, tt := range tests {
+		l, err := NewParser().ParseExpose([]byte(tt.html), "42")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (l.HeatingIncluded == nil) != (tt.want == nil) || (tt.want != nil && *l.HeatingIncluded != *tt.want) {
+			t.Errorf("%s: HeatingIncluded = %v, want %v", tt.name, fmtBoolPtr(l.HeatingIncluded), fmtBoolPtr(tt.want))
+		}
+	}
+}
+
+func TestHeatingIncludedFromResult(t *testing.T) {
+	tests := map[interface{}]string{"NO": "false", "YES": "true", true: "true", "NOT_APPLICABLE": "nil"}
+	for in, want := range tests {
+		l := NewParser().resultToListing(map[string]interface{}{
+			"@id":        "/expose/1",
+			"realEstate": map[string]interface{}{"heatingCostsInServiceCharge": in},
+		})
+		if got := fmtBoolPtr(l.HeatingIncluded); got != want {
+			t.Errorf("%v: HeatingIncluded = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func fmtBoolPtr(b *bool) string {
+	if b == nil {
+		return "nil"
+	}
+	if *b {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,94 @@
+package is24
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// resultlistEntriesRe finds the start of a resultlistEntries array in the
+// page's embedded JavaScript.
+var resultlistEntriesRe = regexp.MustCompile(`"resultlistEntries"\s*:\s*\[`)
+
+// balancedJSON returns the JSON object or array that opens at s[start],
+// ending at its matching bracket. Brackets inside strings are skipped.
+// ok is false when s[start] is no bracket or the value is cut off.
+func balancedJSON(s string, start int) (value string, ok bool) {
+	if start < 0 || start >= len(s) || (s[start] != '[' && s[start] != '{') {
+		return "", false
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// resultlistEntries extracts and decodes the first complete resultlistEntries
+// array. Old pages wrap the listings in {"resultlistEntry": [...]}; those
+// wrappers are flattened into their entries, which are normalized like the
+// embedded state's so bare numeric IDs resolve.
+func resultlistEntries(html string) []map[string]interface{} {
+	for _, loc := range resultlistEntriesRe.FindAllStringIndex(html, -1) {
+		raw, ok := balancedJSON(html, loc[1]-1)
+		if !ok {
+			continue
+		}
+		var entries []interface{}
+		if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+			continue
+		}
+		var flat []map[string]interface{}
+		for _, e := range entries {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch inner := m["resultlistEntry"].(type) {
+			case []interface{}:
+				for _, item := range inner {
+					if im, ok := item.(map[string]interface{}); ok {
+						flat = append(flat, im)
+					}
+				}
+			case map[string]interface{}:
+				flat = append(flat, inner)
+			default:
+				flat = append(flat, m)
+			}
+		}
+		results := make([]map[string]interface{}, 0, len(flat))
+		for _, m := range flat {
+			if entry, _ := normalizeEntry(m); entry != nil {
+				m = entry
+			}
+			results = append(results, m)
+		}
+		if len(results) > 0 {
+			return results
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package is24
+
+import "testing"
+
+// resultListPage embeds the result list in a plain script assignment, with
+// nested objects, arrays and brackets inside strings that broke the old
+// non-greedy regex.
+const resultListPage = `<html><script>
+IS24.resultList = {"searchResponseModel":{"resultlist.resultlist":{"paging":{"numberOfHits":2},"resultlistEntries":[{"@numberOfHits":"2","resultlistEntry":[
+{"@id":"111","realEstate":{"@id":"111","title":"Altbau [saniert] mit {Balkon}","price":{"value":1250,"currency":"EUR"},"livingSpace":68.5,"numberOfRooms":2.5,"address":{"postcode":"10405","city":"Berlin","quarter":"Prenzlauer Berg","wgs84Coordinate":{"latitude":52.53,"longitude":13.42}},"galleryAttachments":{"attachment":[{"@xlink.href":"https://pictures.example/1.jpg"},{"@xlink.href":"https://pictures.example/2.jpg"}]}}},
+{"@id":"222","realEstate":{"@id":"222","title":"Say \"hi\" ]}","price":{"value":980},"livingSpace":45,"numberOfRooms":1,"address":{"postcode":"10115","city":"Berlin"}}}
+]}],"searchId":"abc"}}};
+</script></html>`
+
+func TestParseSearchResultsNestedResultList(t *testing.T) {
+	listings, err := NewParser().ParseSearchResults([]byte(resultListPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 2 {
+		t.Fatalf("got %d listings, want 2: %+v", len(listings), listings)
+	}
+	first := listings[0]
+	if first.IS24ID != "111" || first.Price != 1250 || first.PostalCode != "10405" || len(first.ImageURLs) != 2 || !first.HasCoordinates() {
+		t.Errorf("first listing = %+v", first)
+	}
+	if listings[1].IS24ID != "222" || listings[1].Title != `Say "hi" ]}` {
+		t.Errorf("second listing = %+v", listings[1])
+	}
+}
+
+func TestBalancedJSON(t *testing.T) {
+	tests := []struct {
+		in    string
+		start int
+		want  string
+		ok    bool
+	}{
+		{`x = [1, [2, 3], {"a": "]"}];`, 4, `[1, [2, 3], {"a": "]"}]`, true},
+		{`{"a": "\"}", "b": {}} tail`, 0, `{"a": "\"}", "b": {}}`, true},
+		{`[{"a": 1}`, 0, "", false},
+		{`abc`, 0, "", false},
+		{`[]`, 5, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := balancedJSON(tt.in, tt.start)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("balancedJSON(%q, %d) = %q, %v; want %q, %v", tt.in, tt.start, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResultlistEntriesSkipsTruncatedArray(t *testing.T) {
+	page := `"resultlistEntries": [{"@id": "1"` + "\n" + `"resultlistEntries": [{"@id": "2", "realEstate": {"title": "x"}}]`
+	entries := resultlistEntries(page)
+	if len(entries) != 1 || entries[0]["@id"] != "/expose/2" {
+		t.Errorf("entries = %v", entries)
+	}
+}
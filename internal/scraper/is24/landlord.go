@@ -0,0 +1,137 @@
+package is24
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// landlordInfo is the expose's contact person ("Ansprechpartner"): name,
+// company and whether the offer is private ("privat") or commercial
+// ("gewerblich"). Empty fields are unknown.
+type landlordInfo struct {
+	Name    string
+	Company string
+	Type    string
+}
+
+// fill copies the known fields into l without overwriting what l already has.
+func (li landlordInfo) fill(l *domain.Listing) {
+	if l.LandlordName == "" {
+		l.LandlordName = li.Name
+	}
+	if l.LandlordCompany == "" {
+		l.LandlordCompany = li.Company
+	}
+	if l.LandlordType == "" {
+		l.LandlordType = li.Type
+	}
+}
+
+// contactPersonKeys are the objects search entries and exposes keep the
+// contact person under.
+var contactPersonKeys = []string{"contactDetails", "contactPerson", "contactData", "contact", "realtor"}
+
+// landlordFromJSON reads the contact person from a realEstate object.
+func landlordFromJSON(realEstate map[string]interface{}) landlordInfo {
+	li := landlordInfo{Company: firstString(realEstate, "realtorCompanyName", "companyName")}
+	for _, key := range contactPersonKeys {
+		c, ok := realEstate[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// contactData nests the person and the agency one level deeper.
+		for _, m := range []map[string]interface{}{c, nestedMap(c, "contactPerson"), nestedMap(c, "realtorInformation")} {
+			if m == nil {
+				continue
+			}
+			if li.Name == "" {
+				li.Name = personName(m)
+			}
+			if li.Company == "" {
+				li.Company = firstString(m, "company", "companyName", "realtorCompanyName")
+			}
+		}
+	}
+	li.Name, li.Company = cleanText(li.Name), cleanText(li.Company)
+	return li
+}
+
+func nestedMap(m map[string]interface{}, key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+// personName joins first and last name, falling back to a full-name field.
+func personName(m map[string]interface{}) string {
+	first := firstString(m, "firstName", "firstname")
+	last := firstString(m, "lastName", "lastname")
+	if name := strings.TrimSpace(first + " " + last); name != "" {
+		return name
+	}
+	return firstString(m, "fullName", "contactName", "name")
+}
+
+var (
+	// contactBlockRe finds the start of the "Ansprechpartner" box.
+	contactBlockRe = regexp.MustCompile(`(?i)data-qa="(?:contactBox|contact-box|is24-expose-contact-box)"|class="[^"]*(?:contact-box|is24-expose-contact-box|realtor-box)[^"]*"|>\s*Ansprechpartner(?:in)?\s*<`)
+	// contactBlockLen is how much of the page after the marker is searched.
+	contactBlockLen = 6000
+
+	contactNameRes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)data-qa="(?:contactName|contact-name|realtor-title)"[^>]*>([^<]+)<`),
+		regexp.MustCompile(`(?i)class="[^"]*(?:realtor-title|contact-name|contactName)[^"]*"[^>]*>([^<]+)<`),
+	}
+	contactCompanyRes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)data-qa="(?:companyName|company-name|realtor-company)"[^>]*>([^<]+)<`),
+		regexp.MustCompile(`(?i)class="[^"]*(?:company-name|companyName|realtor-company)[^"]*"[^>]*>([^<]+)<`),
+	}
+	privateOfferRe    = regexp.MustCompile(`(?i)Privat(?:er)?\s*anbieter|Privatangebot`)
+	commercialOfferRe = regexp.MustCompile(`(?i)Gewerblicher\s+Anbieter`)
+)
+
+// parseContactBlock parses the HTML "Ansprechpartner" box. Without a box the
+// whole page is searched for the name, as the old realtor-title markup had
+// none around it.
+func parseContactBlock(page string) landlordInfo {
+	block := page
+	if loc := contactBlockRe.FindStringIndex(page); loc != nil {
+		end := loc[0] + contactBlockLen
+		if end > len(page) {
+			end = len(page)
+		}
+		block = page[loc[0]:end]
+	}
+
+	var li landlordInfo
+	li.Name = firstMatch(block, contactNameRes)
+	li.Company = firstMatch(block, contactCompanyRes)
+	if li.Company != "" && strings.EqualFold(li.Company, li.Name) {
+		li.Name = "" // agencies often show only their name in both slots
+	}
+	switch {
+	case privateOfferRe.MatchString(block):
+		li.Type = "privat"
+	case commercialOfferRe.MatchString(block):
+		li.Type = "gewerblich"
+	}
+	return li
+}
+
+func firstMatch(s string, res []*regexp.Regexp) string {
+	for _, re := range res {
+		if m := re.FindStringSubmatch(s); len(m) > 1 {
+			if v := cleanText(m[1]); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// cleanText unescapes HTML entities and collapses whitespace.
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
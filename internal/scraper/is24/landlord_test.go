@@ -0,0 +1,80 @@
+package is24
+
+import "testing"
+
+// contactBoxHTML is a trimmed expose snapshot with an "Ansprechpartner" box
+// naming the agent, their role and the agency.
+const contactBoxHTML = `<html><body>
+<h1 id="expose-title">Altbau mit Balkon</h1>
+<div class="is24qa-kaltmiete is24-value">1.100 €</div>
+<div data-qa="contactBox" class="contact-box">
+  <h4>Ansprechpartner</h4>
+  <span data-qa="contactName" class="font-semibold">Frau Anna   Schmidt</span>
+  <span data-qa="contactPosition">Vermietungsteam</span>
+  <div data-qa="companyName">Schmidt &amp; Partner Immobilien GmbH</div>
+</div>
+</body></html>`
+
+func TestParseExposeContactBox(t *testing.T) {
+	l, err := NewParser().ParseExpose([]byte(contactBoxHTML), "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.LandlordName != "Frau Anna Schmidt" {
+		t.Errorf("LandlordName = %q", l.LandlordName)
+	}
+	if l.LandlordCompany != "Schmidt & Partner Immobilien GmbH" {
+		t.Errorf("LandlordCompany = %q", l.LandlordCompany)
+	}
+	if l.LandlordType != "gewerblich" {
+		t.Errorf("LandlordType = %q, want gewerblich for an agency", l.LandlordType)
+	}
+}
+
+func TestParseContactBlock(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		html string
+		want landlordInfo
+	}{
+		{
+			name: "legacy realtor-title without box",
+			html: `<span class="realtor-title font-bold">Herr Max Mustermann</span>`,
+			want: landlordInfo{Name: "Herr Max Mustermann"},
+		},
+		{
+			name: "private offer",
+			html: `<section><h3>Ansprechpartner</h3><p class="contact-name">Familie Weber</p><p>Privatanbieter</p></section>`,
+			want: landlordInfo{Name: "Familie Weber", Type: "privat"},
+		},
+		{
+			name: "agency name in both slots",
+			html: `<div class="realtor-box"><span class="realtor-title">Immo AG</span><span class="company-name">Immo AG</span></div>`,
+			want: landlordInfo{Company: "Immo AG"},
+		},
+	} {
+		if got := parseContactBlock(tt.html); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResultToListingContactDetails(t *testing.T) {
+	entry := map[string]interface{}{
+		"@id": "/expose/42",
+		"realEstate": map[string]interface{}{
+			"title":        "Whg",
+			"privateOffer": "false",
+			"contactDetails": map[string]interface{}{
+				"salutation": "MALE",
+				"firstname":  "Max",
+				"lastname":   "Mustermann",
+				"company":    "Mustermann Immobilien",
+			},
+		},
+	}
+	l := NewParser().resultToListing(entry)
+	if l.LandlordName != "Max Mustermann" || l.LandlordCompany != "Mustermann Immobilien" || l.LandlordType != "gewerblich" {
+		t.Errorf("contact details parsed wrong: name=%q company=%q type=%q", l.LandlordName, l.LandlordCompany, l.LandlordType)
+	}
+}
@@ -0,0 +1,275 @@
+package is24
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// IS24's SPA pages embed their state as JSON in <script id="__NEXT_DATA__">
+// (Next.js, often with an Apollo cache under props.pageProps) or in a
+// window.__APOLLO_STATE__ assignment. Both are parsed as a whole and walked
+// for listing objects, which is far more robust than grepping for specific
+// key layouts.
+var (
+	nextDataRe    = regexp.MustCompile(`(?s)<script[^>]*id="__NEXT_DATA__"[^>]*>(.*?)</script>`)
+	apolloStateRe = regexp.MustCompile(`(?s)window\.__APOLLO_STATE__\s*=\s*(\{.*?\})\s*;?\s*</script>`)
+)
+
+// maxWalkDepth bounds the recursive walk over embedded state.
+const maxWalkDepth = 40
+
+// extractEmbeddedState decodes every embedded SPA state blob found in html.
+func extractEmbeddedState(html string) []interface{} {
+	var states []interface{}
+	for _, re := range []*regexp.Regexp{nextDataRe, apolloStateRe} {
+		for _, match := range re.FindAllStringSubmatch(html, -1) {
+			var state interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(match[1])), &state); err == nil {
+				states = append(states, state)
+			}
+		}
+	}
+	return states
+}
+
+// extractNextDataEntries walks the embedded state for search result entries and
+// returns them normalized to the shape resultToListing expects ("@id" holding
+// an /expose/<id> path, details under "realEstate"). Returns nil when the page
+// has no embedded state or no entries were found.
+func (p *Parser) extractNextDataEntries(html string) []map[string]interface{} {
+	var entries []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, state := range extractEmbeddedState(html) {
+		walkJSON(state, 0, func(m map[string]interface{}) bool {
+			entry, id := normalizeEntry(m)
+			if entry == nil {
+				return true
+			}
+			if !seen[id] {
+				seen[id] = true
+				entries = append(entries, entry)
+			}
+			return false
+		})
+	}
+	return entries
+}
+
+// extractNextDataExpose finds the embedded-state object describing the given
+// expose and converts it to a listing. Returns nil when nothing matches.
+func (p *Parser) extractNextDataExpose(html, is24ID string) *domain.Listing {
+	var found map[string]interface{}
+	for _, state := range extractEmbeddedState(html) {
+		walkJSON(state, 0, func(m map[string]interface{}) bool {
+			if found != nil {
+				return false
+			}
+			if entry, id := normalizeEntry(m); entry != nil && id == is24ID {
+				found = entry
+				return false
+			}
+			if jsonID(m) == is24ID && hasListingFields(m) {
+				found = map[string]interface{}{"@id": "/expose/" + is24ID, "realEstate": m}
+				return false
+			}
+			return true
+		})
+		if found != nil {
+			break
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	listing := p.resultToListing(found)
+	if re, ok := found["realEstate"].(map[string]interface{}); ok {
+		listing.Description = firstString(re, "description", "descriptionNote", "objectDescription")
+	}
+	return &listing
+}
+
+// walkJSON calls visit for every object in v, depth first. Returning false
+// from visit stops descending into that object. Object members are walked in
+// key order, so listings keyed by an Apollo cache come back in a stable order.
+func walkJSON(v interface{}, depth int, visit func(map[string]interface{}) bool) {
+	if depth > maxWalkDepth {
+		return
+	}
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if !visit(node) {
+			return
+		}
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkJSON(node[key], depth+1, visit)
+		}
+	case []interface{}:
+		for _, child := range node {
+			walkJSON(child, depth+1, visit)
+		}
+	}
+}
+
+// normalizeEntry recognizes a search result entry: an object carrying listing
+// details under "realEstate" / "resultlist.realEstate" plus an expose ID on
+// either level. Returns the normalized entry and its IS24 ID, or nil.
+func normalizeEntry(m map[string]interface{}) (map[string]interface{}, string) {
+	var estate map[string]interface{}
+	for _, key := range []string{"realEstate", "resultlist.realEstate"} {
+		if re, ok := m[key].(map[string]interface{}); ok {
+			estate = re
+			break
+		}
+	}
+	if estate == nil {
+		return nil, ""
+	}
+	id := jsonID(m)
+	if id == "" {
+		id = jsonID(estate)
+	}
+	if id == "" {
+		return nil, ""
+	}
+	entry := map[string]interface{}{"@id": "/expose/" + id, "realEstate": estate}
+	// Keep the markers resultToListings recognizes new-build projects by.
+	for _, key := range []string{"url", "link", "@xsi.type", "type", "isProject"} {
+		if v, ok := m[key]; ok {
+			entry[key] = v
+		}
+	}
+	return entry, id
+}
+
+// jsonID returns the numeric expose ID of an object from its "@id" or "id"
+// field (plain number, numeric string or /expose/<id> path).
+func jsonID(m map[string]interface{}) string {
+	for _, key := range []string{"@id", "id", "exposeId"} {
+		var s string
+		switch v := m[key].(type) {
+		case string:
+			s = v
+		case float64:
+			s = fmt.Sprintf("%.0f", v)
+		default:
+			continue
+		}
+		if i := strings.LastIndex(s, "/expose/"); i >= 0 {
+			s = s[i+len("/expose/"):]
+		}
+		if s != "" && strings.Trim(s, "0123456789") == "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// hasListingFields reports whether m looks like listing details rather than
+// some unrelated object that happens to share the ID.
+func hasListingFields(m map[string]interface{}) bool {
+	_, hasTitle := m["title"].(string)
+	_, hasAddress := m["address"].(map[string]interface{})
+	return hasTitle || hasAddress
+}
+
+func firstString(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s := getString(m, key); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// mergeListing fills zero-valued fields of dst from src.
+func mergeListing(dst, src *domain.Listing) {
+	if dst.Title == "" {
+		dst.Title = src.Title
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	if dst.Address == "" {
+		dst.Address = src.Address
+	}
+	if dst.City == "" {
+		dst.City = src.City
+	}
+	if dst.District == "" {
+		dst.District = src.District
+	}
+	if dst.PostalCode == "" {
+		dst.PostalCode = src.PostalCode
+	}
+	if dst.Price == 0 {
+		dst.Price = src.Price
+		dst.PriceOnRequest = dst.PriceOnRequest || src.PriceOnRequest
+	}
+	if dst.WarmRent == 0 {
+		dst.WarmRent = src.WarmRent
+	}
+	if dst.ServiceCharge == 0 {
+		dst.ServiceCharge = src.ServiceCharge
+	}
+	if dst.MonthlyFees == 0 {
+		dst.MonthlyFees = src.MonthlyFees
+	}
+	if dst.PropertyType == "" {
+		dst.PropertyType = src.PropertyType
+	}
+	if dst.FloorType == "" {
+		dst.FloorType = src.FloorType
+	}
+	if dst.HeatingIncluded == nil {
+		dst.HeatingIncluded = src.HeatingIncluded
+	}
+	if dst.AvailableFrom == "" {
+		dst.AvailableFrom = src.AvailableFrom
+	}
+	if dst.VacantFrom == "" {
+		dst.VacantFrom = src.VacantFrom
+	}
+	dst.IsProjected = dst.IsProjected || src.IsProjected
+	if dst.Rooms == 0 {
+		dst.Rooms = src.Rooms
+	}
+	if dst.Area == 0 {
+		dst.Area = src.Area
+	}
+	if !dst.HasCoordinates() {
+		dst.Latitude, dst.Longitude = src.Latitude, src.Longitude
+	}
+	if dst.BuildYear == 0 {
+		dst.BuildYear = src.BuildYear
+	}
+	if dst.LandlordName == "" {
+		dst.LandlordName = src.LandlordName
+	}
+	if dst.LandlordCompany == "" {
+		dst.LandlordCompany = src.LandlordCompany
+	}
+	if dst.LandlordType == "" {
+		dst.LandlordType = src.LandlordType
+	}
+	if len(dst.ImageURLs) == 0 {
+		dst.ImageURLs = src.ImageURLs
+	}
+	dst.HasFloorPlan = dst.HasFloorPlan || src.HasFloorPlan
+	dst.HasBalcony = dst.HasBalcony || src.HasBalcony
+	dst.HasEBK = dst.HasEBK || src.HasEBK
+	dst.HasElevator = dst.HasElevator || src.HasElevator
+	dst.HasGuestToilet = dst.HasGuestToilet || src.HasGuestToilet
+	dst.HasCellar = dst.HasCellar || src.HasCellar
+	dst.HasSeparateKitchen = dst.HasSeparateKitchen || src.HasSeparateKitchen
+	dst.RequiresMembership = dst.RequiresMembership || src.RequiresMembership
+}
@@ -0,0 +1,87 @@
+package is24
+
+import (
+	"strings"
+	"testing"
+)
+
+const nextDataSearchHTML = `<html><body>
+<script id="__NEXT_DATA__" type="application/json">
+{"props":{"pageProps":{"searchResponseModel":{"resultlist.resultlist":{"resultlistEntries":[{"resultlistEntry":[
+  {"@id":"111","resultlist.realEstate":{"title":"Altbau mit Balkon","address":{"city":"München","quarter":"Schwabing","postcode":"80796"},"price":{"value":1450},"numberOfRooms":2.5,"livingSpace":68,"balcony":true}},
+  {"@id":"222","resultlist.realEstate":{"title":"Neubau","price":{"value":1900},"numberOfRooms":3,"livingSpace":80}},
+  {"@id":"111","resultlist.realEstate":{"title":"Duplicate"}}
+]}]}}}}}
+</script></body></html>`
+
+func TestParseSearchResultsNextData(t *testing.T) {
+	listings, err := NewParser().ParseSearchResults([]byte(nextDataSearchHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 2 {
+		t.Fatalf("got %d listings, want 2 (deduped): %+v", len(listings), listings)
+	}
+	byID := map[string]int{}
+	for i, l := range listings {
+		byID[l.IS24ID] = i
+	}
+	l := listings[byID["111"]]
+	if l.Title != "Altbau mit Balkon" || l.Price != 1450 || l.Rooms != 2.5 || l.Area != 68 || !l.HasBalcony {
+		t.Errorf("listing 111 parsed wrong: %+v", l)
+	}
+	if l.City != "München" || l.District != "Schwabing" || l.URL != DefaultSite.BaseURL+"/expose/111" {
+		t.Errorf("listing 111 address/url wrong: %+v", l)
+	}
+}
+
+func TestParseExposeNextData(t *testing.T) {
+	html := `<script id="__NEXT_DATA__" type="application/json">
+{"props":{"pageProps":{"apolloState":{"Expose:333":{"id":"333","title":"Helle Wohnung","description":"Parkett und Südbalkon",
+"address":{"city":"Berlin","postcode":"10115"},"baseRent":980,"numberOfRooms":2,"livingSpace":55,"lift":true}}}}}
+</script>`
+	l, err := NewParser().ParseExpose([]byte(html), "333")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Title != "Helle Wohnung" || l.Description != "Parkett und Südbalkon" || l.Price != 980 {
+		t.Errorf("expose parsed wrong: %+v", l)
+	}
+	if l.Rooms != 2 || l.Area != 55 || !l.HasElevator || l.PostalCode != "10115" {
+		t.Errorf("expose details wrong: %+v", l)
+	}
+}
+
+func TestParseSearchResultsApolloOrderIsStable(t *testing.T) {
+	html := `<script>window.__APOLLO_STATE__ = {
+"ResultListEntry:555":{"@id":"555","realEstate":{"title":"E","price":{"value":500}}},
+"ResultListEntry:111":{"@id":"111","realEstate":{"title":"A","price":{"value":100}}},
+"ResultListEntry:333":{"@id":"333","realEstate":{"title":"C","price":{"value":300}}},
+"ResultListEntry:222":{"@id":"222","realEstate":{"title":"B","price":{"value":200}}},
+"ResultListEntry:444":{"@id":"444","realEstate":{"title":"D","price":{"value":400}}}
+};</script>`
+	for i := 0; i < 20; i++ {
+		listings, err := NewParser().ParseSearchResults([]byte(html))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, l := range listings {
+			ids = append(ids, l.IS24ID)
+		}
+		if got := strings.Join(ids, ","); got != "111,222,333,444,555" {
+			t.Fatalf("run %d: order = %s, want 111,222,333,444,555", i, got)
+		}
+	}
+}
+
+func TestNextDataMalformedFallsBack(t *testing.T) {
+	html := `<script id="__NEXT_DATA__">{not json</script><a href="/expose/444">x</a>`
+	listings, err := NewParser().ParseSearchResults([]byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 1 || listings[0].IS24ID != "444" {
+		t.Errorf("malformed state should fall back to HTML parsing, got %+v", listings)
+	}
+}
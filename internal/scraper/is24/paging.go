@@ -0,0 +1,46 @@
+package is24
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// searchPaging is the result list's own paging metadata. Zero fields are
+// unknown.
+type searchPaging struct {
+	Total    int // hits across all pages
+	Pages    int
+	PageSize int
+}
+
+var (
+	pagingTotalRe = regexp.MustCompile(`"(?:numberOfHits|totalResults|numberOfListings|resultCount)"\s*:\s*"?(\d+)`)
+	pagingPagesRe = regexp.MustCompile(`"(?:numberOfPages|totalPages|pageCount)"\s*:\s*"?(\d+)`)
+	pagingSizeRe  = regexp.MustCompile(`"pageSize"\s*:\s*"?(\d+)`)
+)
+
+// parseSearchPaging reads the hit count and page count from a search page's
+// embedded JSON. The page count is derived from the total when only that is
+// given; pageLen (the entries parsed from this page) stands in for a missing
+// page size.
+func parseSearchPaging(html string, pageLen int) searchPaging {
+	atoi := func(re *regexp.Regexp) int {
+		if m := re.FindStringSubmatch(html); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n
+		}
+		return 0
+	}
+	p := searchPaging{
+		Total:    atoi(pagingTotalRe),
+		Pages:    atoi(pagingPagesRe),
+		PageSize: atoi(pagingSizeRe),
+	}
+	if p.PageSize == 0 {
+		p.PageSize = pageLen
+	}
+	if p.Pages == 0 && p.Total > 0 && p.PageSize > 0 {
+		p.Pages = (p.Total + p.PageSize - 1) / p.PageSize
+	}
+	return p
+}
@@ -0,0 +1,29 @@
+package is24
+
+import "testing"
+
+func TestParseSearchPaging(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		pageLen int
+		want    searchPaging
+	}{
+		{
+			"full paging object",
+			`{"paging":{"pageNumber":1,"pageSize":20,"numberOfPages":13,"numberOfHits":251}}`,
+			20, searchPaging{Total: 251, Pages: 13, PageSize: 20},
+		},
+		{"total only, page size from entries", `{"totalResults":"41"}`, 20, searchPaging{Total: 41, Pages: 3, PageSize: 20}},
+		{"exact multiple", `{"numberOfHits":40,"pageSize":20}`, 20, searchPaging{Total: 40, Pages: 2, PageSize: 20}},
+		{"no metadata", `<html></html>`, 20, searchPaging{PageSize: 20}},
+		{"no hits", `{"numberOfHits":0}`, 0, searchPaging{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSearchPaging(tt.html, tt.pageLen); got != tt.want {
+				t.Errorf("parseSearchPaging = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package is24
 
 import (
 	"encoding/json"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -17,6 +18,7 @@ type Parser struct {
 	areaRe       *regexp.Regexp
 	is24IDRe     *regexp.Regexp
 	postalCodeRe *regexp.Regexp
+	baseURL      string // site origin relative links are resolved against
 }
 
 // NewParser creates a new IS24 parser
@@ -25,10 +27,11 @@ func NewParser() *Parser {
 		// Match JSON-LD or embedded result list JSON
 		jsonRe:       regexp.MustCompile(`<script[^>]*type="application/(?:ld\+)?json"[^>]*>(.*?)</script>`),
 		priceRe:      regexp.MustCompile(`(\d+(?:\.\d+)?(?:,\d+)?)\s*€`),
-		roomsRe:      regexp.MustCompile(`(\d+(?:,\d+)?)\s*(?:Zimmer|Zi\.)`),
-		areaRe:       regexp.MustCompile(`(\d+(?:,\d+)?)\s*m²`),
+		roomsRe:      regexp.MustCompile(`(?i)(?:^|[^\d.,])(\d+(?:[,.]\d)?)\s*-?\s*(?:Zimmer|Zi\b)`),
+		areaRe:       regexp.MustCompile(`(?i)(?:^|[^\d.,])(\d+(?:,\d+)?)\s*(?:m²|m2\b|qm\b)`),
 		is24IDRe:     regexp.MustCompile(`/expose/(\d+)`),
 		postalCodeRe: regexp.MustCompile(`\b(\d{5})\b`),
+		baseURL:      DefaultSite.BaseURL,
 	}
 }
 
@@ -37,12 +40,27 @@ func (p *Parser) ParseSearchResults(html []byte) ([]domain.Listing, error) {
 	htmlStr := string(html)
 	var listings []domain.Listing
 
+	// Prefer the SPA state (__NEXT_DATA__ / Apollo cache) when present
+	if results := p.extractNextDataEntries(htmlStr); len(results) > 0 {
+		for _, result := range results {
+			for _, listing := range p.resultToListings(result) {
+				if listing.IS24ID != "" {
+					listings = append(listings, listing)
+				}
+			}
+		}
+		if len(listings) > 0 {
+			return listings, nil
+		}
+	}
+
 	// Try to find embedded JSON data (IS24 embeds search results as JSON)
 	if results := p.extractResultListJSON(htmlStr); results != nil {
 		for _, result := range results {
-			listing := p.resultToListing(result)
-			if listing.IS24ID != "" {
-				listings = append(listings, listing)
+			for _, listing := range p.resultToListings(result) {
+				if listing.IS24ID != "" {
+					listings = append(listings, listing)
+				}
 			}
 		}
 		return listings, nil
@@ -53,13 +71,18 @@ func (p *Parser) ParseSearchResults(html []byte) ([]domain.Listing, error) {
 	return listings, nil
 }
 
-// ParseExpose extracts detailed listing data from expose page
+// ParseExpose extracts detailed listing data from expose page. A removed
+// listing returns domain.ErrListingGone.
 func (p *Parser) ParseExpose(html []byte, is24ID string) (*domain.Listing, error) {
 	htmlStr := string(html)
+	next := p.extractNextDataExpose(htmlStr, is24ID)
+	if exposeGone(htmlStr, next) {
+		return nil, domain.ErrListingGone
+	}
 
 	listing := &domain.Listing{
 		IS24ID: is24ID,
-		URL:    baseURL + "/expose/" + is24ID,
+		URL:    p.baseURL + "/expose/" + is24ID,
 	}
 
 	// Try to extract from JSON-LD
@@ -67,8 +90,38 @@ func (p *Parser) ParseExpose(html []byte, is24ID string) (*domain.Listing, error
 		p.populateFromJSONLD(listing, data)
 	}
 
+	// Fill gaps from the SPA state (__NEXT_DATA__ / Apollo cache)
+	if next != nil {
+		mergeListing(listing, next)
+	}
+
 	// Extract additional details from HTML
 	p.extractExposeDetails(listing, htmlStr)
+	if listing.FloorType == "" {
+		listing.FloorType = exposeFloorType(htmlStr)
+	}
+	if listing.HeatingIncluded == nil {
+		listing.HeatingIncluded = exposeHeatingIncluded(htmlStr)
+	}
+	if listing.AvailableFrom == "" || listing.VacantFrom == "" {
+		available, vacant := exposeMoveInDates(htmlStr)
+		if listing.AvailableFrom == "" {
+			listing.AvailableFrom = available
+		}
+		if listing.VacantFrom == "" {
+			listing.VacantFrom = vacant
+		}
+	}
+	p.fillFromTitle(listing)
+
+	// A new-build project page describes a whole building, not one flat
+	listing.IsProject = isProjectPage(htmlStr)
+	if listing.PropertyType == "" {
+		listing.PropertyType = exposePropertyType(htmlStr)
+	}
+	listing.IsProjected = listing.IsProjected || projectedPageRe.MatchString(htmlStr)
+	listing.PricePerSqm = pricePerSqm(listing)
+	listing.RequiresMembership = requiresMembership(listing) || cooperativePageRe.MatchString(htmlStr)
 
 	return listing, nil
 }
@@ -97,25 +150,9 @@ func (p *Parser) extractResultEntries(html string) []map[string]interface{} {
 	// Pattern to find result entries in IS24's embedded JavaScript
 	// IS24 uses various formats, we try to handle the common ones
 
-	// Look for resultlistEntries array
-	entryPattern := regexp.MustCompile(`"resultlistEntries":\s*\[([\s\S]*?)\](?:\s*,\s*"|})`)
-	if matches := entryPattern.FindStringSubmatch(html); len(matches) > 1 {
-		var entries []map[string]interface{}
-		// Try to parse as JSON array
-		jsonStr := "[" + matches[1] + "]"
-		if err := json.Unmarshal([]byte(jsonStr), &entries); err == nil {
-			return entries
-		}
-	}
-
-	// Try to find searchResponseModel with resultlistEntries
-	searchModelPattern := regexp.MustCompile(`"searchResponseModel"\s*:\s*\{[^}]*"resultlistEntries"\s*:\s*\[([^\]]+)\]`)
-	if matches := searchModelPattern.FindStringSubmatch(html); len(matches) > 1 {
-		var entries []map[string]interface{}
-		jsonStr := "[" + matches[1] + "]"
-		if err := json.Unmarshal([]byte(jsonStr), &entries); err == nil {
-			return entries
-		}
+	// Look for the resultlistEntries array, wherever it is nested
+	if entries := resultlistEntries(html); len(entries) > 0 {
+		return entries
 	}
 
 	// Alternative: Look for individual expose objects with realEstate nested
@@ -158,7 +195,7 @@ func (p *Parser) resultToListing(result map[string]interface{}) domain.Listing {
 	if id, ok := result["@id"].(string); ok {
 		if matches := p.is24IDRe.FindStringSubmatch(id); len(matches) > 1 {
 			listing.IS24ID = matches[1]
-			listing.URL = baseURL + "/expose/" + matches[1]
+			listing.URL = p.baseURL + "/expose/" + matches[1]
 		}
 	}
 
@@ -168,6 +205,12 @@ func (p *Parser) resultToListing(result map[string]interface{}) domain.Listing {
 		realEstate = re
 	}
 
+	listing.PropertyType = propertyType(realEstate)
+	listing.FloorType = floorType(realEstate)
+	listing.HeatingIncluded = heatingIncluded(realEstate)
+	listing.AvailableFrom, listing.VacantFrom = moveInDates(realEstate)
+	listing.IsProjected = isProjected(realEstate)
+
 	// Title
 	if title, ok := realEstate["title"].(string); ok {
 		listing.Title = title
@@ -195,6 +238,7 @@ func (p *Parser) resultToListing(result map[string]interface{}) domain.Listing {
 		}
 		listing.Address = strings.Join(parts, ", ")
 	}
+	coordinatesFromJSON(&listing, realEstate)
 
 	// Price - try multiple possible locations
 	if price, ok := realEstate["price"].(map[string]interface{}); ok {
@@ -227,6 +271,27 @@ func (p *Parser) resultToListing(result map[string]interface{}) domain.Listing {
 	if listing.Price == 0 {
 		listing.Price = int(getFloat(realEstate, "coldRent"))
 	}
+	listing.PriceOnRequest = listing.Price == 0 && priceOnRequest(realEstate)
+
+	// Warm rent (Warmmiete) and service charge (Nebenkosten)
+	if total, ok := realEstate["calculatedTotalRent"].(map[string]interface{}); ok {
+		if value, ok := total["totalRent"].(map[string]interface{}); ok {
+			listing.WarmRent = int(getFloat(value, "value"))
+		}
+	}
+	if listing.WarmRent == 0 {
+		listing.WarmRent = int(getFloat(realEstate, "totalRent"))
+	}
+	if listing.WarmRent == 0 {
+		listing.WarmRent = int(getFloat(realEstate, "warmRent"))
+	}
+	listing.ServiceCharge = int(getFloat(realEstate, "serviceCharge"))
+	// Buy types report the Hausgeld/Wohngeld as serviceCharge; it is no
+	// Nebenkosten there.
+	if isPurchase(realEstate) {
+		listing.MonthlyFees = listing.ServiceCharge
+		listing.ServiceCharge = 0
+	}
 
 	// Rooms
 	listing.Rooms = getFloat(realEstate, "numberOfRooms")
@@ -238,15 +303,62 @@ func (p *Parser) resultToListing(result map[string]interface{}) domain.Listing {
 	listing.HasBalcony = getBool(realEstate, "balcony")
 	listing.HasEBK = getBool(realEstate, "builtInKitchen")
 	listing.HasElevator = getBool(realEstate, "lift")
+	listing.HasGuestToilet = getBool(realEstate, "guestToilet")
+	listing.HasCellar = getBool(realEstate, "cellar")
 
 	// Build year
 	if year := getInt(realEstate, "constructionYear"); year > 0 {
 		listing.BuildYear = year
 	}
 
+	// Photos, floor plan and private vs agent (quality score signals)
+	listing.ImageURLs = galleryURLs(realEstate)
+	listing.HasFloorPlan = getBool(realEstate, "floorplan") || getBool(realEstate, "floorPlan")
+	if _, ok := realEstate["privateOffer"]; ok {
+		listing.LandlordType = "gewerblich"
+		if getBool(realEstate, "privateOffer") {
+			listing.LandlordType = "privat"
+		}
+	}
+
+	// Contact person (Ansprechpartner)
+	landlordFromJSON(realEstate).fill(&listing)
+	landlordFromJSON(result).fill(&listing)
+
+	p.fillFromTitle(&listing)
+	listing.PricePerSqm = pricePerSqm(&listing)
+	listing.RequiresMembership = requiresMembership(&listing)
+
 	return listing
 }
 
+// galleryURLs collects the photo URLs of a result entry's gallery
+// ("galleryAttachments.attachment", a single object or a list).
+func galleryURLs(realEstate map[string]interface{}) []string {
+	gallery, ok := realEstate["galleryAttachments"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var attachments []interface{}
+	switch a := gallery["attachment"].(type) {
+	case []interface{}:
+		attachments = a
+	case map[string]interface{}:
+		attachments = []interface{}{a}
+	}
+	var urls []string
+	for _, a := range attachments {
+		att, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if href := firstString(att, "@xlink.href", "href", "url"); href != "" {
+			urls = append(urls, href)
+		}
+	}
+	return urls
+}
+
 func (p *Parser) parseHTMLResults(html string) []domain.Listing {
 	var listings []domain.Listing
 
@@ -265,7 +377,7 @@ func (p *Parser) parseHTMLResults(html string) []domain.Listing {
 
 			listing := domain.Listing{
 				IS24ID: is24ID,
-				URL:    baseURL + match[1],
+				URL:    p.baseURL + match[1],
 			}
 
 			// Try to extract basic info from surrounding HTML
@@ -315,6 +427,7 @@ func (p *Parser) populateFromJSONLD(listing *domain.Listing, data map[string]int
 			listing.Address = street
 		}
 	}
+	coordinatesFromJSON(listing, data)
 
 	// Offers for price
 	if offers, ok := data["offers"].(map[string]interface{}); ok {
@@ -333,9 +446,12 @@ func (p *Parser) extractExposeDetails(listing *domain.Listing, html string) {
 		}
 	}
 
-	// Extract price - try multiple patterns
+	// Extract price - try multiple patterns. The Kaufpreis comes first: a
+	// purchase expose has no Kaltmiete, but "miete" may still appear.
 	if listing.Price == 0 {
 		pricePatterns := []*regexp.Regexp{
+			regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-kaufpreis[^"]*"[^>]*>([^<]+)</dd>`),
+			regexp.MustCompile(`<div[^>]*class="[^"]*is24qa-kaufpreis[^"]*"[^>]*>([^<]+)</div>`),
 			regexp.MustCompile(`<div[^>]*class="[^"]*is24qa-kaltmiete[^"]*"[^>]*>([^<]+)</div>`),
 			regexp.MustCompile(`<span[^>]*class="[^"]*is24qa-kaltmiete[^"]*"[^>]*>([^<]+)</span>`),
 			regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-kaltmiete[^"]*"[^>]*>([^<]+)</dd>`),
@@ -355,6 +471,61 @@ func (p *Parser) extractExposeDetails(listing *domain.Listing, html string) {
 			}
 		}
 	}
+	if listing.Price == 0 && !listing.PriceOnRequest {
+		listing.PriceOnRequest = priceOnRequestPageRe.MatchString(html)
+	}
+	if listing.Price > 0 {
+		listing.PriceOnRequest = false
+	}
+
+	// Extract warm rent and service charge
+	if listing.WarmRent == 0 {
+		warmPatterns := []*regexp.Regexp{
+			regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-gesamtmiete[^"]*"[^>]*>([^<]+)</dd>`),
+			regexp.MustCompile(`<div[^>]*class="[^"]*is24qa-warmmiete[^"]*"[^>]*>([^<]+)</div>`),
+			regexp.MustCompile(`"totalRent"\s*:\s*(\d+(?:\.\d+)?)`),
+		}
+		for _, pattern := range warmPatterns {
+			if matches := pattern.FindStringSubmatch(html); len(matches) > 1 {
+				if rent := parsePrice(matches[1]); rent > 0 {
+					listing.WarmRent = rent
+					break
+				}
+			}
+		}
+	}
+	if listing.MonthlyFees == 0 {
+		feePatterns := []*regexp.Regexp{
+			regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-hausgeld[^"]*"[^>]*>([^<]+)</dd>`),
+			regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-wohngeld[^"]*"[^>]*>([^<]+)</dd>`),
+		}
+		for _, pattern := range feePatterns {
+			if matches := pattern.FindStringSubmatch(html); len(matches) > 1 {
+				if fees := parsePrice(matches[1]); fees > 0 {
+					listing.MonthlyFees = fees
+					break
+				}
+			}
+		}
+	}
+	if listing.ServiceCharge == 0 {
+		ncPatterns := []*regexp.Regexp{
+			regexp.MustCompile(`<dd[^>]*class="[^"]*is24qa-nebenkosten[^"]*"[^>]*>([^<]+)</dd>`),
+			regexp.MustCompile(`"serviceCharge"\s*:\s*(\d+(?:\.\d+)?)`),
+		}
+		for _, pattern := range ncPatterns {
+			if matches := pattern.FindStringSubmatch(html); len(matches) > 1 {
+				if charge := parsePrice(matches[1]); charge > 0 {
+					listing.ServiceCharge = charge
+					break
+				}
+			}
+		}
+	}
+	// The serviceCharge fallback above is the Hausgeld on purchase exposes.
+	if listing.MonthlyFees > 0 && listing.ServiceCharge == listing.MonthlyFees {
+		listing.ServiceCharge = 0
+	}
 
 	// Extract rooms
 	if listing.Rooms == 0 {
@@ -385,11 +556,40 @@ func (p *Parser) extractExposeDetails(listing *domain.Listing, html string) {
 	   strings.Contains(strings.ToLower(html), "aufzug: ja") {
 		listing.HasElevator = true
 	}
+	if strings.Contains(html, "is24qa-gaeste-wc-ja") ||
+		strings.Contains(strings.ToLower(html), "gäste-wc: ja") {
+		listing.HasGuestToilet = true
+	}
+	if strings.Contains(html, "is24qa-keller-ja") ||
+		strings.Contains(strings.ToLower(html), "keller: ja") {
+		listing.HasCellar = true
+	}
+	if strings.Contains(html, "is24qa-separate-kueche-ja") ||
+		strings.Contains(strings.ToLower(html), "separate küche: ja") {
+		listing.HasSeparateKitchen = true
+	}
 
-	// Landlord info
-	landlordPattern := regexp.MustCompile(`<span[^>]*class="[^"]*realtor-title[^"]*"[^>]*>([^<]+)</span>`)
-	if matches := landlordPattern.FindStringSubmatch(html); len(matches) > 1 {
-		listing.LandlordName = strings.TrimSpace(matches[1])
+	// Map coordinates
+	coordinatesFromHTML(listing, html)
+
+	// Landlord info from the "Ansprechpartner" box
+	parseContactBlock(html).fill(listing)
+
+	// Quality score signals: floor plan and private offer
+	if !listing.HasFloorPlan {
+		listing.HasFloorPlan = strings.Contains(html, "is24qa-grundriss") ||
+			regexp.MustCompile(`"floorplan"\s*:\s*"?true`).MatchString(html)
+	}
+	if listing.LandlordType == "" {
+		if m := regexp.MustCompile(`"privateOffer"\s*:\s*"?(true|false)`).FindStringSubmatch(html); len(m) > 1 {
+			listing.LandlordType = "gewerblich"
+			if m[1] == "true" {
+				listing.LandlordType = "privat"
+			}
+		}
+	}
+	if listing.LandlordType == "" && listing.LandlordCompany != "" {
+		listing.LandlordType = "gewerblich" // private offers have no company
 	}
 
 	// Contact form URL
@@ -397,13 +597,52 @@ func (p *Parser) extractExposeDetails(listing *domain.Listing, html string) {
 	if matches := contactPattern.FindStringSubmatch(html); len(matches) > 1 {
 		listing.ContactFormURL = matches[1]
 		if !strings.HasPrefix(listing.ContactFormURL, "http") {
-			listing.ContactFormURL = baseURL + listing.ContactFormURL
+			listing.ContactFormURL = p.baseURL + listing.ContactFormURL
 		}
 	}
 }
 
 // Helper functions
 
+// onRequestRe matches the "Preis auf Anfrage" price text; priceOnRequestPageRe
+// finds it, or the flag, on an expose page.
+var (
+	onRequestRe          = regexp.MustCompile(`(?i)^\s*(?:preis\s+)?auf\s+anfrage\s*$`)
+	priceOnRequestPageRe = regexp.MustCompile(`(?i)"(?:is)?priceOnRequest"\s*:\s*true|is24qa-(?:kaltmiete|kaufpreis)[^"]*"[^>]*>\s*(?:preis\s+)?auf\s+anfrage`)
+)
+
+// priceOnRequest reports whether a realEstate object states no price on
+// purpose, by flag or as the price text.
+func priceOnRequest(realEstate map[string]interface{}) bool {
+	objs := []map[string]interface{}{realEstate}
+	if price, ok := realEstate["price"].(map[string]interface{}); ok {
+		objs = append(objs, price)
+	}
+	for _, m := range objs {
+		if getBool(m, "priceOnRequest") || getBool(m, "isPriceOnRequest") {
+			return true
+		}
+		for _, key := range []string{"price", "value", "priceText"} {
+			if onRequestRe.MatchString(getString(m, key)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPurchase reports whether a realEstate object is offered for sale, going
+// by its type ("expose:ApartmentBuy", "APARTMENT_BUY") or marketing type.
+func isPurchase(realEstate map[string]interface{}) bool {
+	for _, key := range []string{"@xsi.type", "realEstateType", "type", "marketingType"} {
+		v := strings.ToUpper(getString(realEstate, key))
+		if strings.Contains(v, "BUY") || strings.Contains(v, "PURCHASE") {
+			return true
+		}
+	}
+	return false
+}
+
 func getString(m map[string]interface{}, key string) string {
 	if v, ok := m[key].(string); ok {
 		return v
@@ -442,31 +681,124 @@ func getBool(m map[string]interface{}, key string) bool {
 	case bool:
 		return v
 	case string:
+		v = strings.ToLower(v)
 		return v == "true" || v == "1" || v == "yes" || v == "ja"
 	}
 	return false
 }
 
 func parsePrice(s string) int {
-	// Remove non-numeric chars except dots and commas
-	cleaned := regexp.MustCompile(`[^\d,.]`).ReplaceAllString(s, "")
-	// Handle German number format (1.234,56)
-	cleaned = strings.Replace(cleaned, ".", "", -1)
-	cleaned = strings.Replace(cleaned, ",", ".", 1)
-	f, _ := strconv.ParseFloat(cleaned, 64)
-	return int(f)
+	return int(parseGermanNumber(s))
+}
+
+// pricePerSqm returns the listing's price per m²: Kaltmiete for rentals,
+// Kaufpreis for purchases (Price holds either). 0 when price or area is
+// unknown.
+func pricePerSqm(l *domain.Listing) float64 {
+	if l.Price <= 0 || l.Area <= 0 {
+		return 0
+	}
+	return math.Round(float64(l.Price)/float64(l.Area)*100) / 100
+}
+
+// fillFromTitle recovers rooms, area and floor type from the title
+// ("3,5-Zimmer-Dachgeschosswohnung, 82 m²") when the structured fields are
+// missing, as on many sparse search results. Implausible values are ignored.
+func (p *Parser) fillFromTitle(l *domain.Listing) {
+	if l.Rooms == 0 {
+		if m := p.roomsRe.FindStringSubmatch(l.Title); m != nil {
+			if rooms := parseRooms(m[1]); rooms > 0 && rooms <= 20 {
+				l.Rooms = rooms
+			}
+		}
+	}
+	if l.Area == 0 {
+		if m := p.areaRe.FindStringSubmatch(l.Title); m != nil {
+			if area := parseArea(m[1]); area >= 10 && area <= 1000 {
+				l.Area = area
+			}
+		}
+	}
+	if l.FloorType == "" {
+		l.FloorType = floorTypeFromTitle(l.Title)
+	}
 }
 
 func parseRooms(s string) float64 {
-	cleaned := regexp.MustCompile(`[^\d,.]`).ReplaceAllString(s, "")
-	cleaned = strings.Replace(cleaned, ",", ".", 1)
-	f, _ := strconv.ParseFloat(cleaned, 64)
-	return f
+	return parseGermanNumber(s)
 }
 
 func parseArea(s string) int {
-	cleaned := regexp.MustCompile(`[^\d,.]`).ReplaceAllString(s, "")
-	cleaned = strings.Replace(cleaned, ",", ".", 1)
-	f, _ := strconv.ParseFloat(cleaned, 64)
-	return int(f)
+	return int(parseGermanNumber(s))
+}
+
+var numberRe = regexp.MustCompile(`\d[\d.,]*`)
+
+// groupingRe matches a thousands grouping numberRe doesn't know: a space,
+// (narrow) no-break space or Swiss apostrophe between a digit and a group of
+// exactly three digits. Anything else separates two numbers ("ab 2 3-Zi").
+var groupingRe = regexp.MustCompile(`(\d)[ \x{a0}\x{202f}'](\d{3})\b`)
+
+// stripGrouping removes space and apostrophe thousands groupings. Matches
+// can't overlap, so "1 234 567" takes more than one pass.
+func stripGrouping(s string) string {
+	for {
+		stripped := groupingRe.ReplaceAllString(s, "$1$2")
+		if stripped == s {
+			return s
+		}
+		s = stripped
+	}
+}
+
+// parseGermanNumber parses the first number in s, e.g. "1.250,00 €",
+// "68,5 m²" or "1250.00". When both separators occur, the last one is the
+// decimal mark. A lone separator followed by exactly three digits is a
+// thousands separator ("1.250", "1,250"), otherwise it is a decimal mark
+// ("12.50", "2,5"). Repeated separators are always thousands separators.
+// Space and apostrophe grouping ("1 250 €", "CHF 1'250") is dropped first.
+func parseGermanNumber(s string) float64 {
+	num := strings.TrimRight(numberRe.FindString(stripGrouping(s)), ".,")
+	if num == "" {
+		return 0
+	}
+
+	var decimal string
+	dot, comma := strings.LastIndex(num, "."), strings.LastIndex(num, ",")
+	switch {
+	case dot >= 0 && comma >= 0:
+		decimal = ","
+		if dot > comma {
+			decimal = "."
+		}
+	case dot >= 0:
+		decimal = decimalMark(num, ".")
+	case comma >= 0:
+		decimal = decimalMark(num, ",")
+	}
+
+	var b strings.Builder
+	for i, r := range num {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case string(r) == decimal && i == strings.LastIndex(num, decimal):
+			b.WriteByte('.')
+		}
+	}
+	f, _ := strconv.ParseFloat(b.String(), 64)
+	return f
+}
+
+// decimalMark decides whether sep, the only separator kind in num, marks
+// decimals. Returns sep if so, "" if it separates thousands.
+func decimalMark(num, sep string) string {
+	if strings.Count(num, sep) > 1 {
+		return ""
+	}
+	i := strings.Index(num, sep)
+	if len(num)-i-1 == 3 && num[:i] != "0" {
+		return ""
+	}
+	return sep
 }
@@ -0,0 +1,221 @@
+package is24
+
+import "testing"
+
+func TestParseGermanNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1.250,00 €", 1250},
+		{"1.250 €", 1250},
+		{"1250 €", 1250},
+		{"1250,50 €", 1250.5},
+		{"1.234.567 €", 1234567},
+		{"1.234.567,89 €", 1234567.89},
+		{"12.50", 12.5},
+		{"1250.00", 1250},
+		{"1,250.00", 1250},
+		{"1,250", 1250},
+		{"0,125", 0.125},
+		{"850 € (zzgl. NK)", 850},
+		{"ca. 1.100 €", 1100},
+		{"68,75 m²", 68.75},
+		{"2,5", 2.5},
+		{"3", 3},
+		{"1.250.", 1250},
+		{"1 250 €", 1250},
+		{"1\u00a0250 €", 1250},
+		{"1\u202f250,50 €", 1250.5},
+		{"CHF 1'250", 1250},
+		{"CHF 1'250.50", 1250.5},
+		{"1 234 567 €", 1234567},
+		{"ab 2 3-Zi", 2},
+		{"2 Zimmer, 3 Bäder", 2},
+		{"k.A.", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseGermanNumber(tt.in); got != tt.want {
+			t.Errorf("parseGermanNumber(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParsePriceTruncatesCents(t *testing.T) {
+	if got := parsePrice("1.250,99 €"); got != 1250 {
+		t.Errorf("parsePrice = %d, want 1250", got)
+	}
+	if got := parseArea("102,5 m²"); got != 102 {
+		t.Errorf("parseArea = %d, want 102", got)
+	}
+}
+
+func TestRoomsAndAreaFromTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		rooms float64
+		area  int
+	}{
+		{"3,5-Zimmer-Wohnung in Mitte", 3.5, 0},
+		{"Helle 2 Zimmer Wohnung, 82 m² mit Balkon", 2, 82},
+		{"Schöne 3-Zi.-Whg., ca. 75,5 qm", 3, 75},
+		{"1-2 Zimmer gesucht? 45m2 Single-Apartment", 2, 45},
+		{"Grundstück mit 1.200 m² und Schlafzimmer", 0, 0},
+		{"Traumwohnung am Park", 0, 0},
+		{"Bürofläche 4.000 qm, 2022 Zimmer", 0, 0},
+	}
+	p := NewParser()
+	for _, tt := range tests {
+		l := p.resultToListing(map[string]interface{}{
+			"@id":        "/expose/1",
+			"realEstate": map[string]interface{}{"title": tt.title},
+		})
+		if l.Rooms != tt.rooms || l.Area != tt.area {
+			t.Errorf("%q: rooms %v area %d, want %v/%d", tt.title, l.Rooms, l.Area, tt.rooms, tt.area)
+		}
+	}
+
+	// Structured values win over the title.
+	l := p.resultToListing(map[string]interface{}{
+		"@id": "/expose/1",
+		"realEstate": map[string]interface{}{
+			"title": "3-Zimmer-Wohnung, 80 m²", "numberOfRooms": 2.5, "livingSpace": 70.0,
+		},
+	})
+	if l.Rooms != 2.5 || l.Area != 70 {
+		t.Errorf("structured fields overwritten: rooms %v area %d", l.Rooms, l.Area)
+	}
+}
+
+func TestResultToListingScoreSignals(t *testing.T) {
+	l := NewParser().resultToListing(map[string]interface{}{
+		"@id": "/expose/555",
+		"realEstate": map[string]interface{}{
+			"floorplan":    "true",
+			"privateOffer": "true",
+			"galleryAttachments": map[string]interface{}{
+				"attachment": []interface{}{
+					map[string]interface{}{"@xlink.href": "https://pictures.example/1.jpg"},
+					map[string]interface{}{"@xlink.href": "https://pictures.example/2.jpg"},
+				},
+			},
+		},
+	})
+	if len(l.ImageURLs) != 2 || !l.HasFloorPlan || l.LandlordType != "privat" {
+		t.Errorf("score signals parsed wrong: images=%v floorplan=%v landlord=%q", l.ImageURLs, l.HasFloorPlan, l.LandlordType)
+	}
+}
+
+func TestParseExposeRoomFeatures(t *testing.T) {
+	html := `<dd class="is24qa-gaeste-wc-ja grid-item">Gäste-WC</dd>
+<dd class="is24qa-keller-ja grid-item">Keller</dd>`
+	l, err := NewParser().ParseExpose([]byte(html), "777")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.HasGuestToilet || !l.HasCellar || l.HasSeparateKitchen {
+		t.Errorf("criteria parsed wrong: guest wc=%v cellar=%v kitchen=%v", l.HasGuestToilet, l.HasCellar, l.HasSeparateKitchen)
+	}
+
+	r := NewParser().resultToListing(map[string]interface{}{
+		"@id":        "/expose/778",
+		"realEstate": map[string]interface{}{"guestToilet": "YES", "cellar": "NO"},
+	})
+	if !r.HasGuestToilet || r.HasCellar {
+		t.Errorf("search JSON parsed wrong: guest wc=%v cellar=%v", r.HasGuestToilet, r.HasCellar)
+	}
+}
+
+func TestParseMonthlyFees(t *testing.T) {
+	buy := NewParser().resultToListing(map[string]interface{}{
+		"@id": "/expose/901",
+		"realEstate": map[string]interface{}{
+			"@xsi.type":     "search:ApartmentBuy",
+			"price":         map[string]interface{}{"value": 350000.0},
+			"serviceCharge": 280.0,
+		},
+	})
+	if buy.MonthlyFees != 280 || buy.ServiceCharge != 0 || buy.Price != 350000 {
+		t.Errorf("buy JSON: fees=%d service charge=%d price=%d", buy.MonthlyFees, buy.ServiceCharge, buy.Price)
+	}
+
+	rent := NewParser().resultToListing(map[string]interface{}{
+		"@id":        "/expose/902",
+		"realEstate": map[string]interface{}{"@xsi.type": "search:ApartmentRent", "serviceCharge": 200.0},
+	})
+	if rent.MonthlyFees != 0 || rent.ServiceCharge != 200 {
+		t.Errorf("rent JSON: fees=%d service charge=%d", rent.MonthlyFees, rent.ServiceCharge)
+	}
+
+	html := `<dd class="is24qa-hausgeld grid-item three-fifths">310,50 €</dd>
+<script>var x = {"serviceCharge": 310.5};</script>`
+	l, err := NewParser().ParseExpose([]byte(html), "903")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.MonthlyFees != 310 || l.ServiceCharge != 0 {
+		t.Errorf("expose HTML: fees=%d service charge=%d", l.MonthlyFees, l.ServiceCharge)
+	}
+}
+
+func TestParsePricePerSqm(t *testing.T) {
+	buy := NewParser().resultToListing(map[string]interface{}{
+		"@id": "/expose/904",
+		"realEstate": map[string]interface{}{
+			"@xsi.type":   "search:ApartmentBuy",
+			"price":       map[string]interface{}{"value": 349000.0},
+			"livingSpace": 74.0,
+		},
+	})
+	if buy.PricePerSqm != 4716.22 || !buy.IsPurchase() {
+		t.Errorf("buy JSON: price per sqm %v, purchase %v", buy.PricePerSqm, buy.IsPurchase())
+	}
+
+	// The expose names the Kaufpreis; "Miete" elsewhere on the page must not
+	// be taken for the price.
+	html := `<dd class="is24qa-kaufpreis grid-item three-fifths">349.000 €</dd>
+<p>Vermietet, Mieteinnahmen 950 € im Monat</p>
+<div class="is24qa-wohnflaeche-ca">74 m²</div>`
+	l, err := NewParser().ParseExpose([]byte(html), "905")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Price != 349000 || l.PricePerSqm != 4716.22 {
+		t.Errorf("expose HTML: price %d, price per sqm %v", l.Price, l.PricePerSqm)
+	}
+
+	if rent := NewParser().resultToListing(map[string]interface{}{
+		"@id":        "/expose/906",
+		"realEstate": map[string]interface{}{"price": map[string]interface{}{"value": 1200.0}},
+	}); rent.PricePerSqm != 0 {
+		t.Errorf("unknown area: price per sqm %v, want 0", rent.PricePerSqm)
+	}
+}
+
+func TestParsePriceOnRequest(t *testing.T) {
+	p := NewParser()
+	for name, estate := range map[string]map[string]interface{}{
+		"flag":       {"priceOnRequest": true},
+		"price text": {"price": map[string]interface{}{"value": "Preis auf Anfrage"}},
+	} {
+		l := p.resultToListing(map[string]interface{}{"@id": "/expose/1", "realEstate": estate})
+		if !l.PriceOnRequest || l.Price != 0 {
+			t.Errorf("%s: PriceOnRequest=%v price=%d", name, l.PriceOnRequest, l.Price)
+		}
+	}
+	if l := p.resultToListing(map[string]interface{}{"@id": "/expose/2", "realEstate": map[string]interface{}{}}); l.PriceOnRequest {
+		t.Error("a missing price is not on request")
+	}
+
+	l, err := p.ParseExpose([]byte(`<dd class="is24qa-kaltmiete grid-item three-fifths"> auf Anfrage </dd>`), "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.PriceOnRequest {
+		t.Error("expose HTML: price on request not detected")
+	}
+	if l, _ := p.ParseExpose([]byte(`<dd class="is24qa-kaltmiete">950 €</dd>`), "4"); l.PriceOnRequest || l.Price != 950 {
+		t.Errorf("expose with price: PriceOnRequest=%v price=%d", l.PriceOnRequest, l.Price)
+	}
+}
@@ -0,0 +1,142 @@
+package is24
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/julianbeese/immo_bot/internal/domain"
+)
+
+// New-build projects (Neubauprojekte) show up between normal results but
+// describe a whole building: price and rooms are "ab"/"bis" ranges of all
+// units, so the parsed numbers are garbage. When the project lists its units
+// with their own expose IDs they are returned instead; otherwise the project
+// is returned marked IsProject, which the filter drops as new_build_project.
+var (
+	projectURLRe   = regexp.MustCompile(`(?i)/(?:neubau|neubauprojekte?|projekte?)/`)
+	projectIDRe    = regexp.MustCompile(`(\d+)(?:\.html)?/?(?:[?#].*)?$`)
+	projectPageRes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)"(?:isProject|isNewHomeBuilderProject)"\s*:\s*true`),
+		regexp.MustCompile(`(?i)"(?:@xsi\.type|exposeType|realEstateType|__typename)"\s*:\s*"[^"]*project[^"]*"`),
+		regexp.MustCompile(`(?i)<link[^>]+rel="canonical"[^>]+href="[^"]*/neubau(?:projekte?)?/`),
+		regexp.MustCompile(`(?i)<meta[^>]+property="og:url"[^>]+content="[^"]*/neubau(?:projekte?)?/`),
+	}
+)
+
+// projectUnitKeys are the fields a project entry lists its units under.
+var projectUnitKeys = []string{"units", "projectUnits", "unitExposes", "exposes"}
+
+// resultToListings converts one search result entry, expanding new-build
+// projects into their units.
+func (p *Parser) resultToListings(result map[string]interface{}) []domain.Listing {
+	realEstate := result
+	if re, ok := result["realEstate"].(map[string]interface{}); ok {
+		realEstate = re
+	}
+	if !isProjectEntry(result, realEstate) {
+		return []domain.Listing{p.resultToListing(result)}
+	}
+
+	project := p.resultToListing(result)
+	var units []domain.Listing
+	for _, unit := range projectUnits(result, realEstate) {
+		l := p.resultToListing(unit)
+		if l.IS24ID == "" {
+			continue
+		}
+		// Units usually only carry the flat's own data.
+		if l.Address == "" {
+			l.Address, l.City, l.District, l.PostalCode = project.Address, project.City, project.District, project.PostalCode
+		}
+		if !l.HasCoordinates() {
+			l.Latitude, l.Longitude = project.Latitude, project.Longitude
+		}
+		if l.Title == "" {
+			l.Title = project.Title
+		}
+		units = append(units, l)
+	}
+	if len(units) > 0 {
+		return units
+	}
+
+	project.IsProject = true
+	if project.IS24ID == "" {
+		// Project pages live outside /expose/; keep them under their own URL
+		// so they are still counted (and dropped) with a reason.
+		if url := projectURL(result, realEstate); url != "" {
+			if m := projectIDRe.FindStringSubmatch(url); m != nil {
+				project.IS24ID = m[1]
+				project.URL = url
+				if strings.HasPrefix(url, "/") {
+					project.URL = p.baseURL + url
+				}
+			}
+		}
+	}
+	return []domain.Listing{project}
+}
+
+// isProjectEntry reports whether a search result entry is a new-build project
+// rather than a single apartment.
+func isProjectEntry(result, realEstate map[string]interface{}) bool {
+	for _, m := range []map[string]interface{}{result, realEstate} {
+		for _, key := range []string{"@xsi.type", "type", "realEstateType", "exposeType", "__typename"} {
+			if strings.Contains(strings.ToLower(getString(m, key)), "project") {
+				return true
+			}
+		}
+		if getBool(m, "isProject") || getBool(m, "isNewHomeBuilderProject") {
+			return true
+		}
+	}
+	return projectURLRe.MatchString(projectURL(result, realEstate))
+}
+
+// projectURL returns the entry's link when it points outside /expose/.
+func projectURL(result, realEstate map[string]interface{}) string {
+	for _, m := range []map[string]interface{}{result, realEstate} {
+		for _, key := range []string{"@id", "url", "link", "projectUrl"} {
+			if s := getString(m, key); s != "" && !strings.Contains(s, "/expose/") && strings.Contains(s, "/") {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// projectUnits returns the project's units normalized to the entry shape
+// resultToListing expects.
+func projectUnits(result, realEstate map[string]interface{}) []map[string]interface{} {
+	var units []map[string]interface{}
+	for _, m := range []map[string]interface{}{result, realEstate} {
+		for _, key := range projectUnitKeys {
+			list, ok := m[key].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, item := range list {
+				unit, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if entry, _ := normalizeEntry(unit); entry != nil {
+					units = append(units, entry)
+				} else if id := jsonID(unit); id != "" {
+					units = append(units, map[string]interface{}{"@id": "/expose/" + id, "realEstate": unit})
+				}
+			}
+		}
+	}
+	return units
+}
+
+// isProjectPage reports whether an expose page is a new-build project page.
+func isProjectPage(html string) bool {
+	for _, re := range projectPageRes {
+		if re.MatchString(html) {
+			return true
+		}
+	}
+	return false
+}
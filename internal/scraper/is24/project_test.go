@@ -0,0 +1,94 @@
+package is24
+
+import "testing"
+
+// projectSearchHTML is a trimmed search page snapshot with a normal flat, a
+// new-build project listing its units and one without unit data.
+const projectSearchHTML = `<html><body>
+<script id="__NEXT_DATA__" type="application/json">
+{"props":{"pageProps":{"searchResponseModel":{"resultlist.resultlist":{"resultlistEntries":[{"resultlistEntry":[
+  {"@id":"111","resultlist.realEstate":{"title":"Altbau","address":{"city":"Berlin","postcode":"10115"},"price":{"value":1100},"numberOfRooms":2,"livingSpace":60}},
+  {"@id":"500","resultlist.realEstate":{"@xsi.type":"search:NewHomeBuilderProject","title":"Wohnen am Park – 48 Einheiten",
+    "address":{"city":"Berlin","quarter":"Pankow","postcode":"13187"},"price":{"value":690},"numberOfRooms":1,"livingSpace":32,
+    "units":[
+      {"id":"501","title":"2-Zimmer mit Loggia","price":{"value":1250},"numberOfRooms":2,"livingSpace":58},
+      {"id":"502","price":{"value":1890},"numberOfRooms":4,"livingSpace":102}
+    ]}},
+  {"@id":"600","resultlist.realEstate":{"@xsi.type":"search:NewHomeBuilderProject","title":"Quartier Nord","price":{"value":750},"numberOfRooms":1}}
+]}]}}}}}
+</script></body></html>`
+
+func TestParseSearchResultsExpandsProjects(t *testing.T) {
+	listings, err := NewParser().ParseSearchResults([]byte(projectSearchHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]int{}
+	for i, l := range listings {
+		byID[l.IS24ID] = i
+	}
+	if len(listings) != 4 {
+		t.Fatalf("got %d listings, want flat + 2 units + unexpanded project: %+v", len(listings), listings)
+	}
+	if _, ok := byID["500"]; ok {
+		t.Error("project with units should be replaced by its units")
+	}
+
+	unit := listings[byID["501"]]
+	if unit.Price != 1250 || unit.Rooms != 2 || unit.Area != 58 || unit.IsProject {
+		t.Errorf("unit 501 parsed wrong: %+v", unit)
+	}
+	if unit.City != "Berlin" || unit.District != "Pankow" || unit.URL != DefaultSite.BaseURL+"/expose/501" {
+		t.Errorf("unit 501 should inherit the project address: %+v", unit)
+	}
+	if l := listings[byID["502"]]; l.Title != "Wohnen am Park – 48 Einheiten" || l.Price != 1890 {
+		t.Errorf("unit 502 should fall back to the project title: %+v", l)
+	}
+	if !listings[byID["600"]].IsProject {
+		t.Errorf("project without units should be marked: %+v", listings[byID["600"]])
+	}
+	if listings[byID["111"]].IsProject {
+		t.Error("normal flat marked as project")
+	}
+}
+
+func TestParseSearchResultsProjectLink(t *testing.T) {
+	html := `<script id="__NEXT_DATA__" type="application/json">
+{"props":{"pageProps":{"entries":[
+  {"id":"700","url":"/neubau/wohnpark-sued/700.html","realEstate":{"title":"Wohnpark Süd","price":{"value":990}}}
+]}}}
+</script>`
+	listings, err := NewParser().ParseSearchResults([]byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 1 || !listings[0].IsProject || listings[0].IS24ID != "700" {
+		t.Fatalf("project link not detected: %+v", listings)
+	}
+}
+
+// projectExposeHTML is a trimmed Neubauprojekt page snapshot.
+const projectExposeHTML = `<html><head>
+<link rel="canonical" href="https://www.immobilienscout24.de/neubau/bauwert/wohnen-am-park/500.html">
+<title>Neubauprojekt Wohnen am Park</title></head><body>
+<h1 id="expose-title">Wohnen am Park – 48 Einheiten</h1>
+<dd class="is24qa-kaltmiete">ab 690 €</dd>
+</body></html>`
+
+func TestParseExposeDetectsProjectPage(t *testing.T) {
+	l, err := NewParser().ParseExpose([]byte(projectExposeHTML), "500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.IsProject {
+		t.Errorf("project page not detected: %+v", l)
+	}
+
+	flat, err := NewParser().ParseExpose([]byte(`<h1 id="expose-title">Neubau-Wohnung</h1><dd class="is24qa-kaltmiete">900 €</dd>`), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flat.IsProject {
+		t.Error("a flat in a new building is not a project")
+	}
+}
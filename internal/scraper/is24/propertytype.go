@@ -0,0 +1,66 @@
+package is24
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Broad search URLs let commercial listings (Büro, Laden, Gastronomie, …) and
+// planned buildings (Bauphase "projektiert") slip in between the flats. The
+// parser records the IS24 real-estate type and the planning state; the filter
+// drops what does not fit the profile.
+var (
+	exposeTypeRes = []*regexp.Regexp{
+		regexp.MustCompile(`"realEstateType"\s*:\s*"([A-Za-z_]+)"`),
+		regexp.MustCompile(`"@xsi\.type"\s*:\s*"expose:([A-Za-z]+)"`),
+		regexp.MustCompile(`"obj_immotype"\s*:\s*"([a-z_]+)"`),
+	}
+	projectedPageRe = regexp.MustCompile(`(?i)"constructionPhase(?:Type)?"\s*:\s*"PROJECTED"|is24qa-bauphase[^>]*>\s*projektiert`)
+)
+
+// immotypeWords translate the German words of IS24's tracking type
+// ("wohnung_miete") into the API's ("ApartmentRent").
+var immotypeWords = strings.NewReplacer("wohnung", "apartment", "haus", "house", "miete", "rent", "kauf", "buy")
+
+// normalizePropertyType lowercases an IS24 type and drops its namespace and
+// separators: "search:ApartmentRent", "APARTMENT_RENT" and "wohnung_miete"
+// all become "apartmentrent".
+func normalizePropertyType(t string) string {
+	if i := strings.LastIndex(t, ":"); i >= 0 {
+		t = t[i+1:]
+	}
+	t = strings.ToLower(strings.ReplaceAll(t, "_", ""))
+	return immotypeWords.Replace(t)
+}
+
+// propertyType returns the normalized type of a realEstate object, "" when it
+// has none.
+func propertyType(realEstate map[string]interface{}) string {
+	for _, key := range []string{"@xsi.type", "realEstateType", "type"} {
+		if t := normalizePropertyType(getString(realEstate, key)); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// isProjected reports whether a realEstate object is only planned.
+func isProjected(realEstate map[string]interface{}) bool {
+	for _, key := range []string{"constructionPhase", "constructionPhaseType"} {
+		if strings.EqualFold(getString(realEstate, key), "PROJECTED") {
+			return true
+		}
+	}
+	return false
+}
+
+// exposePropertyType finds the real-estate type in an expose page's embedded
+// data, "" when it has none.
+func exposePropertyType(html string) string {
+	for _, re := range exposeTypeRes {
+		if m := re.FindStringSubmatch(html); m != nil {
+			return normalizePropertyType(m[1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package is24
+
+import "testing"
+
+// mixedSearchHTML is a trimmed search page snapshot where a broad URL let an
+// office and a planned building in between the flats.
+const mixedSearchHTML = `<html><body>
+<script id="__NEXT_DATA__" type="application/json">
+{"props":{"pageProps":{"searchResponseModel":{"resultlist.resultlist":{"resultlistEntries":[{"resultlistEntry":[
+  {"@id":"111","resultlist.realEstate":{"@xsi.type":"search:ApartmentRent","title":"Altbau","price":{"value":1100},"numberOfRooms":2}},
+  {"@id":"222","resultlist.realEstate":{"@xsi.type":"search:Office","title":"Büro am Ring","price":{"value":900}}},
+  {"@id":"333","resultlist.realEstate":{"@xsi.type":"search:ApartmentRent","constructionPhase":"PROJECTED","title":"Bald: Neubau","price":{"value":1300}}},
+  {"@id":"444","resultlist.realEstate":{"title":"Ohne Typ","price":{"value":800}}}
+]}]}}}}}
+</script></body></html>`
+
+func TestParseSearchResultsPropertyType(t *testing.T) {
+	listings, err := NewParser().ParseSearchResults([]byte(mixedSearchHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct {
+		typ       string
+		projected bool
+	}{
+		"111": {"apartmentrent", false},
+		"222": {"office", false},
+		"333": {"apartmentrent", true},
+		"444": {"", false},
+	}
+	if len(listings) != len(want) {
+		t.Fatalf("got %d listings, want %d", len(listings), len(want))
+	}
+	for _, l := range listings {
+		w := want[l.IS24ID]
+		if l.PropertyType != w.typ || l.IsProjected != w.projected {
+			t.Errorf("%s: type %q projected %v, want %q %v", l.IS24ID, l.PropertyType, l.IsProjected, w.typ, w.projected)
+		}
+	}
+}
+
+func TestNormalizePropertyType(t *testing.T) {
+	for in, want := range map[string]string{
+		"search:ApartmentRent": "apartmentrent",
+		"expose:HouseBuy":      "housebuy",
+		"APARTMENT_RENT":       "apartmentrent",
+		"wohnung_miete":        "apartmentrent",
+		"haus_kauf":            "housebuy",
+		"GASTRONOMY":           "gastronomy",
+		"":                     "",
+	} {
+		if got := normalizePropertyType(in); got != want {
+			t.Errorf("normalizePropertyType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseExposePropertyType(t *testing.T) {
+	html := `<h1 id="expose-title">Ladenlokal</h1><script>var keyValues = {"obj_immotype":"laden"};</script>
+<dd class="is24qa-bauphase grid-item three-fifths">Projektiert</dd>`
+	l, err := NewParser().ParseExpose([]byte(html), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.PropertyType != "laden" || !l.IsProjected {
+		t.Errorf("got type %q projected %v", l.PropertyType, l.IsProjected)
+	}
+}
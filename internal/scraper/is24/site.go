@@ -0,0 +1,70 @@
+package is24
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Site is the IS24 country site the clients talk to. A zero Site is
+// DefaultSite (Germany); the Austrian and Swiss sites use the same markup
+// under their own domain and search path.
+type Site struct {
+	BaseURL    string // origin, e.g. "https://www.immobilienscout24.at"
+	SearchPath string // city search path, %s is the city slug
+}
+
+// DefaultSite is immobilienscout24.de.
+var DefaultSite = Site{
+	BaseURL:    "https://www.immobilienscout24.de",
+	SearchPath: "/Suche/de/%s/wohnung-mieten",
+}
+
+// withDefaults fills empty fields from DefaultSite and drops a trailing
+// slash from BaseURL.
+func (s Site) withDefaults() Site {
+	if s.BaseURL == "" {
+		s.BaseURL = DefaultSite.BaseURL
+	}
+	if s.SearchPath == "" {
+		s.SearchPath = DefaultSite.SearchPath
+	}
+	s.BaseURL = strings.TrimRight(s.BaseURL, "/")
+	return s
+}
+
+// Validate reports a BaseURL that is not an absolute http(s) origin or a
+// SearchPath without exactly one %s.
+func (s Site) Validate() error {
+	s = s.withDefaults()
+	u, err := url.Parse(s.BaseURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("base url %q must be like https://www.immobilienscout24.de", s.BaseURL)
+	}
+	if strings.Count(s.SearchPath, "%s") != 1 || !strings.HasPrefix(s.SearchPath, "/") {
+		return fmt.Errorf("search path %q must start with / and contain one %%s for the city", s.SearchPath)
+	}
+	return nil
+}
+
+// ExposeURL returns the expose page of a listing.
+func (s Site) ExposeURL(is24ID string) string {
+	s = s.withDefaults()
+	return s.BaseURL + "/expose/" + is24ID
+}
+
+// CitySearchURL returns the search page for a city slug.
+func (s Site) CitySearchURL(city string) string {
+	s = s.withDefaults()
+	return s.BaseURL + fmt.Sprintf(s.SearchPath, city)
+}
+
+// CookieDomain is the domain the session cookie is set on, covering the
+// site's subdomains (".immobilienscout24.at").
+func (s Site) CookieDomain() string {
+	u, err := url.Parse(s.withDefaults().BaseURL)
+	if err != nil {
+		return ""
+	}
+	return "." + strings.TrimPrefix(u.Hostname(), "www.")
+}
@@ -0,0 +1,49 @@
+package is24
+
+import "testing"
+
+func TestSite(t *testing.T) {
+	at := Site{BaseURL: "https://www.immobilienscout24.at/", SearchPath: "/regional/%s/wohnung-mieten"}.withDefaults()
+	if err := at.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := at.ExposeURL("7"); got != "https://www.immobilienscout24.at/expose/7" {
+		t.Errorf("ExposeURL = %q", got)
+	}
+	if got := at.CitySearchURL("wien"); got != "https://www.immobilienscout24.at/regional/wien/wohnung-mieten" {
+		t.Errorf("CitySearchURL = %q", got)
+	}
+	if got := at.CookieDomain(); got != ".immobilienscout24.at" {
+		t.Errorf("CookieDomain = %q", got)
+	}
+	if got := (Site{}).withDefaults(); got != DefaultSite {
+		t.Errorf("empty site = %+v, want the default", got)
+	}
+
+	for _, bad := range []Site{
+		{BaseURL: "www.immobilienscout24.at"},
+		{SearchPath: "/Suche/wohnung-mieten"},
+		{SearchPath: "/Suche/%s/%s"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v should be invalid", bad)
+		}
+	}
+}
+
+func TestSetSiteResolvesListingURLs(t *testing.T) {
+	c := NewBrowserClient("", nil, "")
+	if err := c.SetSite(Site{BaseURL: "https://www.immobilienscout24.at"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.site.SearchPath != DefaultSite.SearchPath {
+		t.Errorf("empty search path should keep the default, got %q", c.site.SearchPath)
+	}
+	l, err := c.parser.ParseExpose([]byte(`<html><h1 id="expose-title">Wohnung</h1></html>`), "99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.URL != "https://www.immobilienscout24.at/expose/99" {
+		t.Errorf("listing URL = %q", l.URL)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/julianbeese/immo_bot/internal/antidetect"
 	"github.com/julianbeese/immo_bot/internal/config"
 	"github.com/julianbeese/immo_bot/internal/control"
 	"github.com/julianbeese/immo_bot/internal/domain"
@@ -46,6 +47,7 @@ type Server struct {
 	cfg       *config.Config
 	stats     StatsFunc
 	setCookie CookieSetter
+	rate      *antidetect.RateLimiter // nil = no request budget metrics
 	logger    *slog.Logger
 }
 
@@ -54,6 +56,11 @@ func New(repo *sqlite.Repository, ctrl *control.Controller, cfg *config.Config,
 	return &Server{repo: repo, ctrl: ctrl, cfg: cfg, stats: stats, setCookie: setCookie, logger: logger}
 }
 
+// SetRateLimiter makes the overview report the IS24 request budget metrics.
+func (s *Server) SetRateLimiter(rl *antidetect.RateLimiter) {
+	s.rate = rl
+}
+
 // Handler returns the dashboard's HTTP routes.
 func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
@@ -120,6 +127,18 @@ func (s *Server) handleOverview(w http.ResponseWriter, r *http.Request) {
 			"contacted": contacted,
 		},
 	}
+	if s.rate != nil {
+		rs := s.rate.Stats()
+		resp["rate"] = map[string]any{
+			"per_minute":     rs.PerMinute,
+			"last_minute":    rs.LastMinute,
+			"requests":       rs.Requests,
+			"blocked":        rs.Blocked,
+			"saturation":     rs.Saturation(),
+			"waited_seconds": int(rs.Waited.Seconds()),
+			"slowdown":       rs.Slowdown,
+		}
+	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
@@ -266,6 +285,13 @@ func (s *Server) handleSaveCampaign(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, err)
 		return
 	}
+	// A broken template would fail every message; reject it before saving anything.
+	if body.Template != nil {
+		if err := messenger.ValidateTemplate(strings.TrimSpace(*body.Template)); err != nil {
+			writeErr(w, http.StatusBadRequest, err)
+			return
+		}
+	}
 	// An empty value clears the override (scheduler then falls back to config).
 	if body.AIPrompt != nil {
 		if err := s.repo.SetMeta(r.Context(), sqlite.CampaignPromptKey(name), strings.TrimSpace(*body.AIPrompt)); err != nil {
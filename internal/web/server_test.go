@@ -116,6 +116,19 @@ func TestAddProfileValidatesCampaign(t *testing.T) {
 	}
 }
 
+func TestSaveCampaignRejectsBrokenTemplate(t *testing.T) {
+	s, _ := newTestServer(t)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest("POST", "/api/campaigns/wg",
+		strings.NewReader(`{"ai_prompt":"neu","template":"Wohnung in {{.Distrct}}"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("misspelled field should 400, got %d", rec.Code)
+	}
+	if v, _ := s.repo.GetMeta(context.Background(), sqlite.CampaignPromptKey("wg")); v != "" {
+		t.Errorf("rejected request must not save the prompt, got %q", v)
+	}
+}
+
 func TestIndexServed(t *testing.T) {
 	s, _ := newTestServer(t)
 	rec := httptest.NewRecorder()